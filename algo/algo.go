@@ -0,0 +1,378 @@
+/*
+算法交易，在任意SpotAPI上按TWAP、VWAP、冰山、POV等算法拆分订单执行。
+每个算法用Start开始，Pause暂停下新的子订单，Resume恢复，Stop停止并撤销未成交的子订单，
+Progress返回执行进度，Done在算法结束时关闭。
+*/
+package algo
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/betterjun/exapi"
+	"math"
+	"sync"
+	"time"
+)
+
+// 算法状态
+type AlgoStatus int
+
+const (
+	ALGO_PENDING  AlgoStatus = iota // 未开始
+	ALGO_RUNNING                    // 执行中
+	ALGO_PAUSED                     // 已暂停
+	ALGO_STOPPED                    // 已停止，未完成目标数量
+	ALGO_FINISHED                   // 已完成目标数量或执行时间结束
+	ALGO_FAILED                     // 出错结束
+)
+
+func (s AlgoStatus) String() string {
+	switch s {
+	case ALGO_PENDING:
+		return "PENDING"
+	case ALGO_RUNNING:
+		return "RUNNING"
+	case ALGO_PAUSED:
+		return "PAUSED"
+	case ALGO_STOPPED:
+		return "STOPPED"
+	case ALGO_FINISHED:
+		return "FINISHED"
+	case ALGO_FAILED:
+		return "FAILED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// 算法的公共参数
+type Params struct {
+	Pair       CurrencyPair // 交易对
+	Side       TradeSide    // 交易方向，BUY或SELL
+	Amount     float64      // 目标数量，基础币种
+	LimitPrice float64      // 保护价，买入不高于此价格，卖出不低于此价格，0表示不限制
+}
+
+// 执行进度
+type Progress struct {
+	Status     AlgoStatus `json:"status"`             // 算法状态
+	Amount     float64    `json:"amount,string"`      // 目标数量
+	DealAmount float64    `json:"deal_amount,string"` // 已成交数量
+	AvgPrice   float64    `json:"avg_price,string"`   // 成交均价
+	Fee        float64    `json:"fee,string"`         // 手续费
+	Orders     int        `json:"orders"`             // 已下的子订单数
+	OpenOrders int        `json:"open_orders"`        // 未结束的子订单数
+	Err        error      `json:"-"`                  // 最近一次的错误
+}
+
+// 执行算法
+type Algo interface {
+	// 开始执行
+	Start() error
+	// 暂停，不再下新的子订单，已下的子订单保留
+	Pause() error
+	// 恢复执行
+	Resume() error
+	// 停止执行，撤销未成交的子订单
+	Stop() error
+	// 执行进度
+	Progress() Progress
+	// 算法结束时关闭
+	Done() <-chan struct{}
+}
+
+var ErrorAlgoState = errors.New("invalid algo state")
+
+/*
+算法的公共部分：管理状态和子订单，统计成交。
+子订单为限价单，价格取对手方最优价，并受保护价限制。
+*/
+type engine struct {
+	sync.Mutex
+	api     SpotAPI
+	params  Params
+	setting SymbolSetting
+	status  AlgoStatus
+	orders  map[string]*Order // 所有子订单的最新状态
+	count   int
+	err     error
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func newEngine(api SpotAPI, params Params) *engine {
+	return &engine{
+		api:    api,
+		params: params,
+		orders: make(map[string]*Order),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// 检查参数，获取交易对信息后在新的goroutine中执行run
+func (e *engine) start(run func()) error {
+	e.Lock()
+	if e.status != ALGO_PENDING {
+		e.Unlock()
+		return ErrorAlgoState
+	}
+	e.Unlock()
+
+	if e.params.Side != BUY && e.params.Side != SELL {
+		return fmt.Errorf("unsupported TradeSide:%v", e.params.Side)
+	}
+	if e.params.Amount <= 0 {
+		return fmt.Errorf("invalid amount:%v", e.params.Amount)
+	}
+	ssm, err := e.api.GetAllCurrencyPair()
+	if err != nil {
+		return err
+	}
+	setting, ok := ssm[e.params.Pair.ToSymbol("/")]
+	if !ok {
+		return ErrorAssetNotFound
+	}
+
+	e.Lock()
+	e.setting = setting
+	e.status = ALGO_RUNNING
+	e.Unlock()
+
+	// 子订单只在run中下单，run返回后再撤销未成交的子订单，避免遗漏正在下的子订单
+	go func() {
+		run()
+		e.finish(ALGO_FINISHED, nil)
+		e.cancelOpen()
+		close(e.done)
+	}()
+	return nil
+}
+
+func (e *engine) Pause() error {
+	e.Lock()
+	defer e.Unlock()
+	if e.status != ALGO_RUNNING {
+		return ErrorAlgoState
+	}
+	e.status = ALGO_PAUSED
+	return nil
+}
+
+func (e *engine) Resume() error {
+	e.Lock()
+	defer e.Unlock()
+	if e.status != ALGO_PAUSED {
+		return ErrorAlgoState
+	}
+	e.status = ALGO_RUNNING
+	return nil
+}
+
+func (e *engine) Stop() error {
+	e.Lock()
+	if e.status != ALGO_RUNNING && e.status != ALGO_PAUSED {
+		e.Unlock()
+		return ErrorAlgoState
+	}
+	e.Unlock()
+
+	e.finish(ALGO_STOPPED, nil)
+	<-e.done
+	return nil
+}
+
+func (e *engine) Done() <-chan struct{} {
+	return e.done
+}
+
+func (e *engine) Progress() Progress {
+	e.Lock()
+	defer e.Unlock()
+
+	p := Progress{Status: e.status, Amount: e.params.Amount, Orders: e.count, Err: e.err}
+	var value float64
+	for _, o := range e.orders {
+		p.DealAmount += o.DealAmount
+		value += o.DealAmount * o.AvgPrice
+		p.Fee += o.Fee
+		if !o.Status.IsFinal() {
+			p.OpenOrders++
+		}
+	}
+	if p.DealAmount > 0 {
+		p.AvgPrice = value / p.DealAmount
+	}
+	return p
+}
+
+// 通知run结束算法，只有第一次调用生效，未成交的子订单在run返回后撤销
+func (e *engine) finish(status AlgoStatus, err error) {
+	e.Lock()
+	if e.status == ALGO_STOPPED || e.status == ALGO_FINISHED || e.status == ALGO_FAILED {
+		e.Unlock()
+		return
+	}
+	e.status = status
+	if err != nil {
+		e.status, e.err = ALGO_FAILED, err
+	}
+	close(e.stop)
+	e.Unlock()
+}
+
+// 等待d，期间算法结束时返回false
+func (e *engine) sleep(d time.Duration) bool {
+	if d <= 0 {
+		d = time.Millisecond
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-e.stop:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (e *engine) stopped() bool {
+	select {
+	case <-e.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (e *engine) paused() bool {
+	e.Lock()
+	defer e.Unlock()
+	return e.status == ALGO_PAUSED
+}
+
+// 更新未结束的子订单
+func (e *engine) refresh() {
+	for _, o := range e.openOrders() {
+		order, err := e.api.GetOrder(o.OrderID, e.params.Pair)
+		e.Lock()
+		if err != nil {
+			e.err = err
+		} else {
+			e.orders[o.OrderID] = order
+		}
+		e.Unlock()
+	}
+}
+
+// 撤销未结束的子订单，并更新其成交
+func (e *engine) cancelOpen() {
+	for _, o := range e.openOrders() {
+		if _, err := e.api.Cancel(o.OrderID, e.params.Pair); err != nil {
+			e.Lock()
+			e.err = err
+			e.Unlock()
+		}
+	}
+	e.refresh()
+}
+
+func (e *engine) openOrders() []Order {
+	e.Lock()
+	defer e.Unlock()
+	var orders []Order
+	for _, o := range e.orders {
+		if !o.Status.IsFinal() {
+			orders = append(orders, *o)
+		}
+	}
+	return orders
+}
+
+// 已成交和未结束子订单中未成交的数量
+func (e *engine) committed() (deal, open float64) {
+	e.Lock()
+	defer e.Unlock()
+	for _, o := range e.orders {
+		deal += o.DealAmount
+		if !o.Status.IsFinal() {
+			open += o.Amount - o.DealAmount
+		}
+	}
+	return deal, open
+}
+
+// 剩余未成交的数量
+func (e *engine) remaining() float64 {
+	deal, _ := e.committed()
+	return math.Max(e.params.Amount-deal, 0)
+}
+
+// 数量是否低于交易所的最小下单量，低于时无法再下单
+func (e *engine) tooSmall(amount, price float64) bool {
+	amount = e.setting.TruncateAmount(amount)
+	return amount <= 0 || amount < e.setting.MinSize || amount*price < e.setting.MinNotional
+}
+
+/*
+以price下数量为amount的子订单，price为0时取对手方最优价。
+价格超出保护价时以保护价下单，数量低于最小下单量时不下单。
+买单价格向下、卖单价格向上取整到最小变动单位，不会越过保护价。
+*/
+func (e *engine) place(amount, price float64) error {
+	if price <= 0 {
+		ticker, err := e.api.GetTicker(e.params.Pair)
+		if err != nil {
+			return e.fail(err)
+		}
+		price = ticker.Sell
+		if e.params.Side == SELL {
+			price = ticker.Buy
+		}
+	}
+	if limit := e.params.LimitPrice; limit > 0 {
+		if e.params.Side == BUY {
+			price = math.Min(price, limit)
+		} else {
+			price = math.Max(price, limit)
+		}
+	}
+	if price <= 0 || e.tooSmall(amount, price) {
+		return nil
+	}
+
+	tick := PrecisionOf(e.setting.PriceTick())
+	p := FloatToString(e.setting.CeilPrice(price), tick)
+	if e.params.Side == BUY {
+		p = FloatToString(e.setting.FloorPrice(price), tick)
+	}
+	a := e.setting.FormatAmount(amount)
+
+	var order *Order
+	var err error
+	if e.params.Side == BUY {
+		order, err = e.api.LimitBuy(e.params.Pair, p, a)
+	} else {
+		order, err = e.api.LimitSell(e.params.Pair, p, a)
+	}
+	if err != nil {
+		return e.fail(err)
+	}
+	if order.Amount == 0 {
+		order.Amount = ToFloat64(a)
+	}
+
+	e.Lock()
+	e.orders[order.OrderID] = order
+	e.count++
+	e.Unlock()
+	return nil
+}
+
+// 记录错误，不结束算法，由下一次调度重试
+func (e *engine) fail(err error) error {
+	e.Lock()
+	e.err = err
+	e.Unlock()
+	return err
+}
@@ -0,0 +1,198 @@
+package algo
+
+import (
+	"fmt"
+	. "github.com/betterjun/exapi"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockSpotAPI struct {
+	SpotAPI
+	sync.Mutex
+	fill   float64 // 下单后立即成交的比例
+	orders map[string]*Order
+	prices []float64
+}
+
+func newMockSpotAPI(fill float64) *mockSpotAPI {
+	return &mockSpotAPI{fill: fill, orders: make(map[string]*Order)}
+}
+
+func (api *mockSpotAPI) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
+	return map[string]SymbolSetting{"BTC/USDT": {StepSize: 0.0001, TickSize: 0.01, MinSize: 0.001}}, nil
+}
+
+func (api *mockSpotAPI) GetTicker(pair CurrencyPair) (*Ticker, error) {
+	return &Ticker{Buy: 99, Sell: 101}, nil
+}
+
+func (api *mockSpotAPI) LimitBuy(pair CurrencyPair, price, amount string) (*Order, error) {
+	api.Lock()
+	defer api.Unlock()
+	o := &Order{OrderID: fmt.Sprint(len(api.orders)), Price: ToFloat64(price), Amount: ToFloat64(amount), Side: BUY}
+	o.DealAmount, o.AvgPrice = o.Amount*api.fill, o.Price
+	o.Status = ORDER_PART_FINISH
+	if api.fill >= 1 {
+		o.Status = ORDER_FINISH
+	}
+	api.orders[o.OrderID] = o
+	api.prices = append(api.prices, o.Price)
+	copied := *o
+	return &copied, nil
+}
+
+func (api *mockSpotAPI) GetOrder(orderId string, pair CurrencyPair) (*Order, error) {
+	api.Lock()
+	defer api.Unlock()
+	o := *api.orders[orderId]
+	return &o, nil
+}
+
+func (api *mockSpotAPI) Cancel(orderId string, pair CurrencyPair) (bool, error) {
+	api.Lock()
+	defer api.Unlock()
+	api.orders[orderId].Status = ORDER_CANCEL
+	return true, nil
+}
+
+func (api *mockSpotAPI) GetKlineRecords(pair CurrencyPair, period KlinePeriod, size, since int) ([]Kline, error) {
+	return nil, nil
+}
+
+type mockWebsocket struct {
+	SpotWebsocket
+	cb func([]Trade) error
+}
+
+func (ws *mockWebsocket) SubTrade(pair CurrencyPair, cb func([]Trade) error) error {
+	ws.cb = cb
+	return nil
+}
+
+var testParams = Params{Pair: NewCurrencyPairFromString("BTC/USDT"), Side: BUY, Amount: 8, LimitPrice: 100}
+
+func TestTWAP(t *testing.T) {
+	api := newMockSpotAPI(0.5)
+	a := NewTWAP(api, testParams, 40*time.Millisecond, 4)
+	assert.Nil(t, a.Start())
+	assert.Equal(t, ErrorAlgoState, a.Start())
+	<-a.Done()
+
+	p := a.Progress()
+	assert.Equal(t, ALGO_FINISHED, p.Status)
+	assert.Equal(t, 4, p.Orders)
+	assert.Equal(t, 0, p.OpenOrders)
+	// 每次成交一半，剩余数量分配到后面的时间片：2、7/3、5.8333/2、4.375，按精度截断
+	assert.InDelta(t, (2+2.3333+2.9166+4.375)*0.5, p.DealAmount, 1e-9)
+	// 对手价101超过保护价，以保护价下单
+	for _, price := range api.prices {
+		assert.Equal(t, 100.0, price)
+	}
+}
+
+func TestTWAPStop(t *testing.T) {
+	api := newMockSpotAPI(0)
+	a := NewTWAP(api, testParams, time.Hour, 4)
+	assert.Nil(t, a.Start())
+	time.Sleep(10 * time.Millisecond)
+	assert.Nil(t, a.Pause())
+	assert.Equal(t, ALGO_PAUSED, a.Progress().Status)
+	assert.Nil(t, a.Resume())
+	assert.Nil(t, a.Stop())
+
+	p := a.Progress()
+	assert.Equal(t, ALGO_STOPPED, p.Status)
+	assert.Equal(t, 1, p.Orders)
+	assert.Equal(t, 0, p.OpenOrders)
+	assert.Equal(t, ErrorAlgoState, a.Stop())
+}
+
+func TestPlaceTick(t *testing.T) {
+	// 保护价不是最小变动单位的整数倍时，买单向下取整，不会越过保护价
+	api := newMockSpotAPI(1)
+	params := testParams
+	params.LimitPrice = 100.009
+	a := NewIceberg(api, params, 8, time.Millisecond)
+	assert.Nil(t, a.Start())
+	<-a.Done()
+	assert.Equal(t, []float64{100}, api.prices)
+}
+
+func TestIceberg(t *testing.T) {
+	api := newMockSpotAPI(1)
+	a := NewIceberg(api, testParams, 3, time.Millisecond)
+	assert.Nil(t, a.Start())
+	<-a.Done()
+
+	p := a.Progress()
+	assert.Equal(t, ALGO_FINISHED, p.Status)
+	assert.Equal(t, 3, p.Orders)
+	assert.InDelta(t, 8, p.DealAmount, 1e-9)
+}
+
+func TestPOV(t *testing.T) {
+	api := newMockSpotAPI(1)
+	ws := &mockWebsocket{}
+	a := NewPOV(api, ws, testParams, 0.5, time.Millisecond)
+	assert.Nil(t, a.Start())
+
+	// 其他交易对的成交被忽略
+	ws.cb([]Trade{{Market: NewCurrencyPairFromString("ETH/USDT"), Amount: 100}})
+	ws.cb([]Trade{{Market: testParams.Pair, Amount: 4}})
+	assert.Eventually(t, func() bool { return a.Progress().DealAmount == 2 }, time.Second, time.Millisecond)
+	ws.cb([]Trade{{Market: testParams.Pair, Amount: 20}})
+	<-a.Done()
+
+	p := a.Progress()
+	assert.Equal(t, ALGO_FINISHED, p.Status)
+	assert.InDelta(t, 8, p.DealAmount, 1e-9)
+	assert.Nil(t, ws.cb)
+}
+
+type rangeSpotAPI struct {
+	*mockSpotAPI
+	start, end time.Time
+}
+
+func (api *rangeSpotAPI) GetKlineRange(pair CurrencyPair, period KlinePeriod, start, end time.Time) ([]Kline, []KlineGap, error) {
+	api.start, api.end = start, end
+	return nil, nil, nil
+}
+
+type capsSpotAPI struct {
+	*mockSpotAPI
+}
+
+func (api *capsSpotAPI) Capabilities() *Capabilities {
+	return &Capabilities{MaxKlineSize: 1000}
+}
+
+func TestVWAPHistory(t *testing.T) {
+	// 实现了KlineRangeAPI时按区间获取lookbackDays天的K线
+	api := &rangeSpotAPI{mockSpotAPI: newMockSpotAPI(1)}
+	a := NewVWAP(api, testParams, time.Hour, KLINE_M1, 3)
+	now := time.Now()
+	_, err := a.history(now)
+	assert.Nil(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -3), api.start)
+	assert.Equal(t, now, api.end)
+
+	// 所需条数超过MaxKlineSize时返回错误，3天的1分钟K线为4320条
+	_, err = NewVWAP(&capsSpotAPI{newMockSpotAPI(1)}, testParams, time.Hour, KLINE_M1, 3).history(now)
+	assert.NotNil(t, err)
+	_, err = NewVWAP(&capsSpotAPI{newMockSpotAPI(1)}, testParams, time.Hour, KLINE_H1, 3).history(now)
+	assert.Nil(t, err)
+}
+
+func TestVolumeProfile(t *testing.T) {
+	klines := []Kline{
+		{TS: 0, Vol: 10}, {TS: 60, Vol: 20},
+		{TS: 86400, Vol: 30}, {TS: 86460, Vol: 40},
+	}
+	weights := VolumeProfile(klines, KLINE_M1, time.Unix(2*86400+30, 0), 3)
+	assert.Equal(t, []float64{20, 30, 25}, weights)
+	assert.Equal(t, []float64{1, 1}, VolumeProfile(nil, KLINE_M1, time.Unix(0, 0), 2))
+}
@@ -0,0 +1,44 @@
+package algo
+
+import (
+	"fmt"
+	. "github.com/betterjun/exapi"
+	"math"
+	"time"
+)
+
+/*
+冰山算法，以Params.LimitPrice挂单，每次只显示display数量。
+当前子订单完全成交后再挂下一笔，直到完成目标数量，每隔interval检查一次子订单状态。
+*/
+type Iceberg struct {
+	*engine
+	display  float64
+	interval time.Duration
+}
+
+func NewIceberg(api SpotAPI, params Params, display float64, interval time.Duration) *Iceberg {
+	return &Iceberg{engine: newEngine(api, params), display: display, interval: interval}
+}
+
+func (a *Iceberg) Start() error {
+	if a.params.LimitPrice <= 0 || a.display <= 0 {
+		return fmt.Errorf("invalid iceberg price:%v or display amount:%v", a.params.LimitPrice, a.display)
+	}
+	return a.start(func() {
+		for !a.stopped() {
+			a.refresh()
+			deal, open := a.committed()
+			remaining := a.params.Amount - deal
+			if remaining <= 0 || (open == 0 && a.tooSmall(remaining, a.params.LimitPrice)) {
+				return
+			}
+			if open == 0 && !a.paused() {
+				a.place(math.Min(a.display, remaining), a.params.LimitPrice)
+			}
+			if !a.sleep(a.interval) {
+				return
+			}
+		}
+	})
+}
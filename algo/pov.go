@@ -0,0 +1,78 @@
+package algo
+
+import (
+	"fmt"
+	. "github.com/betterjun/exapi"
+	"math"
+	"sync"
+	"time"
+)
+
+/*
+按成交量比例算法，通过SubTrade统计开始后的市场成交量，使累计成交量保持为市场成交量的rate倍。
+每隔interval检查一次，落后于目标时以对手方最优价下单补足，直到完成目标数量。
+SpotWsBase每个连接只保存一个成交回调：订阅新的交易对会替换所有交易对的回调，重复订阅已订阅的交易对不会设置回调，
+因此ws不能与其他订阅成交的算法共用，停止时取消订阅。
+*/
+type POV struct {
+	*engine
+	ws       SpotWebsocket
+	rate     float64
+	interval time.Duration
+
+	volLock sync.Mutex
+	volume  float64 // 开始后的市场成交量
+}
+
+func NewPOV(api SpotAPI, ws SpotWebsocket, params Params, rate float64, interval time.Duration) *POV {
+	return &POV{engine: newEngine(api, params), ws: ws, rate: rate, interval: interval}
+}
+
+// 处理推送的成交，统计市场成交量，可作为SubTrade的回调，其他交易对的成交被忽略
+func (a *POV) OnTrades(trades []Trade) error {
+	a.volLock.Lock()
+	for _, t := range trades {
+		if t.Market.Equal(a.params.Pair) {
+			a.volume += t.Amount
+		}
+	}
+	a.volLock.Unlock()
+	return nil
+}
+
+// 开始后的市场成交量
+func (a *POV) MarketVolume() float64 {
+	a.volLock.Lock()
+	defer a.volLock.Unlock()
+	return a.volume
+}
+
+func (a *POV) Start() error {
+	if a.rate <= 0 || a.rate > 1 {
+		return fmt.Errorf("invalid participation rate:%v", a.rate)
+	}
+	if err := a.ws.SubTrade(a.params.Pair, a.OnTrades); err != nil {
+		return err
+	}
+	err := a.start(func() {
+		defer a.ws.SubTrade(a.params.Pair, nil)
+		for !a.stopped() {
+			a.refresh()
+			deal, open := a.committed()
+			if deal >= a.params.Amount {
+				return
+			}
+			target := math.Min(a.MarketVolume()*a.rate, a.params.Amount)
+			if lag := target - deal - open; lag > 0 && !a.paused() {
+				a.place(lag, 0)
+			}
+			if !a.sleep(a.interval) {
+				return
+			}
+		}
+	})
+	if err != nil {
+		a.ws.SubTrade(a.params.Pair, nil)
+	}
+	return err
+}
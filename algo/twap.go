@@ -0,0 +1,69 @@
+package algo
+
+import (
+	. "github.com/betterjun/exapi"
+	"time"
+)
+
+/*
+时间加权平均价格算法，在duration内平均分成slices次下单。
+每次下单前撤销上一次未成交的部分，剩余数量平均分配到剩下的次数中。
+*/
+type TWAP struct {
+	*engine
+	duration time.Duration
+	slices   int
+}
+
+func NewTWAP(api SpotAPI, params Params, duration time.Duration, slices int) *TWAP {
+	if slices < 1 {
+		slices = 1
+	}
+	return &TWAP{engine: newEngine(api, params), duration: duration, slices: slices}
+}
+
+func (a *TWAP) Start() error {
+	weights := make([]float64, a.slices)
+	for i := range weights {
+		weights[i] = 1
+	}
+	return a.start(func() {
+		runSchedule(a.engine, weights, a.duration/time.Duration(a.slices))
+	})
+}
+
+/*
+按权重在每个时间片下单，每个时间片下剩余数量乘以当前权重占剩余权重的比例。
+时间片开始时撤销上一个时间片未成交的子订单，暂停时跳过该时间片。
+*/
+func runSchedule(e *engine, weights []float64, interval time.Duration) {
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+
+	for i, w := range weights {
+		if e.stopped() {
+			return
+		}
+		e.cancelOpen()
+
+		remaining := e.remaining()
+		if remaining <= 0 {
+			return
+		}
+		if !e.paused() && total > 0 {
+			e.place(remaining*w/total, 0)
+		}
+		total -= w
+
+		if i < len(weights)-1 && !e.sleep(interval) {
+			return
+		}
+	}
+
+	// 最后一个时间片结束后撤销未成交的部分
+	if e.sleep(interval) {
+		e.cancelOpen()
+	}
+}
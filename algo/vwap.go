@@ -0,0 +1,99 @@
+package algo
+
+import (
+	"fmt"
+	. "github.com/betterjun/exapi"
+	"time"
+)
+
+/*
+成交量加权平均价格算法，按历史成交量分布在duration内下单。
+每个时间片为一个period，权重为最近lookbackDays天中同一时刻K线的平均成交量，没有历史数据时按TWAP平均分配。
+api实现了KlineRangeAPI时用GetKlineRange分页获取历史K线，否则用GetKlineRecords获取，
+所需条数超过Capabilities的MaxKlineSize时返回错误。
+*/
+type VWAP struct {
+	*engine
+	duration     time.Duration
+	period       KlinePeriod
+	lookbackDays int
+}
+
+func NewVWAP(api SpotAPI, params Params, duration time.Duration, period KlinePeriod, lookbackDays int) *VWAP {
+	if lookbackDays < 1 {
+		lookbackDays = 1
+	}
+	return &VWAP{engine: newEngine(api, params), duration: duration, period: period, lookbackDays: lookbackDays}
+}
+
+func (a *VWAP) Start() error {
+	sec := a.period.Seconds()
+	if sec == 0 || sec > 86400 {
+		return ErrorUnsupported
+	}
+	interval := time.Duration(sec) * time.Second
+	slices := int(a.duration / interval)
+	if slices < 1 {
+		slices = 1
+	}
+
+	now := time.Now()
+	klines, err := a.history(now)
+	if err != nil {
+		return err
+	}
+	weights := VolumeProfile(klines, a.period, now, slices)
+
+	return a.start(func() {
+		runSchedule(a.engine, weights, interval)
+	})
+}
+
+// 获取now之前lookbackDays天的K线
+func (a *VWAP) history(now time.Time) ([]Kline, error) {
+	if r, ok := a.api.(KlineRangeAPI); ok {
+		klines, _, err := r.GetKlineRange(a.params.Pair, a.period, now.AddDate(0, 0, -a.lookbackDays), now)
+		return klines, err
+	}
+
+	size := a.lookbackDays * int(86400/a.period.Seconds())
+	if c, ok := a.api.(CapabilitiesAPI); ok {
+		if max := c.Capabilities().MaxKlineSize; max > 0 && size > max {
+			return nil, fmt.Errorf("lookback %v days needs %v klines, exceeds MaxKlineSize:%v", a.lookbackDays, size, max)
+		}
+	}
+	return a.api.GetKlineRecords(a.params.Pair, a.period, size, 0)
+}
+
+/*
+按历史K线计算从start开始的slices个周期的成交量权重，权重为历史上同一时刻K线的平均成交量。
+没有对应时刻的历史数据时取所有K线的平均成交量，没有历史数据时权重均为1。
+*/
+func VolumeProfile(klines []Kline, period KlinePeriod, start time.Time, slices int) []float64 {
+	sec := period.Seconds()
+	sum := make(map[int64]float64)
+	count := make(map[int64]int)
+	var total float64
+	for _, k := range klines {
+		sum[k.TS%86400] += k.Vol
+		count[k.TS%86400]++
+		total += k.Vol
+	}
+
+	avg := 1.0
+	if len(klines) > 0 && total > 0 {
+		avg = total / float64(len(klines))
+	}
+
+	weights := make([]float64, slices)
+	ts := start.Unix() - start.Unix()%sec
+	for i := range weights {
+		key := (ts + int64(i)*sec) % 86400
+		if n := count[key]; n > 0 && sum[key] > 0 {
+			weights[i] = sum[key] / float64(n)
+		} else {
+			weights[i] = avg
+		}
+	}
+	return weights
+}
@@ -59,6 +59,18 @@ func (aofex *Aofex) GetURL() string {
 	return aofex.baseUrl
 }
 
+// 奥飞没有websocket接口
+var spotCapabilities = &Capabilities{
+	Exchange:           AOFEX,
+	UnsupportedMethods: []string{"GetUserTrades"},
+	KlinePeriods:       KlinePeriodsOf(_INERNAL_KLINE_PERIOD_CONVERTER),
+	OrderSides:         AllOrderSides,
+}
+
+func (aofex *Aofex) Capabilities() *Capabilities {
+	return spotCapabilities.Clone()
+}
+
 func (aofex *Aofex) getDataMap(reqUrl string) (map[string]interface{}, error) {
 	respmap, err := HttpGet(aofex.httpClient, reqUrl)
 	if err != nil {
@@ -105,6 +117,25 @@ func (aofex *Aofex) getDataArray(reqUrl string) ([]interface{}, error) {
 	return dataArr, nil
 }
 
+// aofex没有账户费率查询接口，返回币对公布的费率
+func (aofex *Aofex) GetTradeFee(pair CurrencyPair) (*TradeFee, error) {
+	tfm, err := aofex.GetTradeFeeMap()
+	if err != nil {
+		return nil, err
+	}
+
+	return TradeFeeOf(tfm, pair)
+}
+
+func (aofex *Aofex) GetTradeFeeMap() (map[string]TradeFee, error) {
+	ssm, err := aofex.GetAllCurrencyPair()
+	if err != nil {
+		return nil, err
+	}
+
+	return TradeFeeMapOf(ssm), nil
+}
+
 func (aofex *Aofex) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
 	url := aofex.baseUrl + "openApi/market/symbols"
 	dataArr, err := aofex.getDataArray(url)
@@ -131,19 +162,28 @@ func (aofex *Aofex) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
 		obj := v.(map[string]interface{})
 		symbol := strings.Replace(ToString(obj["symbol"]), "-", "/", -1)
 
+		minSize := ToFloat64(obj["min_size"])
+		minPrice := ToFloat64(obj["min_price"])
 		ssm[symbol] = SymbolSetting{
 			Symbol:   symbol,
 			Base:     strings.ToUpper(ToString(obj["base_currency"])),
 			Quote:    strings.ToUpper(ToString(obj["quote_currency"])),
-			MinSize:  ToFloat64(obj["min_size"]),
-			MinPrice: ToFloat64(obj["min_price"]),
+			MinSize:  minSize,
+			MinPrice: minPrice,
 			//MinNotional: ToFloat64(obj["minTrade"]),
 			MakerFee: ToFloat64(obj["maker_fee"]),
 			TakerFee: ToFloat64(obj["taker_fee"]),
+			// 奥飞没有单独的变动单位，最小值即为精度
+			TickSize:        minPrice,
+			StepSize:        minSize,
+			PricePrecision:  PrecisionOf(minPrice),
+			AmountPrecision: PrecisionOf(minSize),
+			MaxSize:         ToFloat64(obj["max_size"]),
+			MaxPrice:        ToFloat64(obj["max_price"]),
 		}
 	}
 
-	return ssm, nil
+	return CanonicalSymbolSettings(AOFEX, ssm), nil
 }
 
 func (aofex *Aofex) GetCurrencyStatus(currency Currency) (CurrencyStatus, error) {
@@ -196,7 +236,7 @@ func (aofex *Aofex) parseTicker(pair CurrencyPair, tickmap map[string]interface{
 }
 
 func (aofex *Aofex) GetTicker(pair CurrencyPair) (*Ticker, error) {
-	url := aofex.baseUrl + "openApi/market/detail?symbol=" + pair.ToSymbol("-")
+	url := aofex.baseUrl + "openApi/market/detail?symbol=" + ExchangeSymbol(AOFEX, pair, "-")
 
 	datamap, err := aofex.getDataMap(url)
 	if err != nil {
@@ -234,7 +274,7 @@ func (aofex *Aofex) GetAllTicker() ([]Ticker, error) {
 			continue
 		}
 
-		pair := NewCurrencyPairFromString(strings.Replace(symbol, "-", "/", -1))
+		pair := CanonicalPair(AOFEX, NewCurrencyPairFromString(strings.Replace(symbol, "-", "/", -1)))
 		t, err := aofex.parseTicker(pair, tickmap, ts)
 		if err != nil {
 			Error("parse ticker failed:%v", err)
@@ -247,7 +287,7 @@ func (aofex *Aofex) GetAllTicker() ([]Ticker, error) {
 }
 
 func (aofex *Aofex) GetDepth(pair CurrencyPair, size int, step int) (*Depth, error) {
-	url := aofex.baseUrl + "openApi/market/depth?symbol=" + pair.ToSymbol("-")
+	url := aofex.baseUrl + "openApi/market/depth?symbol=" + ExchangeSymbol(AOFEX, pair, "-")
 	datamap, err := aofex.getDataMap(url)
 	if err != nil {
 		return nil, err
@@ -261,7 +301,7 @@ func (aofex *Aofex) GetDepth(pair CurrencyPair, size int, step int) (*Depth, err
 }
 
 func (aofex *Aofex) GetTrades(pair CurrencyPair, size int) ([]Trade, error) {
-	url := aofex.baseUrl + "openApi/market/trade?symbol=" + pair.ToSymbol("-")
+	url := aofex.baseUrl + "openApi/market/trade?symbol=" + ExchangeSymbol(AOFEX, pair, "-")
 	datamap, err := aofex.getDataMap(url)
 	if err != nil {
 		return nil, err
@@ -300,10 +340,13 @@ func (aofex *Aofex) GetTrades(pair CurrencyPair, size int) ([]Trade, error) {
 func (aofex *Aofex) GetKlineRecords(pair CurrencyPair, period KlinePeriod, size, since int) ([]Kline, error) {
 	periodS, isOk := _INERNAL_KLINE_PERIOD_CONVERTER[period]
 	if isOk != true {
-		return nil, fmt.Errorf("unsupported %v KlinePeriod:%v", aofex.GetExchangeName(), period)
+		// 交易所不支持的周期，用较小周期的K线合成
+		return GetKlinesWithResample(spotCapabilities, func(p KlinePeriod, n, t int) ([]Kline, error) {
+			return aofex.GetKlineRecords(pair, p, n, t)
+		}, period, size, since)
 	}
 	url := aofex.baseUrl + "openApi/market/kline?symbol=%s&period=%v&size=%v"
-	symbol := pair.ToSymbol("-")
+	symbol := ExchangeSymbol(AOFEX, pair, "-")
 	datamap, err := aofex.getDataMap(fmt.Sprintf(url, symbol, periodS, size))
 	if err != nil {
 		return nil, err
@@ -339,6 +382,13 @@ func (aofex *Aofex) GetKlineRecords(pair CurrencyPair, period KlinePeriod, size,
 	return klines, nil
 }
 
+// 交易所只能查询最近的K线，范围早于可查询的K线时返回ErrorKlineRangeTooOld
+func (aofex *Aofex) GetKlineRange(pair CurrencyPair, period KlinePeriod, start, end time.Time) ([]Kline, []KlineGap, error) {
+	return GetLatestKlineRange(spotCapabilities, func(p KlinePeriod, n, t int) ([]Kline, error) {
+		return aofex.GetKlineRecords(pair, p, n, t)
+	}, period, start, end)
+}
+
 func (aofex *Aofex) LimitBuy(pair CurrencyPair, price, amount string) (*Order, error) {
 	orderId, err := aofex.placeOrder(amount, price, pair, "buy-limit")
 	if err != nil {
@@ -438,7 +488,7 @@ func (aofex *Aofex) GetOrder(orderId string, pair CurrencyPair) (*Order, error)
 func (aofex *Aofex) GetPendingOrders(pair CurrencyPair) ([]Order, error) {
 	requrl := aofex.baseUrl + "openApi/entrust/currentList"
 	params := map[string]string{}
-	params["symbol"] = pair.ToSymbol("-")
+	params["symbol"] = ExchangeSymbol(AOFEX, pair, "-")
 	params["limit"] = fmt.Sprint(100)
 
 	respmap, err := aofex.httpGet(requrl, params)
@@ -463,7 +513,7 @@ func (aofex *Aofex) GetPendingOrders(pair CurrencyPair) ([]Order, error) {
 func (aofex *Aofex) GetFinishedOrders(pair CurrencyPair) ([]Order, error) {
 	requrl := aofex.baseUrl + "openApi/entrust/historyList"
 	params := map[string]string{}
-	params["symbol"] = pair.ToSymbol("-")
+	params["symbol"] = ExchangeSymbol(AOFEX, pair, "-")
 	params["limit"] = fmt.Sprint(100)
 
 	respmap, err := aofex.httpGet(requrl, params)
@@ -549,7 +599,7 @@ func (aofex *Aofex) GetAccount() (*Account, error) {
 		*/
 		balancemap := v.(map[string]interface{})
 		currencySymbol := balancemap["currency"].(string)
-		currency := NewCurrency(currencySymbol)
+		currency := CanonicalCurrency(AOFEX, currencySymbol)
 
 		acc.SubAccounts[currency] = SubAccount{
 			Currency:     currency,
@@ -572,7 +622,7 @@ func (aofex *Aofex) placeOrder(amount, price string, pair CurrencyPair, orderTyp
 	*/
 	requrl := aofex.baseUrl + "openApi/entrust/add"
 	params := map[string]string{}
-	params["symbol"] = pair.ToSymbol("-")
+	params["symbol"] = ExchangeSymbol(AOFEX, pair, "-")
 	params["type"] = orderType
 	params["amount"] = amount
 	if strings.Contains(orderType, "limit") {
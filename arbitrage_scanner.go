@@ -0,0 +1,468 @@
+package exapi
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 跨交易所套利机会：在BuyExchange买入，同时在SellExchange卖出
+type ArbitrageOpportunity struct {
+	Market       CurrencyPair `json:"market"`             // 交易对
+	Symbol       string       `json:"symbol"`             // 交易对
+	BuyExchange  string       `json:"buy_exchange"`       // 买入的交易所
+	SellExchange string       `json:"sell_exchange"`      // 卖出的交易所
+	BuyPrice     float64      `json:"buy_price,string"`   // 买入均价，不含手续费
+	SellPrice    float64      `json:"sell_price,string"`  // 卖出均价，不含手续费
+	Amount       float64      `json:"amount,string"`      // 可执行的数量，没有深度数据时为0
+	Profit       float64      `json:"profit,string"`      // 扣除双方taker手续费后的利润，报价币种，没有深度数据时为单位数量的利润
+	ProfitRate   float64      `json:"profit_rate,string"` // 利润率，利润除以买入成本
+	Transferable bool         `json:"transferable"`       // 基础币种可从买入交易所转到卖出交易所，报价币种可以转回
+	TS           int64        `json:"ts"`                 // 发现时间，单位为毫秒(millisecond)
+}
+
+type arbitrageVenue struct {
+	setting  SymbolSetting
+	status   map[string]CurrencyStatus // 为nil时表示交易所未提供充提信息
+	ticker   *Ticker
+	tickerAt time.Time
+	depth    *Depth
+	depthAt  time.Time
+	api      SpotAPI
+	ws       SpotWebsocket
+}
+
+// 最优买卖价，取最近更新的行情或深度
+func (v *arbitrageVenue) best(now time.Time, maxAge time.Duration) (bid, ask float64, ok bool) {
+	depthOk := v.hasDepth(now, maxAge) && len(v.depth.AskList) > 0 && len(v.depth.BidList) > 0
+	tickerOk := fresh(v.tickerAt, now, maxAge) && v.ticker.Buy > 0 && v.ticker.Sell > 0
+	switch {
+	case depthOk && (!tickerOk || !v.tickerAt.After(v.depthAt)):
+		return v.depth.BidList[0].Price, v.depth.AskList[0].Price, true
+	case tickerOk:
+		return v.ticker.Buy, v.ticker.Sell, true
+	default:
+		return 0, 0, false
+	}
+}
+
+func (v *arbitrageVenue) hasDepth(now time.Time, maxAge time.Duration) bool {
+	return fresh(v.depthAt, now, maxAge)
+}
+
+func fresh(t, now time.Time, maxAge time.Duration) bool {
+	return !t.IsZero() && (maxAge <= 0 || now.Sub(t) <= maxAge)
+}
+
+/*
+跨交易所套利扫描。
+跟踪同一交易对在多个交易所的行情和深度，任一交易所更新时计算所有交易所两两之间的价差，
+扣除双方taker手续费后利润率不低于最小利润率时回调cb。
+可执行数量按双方深度逐档计算，并受交易对的最小、最大下单量和数量精度限制。
+手续费取SymbolSetting中的TakerFee，充提状态取GetAllCurrencyStatus，用于判断能否在交易所之间转移资产。
+*/
+type ArbitrageScanner struct {
+	sync.Mutex
+	pair            CurrencyPair
+	venues          map[string]*arbitrageVenue
+	minProfitRate   float64
+	maxAge          time.Duration
+	requireTransfer bool
+	cb              func(*ArbitrageOpportunity) error
+}
+
+func NewArbitrageScanner(pair CurrencyPair, cb func(*ArbitrageOpportunity) error) *ArbitrageScanner {
+	return &ArbitrageScanner{
+		pair:   pair,
+		venues: make(map[string]*arbitrageVenue),
+		cb:     cb,
+	}
+}
+
+// 设置最小利润率，默认为0，即扣除手续费后有利润即回调
+func (as *ArbitrageScanner) SetMinProfitRate(rate float64) {
+	as.Lock()
+	as.minProfitRate = rate
+	as.Unlock()
+}
+
+// 设置行情和深度的最长有效时间，超过该时间未更新的数据不参与计算，默认为0，即不过期
+func (as *ArbitrageScanner) SetMaxAge(d time.Duration) {
+	as.Lock()
+	as.maxAge = d
+	as.Unlock()
+}
+
+// 设置是否只回调可以在交易所之间转移资产的套利机会
+func (as *ArbitrageScanner) SetRequireTransferable(require bool) {
+	as.Lock()
+	as.requireTransfer = require
+	as.Unlock()
+}
+
+/*
+设置交易所的交易对信息和充提状态，status为nil表示未知。
+用Add添加交易所时会自动设置。
+*/
+func (as *ArbitrageScanner) SetVenue(exchange string, setting SymbolSetting, status map[string]CurrencyStatus) {
+	as.Lock()
+	v := as.venue(exchange)
+	v.setting = setting
+	v.status = status
+	as.Unlock()
+}
+
+/*
+添加交易所，用api获取交易对信息和充提状态，并订阅ws的行情和深度。
+交易所不支持GetAllCurrencyStatus时充提状态为未知。
+SpotWsBase每个连接只保存一个行情回调和一个深度回调：订阅新的交易对会替换所有交易对的回调，
+重复订阅已订阅的交易对不会设置回调。同一ws上有多个ArbitrageScanner时，应由一个回调分发给各个扫描器。
+*/
+func (as *ArbitrageScanner) Add(api SpotAPI, ws SpotWebsocket) error {
+	exchange := api.GetExchangeName()
+	ssm, err := api.GetAllCurrencyPair()
+	if err != nil {
+		return err
+	}
+	setting, ok := ssm[as.pair.ToSymbol("/")]
+	if !ok {
+		return ErrorAssetNotFound
+	}
+	status, err := api.GetAllCurrencyStatus()
+	if err != nil {
+		status = nil
+	}
+	as.SetVenue(exchange, setting, status)
+
+	err = ws.SubTicker(as.pair, func(ticker *Ticker) error {
+		return as.OnTicker(exchange, ticker)
+	})
+	if err != nil {
+		return err
+	}
+	err = ws.SubDepth(as.pair, func(depth *Depth) error {
+		return as.OnDepth(exchange, depth)
+	})
+	if err != nil {
+		return err
+	}
+
+	as.Lock()
+	v := as.venue(exchange)
+	v.api, v.ws = api, ws
+	as.Unlock()
+	return nil
+}
+
+// 重新获取各交易所的充提状态
+func (as *ArbitrageScanner) RefreshCurrencyStatus() error {
+	as.Lock()
+	apis := make(map[string]SpotAPI)
+	for exchange, v := range as.venues {
+		if v.api != nil {
+			apis[exchange] = v.api
+		}
+	}
+	as.Unlock()
+
+	for exchange, api := range apis {
+		status, err := api.GetAllCurrencyStatus()
+		if err != nil {
+			if err == ErrorUnsupported {
+				continue
+			}
+			return err
+		}
+		as.Lock()
+		as.venue(exchange).status = status
+		as.Unlock()
+	}
+	return nil
+}
+
+// 取消所有交易所的行情和深度订阅
+func (as *ArbitrageScanner) Close() (err error) {
+	as.Lock()
+	var wss []SpotWebsocket
+	for _, v := range as.venues {
+		if v.ws != nil {
+			wss = append(wss, v.ws)
+			v.ws = nil
+		}
+	}
+	as.Unlock()
+
+	for _, ws := range wss {
+		if e := ws.SubTicker(as.pair, nil); e != nil {
+			err = e
+		}
+		if e := ws.SubDepth(as.pair, nil); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// 处理交易所推送的行情，可作为SubTicker的回调，其他交易对的行情被忽略
+func (as *ArbitrageScanner) OnTicker(exchange string, ticker *Ticker) error {
+	if !ticker.Market.Equal(as.pair) {
+		return nil
+	}
+
+	as.Lock()
+	v := as.venue(exchange)
+	v.ticker, v.tickerAt = ticker, time.Now()
+	opportunities := as.scan(exchange)
+	as.Unlock()
+
+	return as.emit(opportunities)
+}
+
+// 处理交易所推送的深度，可作为SubDepth的回调，其他交易对的深度被忽略
+func (as *ArbitrageScanner) OnDepth(exchange string, depth *Depth) error {
+	if !depth.Market.Equal(as.pair) {
+		return nil
+	}
+
+	as.Lock()
+	v := as.venue(exchange)
+	v.depth, v.depthAt = depth, time.Now()
+	opportunities := as.scan(exchange)
+	as.Unlock()
+
+	return as.emit(opportunities)
+}
+
+// 当前所有交易所之间的套利机会
+func (as *ArbitrageScanner) Scan() []ArbitrageOpportunity {
+	as.Lock()
+	defer as.Unlock()
+	return as.scan("")
+}
+
+func (as *ArbitrageScanner) venue(exchange string) *arbitrageVenue {
+	v, ok := as.venues[exchange]
+	if !ok {
+		v = &arbitrageVenue{}
+		as.venues[exchange] = v
+	}
+	return v
+}
+
+// 计算exchange与其他交易所之间的套利机会，exchange为空时计算所有交易所
+func (as *ArbitrageScanner) scan(exchange string) []ArbitrageOpportunity {
+	exchanges := make([]string, 0, len(as.venues))
+	for name := range as.venues {
+		exchanges = append(exchanges, name)
+	}
+	sort.Strings(exchanges)
+
+	var result []ArbitrageOpportunity
+	for _, buy := range exchanges {
+		for _, sell := range exchanges {
+			if buy == sell || (exchange != "" && buy != exchange && sell != exchange) {
+				continue
+			}
+			if op, ok := as.evaluate(buy, sell); ok {
+				result = append(result, op)
+			}
+		}
+	}
+	return result
+}
+
+func (as *ArbitrageScanner) evaluate(buy, sell string) (ArbitrageOpportunity, bool) {
+	now := time.Now()
+	bv, sv := as.venues[buy], as.venues[sell]
+	_, ask, ok := bv.best(now, as.maxAge)
+	if !ok {
+		return ArbitrageOpportunity{}, false
+	}
+	bid, _, ok := sv.best(now, as.maxAge)
+	if !ok {
+		return ArbitrageOpportunity{}, false
+	}
+
+	buyFee, sellFee := 1+bv.setting.TakerFee, 1-sv.setting.TakerFee
+	if bid*sellFee <= ask*buyFee {
+		return ArbitrageOpportunity{}, false
+	}
+
+	op := ArbitrageOpportunity{
+		Market:       as.pair,
+		Symbol:       as.pair.ToLowerSymbol("/"),
+		BuyExchange:  buy,
+		SellExchange: sell,
+		BuyPrice:     ask,
+		SellPrice:    bid,
+		Profit:       bid*sellFee - ask*buyFee,
+		ProfitRate:   (bid*sellFee - ask*buyFee) / (ask * buyFee),
+		Transferable: as.transferable(bv, sv),
+		TS:           now.UnixNano() / int64(time.Millisecond),
+	}
+
+	// 有深度时按深度计算可执行的数量，否则只给出单位利润
+	if bv.hasDepth(now, as.maxAge) && sv.hasDepth(now, as.maxAge) {
+		amount, cost, revenue := walkArbitrageDepth(bv.depth.AskList, sv.depth.BidList, buyFee, sellFee, math.Inf(1))
+		amount = as.limitAmount(amount, bv.setting, sv.setting)
+		amount, cost, revenue = walkArbitrageDepth(bv.depth.AskList, sv.depth.BidList, buyFee, sellFee, amount)
+		if amount <= 0 ||
+			!meetsMinimum(bv.setting, amount, cost) || !meetsMinimum(sv.setting, amount, revenue) {
+			return ArbitrageOpportunity{}, false
+		}
+		op.Amount = amount
+		op.BuyPrice = cost / amount
+		op.SellPrice = revenue / amount
+		op.Profit = revenue*sellFee - cost*buyFee
+		op.ProfitRate = op.Profit / (cost * buyFee)
+	}
+
+	if op.ProfitRate < as.minProfitRate || (as.requireTransfer && !op.Transferable) {
+		return ArbitrageOpportunity{}, false
+	}
+	return op, true
+}
+
+// 按交易对的最大下单量和数量精度限制数量
+func (as *ArbitrageScanner) limitAmount(amount float64, settings ...SymbolSetting) float64 {
+	for _, ss := range settings {
+		if ss.MaxSize > 0 && amount > ss.MaxSize {
+			amount = ss.MaxSize
+		}
+	}
+	for _, ss := range settings {
+		if ss.StepSize > 0 || ss.AmountPrecision > 0 {
+			amount = ss.TruncateAmount(amount)
+		}
+	}
+	return amount
+}
+
+func meetsMinimum(ss SymbolSetting, amount, value float64) bool {
+	return amount >= ss.MinSize && value >= ss.MinNotional
+}
+
+/*
+逐档匹配买入方的卖单和卖出方的买单，直到扣除手续费后没有利润或达到limit。
+返回数量、买入金额和卖出金额，金额不含手续费。
+*/
+func walkArbitrageDepth(asks, bids DepthRecords, buyFee, sellFee, limit float64) (amount, cost, revenue float64) {
+	i, j := 0, 0
+	var askLeft, bidLeft float64
+	if len(asks) > 0 {
+		askLeft = asks[0].Amount
+	}
+	if len(bids) > 0 {
+		bidLeft = bids[0].Amount
+	}
+
+	for i < len(asks) && j < len(bids) && amount < limit {
+		if bids[j].Price*sellFee <= asks[i].Price*buyFee {
+			break
+		}
+
+		q := math.Min(math.Min(askLeft, bidLeft), limit-amount)
+		amount += q
+		cost += q * asks[i].Price
+		revenue += q * bids[j].Price
+		askLeft -= q
+		bidLeft -= q
+
+		if askLeft <= 0 {
+			i++
+			if i < len(asks) {
+				askLeft = asks[i].Amount
+			}
+		}
+		if bidLeft <= 0 {
+			j++
+			if j < len(bids) {
+				bidLeft = bids[j].Amount
+			}
+		}
+	}
+	return amount, cost, revenue
+}
+
+// 基础币种能否从买入交易所转到卖出交易所，且报价币种能否从卖出交易所转回
+func (as *ArbitrageScanner) transferable(bv, sv *arbitrageVenue) bool {
+	return canTransfer(bv.status, sv.status, as.pair.Stock.Symbol()) &&
+		canTransfer(sv.status, bv.status, as.pair.Money.Symbol())
+}
+
+// 币种能否从from提币并充值到to，两边都提供链信息时要求有共同的可用链
+func canTransfer(from, to map[string]CurrencyStatus, currency string) bool {
+	fs, ok := from[strings.ToUpper(currency)]
+	if !ok || !fs.Withdraw {
+		return false
+	}
+	ts, ok := to[strings.ToUpper(currency)]
+	if !ok || !ts.Deposit {
+		return false
+	}
+	if len(fs.Chains) == 0 || len(ts.Chains) == 0 {
+		return true
+	}
+
+	for _, fc := range fs.Chains {
+		if !fc.Withdraw {
+			continue
+		}
+		if tc, ok := ts.GetChain(fc.Chain); ok && tc.Deposit {
+			return true
+		}
+	}
+	return false
+}
+
+func (as *ArbitrageScanner) emit(opportunities []ArbitrageOpportunity) error {
+	if as.cb == nil {
+		return nil
+	}
+	for i := range opportunities {
+		if err := as.cb(&opportunities[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+找出在至少minExchanges个交易所上市的交易对，ssms为各交易所GetAllCurrencyPair的结果。
+两两用IntersectSymbols求共同的交易对，minExchanges小于2时按2处理。
+返回交易对名称到交易所名称的映射，交易所名称按字母排序。
+*/
+func CommonSymbols(ssms map[string]map[string]SymbolSetting, minExchanges int) map[string][]string {
+	exchanges := make([]string, 0, len(ssms))
+	for exchange := range ssms {
+		exchanges = append(exchanges, exchange)
+	}
+	sort.Strings(exchanges)
+
+	listed := make(map[string]map[string]bool)
+	for i, a := range exchanges {
+		for _, b := range exchanges[i+1:] {
+			for symbol := range IntersectSymbols(ssms[a], ssms[b]) {
+				if listed[symbol] == nil {
+					listed[symbol] = make(map[string]bool)
+				}
+				listed[symbol][a], listed[symbol][b] = true, true
+			}
+		}
+	}
+
+	symbols := make(map[string][]string)
+	for symbol, m := range listed {
+		if len(m) < minExchanges {
+			continue
+		}
+		for _, exchange := range exchanges {
+			if m[exchange] {
+				symbols[symbol] = append(symbols[symbol], exchange)
+			}
+		}
+	}
+	return symbols
+}
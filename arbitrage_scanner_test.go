@@ -0,0 +1,81 @@
+package exapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestArbitrageScanner(t *testing.T) {
+	pair := NewCurrencyPairFromString("BTC/USDT")
+	var found []ArbitrageOpportunity
+	as := NewArbitrageScanner(pair, func(op *ArbitrageOpportunity) error {
+		found = append(found, *op)
+		return nil
+	})
+
+	status := map[string]CurrencyStatus{
+		"BTC":  {Deposit: true, Withdraw: true},
+		"USDT": {Deposit: true, Withdraw: true, Chains: []ChainStatus{{Chain: "TRC20", Deposit: true, Withdraw: true}}},
+	}
+	as.SetVenue("a", SymbolSetting{TakerFee: 0.001, StepSize: 0.1, MinSize: 0.1}, status)
+	as.SetVenue("b", SymbolSetting{TakerFee: 0.001, StepSize: 0.1, MinSize: 0.1}, map[string]CurrencyStatus{
+		"BTC":  {Deposit: true, Withdraw: true},
+		"USDT": {Deposit: true, Withdraw: true, Chains: []ChainStatus{{Chain: "ERC20", Deposit: true, Withdraw: true}}},
+	})
+
+	assert.Nil(t, as.OnDepth("a", &Depth{
+		Market:  pair,
+		AskList: DepthRecords{{Price: 100, Amount: 1}, {Price: 101, Amount: 2}, {Price: 103, Amount: 5}},
+		BidList: DepthRecords{{Price: 99, Amount: 1}},
+	}))
+	assert.Equal(t, 0, len(found))
+
+	assert.Nil(t, as.OnDepth("b", &Depth{
+		Market:  pair,
+		AskList: DepthRecords{{Price: 104, Amount: 1}},
+		BidList: DepthRecords{{Price: 102, Amount: 1.5}, {Price: 101.5, Amount: 3}},
+	}))
+	assert.Equal(t, 1, len(found))
+	op := found[0]
+	assert.Equal(t, "a", op.BuyExchange)
+	assert.Equal(t, "b", op.SellExchange)
+	// 101.5*0.999 > 101*1.001，可成交1+2档，共3个，按精度截断后为3
+	assert.InDelta(t, 3.0, op.Amount, 1e-9)
+	assert.InDelta(t, (100+101*2)/3.0, op.BuyPrice, 1e-9)
+	assert.InDelta(t, (102*1.5+101.5*1.5)/3, op.SellPrice, 1e-9)
+	assert.InDelta(t, (102*1.5+101.5*1.5)*0.999-(100+101*2)*1.001, op.Profit, 1e-9)
+	// USDT没有共同的链，不能转回
+	assert.False(t, op.Transferable)
+
+	as.SetRequireTransferable(true)
+	assert.Equal(t, 0, len(as.Scan()))
+	as.SetRequireTransferable(false)
+
+	// 只有行情时给出单位利润
+	as.SetMinProfitRate(0.05)
+	assert.Equal(t, 0, len(as.Scan()))
+	as.SetMinProfitRate(0)
+	// 同一ws上其他交易对的行情被忽略
+	assert.Nil(t, as.OnTicker("c", &Ticker{Market: NewCurrencyPairFromString("ETH/USDT"), Buy: 2000, Sell: 2001}))
+	assert.Equal(t, 1, len(as.Scan()))
+	assert.Nil(t, as.OnTicker("c", &Ticker{Market: pair, Buy: 110, Sell: 111}))
+	ops := as.Scan()
+	assert.Equal(t, 3, len(ops))
+	assert.Equal(t, "c", ops[1].SellExchange)
+	assert.Equal(t, 0.0, ops[1].Amount)
+	assert.InDelta(t, 110-100*1.001, ops[1].Profit, 1e-9)
+}
+
+func TestCommonSymbols(t *testing.T) {
+	symbols := CommonSymbols(map[string]map[string]SymbolSetting{
+		"b": {"BTC/USDT": {}, "ETH/USDT": {}},
+		"a": {"BTC/USDT": {}},
+		"c": {"BTC/USDT": {}, "ETH/USDT": {}, "EOS/USDT": {}},
+	}, 2)
+	assert.Equal(t, map[string][]string{"BTC/USDT": {"a", "b", "c"}, "ETH/USDT": {"b", "c"}}, symbols)
+	assert.Equal(t, 1, len(CommonSymbols(map[string]map[string]SymbolSetting{
+		"a": {"BTC/USDT": {}, "ETH/USDT": {}},
+		"b": {"BTC/USDT": {}},
+		"c": {"BTC/USDT": {}},
+	}, 3)))
+}
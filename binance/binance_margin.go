@@ -0,0 +1,242 @@
+package binance
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/betterjun/exapi"
+	"net/http"
+	"net/url"
+)
+
+/**
+ * isolated margin
+ */
+func NewMarginAPI(client *http.Client, api_key, secret_key string) MarginAPI {
+	return NewSpotAPI(client, api_key, secret_key).(*Binance)
+}
+
+func (bn *Binance) GetMarginAccount(pair CurrencyPair) (*MarginAccount, error) {
+	params := url.Values{}
+	params.Set("symbols", ExchangeSymbol(BINANCE, pair, ""))
+
+	type asset struct {
+		Asset    string  `json:"asset"`
+		Borrowed float64 `json:"borrowed,string"`
+		Free     float64 `json:"free,string"`
+		Interest float64 `json:"interest,string"`
+		Locked   float64 `json:"locked,string"`
+	}
+	var response struct {
+		Assets []struct {
+			BaseAsset      asset   `json:"baseAsset"`
+			QuoteAsset     asset   `json:"quoteAsset"`
+			MarginRatio    float64 `json:"marginRatio,string"`
+			IndexPrice     float64 `json:"indexPrice,string"`
+			LiquidatePrice float64 `json:"liquidatePrice,string"`
+		} `json:"assets"`
+	}
+	err := bn.doSigned("GET", "/sapi/v1/margin/isolated/account", params, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response.Assets) == 0 {
+		return nil, fmt.Errorf("margin account not found, pair:%v", pair)
+	}
+
+	v := response.Assets[0]
+	acc := &MarginAccount{
+		Account: Account{
+			Exchange:    bn.GetExchangeName(),
+			SubAccounts: make(map[Currency]SubAccount, 2),
+		},
+		Market:           pair,
+		Symbol:           pair.ToLowerSymbol("/"),
+		RiskRate:         v.MarginRatio,
+		LiquidationPrice: v.LiquidatePrice,
+	}
+	for _, a := range []asset{v.BaseAsset, v.QuoteAsset} {
+		currency := CanonicalCurrency(BINANCE, a.Asset)
+		acc.SubAccounts[currency] = SubAccount{
+			Currency:     currency,
+			Amount:       a.Free,
+			FrozenAmount: a.Locked,
+			LoanAmount:   a.Borrowed + a.Interest,
+		}
+	}
+	acc.Valuate(v.IndexPrice)
+
+	return acc, nil
+}
+
+func (bn *Binance) GetMaxBorrowable(pair CurrencyPair, currency Currency) (float64, error) {
+	params := url.Values{}
+	params.Set("asset", ExchangeCurrency(BINANCE, currency).Symbol())
+	params.Set("isolatedSymbol", ExchangeSymbol(BINANCE, pair, ""))
+
+	var response struct {
+		Amount float64 `json:"amount,string"`
+	}
+	err := bn.doSigned("GET", "/sapi/v1/margin/maxBorrowable", params, &response)
+	if err != nil {
+		return 0, err
+	}
+
+	return response.Amount, nil
+}
+
+// 借币、还币和划转返回的交易id
+type marginTransferResponse struct {
+	TranId int64 `json:"tranId"`
+}
+
+func (bn *Binance) Borrow(pair CurrencyPair, currency Currency, amount string) (string, error) {
+	params := url.Values{}
+	params.Set("asset", ExchangeCurrency(BINANCE, currency).Symbol())
+	params.Set("isIsolated", "TRUE")
+	params.Set("symbol", ExchangeSymbol(BINANCE, pair, ""))
+	params.Set("amount", amount)
+
+	var response marginTransferResponse
+	err := bn.doSigned("POST", "/sapi/v1/margin/loan", params, &response)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprint(response.TranId), nil
+}
+
+// 币安按借币时间先后自动归还，忽略loanId
+func (bn *Binance) Repay(pair CurrencyPair, currency Currency, amount, loanId string) error {
+	params := url.Values{}
+	params.Set("asset", ExchangeCurrency(BINANCE, currency).Symbol())
+	params.Set("isIsolated", "TRUE")
+	params.Set("symbol", ExchangeSymbol(BINANCE, pair, ""))
+	params.Set("amount", amount)
+
+	var response marginTransferResponse
+	return bn.doSigned("POST", "/sapi/v1/margin/repay", params, &response)
+}
+
+// 币安的借币记录不包含未还数量和利息
+func (bn *Binance) GetLoanRecords(pair CurrencyPair, currency Currency) ([]LoanRecord, error) {
+	params := url.Values{}
+	params.Set("asset", ExchangeCurrency(BINANCE, currency).Symbol())
+	params.Set("isolatedSymbol", ExchangeSymbol(BINANCE, pair, ""))
+	params.Set("size", "100")
+
+	var response struct {
+		Rows []struct {
+			TxId      int64   `json:"txId"`
+			Principal float64 `json:"principal,string"`
+			Timestamp int64   `json:"timestamp"`
+			Status    string  `json:"status"`
+		} `json:"rows"`
+	}
+	err := bn.doSigned("GET", "/sapi/v1/margin/loan", params, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]LoanRecord, 0, len(response.Rows))
+	for _, v := range response.Rows {
+		record := LoanRecord{
+			LoanId:   fmt.Sprint(v.TxId),
+			Market:   pair,
+			Symbol:   pair.ToLowerSymbol("/"),
+			Currency: currency,
+			Amount:   v.Principal,
+			Status:   LOAN_ACTIVE,
+			TS:       v.Timestamp,
+		}
+		if v.Status == "FAILED" {
+			record.Status = LOAN_FAILED
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// 使用逐仓杠杆账户下单
+func (bn *Binance) placeMarginOrder(amount, price string, pair CurrencyPair, orderType, orderSide string) (*Order, error) {
+	params := url.Values{}
+	params.Set("symbol", ExchangeSymbol(BINANCE, pair, ""))
+	params.Set("isIsolated", "TRUE")
+	params.Set("side", orderSide)
+	params.Set("type", orderType)
+	params.Set("newOrderRespType", "RESULT")
+
+	switch orderType {
+	case "LIMIT":
+		params.Set("timeInForce", "GTC")
+		params.Set("price", price)
+		params.Set("quantity", amount)
+	case "MARKET":
+		if orderSide == "BUY" {
+			params.Set("quoteOrderQty", amount)
+		} else {
+			params.Set("quantity", amount)
+		}
+	}
+
+	var respmap map[string]interface{}
+	err := bn.doSigned("POST", "/sapi/v1/margin/order", params, &respmap)
+	if err != nil {
+		return nil, err
+	}
+
+	if ToInt64(respmap["orderId"]) <= 0 {
+		return nil, errors.New("place order failed")
+	}
+
+	// 下单返回成交时间，查询返回创建时间
+	respmap["time"] = respmap["transactTime"]
+	return bn.parseOrder(respmap, pair)
+}
+
+func (bn *Binance) MarginLimitBuy(pair CurrencyPair, price, amount string) (*Order, error) {
+	return bn.placeMarginOrder(amount, price, pair, "LIMIT", "BUY")
+}
+
+func (bn *Binance) MarginLimitSell(pair CurrencyPair, price, amount string) (*Order, error) {
+	return bn.placeMarginOrder(amount, price, pair, "LIMIT", "SELL")
+}
+
+func (bn *Binance) MarginMarketBuy(pair CurrencyPair, amount string) (*Order, error) {
+	return bn.placeMarginOrder(amount, "", pair, "MARKET", "BUY")
+}
+
+func (bn *Binance) MarginMarketSell(pair CurrencyPair, amount string) (*Order, error) {
+	return bn.placeMarginOrder(amount, "", pair, "MARKET", "SELL")
+}
+
+func (bn *Binance) CancelMarginOrder(orderId string, pair CurrencyPair) (bool, error) {
+	params := url.Values{}
+	params.Set("symbol", ExchangeSymbol(BINANCE, pair, ""))
+	params.Set("isIsolated", "TRUE")
+	params.Set("orderId", orderId)
+
+	var respmap map[string]interface{}
+	err := bn.doSigned("DELETE", "/sapi/v1/margin/order", params, &respmap)
+	if err != nil {
+		return false, err
+	}
+
+	return ToInt64(respmap["orderId"]) > 0, nil
+}
+
+func (bn *Binance) GetMarginOrder(orderId string, pair CurrencyPair) (*Order, error) {
+	params := url.Values{}
+	params.Set("symbol", ExchangeSymbol(BINANCE, pair, ""))
+	params.Set("isIsolated", "TRUE")
+	params.Set("orderId", orderId)
+
+	var respmap map[string]interface{}
+	err := bn.doSigned("GET", "/sapi/v1/margin/order", params, &respmap)
+	if err != nil {
+		return nil, err
+	}
+
+	return bn.parseOrder(respmap, pair)
+}
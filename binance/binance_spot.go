@@ -84,6 +84,8 @@ type Binance struct {
 	httpClient   *http.Client
 	timeoffset   int64 //nanosecond
 	tradeSymbols []TradeSymbol
+	accountType  AccountType  // GetAccount读取的账户类型
+	symbols      *SymbolIndex // 交易对名称到交易对的索引
 }
 
 func (bn *Binance) buildParamsSigned(postForm *url.Values) error {
@@ -97,6 +99,40 @@ func (bn *Binance) buildParamsSigned(postForm *url.Values) error {
 	return nil
 }
 
+// 签名的私有接口请求，path为相对baseUrl的路径，结果解析到result
+func (bn *Binance) doSigned(method, path string, params url.Values, result interface{}) error {
+	bn.buildParamsSigned(&params)
+	headers := map[string]string{"X-MBX-APIKEY": bn.accessKey}
+
+	var resp []byte
+	var err error
+	switch method {
+	case "GET":
+		resp, err = HttpGet5(bn.httpClient, bn.baseUrl+path+"?"+params.Encode(), headers)
+	case "POST":
+		resp, err = HttpPostForm2(bn.httpClient, bn.baseUrl+path, params, headers)
+	case "DELETE":
+		resp, err = HttpDeleteForm(bn.httpClient, bn.baseUrl+path, params, headers)
+	default:
+		return fmt.Errorf("unsupported http method:%v", method)
+	}
+	if err != nil {
+		return err
+	}
+
+	// a failure example:
+	// {"code":-1121,"msg":"Invalid symbol."}
+	var errResp struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if json.Unmarshal(resp, &errResp) == nil && errResp.Code < 0 {
+		return fmt.Errorf("code:%v, msg:%v", errResp.Code, errResp.Msg)
+	}
+
+	return json.Unmarshal(resp, result)
+}
+
 func NewSpotAPI(client *http.Client, api_key, secret_key string) SpotAPI {
 	bn := &Binance{
 		baseUrl:    "https://api.binance.com",
@@ -104,6 +140,7 @@ func NewSpotAPI(client *http.Client, api_key, secret_key string) SpotAPI {
 		accessKey:  api_key,
 		secretKey:  secret_key,
 		httpClient: client}
+	bn.symbols = NewSymbolIndex(BINANCE, bn.GetAllCurrencyPair)
 	return bn
 }
 
@@ -120,78 +157,116 @@ func (bn *Binance) GetURL() string {
 	return bn.baseUrl
 }
 
-/*
-"maker": "0.001",
-"taker": "0.0015",
-"timestamp": "2019-12-05T09:06:20.260Z"
-*/
-type TradeFee struct {
-	Maker float64 `json:"maker,string"` // 挂单手续费
-	Taker float64 `json:"taker,string"` // 吃单手续费
+var spotCapabilities = &Capabilities{
+	Exchange:           BINANCE,
+	UnsupportedMethods: []string{"GetOrderDeal"},
+	KlinePeriods:       KlinePeriodsOf(_INERNAL_KLINE_PERIOD_CONVERTER),
+	MaxKlineSize:       1000,
+	MaxTradeSize:       1000,
+	MaxDepthSize:       1000,
+	OrderSides:         AllOrderSides,
+	WebsocketStreams:   AllStreams,
 }
 
-func (bn *Binance) GetTradeFee(symbols string) (tf *TradeFee, err error) {
-	tf = &TradeFee{
-		Maker: 0.001,
-		Taker: 0.001,
-	}
+func (bn *Binance) Capabilities() *Capabilities {
+	return spotCapabilities.Clone()
+}
 
-	return tf, nil
+type tradeFeeResponse struct {
+	Symbol          string  `json:"symbol"`
+	MakerCommission float64 `json:"makerCommission,string"` // 挂单手续费率
+	TakerCommission float64 `json:"takerCommission,string"` // 吃单手续费率
 }
 
-func (bn *Binance) GetTradeFeeMap() (tfmap map[string]TradeFee, err error) {
+func (bn *Binance) GetTradeFee(pair CurrencyPair) (*TradeFee, error) {
 	params := url.Values{}
-	//params.Set("symbol", "ADABNB")
-	bn.buildParamsSigned(&params)
-	path := bn.baseUrl + "/wapi/v3/tradeFee.html?" + params.Encode()
+	params.Set("symbol", ExchangeSymbol(BINANCE, pair, ""))
 
-	// todo: 币安的接口有bug，此接口一直报签名错误
-	respmap, err := HttpGet2(bn.httpClient, path, map[string]string{"X-MBX-APIKEY": bn.accessKey})
+	var response []tradeFeeResponse
+	err := bn.doSigned("GET", "/sapi/v1/asset/tradeFee", params, &response)
 	if err != nil {
 		return nil, err
 	}
 
-	dataArr, ok := respmap["tradeFee"].([]interface{})
-	if !ok {
-		return nil, errors.New("tradeFee assert error")
+	if len(response) == 0 {
+		return nil, ErrorAssetNotFound
 	}
 
-	ssm := make(map[string]TradeFee)
-	for _, v := range dataArr {
-		d := v.(map[string]interface{})
-		symbol := strings.ToLower(ToString(d["symbol"]))
-		ssm[symbol] = TradeFee{
-			Maker: ToFloat64(d["maker"]),
-			Taker: ToFloat64(d["taker"]),
+	return &TradeFee{
+		Symbol:    pair.ToSymbol("/"),
+		MakerRate: response[0].MakerCommission,
+		TakerRate: response[0].TakerCommission,
+	}, nil
+}
+
+func (bn *Binance) GetTradeFeeMap() (map[string]TradeFee, error) {
+	ssm, err := bn.getSymbolSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	return bn.getTradeFeeMap(ssm)
+}
+
+// 币安返回的币对名称没有分隔符，按币对信息转换为BASE/QUOTE格式
+func (bn *Binance) getTradeFeeMap(ssm map[string]SymbolSetting) (map[string]TradeFee, error) {
+	var response []tradeFeeResponse
+	err := bn.doSigned("GET", "/sapi/v1/asset/tradeFee", url.Values{}, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make(map[string]string, len(ssm))
+	for k := range ssm {
+		symbols[ExchangeSymbol(BINANCE, NewCurrencyPairFromString(k), "")] = k
+	}
+
+	tfm := make(map[string]TradeFee)
+	for _, v := range response {
+		symbol, ok := symbols[v.Symbol]
+		if !ok {
+			continue
+		}
+		tfm[symbol] = TradeFee{
+			Symbol:    symbol,
+			MakerRate: v.MakerCommission,
+			TakerRate: v.TakerCommission,
 		}
 	}
 
-	return ssm, nil
+	return tfm, nil
 }
 
+// MakerFee和TakerFee为账户的实际费率，未设置apikey或查询费率失败时为公布的费率
 func (bn *Binance) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
-	exchangeUri := bn.apiV3 + "exchangeInfo"
-	respmap, err := HttpGet(bn.httpClient, exchangeUri)
+	ssm, err := bn.getSymbolSettings()
 	if err != nil {
 		return nil, err
 	}
 
-	dataArr, ok := respmap["symbols"].([]interface{})
-	if !ok {
-		return nil, errors.New("symbols assert error")
+	if len(bn.accessKey) > 0 {
+		tfm, err := bn.getTradeFeeMap(ssm)
+		if err == nil {
+			ApplyTradeFee(ssm, tfm)
+		}
 	}
 
-	//tfmap, err := bn.GetTradeFeeMap()
-	//if err != nil {
-	//	return nil, err
-	//}
+	return ssm, nil
+}
 
-	// TODO FIXME: 火币的费率查询，目前最多支持10个币种对。这里用了一个tricky，查一个币种费率，所有币种都用一个费率。
-	tf, err := bn.GetTradeFee("btcusdt")
+// 获取币对信息，账户费率由GetAllCurrencyPair填入
+func (bn *Binance) getSymbolSettings() (map[string]SymbolSetting, error) {
+	exchangeUri := bn.apiV3 + "exchangeInfo"
+	respmap, err := HttpGet(bn.httpClient, exchangeUri)
 	if err != nil {
 		return nil, err
 	}
 
+	dataArr, ok := respmap["symbols"].([]interface{})
+	if !ok {
+		return nil, errors.New("symbols assert error")
+	}
+
 	ssm := make(map[string]SymbolSetting)
 	for _, v := range dataArr {
 		d := v.(map[string]interface{})
@@ -203,8 +278,8 @@ func (bn *Binance) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
 		ss := SymbolSetting{
 			Base:     strings.ToUpper(ToString(d["baseAsset"])),
 			Quote:    strings.ToUpper(ToString(d["quoteAsset"])),
-			MakerFee: tf.Maker,
-			TakerFee: tf.Taker,
+			MakerFee: 0.001,
+			TakerFee: 0.001,
 		}
 		ss.Symbol = ss.Base + "/" + ss.Quote
 
@@ -214,17 +289,23 @@ func (bn *Binance) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
 			switch filterType {
 			case "LOT_SIZE":
 				ss.MinSize = ToFloat64(obj["minQty"])
+				ss.MaxSize = ToFloat64(obj["maxQty"])
+				ss.StepSize = ToFloat64(obj["stepSize"])
 			case "PRICE_FILTER":
 				ss.MinPrice = ToFloat64(obj["minPrice"])
+				ss.MaxPrice = ToFloat64(obj["maxPrice"])
+				ss.TickSize = ToFloat64(obj["tickSize"])
 			case "MIN_NOTIONAL":
 				ss.MinNotional = ToFloat64(obj["minNotional"])
 			}
 		}
+		ss.PricePrecision = PrecisionOf(ss.TickSize)
+		ss.AmountPrecision = PrecisionOf(ss.StepSize)
 
 		ssm[ss.Symbol] = ss
 	}
 
-	return ssm, nil
+	return CanonicalSymbolSettings(BINANCE, ssm), nil
 }
 
 func (bn *Binance) GetCurrencyStatus(currency Currency) (CurrencyStatus, error) {
@@ -239,11 +320,8 @@ func (bn *Binance) GetCurrencyStatus(currency Currency) (CurrencyStatus, error)
 
 	for _, v := range dataArr {
 		d := v.(map[string]interface{})
-		if strings.ToUpper(ToString(d["coin"])) == currency.Symbol() {
-			return CurrencyStatus{
-				Deposit:  ToBool(d["depositAllEnable"]),
-				Withdraw: ToBool(d["withdrawAllEnable"]),
-			}, nil
+		if strings.ToUpper(ToString(d["coin"])) == ExchangeCurrency(BINANCE, currency).Symbol() {
+			return parseCurrencyStatus(d), nil
 		}
 	}
 
@@ -263,18 +341,40 @@ func (bn *Binance) GetAllCurrencyStatus() (all map[string]CurrencyStatus, err er
 	all = make(map[string]CurrencyStatus)
 	for _, v := range dataArr {
 		d := v.(map[string]interface{})
-		all[strings.ToUpper(ToString(d["coin"]))] = CurrencyStatus{
-			Deposit:  ToBool(d["depositAllEnable"]),
-			Withdraw: ToBool(d["withdrawAllEnable"]),
-		}
+		all[strings.ToUpper(ToString(d["coin"]))] = parseCurrencyStatus(d)
+	}
+
+	return CanonicalCurrencyStatus(BINANCE, all), nil
+}
+
+// 解析/sapi/v1/capital/config/getall返回的币种信息
+func parseCurrencyStatus(d map[string]interface{}) CurrencyStatus {
+	cs := CurrencyStatus{
+		Deposit:  ToBool(d["depositAllEnable"]),
+		Withdraw: ToBool(d["withdrawAllEnable"]),
+	}
+
+	networks, _ := d["networkList"].([]interface{})
+	for _, v := range networks {
+		n := v.(map[string]interface{})
+		cs.Chains = append(cs.Chains, ChainStatus{
+			Chain:             ToString(n["network"]),
+			IsDefault:         ToBool(n["isDefault"]),
+			Deposit:           ToBool(n["depositEnable"]),
+			Withdraw:          ToBool(n["withdrawEnable"]),
+			WithdrawFee:       ToFloat64(n["withdrawFee"]),
+			MinWithdraw:       ToFloat64(n["withdrawMin"]),
+			MaxWithdraw:       ToFloat64(n["withdrawMax"]),
+			Confirmations:     ToInt(n["minConfirm"]),
+			WithdrawPrecision: PrecisionOf(ToFloat64(n["withdrawIntegerMultiple"])),
+		})
 	}
 
-	return all, nil
+	return cs
 }
 
 func (bn *Binance) GetTicker(pair CurrencyPair) (*Ticker, error) {
-	currency2 := bn.adaptCurrencyPair(pair)
-	tickerUri := bn.apiV3 + fmt.Sprintf(TICKER_URI, currency2.ToSymbol(""))
+	tickerUri := bn.apiV3 + fmt.Sprintf(TICKER_URI, ExchangeSymbol(BINANCE, pair, ""))
 	tickerMap, err := HttpGet(bn.httpClient, tickerUri)
 
 	if err != nil {
@@ -296,6 +396,11 @@ func (bn *Binance) GetTicker(pair CurrencyPair) (*Ticker, error) {
 }
 
 func (bn *Binance) GetAllTicker() ([]Ticker, error) {
+	// 交易对索引获取失败时无法转换交易对名称
+	if err := bn.symbols.Load(); err != nil {
+		return nil, err
+	}
+
 	tickerUri := bn.apiV3 + ALL_TICKER_URI
 	data, err := HttpGet3(bn.httpClient, tickerUri, nil)
 
@@ -310,13 +415,13 @@ func (bn *Binance) GetAllTicker() ([]Ticker, error) {
 			continue
 		}
 
-		base, quote := getSymbol(ToString(tickerMap["symbol"]))
-		if len(base) == 0 || len(quote) == 0 {
+		pair, err := bn.symbols.Pair(ToString(tickerMap["symbol"]))
+		if err != nil {
 			continue
 		}
 		ticker := Ticker{}
-		ticker.Symbol = base + "/" + quote
-		ticker.Market = NewCurrencyPairFromString(ticker.Symbol)
+		ticker.Symbol = pair.ToSymbol("/")
+		ticker.Market = pair
 		ticker.Open = ToFloat64(tickerMap["openPrice"])
 		ticker.Last = ToFloat64(tickerMap["lastPrice"])
 		ticker.High = ToFloat64(tickerMap["highPrice"])
@@ -331,28 +436,13 @@ func (bn *Binance) GetAllTicker() ([]Ticker, error) {
 	return tickers, nil
 }
 
-func getSymbol(market string) (base, quote string) {
-	fiatCoin := []string{"BNB", "BTC", "ETH", "TRX", "XRP", "USDT", "BUSD", "PAX", "TUSD", "USDC", "EUR", "NGN", "RUB", "TRY"}
-
-	for _, v := range fiatCoin {
-		if strings.HasSuffix(market, v) {
-			p := strings.LastIndex(market, v)
-			return market[0:p], v
-		}
-	}
-
-	return "", ""
-}
-
 func (bn *Binance) GetDepth(pair CurrencyPair, size int, step int) (*Depth, error) {
 	if size > 1000 {
 		size = 1000
 	} else if size < 5 {
 		size = 5
 	}
-	currencyPair2 := bn.adaptCurrencyPair(pair)
-
-	apiUrl := fmt.Sprintf(bn.apiV3+DEPTH_URI, currencyPair2.ToSymbol(""), size)
+	apiUrl := fmt.Sprintf(bn.apiV3+DEPTH_URI, ExchangeSymbol(BINANCE, pair, ""), size)
 	resp, err := HttpGet(bn.httpClient, apiUrl)
 	if err != nil {
 		return nil, err
@@ -391,7 +481,7 @@ func (bn *Binance) GetDepth(pair CurrencyPair, size int, step int) (*Depth, erro
 //注意：since is fromId
 func (bn *Binance) GetTrades(pair CurrencyPair, size int) ([]Trade, error) {
 	param := url.Values{}
-	param.Set("symbol", bn.adaptCurrencyPair(pair).ToSymbol(""))
+	param.Set("symbol", ExchangeSymbol(BINANCE, pair, ""))
 	param.Set("limit", "500")
 	//if since > 0 {
 	//	param.Set("fromId", strconv.Itoa(int(since)))
@@ -417,6 +507,9 @@ func (bn *Binance) GetTrades(pair CurrencyPair, size int) ([]Trade, error) {
 			TS:     ToInt64(m["time"]),
 			Market: pair,
 			Symbol: pair.ToLowerSymbol("/"),
+			// 手续费从收到的币种或BNB中扣除
+			Fee:         ToFloat64(m["commission"]),
+			FeeCurrency: CanonicalCurrency(BINANCE, ToString(m["commissionAsset"])),
 		})
 	}
 
@@ -426,12 +519,14 @@ func (bn *Binance) GetTrades(pair CurrencyPair, size int) ([]Trade, error) {
 func (bn *Binance) GetKlineRecords(pair CurrencyPair, period KlinePeriod, size, since int) ([]Kline, error) {
 	periodS, isOk := _INERNAL_KLINE_PERIOD_CONVERTER[period]
 	if isOk != true {
-		return nil, fmt.Errorf("unsupported %v KlinePeriod:%v", bn.GetExchangeName(), period)
+		// 交易所不支持的周期，用较小周期的K线合成
+		return GetKlinesWithResample(spotCapabilities, func(p KlinePeriod, n, t int) ([]Kline, error) {
+			return bn.GetKlineRecords(pair, p, n, t)
+		}, period, size, since)
 	}
 
-	currency2 := bn.adaptCurrencyPair(pair)
 	params := url.Values{}
-	params.Set("symbol", currency2.ToSymbol(""))
+	params.Set("symbol", ExchangeSymbol(BINANCE, pair, ""))
 
 	params.Set("interval", periodS)
 	if since > 0 {
@@ -443,6 +538,25 @@ func (bn *Binance) GetKlineRecords(pair CurrencyPair, period KlinePeriod, size,
 	}
 	params.Set("limit", fmt.Sprintf("%d", size))
 
+	return bn.getKlines(pair, params)
+}
+
+// 币安按区间获取K线时的请求间隔，以免超过频率限制
+const BN_KLINE_RANGE_INTERVAL = 100 * time.Millisecond
+
+func (bn *Binance) GetKlineRange(pair CurrencyPair, period KlinePeriod, start, end time.Time) ([]Kline, []KlineGap, error) {
+	return FetchKlineRange(spotCapabilities, func(p KlinePeriod, from, to int64) ([]Kline, error) {
+		params := url.Values{}
+		params.Set("symbol", ExchangeSymbol(BINANCE, pair, ""))
+		params.Set("interval", _INERNAL_KLINE_PERIOD_CONVERTER[p])
+		params.Set("startTime", strconv.FormatInt(from*1000, 10))
+		params.Set("endTime", strconv.FormatInt(to*1000-1, 10))
+		params.Set("limit", strconv.Itoa(spotCapabilities.MaxKlineSize))
+		return bn.getKlines(pair, params)
+	}, period, start, end, BN_KLINE_RANGE_INTERVAL)
+}
+
+func (bn *Binance) getKlines(pair CurrencyPair, params url.Values) ([]Kline, error) {
 	klineUrl := bn.apiV3 + KLINE_URI + "?" + params.Encode()
 	klines, err := HttpGet3(bn.httpClient, klineUrl, nil)
 	if err != nil {
@@ -493,10 +607,9 @@ func (bn *Binance) MarketSell(pair CurrencyPair, amount string) (*Order, error)
 }
 
 func (bn *Binance) Cancel(orderId string, pair CurrencyPair) (bool, error) {
-	pair = bn.adaptCurrencyPair(pair)
 	path := bn.apiV3 + ORDER_URI
 	params := url.Values{}
-	params.Set("symbol", pair.ToSymbol(""))
+	params.Set("symbol", ExchangeSymbol(BINANCE, pair, ""))
 	params.Set("orderId", orderId)
 
 	bn.buildParamsSigned(&params)
@@ -523,8 +636,7 @@ func (bn *Binance) Cancel(orderId string, pair CurrencyPair) (bool, error) {
 
 func (bn *Binance) GetOrder(orderId string, pair CurrencyPair) (*Order, error) {
 	params := url.Values{}
-	pair = bn.adaptCurrencyPair(pair)
-	params.Set("symbol", pair.ToSymbol(""))
+	params.Set("symbol", ExchangeSymbol(BINANCE, pair, ""))
 	if orderId != "" {
 		params.Set("orderId", orderId)
 	}
@@ -543,8 +655,7 @@ func (bn *Binance) GetOrder(orderId string, pair CurrencyPair) (*Order, error) {
 
 func (bn *Binance) GetPendingOrders(pair CurrencyPair) ([]Order, error) {
 	params := url.Values{}
-	pair = bn.adaptCurrencyPair(pair)
-	params.Set("symbol", pair.ToSymbol(""))
+	params.Set("symbol", ExchangeSymbol(BINANCE, pair, ""))
 
 	bn.buildParamsSigned(&params)
 	path := bn.apiV3 + UNFINISHED_ORDERS_INFO + params.Encode()
@@ -569,8 +680,7 @@ func (bn *Binance) GetPendingOrders(pair CurrencyPair) ([]Order, error) {
 
 func (bn *Binance) GetFinishedOrders(pair CurrencyPair) ([]Order, error) {
 	params := url.Values{}
-	pair = bn.adaptCurrencyPair(pair)
-	params.Set("symbol", pair.ToSymbol(""))
+	params.Set("symbol", ExchangeSymbol(BINANCE, pair, ""))
 	//params.Set("limit", 1000)
 
 	bn.buildParamsSigned(&params)
@@ -600,8 +710,7 @@ func (bn *Binance) GetOrderDeal(orderId string, pair CurrencyPair) ([]OrderDeal,
 
 func (bn *Binance) GetUserTrades(pair CurrencyPair) ([]Trade, error) {
 	params := url.Values{}
-	pair = bn.adaptCurrencyPair(pair)
-	params.Set("symbol", pair.ToSymbol(""))
+	params.Set("symbol", ExchangeSymbol(BINANCE, pair, ""))
 	//params.Set("limit", 1000)
 
 	bn.buildParamsSigned(&params)
@@ -633,7 +742,15 @@ func (bn *Binance) GetUserTrades(pair CurrencyPair) ([]Trade, error) {
 	return trades, nil
 }
 
+// 读取SetAccountType设置的账户，默认为现货账户
 func (bn *Binance) GetAccount() (*Account, error) {
+	if bn.accountType == 0 {
+		return bn.GetAccountByType(ACCOUNT_SPOT)
+	}
+	return bn.GetAccountByType(bn.accountType)
+}
+
+func (bn *Binance) getSpotAccount() (*Account, error) {
 	params := url.Values{}
 	bn.buildParamsSigned(&params)
 	path := bn.apiV3 + ACCOUNT_URI + params.Encode()
@@ -651,7 +768,7 @@ func (bn *Binance) GetAccount() (*Account, error) {
 	balances := respmap["balances"].([]interface{})
 	for _, v := range balances {
 		vv := v.(map[string]interface{})
-		currency := NewCurrency(vv["asset"].(string))
+		currency := CanonicalCurrency(BINANCE, vv["asset"].(string))
 		acc.SubAccounts[currency] = SubAccount{
 			Currency:     currency,
 			Amount:       ToFloat64(vv["free"]),
@@ -717,20 +834,6 @@ func (bn *Binance) parseOrder(respmap map[string]interface{}, pair CurrencyPair)
 	return &ord, nil
 }
 
-func (ba *Binance) adaptCurrencyPair(pair CurrencyPair) CurrencyPair {
-	//if pair.CurrencyA.Eq(BCH) || pair.CurrencyA.Eq(BCC) {
-	//	return NewCurrencyPair(NewCurrency("BCHABC", ""), pair.CurrencyB).AdaptUsdToUsdt()
-	//}
-	//
-	//if pair.CurrencyA.Symbol == "BSV" {
-	//	return NewCurrencyPair(NewCurrency("BCHSV", ""), pair.CurrencyB).AdaptUsdToUsdt()
-	//}
-	//
-	//return pair.AdaptUsdToUsdt()
-
-	return pair
-}
-
 func (bn *Binance) getTradeSymbols() ([]TradeSymbol, error) {
 	resp, err := HttpGet5(bn.httpClient, bn.apiV3+"exchangeInfo", nil)
 	if err != nil {
@@ -754,7 +857,7 @@ func (bn *Binance) GetTradeSymbols(pair CurrencyPair) (*TradeSymbol, error) {
 		}
 	}
 	for k, v := range bn.tradeSymbols {
-		if v.Symbol == pair.ToSymbol("") {
+		if v.Symbol == ExchangeSymbol(BINANCE, pair, "") {
 			return &bn.tradeSymbols[k], nil
 		}
 	}
@@ -779,10 +882,9 @@ func (bn *Binance) setTimeOffset() error {
 }
 
 func (bn *Binance) placeOrder(amount, price string, pair CurrencyPair, orderType, orderSide string) (*Order, error) {
-	pair = bn.adaptCurrencyPair(pair)
 	path := bn.apiV3 + ORDER_URI
 	params := url.Values{}
-	params.Set("symbol", pair.ToSymbol(""))
+	params.Set("symbol", ExchangeSymbol(BINANCE, pair, ""))
 	params.Set("side", orderSide)
 	params.Set("type", orderType)
 	params.Set("newOrderRespType", "RESULT")
@@ -888,11 +990,3 @@ func (bn *Binance) placeOrder(amount, price string, pair CurrencyPair, orderType
 
 	return order, nil
 }
-
-func adaptCurrencyPair(pair CurrencyPair) CurrencyPair {
-	if pair.Stock.Equal(BCC) {
-		return NewCurrencyPair(NewCurrency("BCH"), pair.Money).AdaptUsdToUsdt()
-	}
-
-	return pair.AdaptUsdToUsdt()
-}
@@ -48,7 +48,7 @@ func (ws *BinanceWs) GetExchangeName() string {
 
 // 格式化流名称
 func (ws *BinanceWs) FormatTopicName(topic string, pair CurrencyPair) string {
-	symbol := pair.ToLowerSymbol("")
+	symbol := ExchangeLowerSymbol(BINANCE, pair, "")
 	switch topic {
 	case STREAM_TICKER:
 		return fmt.Sprintf("%s@ticker", symbol)
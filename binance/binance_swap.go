@@ -0,0 +1,499 @@
+package binance
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/betterjun/exapi"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	SWAP_ENDPOINT = "https://fapi.binance.com"
+)
+
+// 币安U本位永续合约
+type BinanceSwap struct {
+	// 签名规则与现货相同，复用现货的签名
+	bn *Binance
+}
+
+/**
+ * perpetual swap
+ */
+func NewSwapAPI(client *http.Client, api_key, secret_key string) SwapAPI {
+	return &BinanceSwap{
+		bn: &Binance{
+			baseUrl:    SWAP_ENDPOINT,
+			apiV3:      SWAP_ENDPOINT + "/fapi/v1/",
+			accessKey:  api_key,
+			secretKey:  secret_key,
+			httpClient: client},
+	}
+}
+
+func (swap *BinanceSwap) GetExchangeName() string {
+	return BINANCE
+}
+
+func (swap *BinanceSwap) SetURL(exurl string) {
+	swap.bn.baseUrl = exurl
+	swap.bn.apiV3 = exurl + "/fapi/v1/"
+}
+
+func (swap *BinanceSwap) GetURL() string {
+	return swap.bn.baseUrl
+}
+
+// U本位合约以USDT计价，如BTCUSDT
+func (swap *BinanceSwap) getSymbol(pair CurrencyPair) string {
+	return ExchangeSymbol(BINANCE, pair.AdaptUsdToUsdt(), "")
+}
+
+func (swap *BinanceSwap) GetSwapContracts() ([]FutureContract, error) {
+	var response struct {
+		Symbols []struct {
+			Symbol       string `json:"symbol"`
+			ContractType string `json:"contractType"`
+			BaseAsset    string `json:"baseAsset"`
+			QuoteAsset   string `json:"quoteAsset"`
+			Filters      []struct {
+				FilterType string `json:"filterType"`
+				TickSize   string `json:"tickSize"`
+			} `json:"filters"`
+		} `json:"symbols"`
+	}
+	err := HttpGet4(swap.bn.httpClient, swap.bn.apiV3+"exchangeInfo", nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	contracts := make([]FutureContract, 0, len(response.Symbols))
+	for _, v := range response.Symbols {
+		// 交易所同时返回交割合约
+		if v.ContractType != "" && v.ContractType != "PERPETUAL" {
+			continue
+		}
+
+		c := FutureContract{
+			ContractId:   v.Symbol,
+			ContractType: SWAP_CONTRACT,
+			Market:       NewCurrencyPair(CanonicalCurrency(BINANCE, v.BaseAsset), CanonicalCurrency(BINANCE, v.QuoteAsset)),
+			ContractVal:  1,
+		}
+		for _, f := range v.Filters {
+			if f.FilterType == "PRICE_FILTER" {
+				c.TickSize = ToFloat64(f.TickSize)
+			}
+		}
+		contracts = append(contracts, c)
+	}
+
+	return contracts, nil
+}
+
+func (swap *BinanceSwap) GetSwapTicker(pair CurrencyPair) (*Ticker, error) {
+	symbol := swap.getSymbol(pair)
+	tickmap, err := HttpGet(swap.bn.httpClient, swap.bn.apiV3+fmt.Sprintf(TICKER_URI, symbol))
+	if err != nil {
+		return nil, err
+	}
+
+	bookmap, err := HttpGet(swap.bn.httpClient, swap.bn.apiV3+"ticker/bookTicker?symbol="+symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ticker{
+		Market: pair,
+		Symbol: pair.ToLowerSymbol("/"),
+		Open:   ToFloat64(tickmap["openPrice"]),
+		Last:   ToFloat64(tickmap["lastPrice"]),
+		High:   ToFloat64(tickmap["highPrice"]),
+		Low:    ToFloat64(tickmap["lowPrice"]),
+		Vol:    ToFloat64(tickmap["volume"]),
+		Buy:    ToFloat64(bookmap["bidPrice"]),
+		Sell:   ToFloat64(bookmap["askPrice"]),
+		TS:     ToInt64(tickmap["closeTime"]),
+	}, nil
+}
+
+func (swap *BinanceSwap) GetSwapDepth(pair CurrencyPair, size int) (*Depth, error) {
+	// 币安只支持固定档位
+	limit := 1000
+	for _, l := range []int{5, 10, 20, 50, 100, 500} {
+		if size <= l {
+			limit = l
+			break
+		}
+	}
+
+	respmap, err := HttpGet(swap.bn.httpClient, swap.bn.apiV3+fmt.Sprintf(DEPTH_URI, swap.getSymbol(pair), limit))
+	if err != nil {
+		return nil, err
+	}
+
+	dep := new(Depth)
+	dep.ContractType = SWAP_CONTRACT
+	dep.Market = pair
+	dep.Symbol = pair.ToLowerSymbol("/")
+	dep.TS = ToInt64(respmap["T"])
+
+	asks, _ := respmap["asks"].([]interface{})
+	for _, v := range asks {
+		itm := v.([]interface{})
+		dep.AskList = append(dep.AskList, DepthRecord{
+			Price:  ToFloat64(itm[0]),
+			Amount: ToFloat64(itm[1]),
+		})
+	}
+
+	bids, _ := respmap["bids"].([]interface{})
+	for _, v := range bids {
+		itm := v.([]interface{})
+		dep.BidList = append(dep.BidList, DepthRecord{
+			Price:  ToFloat64(itm[0]),
+			Amount: ToFloat64(itm[1]),
+		})
+	}
+
+	if size > 0 {
+		if len(dep.AskList) > size {
+			dep.AskList = dep.AskList[:size]
+		}
+		if len(dep.BidList) > size {
+			dep.BidList = dep.BidList[:size]
+		}
+	}
+
+	return dep, nil
+}
+
+func (swap *BinanceSwap) getPremiumIndex(pair CurrencyPair) (map[string]interface{}, error) {
+	return HttpGet(swap.bn.httpClient, swap.bn.apiV3+"premiumIndex?symbol="+swap.getSymbol(pair))
+}
+
+// 币安不提供预测费率，EstimatedRate为0
+func (swap *BinanceSwap) GetFundingRate(pair CurrencyPair) (*FundingRate, error) {
+	respmap, err := swap.getPremiumIndex(pair)
+	if err != nil {
+		return nil, err
+	}
+
+	// 币安每8小时收取一次资金费用，lastFundingRate在nextFundingTime收取
+	fundingTime := ToInt64(respmap["nextFundingTime"])
+	return &FundingRate{
+		Market:          pair,
+		Symbol:          pair.ToLowerSymbol("/"),
+		Rate:            ToFloat64(respmap["lastFundingRate"]),
+		FundingTime:     fundingTime,
+		NextFundingTime: fundingTime + int64(8*time.Hour/time.Millisecond),
+	}, nil
+}
+
+func (swap *BinanceSwap) GetFundingRateHistory(pair CurrencyPair, size int) ([]FundingRate, error) {
+	url := swap.bn.apiV3 + fmt.Sprintf("fundingRate?symbol=%s&limit=%d", swap.getSymbol(pair), size)
+	respArr, err := HttpGet3(swap.bn.httpClient, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make([]FundingRate, 0, len(respArr))
+	for _, v := range respArr {
+		obj := v.(map[string]interface{})
+		rates = append(rates, FundingRate{
+			Market:      pair,
+			Symbol:      pair.ToLowerSymbol("/"),
+			Rate:        ToFloat64(obj["fundingRate"]),
+			FundingTime: ToInt64(obj["fundingTime"]),
+		})
+	}
+
+	return rates, nil
+}
+
+func (swap *BinanceSwap) GetSwapPrice(pair CurrencyPair) (*SwapPrice, error) {
+	respmap, err := swap.getPremiumIndex(pair)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SwapPrice{
+		Market:     pair,
+		Symbol:     pair.ToLowerSymbol("/"),
+		MarkPrice:  ToFloat64(respmap["markPrice"]),
+		IndexPrice: ToFloat64(respmap["indexPrice"]),
+		TS:         ToInt64(respmap["time"]),
+	}, nil
+}
+
+/*
+下单，仅支持单向持仓模式。
+平多为卖出只减仓，平空为买入只减仓，数量单位为基础币种。
+*/
+func (swap *BinanceSwap) PlaceSwapOrder(pair CurrencyPair, price, amount string, openType, matchPrice int) (*FutureOrder, error) {
+	params := url.Values{}
+	params.Set("symbol", swap.getSymbol(pair))
+	params.Set("quantity", amount)
+
+	switch openType {
+	case OPEN_BUY:
+		params.Set("side", "BUY")
+	case OPEN_SELL:
+		params.Set("side", "SELL")
+	case CLOSE_BUY:
+		params.Set("side", "SELL")
+		params.Set("reduceOnly", "true")
+	case CLOSE_SELL:
+		params.Set("side", "BUY")
+		params.Set("reduceOnly", "true")
+	default:
+		return nil, fmt.Errorf("unsupported open type:%v", openType)
+	}
+
+	if matchPrice == 1 {
+		params.Set("type", "MARKET")
+	} else {
+		params.Set("type", "LIMIT")
+		params.Set("timeInForce", "GTC")
+		params.Set("price", price)
+	}
+
+	var response swapOrderResponse
+	err := swap.bn.doSigned("POST", "/fapi/v1/order", params, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.OrderId <= 0 {
+		return nil, errors.New("place order failed")
+	}
+
+	ord := swap.adaptOrder(&response, pair)
+	ord.OType = openType
+	return &ord, nil
+}
+
+func (swap *BinanceSwap) CancelSwapOrder(orderId string, pair CurrencyPair) (bool, error) {
+	params := url.Values{}
+	params.Set("symbol", swap.getSymbol(pair))
+	params.Set("orderId", orderId)
+
+	var response swapOrderResponse
+	err := swap.bn.doSigned("DELETE", "/fapi/v1/order", params, &response)
+	if err != nil {
+		return false, err
+	}
+
+	return response.OrderId > 0, nil
+}
+
+type swapOrderResponse struct {
+	OrderId     int64   `json:"orderId"`
+	Symbol      string  `json:"symbol"`
+	Status      string  `json:"status"`
+	Price       float64 `json:"price,string"`
+	AvgPrice    float64 `json:"avgPrice,string"`
+	OrigQty     float64 `json:"origQty,string"`
+	ExecutedQty float64 `json:"executedQty,string"`
+	Side        string  `json:"side"`
+	ReduceOnly  bool    `json:"reduceOnly"`
+	Time        int64   `json:"time"`
+	UpdateTime  int64   `json:"updateTime"`
+}
+
+func (swap *BinanceSwap) adaptOrder(response *swapOrderResponse, pair CurrencyPair) FutureOrder {
+	ord := FutureOrder{
+		OrderID2:     fmt.Sprint(response.OrderId),
+		OrderID:      response.OrderId,
+		Price:        response.Price,
+		Amount:       response.OrigQty,
+		AvgPrice:     response.AvgPrice,
+		DealAmount:   response.ExecutedQty,
+		OrderTime:    response.Time,
+		Currency:     pair,
+		ContractName: response.Symbol,
+	}
+	if ord.OrderTime == 0 {
+		ord.OrderTime = response.UpdateTime
+	}
+
+	switch response.Status {
+	case "NEW":
+		ord.Status = ORDER_UNFINISH
+	case "PARTIALLY_FILLED":
+		ord.Status = ORDER_PART_FINISH
+	case "FILLED":
+		ord.Status = ORDER_FINISH
+	case "CANCELED":
+		ord.Status = ORDER_CANCEL
+	case "REJECTED":
+		ord.Status = ORDER_REJECT
+	case "EXPIRED":
+		ord.Status = ORDER_FAIL
+	}
+
+	switch {
+	case response.Side == "BUY" && !response.ReduceOnly:
+		ord.OType = OPEN_BUY
+	case response.Side == "SELL" && !response.ReduceOnly:
+		ord.OType = OPEN_SELL
+	case response.Side == "SELL" && response.ReduceOnly:
+		ord.OType = CLOSE_BUY
+	case response.Side == "BUY" && response.ReduceOnly:
+		ord.OType = CLOSE_SELL
+	}
+
+	return ord
+}
+
+func (swap *BinanceSwap) GetSwapOrder(orderId string, pair CurrencyPair) (*FutureOrder, error) {
+	params := url.Values{}
+	params.Set("symbol", swap.getSymbol(pair))
+	params.Set("orderId", orderId)
+
+	var response swapOrderResponse
+	err := swap.bn.doSigned("GET", "/fapi/v1/order", params, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	ord := swap.adaptOrder(&response, pair)
+	return &ord, nil
+}
+
+func (swap *BinanceSwap) GetUnfinishSwapOrders(pair CurrencyPair) ([]FutureOrder, error) {
+	params := url.Values{}
+	params.Set("symbol", swap.getSymbol(pair))
+
+	var response []swapOrderResponse
+	err := swap.bn.doSigned("GET", "/fapi/v1/openOrders", params, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]FutureOrder, 0, len(response))
+	for i := range response {
+		orders = append(orders, swap.adaptOrder(&response[i], pair))
+	}
+
+	return orders, nil
+}
+
+// 单向持仓模式下，持仓量为负表示空仓
+func (swap *BinanceSwap) GetSwapPosition(pair CurrencyPair) ([]SwapPosition, error) {
+	params := url.Values{}
+	params.Set("symbol", swap.getSymbol(pair))
+
+	var response []struct {
+		PositionAmt      float64 `json:"positionAmt,string"`
+		EntryPrice       float64 `json:"entryPrice,string"`
+		UnRealizedProfit float64 `json:"unRealizedProfit,string"`
+		LiquidationPrice float64 `json:"liquidationPrice,string"`
+		Leverage         float64 `json:"leverage,string"`
+		MarginType       string  `json:"marginType"`
+		IsolatedMargin   float64 `json:"isolatedMargin,string"`
+	}
+	err := swap.bn.doSigned("GET", "/fapi/v2/positionRisk", params, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make([]SwapPosition, 0, len(response))
+	for _, v := range response {
+		if v.PositionAmt == 0 {
+			continue
+		}
+
+		pos := SwapPosition{
+			Market:           pair,
+			Symbol:           pair.ToLowerSymbol("/"),
+			Side:             POSITION_LONG,
+			Amount:           v.PositionAmt,
+			Available:        v.PositionAmt,
+			AvgPrice:         v.EntryPrice,
+			LiquidationPrice: v.LiquidationPrice,
+			Leverage:         int(v.Leverage),
+			MarginMode:       MARGIN_CROSSED,
+			Margin:           v.IsolatedMargin,
+			UnrealizedPnl:    v.UnRealizedProfit,
+		}
+		if v.PositionAmt < 0 {
+			pos.Side = POSITION_SHORT
+			pos.Amount = -v.PositionAmt
+			pos.Available = -v.PositionAmt
+		}
+		if v.MarginType == "isolated" {
+			pos.MarginMode = MARGIN_ISOLATED
+		}
+		positions = append(positions, pos)
+	}
+
+	return positions, nil
+}
+
+func (swap *BinanceSwap) GetSwapAccount() (*FutureAccount, error) {
+	var response struct {
+		Assets []struct {
+			Asset            string  `json:"asset"`
+			MarginBalance    float64 `json:"marginBalance,string"`
+			InitialMargin    float64 `json:"initialMargin,string"`
+			MaintMargin      float64 `json:"maintMargin,string"`
+			UnrealizedProfit float64 `json:"unrealizedProfit,string"`
+		} `json:"assets"`
+	}
+	err := swap.bn.doSigned("GET", "/fapi/v2/account", url.Values{}, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	acc := &FutureAccount{FutureSubAccounts: make(map[Currency]FutureSubAccount, len(response.Assets))}
+	for _, v := range response.Assets {
+		currency := CanonicalCurrency(BINANCE, v.Asset)
+		sub := FutureSubAccount{
+			Currency:      currency,
+			AccountRights: v.MarginBalance,
+			KeepDeposit:   v.InitialMargin,
+			ProfitUnreal:  v.UnrealizedProfit,
+		}
+		if v.MarginBalance > 0 {
+			sub.RiskRate = v.MaintMargin / v.MarginBalance
+		}
+		acc.FutureSubAccounts[currency] = sub
+	}
+
+	return acc, nil
+}
+
+func (swap *BinanceSwap) SetSwapLeverage(pair CurrencyPair, leverRate int) error {
+	params := url.Values{}
+	params.Set("symbol", swap.getSymbol(pair))
+	params.Set("leverage", fmt.Sprint(leverRate))
+
+	var response map[string]interface{}
+	return swap.bn.doSigned("POST", "/fapi/v1/leverage", params, &response)
+}
+
+// 有持仓或挂单时无法切换
+func (swap *BinanceSwap) SetSwapMarginMode(pair CurrencyPair, mode MarginMode) error {
+	params := url.Values{}
+	params.Set("symbol", swap.getSymbol(pair))
+	switch mode {
+	case MARGIN_CROSSED:
+		params.Set("marginType", "CROSSED")
+	case MARGIN_ISOLATED:
+		params.Set("marginType", "ISOLATED")
+	default:
+		return ErrorUnsupported
+	}
+
+	// 已经是目标模式时返回{"code":-4046,"msg":"No need to change margin type."}，视为成功
+	var response map[string]interface{}
+	err := swap.bn.doSigned("POST", "/fapi/v1/marginType", params, &response)
+	if err != nil && strings.Contains(err.Error(), "-4046") {
+		return nil
+	}
+	return err
+}
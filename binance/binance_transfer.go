@@ -0,0 +1,125 @@
+package binance
+
+import (
+	"fmt"
+	. "github.com/betterjun/exapi"
+	"net/http"
+	"net/url"
+)
+
+/**
+ * asset transfer
+ */
+func NewTransferAPI(client *http.Client, api_key, secret_key string) TransferAPI {
+	return NewSpotAPI(client, api_key, secret_key).(*Binance)
+}
+
+// 币安的现货接口支持现货账户和资金账户
+func (bn *Binance) SetAccountType(accountType AccountType) error {
+	if accountType != ACCOUNT_SPOT && accountType != ACCOUNT_FUNDING {
+		return ErrorUnsupported
+	}
+
+	bn.accountType = accountType
+	return nil
+}
+
+func (bn *Binance) GetAccountByType(accountType AccountType) (*Account, error) {
+	switch accountType {
+	case ACCOUNT_SPOT:
+		return bn.getSpotAccount()
+	case ACCOUNT_FUNDING:
+		var response []struct {
+			Asset  string  `json:"asset"`
+			Free   float64 `json:"free,string"`
+			Locked float64 `json:"locked,string"`
+			Freeze float64 `json:"freeze,string"`
+		}
+		err := bn.doSigned("POST", "/sapi/v1/asset/get-funding-asset", url.Values{}, &response)
+		if err != nil {
+			return nil, err
+		}
+
+		acc := &Account{
+			Exchange:    bn.GetExchangeName(),
+			SubAccounts: make(map[Currency]SubAccount),
+		}
+		for _, v := range response {
+			currency := CanonicalCurrency(BINANCE, v.Asset)
+			acc.SubAccounts[currency] = SubAccount{
+				Currency:     currency,
+				Amount:       v.Free,
+				FrozenAmount: v.Locked + v.Freeze,
+			}
+		}
+		return acc, nil
+	default:
+		return nil, ErrorUnsupported
+	}
+}
+
+// 万向划转的账户名称，交割合约为币本位合约，永续合约为U本位合约
+var _TRANSFER_ACCOUNT_CONVERTER = map[AccountType]string{
+	ACCOUNT_SPOT:    "MAIN",
+	ACCOUNT_FUTURE:  "CMFUTURE",
+	ACCOUNT_SWAP:    "UMFUTURE",
+	ACCOUNT_FUNDING: "FUNDING",
+}
+
+// 逐仓杠杆账户只能与现货账户划转
+func (bn *Binance) Transfer(currency Currency, amount string, from, to AccountType, pair CurrencyPair) (string, error) {
+	params := url.Values{}
+	params.Set("asset", ExchangeCurrency(BINANCE, currency).Symbol())
+	params.Set("amount", amount)
+
+	path := "/sapi/v1/asset/transfer"
+	switch {
+	case from == ACCOUNT_SPOT && to == ACCOUNT_MARGIN:
+		path = "/sapi/v1/margin/isolated/transfer"
+		params.Set("symbol", ExchangeSymbol(BINANCE, pair, ""))
+		params.Set("transFrom", "SPOT")
+		params.Set("transTo", "ISOLATED_MARGIN")
+	case from == ACCOUNT_MARGIN && to == ACCOUNT_SPOT:
+		path = "/sapi/v1/margin/isolated/transfer"
+		params.Set("symbol", ExchangeSymbol(BINANCE, pair, ""))
+		params.Set("transFrom", "ISOLATED_MARGIN")
+		params.Set("transTo", "SPOT")
+	default:
+		fromName, ok1 := _TRANSFER_ACCOUNT_CONVERTER[from]
+		toName, ok2 := _TRANSFER_ACCOUNT_CONVERTER[to]
+		if !ok1 || !ok2 || from == to {
+			return "", ErrorUnsupported
+		}
+		params.Set("type", fromName+"_"+toName)
+	}
+
+	var response marginTransferResponse
+	err := bn.doSigned("POST", path, params, &response)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprint(response.TranId), nil
+}
+
+// 币安的子账户为子账户邮箱，在母子账户的现货账户之间划转
+func (bn *Binance) SubAccountTransfer(subAccount string, currency Currency, amount string, toSub bool) (string, error) {
+	params := url.Values{}
+	if toSub {
+		params.Set("toEmail", subAccount)
+	} else {
+		params.Set("fromEmail", subAccount)
+	}
+	params.Set("fromAccountType", "SPOT")
+	params.Set("toAccountType", "SPOT")
+	params.Set("asset", ExchangeCurrency(BINANCE, currency).Symbol())
+	params.Set("amount", amount)
+
+	var response marginTransferResponse
+	err := bn.doSigned("POST", "/sapi/v1/sub-account/universalTransfer", params, &response)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprint(response.TranId), nil
+}
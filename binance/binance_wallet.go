@@ -0,0 +1,161 @@
+package binance
+
+import (
+	. "github.com/betterjun/exapi"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+/**
+ * deposit and withdrawal
+ */
+func NewWalletAPI(client *http.Client, api_key, secret_key string) WalletAPI {
+	return NewSpotAPI(client, api_key, secret_key).(*Binance)
+}
+
+func (bn *Binance) GetDepositAddress(currency Currency, chain string) (*DepositAddress, error) {
+	params := url.Values{}
+	params.Set("coin", ExchangeCurrency(BINANCE, currency).Symbol())
+	if len(chain) > 0 {
+		params.Set("network", chain)
+	}
+
+	var response struct {
+		Address string `json:"address"`
+		Tag     string `json:"tag"`
+	}
+	err := bn.doSigned("GET", "/sapi/v1/capital/deposit/address", params, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DepositAddress{
+		Currency: currency,
+		Chain:    chain,
+		Address:  response.Address,
+		Tag:      response.Tag,
+	}, nil
+}
+
+func (bn *Binance) Withdraw(currency Currency, amount, address, tag, chain string) (string, error) {
+	params := url.Values{}
+	params.Set("coin", ExchangeCurrency(BINANCE, currency).Symbol())
+	params.Set("address", address)
+	params.Set("amount", amount)
+	if len(tag) > 0 {
+		params.Set("addressTag", tag)
+	}
+	if len(chain) > 0 {
+		params.Set("network", chain)
+	}
+
+	var response struct {
+		Id string `json:"id"`
+	}
+	err := bn.doSigned("POST", "/sapi/v1/capital/withdraw/apply", params, &response)
+	if err != nil {
+		return "", err
+	}
+
+	return response.Id, nil
+}
+
+// 币安不支持撤销提币
+func (bn *Binance) CancelWithdraw(id string, currency Currency) (bool, error) {
+	return false, ErrorUnsupported
+}
+
+func (bn *Binance) GetDepositHistory(currency Currency) ([]WalletRecord, error) {
+	params := url.Values{}
+	params.Set("coin", ExchangeCurrency(BINANCE, currency).Symbol())
+
+	var response []struct {
+		Amount     float64 `json:"amount,string"`
+		Network    string  `json:"network"`
+		Status     int     `json:"status"`
+		Address    string  `json:"address"`
+		AddressTag string  `json:"addressTag"`
+		TxId       string  `json:"txId"`
+		InsertTime int64   `json:"insertTime"`
+	}
+	err := bn.doSigned("GET", "/sapi/v1/capital/deposit/hisrec", params, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]WalletRecord, 0, len(response))
+	for _, v := range response {
+		record := WalletRecord{
+			Id:       v.TxId,
+			Type:     WALLET_DEPOSIT,
+			Currency: currency,
+			Chain:    v.Network,
+			Amount:   v.Amount,
+			Address:  v.Address,
+			Tag:      v.AddressTag,
+			TxId:     v.TxId,
+			Status:   WALLET_PENDING,
+			TS:       v.InsertTime,
+		}
+		// 0:处理中 6:已上账但不可提币 1:成功
+		if v.Status == 1 {
+			record.Status = WALLET_SUCCESS
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func (bn *Binance) GetWithdrawHistory(currency Currency) ([]WalletRecord, error) {
+	params := url.Values{}
+	params.Set("coin", ExchangeCurrency(BINANCE, currency).Symbol())
+
+	var response []struct {
+		Id             string  `json:"id"`
+		Amount         float64 `json:"amount,string"`
+		TransactionFee float64 `json:"transactionFee,string"`
+		Network        string  `json:"network"`
+		Status         int     `json:"status"`
+		Address        string  `json:"address"`
+		AddressTag     string  `json:"addressTag"`
+		TxId           string  `json:"txId"`
+		ApplyTime      string  `json:"applyTime"`
+	}
+	err := bn.doSigned("GET", "/sapi/v1/capital/withdraw/history", params, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]WalletRecord, 0, len(response))
+	for _, v := range response {
+		applyTime, _ := time.Parse("2006-01-02 15:04:05", v.ApplyTime)
+		record := WalletRecord{
+			Id:       v.Id,
+			Type:     WALLET_WITHDRAW,
+			Currency: currency,
+			Chain:    v.Network,
+			Amount:   v.Amount,
+			Fee:      v.TransactionFee,
+			Address:  v.Address,
+			Tag:      v.AddressTag,
+			TxId:     v.TxId,
+			TS:       applyTime.UnixNano() / int64(time.Millisecond),
+		}
+		// 0:已发送确认邮件 1:已取消 2:等待确认 3:被拒绝 4:处理中 5:提现交易失败 6:提现完成
+		switch v.Status {
+		case 1:
+			record.Status = WALLET_CANCELED
+		case 3, 5:
+			record.Status = WALLET_FAILED
+		case 6:
+			record.Status = WALLET_SUCCESS
+		default:
+			record.Status = WALLET_PENDING
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
@@ -66,43 +66,35 @@ func (bitz *Bitz) GetURL() string {
 	return bitz.baseUrl
 }
 
-/*
-"symbol": "btcusdt",
-"makerFeeRate":"0.002",
-"takerFeeRate":"0.002",
-"actualMakerRate": "0.002",
-"actualTakerRate":"0.002
-*/
-type TradeFee struct {
-	Symbol          string  `json:"symbol"`
-	ActualMakerRate float64 `json:"actualMakerRate,string"` // 挂单手续费
-	ActualTakerRate float64 `json:"actualTakerRate,string"` // 吃单手续费
-}
-
-func (bitz *Bitz) GetTradeFee(symbols string) (tf *TradeFee, err error) {
-	return &TradeFee{
-		Symbol:          symbols,
-		ActualMakerRate: 0.002,
-		ActualTakerRate: 0.002,
-	}, nil
+var spotCapabilities = &Capabilities{
+	Exchange:           BITZ,
+	UnsupportedMethods: []string{"GetOrderDeal", "GetUserTrades"},
+	KlinePeriods:       KlinePeriodsOf(_INERNAL_KLINE_PERIOD_CONVERTER),
+	OrderSides:         AllOrderSides,
+	WebsocketStreams:   AllStreams,
 }
 
-func (bitz *Bitz) GetTradeFeeMap() (tfmap map[string]TradeFee, err error) {
-	ssm, err := bitz.GetAllCurrencyPair()
+func (bitz *Bitz) Capabilities() *Capabilities {
+	return spotCapabilities.Clone()
+}
+
+// bitz没有账户费率查询接口，返回币对公布的费率
+func (bitz *Bitz) GetTradeFee(pair CurrencyPair) (*TradeFee, error) {
+	tfm, err := bitz.GetTradeFeeMap()
 	if err != nil {
 		return nil, err
 	}
 
-	tfmap = make(map[string]TradeFee)
-	for k, v := range ssm {
-		tfmap[k] = TradeFee{
-			Symbol:          k,
-			ActualMakerRate: v.MakerFee,
-			ActualTakerRate: v.TakerFee,
-		}
+	return TradeFeeOf(tfm, pair)
+}
+
+func (bitz *Bitz) GetTradeFeeMap() (map[string]TradeFee, error) {
+	ssm, err := bitz.GetAllCurrencyPair()
+	if err != nil {
+		return nil, err
 	}
 
-	return tfmap, nil
+	return TradeFeeMapOf(ssm), nil
 }
 
 func (bitz *Bitz) getDataMap(reqUrl string) (map[string]interface{}, error) {
@@ -135,15 +127,6 @@ func (bitz *Bitz) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
 		return nil, err
 	}
 
-	// TODO FIXME: 用一个就可以了，反正写死的
-	tf, err := bitz.GetTradeFee("btc_usdt")
-	if err != nil {
-		tf = &TradeFee{
-			ActualMakerRate: 0.002,
-			ActualTakerRate: 0.002,
-		}
-	}
-
 	ssm := make(map[string]SymbolSetting)
 	for _, v := range datamap {
 		obj := v.(map[string]interface{})
@@ -151,19 +134,26 @@ func (bitz *Bitz) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
 		quote := strings.ToUpper(ToString(obj["coinTo"]))
 		symbol := base + "/" + quote
 
+		pricePrecision := ToInt(obj["priceFloat"])
+		amountPrecision := ToInt(obj["numberFloat"])
 		ssm[symbol] = SymbolSetting{
-			Symbol:      symbol,
-			Base:        base,
-			Quote:       quote,
-			MinSize:     math.Pow10(-ToInt(obj["numberFloat"])),
-			MinPrice:    math.Pow10(-ToInt(obj["priceFloat"])),
-			MinNotional: ToFloat64(obj["minTrade"]),
-			MakerFee:    tf.ActualMakerRate,
-			TakerFee:    tf.ActualTakerRate,
+			Symbol:          symbol,
+			Base:            base,
+			Quote:           quote,
+			MinSize:         math.Pow10(-amountPrecision),
+			MinPrice:        math.Pow10(-pricePrecision),
+			MinNotional:     ToFloat64(obj["minTrade"]),
+			MakerFee:        0.002,
+			TakerFee:        0.002,
+			TickSize:        math.Pow10(-pricePrecision),
+			StepSize:        math.Pow10(-amountPrecision),
+			PricePrecision:  pricePrecision,
+			AmountPrecision: amountPrecision,
+			MaxNotional:     ToFloat64(obj["maxTrade"]),
 		}
 	}
 
-	return ssm, nil
+	return CanonicalSymbolSettings(BITZ, ssm), nil
 }
 
 func (bitz *Bitz) GetCurrencyStatus(currency Currency) (CurrencyStatus, error) {
@@ -234,7 +224,7 @@ func parseMicroTime(respmap map[string]interface{}) (ts int64, err error) {
 }
 
 func (bitz *Bitz) GetTicker(pair CurrencyPair) (*Ticker, error) {
-	url := bitz.baseUrl + "Market/ticker?symbol=" + pair.ToLowerSymbol("_")
+	url := bitz.baseUrl + "Market/ticker?symbol=" + ExchangeLowerSymbol(BITZ, pair, "_")
 	respmap, err := HttpGet(bitz.httpClient, url)
 	if err != nil {
 		return nil, err
@@ -295,7 +285,7 @@ func (bitz *Bitz) GetAllTicker() ([]Ticker, error) {
 			continue
 		}
 
-		pair := NewCurrencyPairFromString(strings.Replace(k, "_", "/", -1))
+		pair := CanonicalPair(BITZ, NewCurrencyPairFromString(strings.Replace(k, "_", "/", -1)))
 		t, err := bitz.parseTicker(pair, tickmap, ts)
 		if err != nil {
 			Error("parse ticker failed:%v", err)
@@ -308,7 +298,7 @@ func (bitz *Bitz) GetAllTicker() ([]Ticker, error) {
 }
 
 func (bitz *Bitz) GetDepth(pair CurrencyPair, size int, step int) (*Depth, error) {
-	url := bitz.baseUrl + "Market/depth?symbol=" + pair.ToLowerSymbol("_")
+	url := bitz.baseUrl + "Market/depth?symbol=" + ExchangeLowerSymbol(BITZ, pair, "_")
 	respmap, err := HttpGet(bitz.httpClient, url)
 	if err != nil {
 		return nil, err
@@ -342,7 +332,7 @@ func (bitz *Bitz) GetDepth(pair CurrencyPair, size int, step int) (*Depth, error
 }
 
 func (bitz *Bitz) GetTrades(pair CurrencyPair, size int) ([]Trade, error) {
-	url := bitz.baseUrl + "Market/order?symbol=" + pair.ToLowerSymbol("_")
+	url := bitz.baseUrl + "Market/order?symbol=" + ExchangeLowerSymbol(BITZ, pair, "_")
 	respmap, err := HttpGet(bitz.httpClient, url)
 	if err != nil {
 		return nil, err
@@ -391,10 +381,13 @@ func (bitz *Bitz) GetTrades(pair CurrencyPair, size int) ([]Trade, error) {
 func (bitz *Bitz) GetKlineRecords(pair CurrencyPair, period KlinePeriod, size, since int) ([]Kline, error) {
 	periodS, isOk := _INERNAL_KLINE_PERIOD_CONVERTER[period]
 	if isOk != true {
-		return nil, fmt.Errorf("unsupported %v KlinePeriod:%v", bitz.GetExchangeName(), period)
+		// 交易所不支持的周期，用较小周期的K线合成
+		return GetKlinesWithResample(spotCapabilities, func(p KlinePeriod, n, t int) ([]Kline, error) {
+			return bitz.GetKlineRecords(pair, p, n, t)
+		}, period, size, since)
 	}
 	url := bitz.baseUrl + "Market/kline?symbol=%s&resolution=%v&size=%v"
-	symbol := pair.ToLowerSymbol("_")
+	symbol := ExchangeLowerSymbol(BITZ, pair, "_")
 	datamap, err := bitz.getDataMap(fmt.Sprintf(url, symbol, periodS, size))
 	if err != nil {
 		return nil, err
@@ -421,6 +414,13 @@ func (bitz *Bitz) GetKlineRecords(pair CurrencyPair, period KlinePeriod, size, s
 	return klines, nil
 }
 
+// 交易所只能查询最近的K线，范围早于可查询的K线时返回ErrorKlineRangeTooOld
+func (bitz *Bitz) GetKlineRange(pair CurrencyPair, period KlinePeriod, start, end time.Time) ([]Kline, []KlineGap, error) {
+	return GetLatestKlineRange(spotCapabilities, func(p KlinePeriod, n, t int) ([]Kline, error) {
+		return bitz.GetKlineRecords(pair, p, n, t)
+	}, period, start, end)
+}
+
 func (bitz *Bitz) LimitBuy(pair CurrencyPair, price, amount string) (*Order, error) {
 	orderId, err := bitz.placeLimitOrder(amount, price, pair, "1")
 	if err != nil {
@@ -532,8 +532,8 @@ func (bitz *Bitz) GetOrder(orderId string, pair CurrencyPair) (*Order, error) {
 func (bitz *Bitz) GetPendingOrders(pair CurrencyPair) ([]Order, error) {
 	requrl := bitz.baseUrl + "Trade/getUserNowEntrustSheet"
 	params := map[string]string{}
-	params["coinFrom"] = pair.Stock.LowerSymbol()
-	params["coinTo"] = pair.Money.LowerSymbol()
+	params["coinFrom"] = ExchangeCurrency(BITZ, pair.Stock).LowerSymbol()
+	params["coinTo"] = ExchangeCurrency(BITZ, pair.Money).LowerSymbol()
 	params["pageSize"] = fmt.Sprint(100)
 
 	respmap, err := bitz.httpPostRequest(requrl, params)
@@ -571,8 +571,8 @@ func (bitz *Bitz) GetPendingOrders(pair CurrencyPair) ([]Order, error) {
 func (bitz *Bitz) GetFinishedOrders(pair CurrencyPair) ([]Order, error) {
 	requrl := bitz.baseUrl + "Trade/getUserHistoryEntrustSheet"
 	params := map[string]string{}
-	params["coinFrom"] = pair.Stock.LowerSymbol()
-	params["coinTo"] = pair.Money.LowerSymbol()
+	params["coinFrom"] = ExchangeCurrency(BITZ, pair.Stock).LowerSymbol()
+	params["coinTo"] = ExchangeCurrency(BITZ, pair.Money).LowerSymbol()
 	params["pageSize"] = fmt.Sprint(100)
 
 	respmap, err := bitz.httpPostRequest(requrl, params)
@@ -652,7 +652,7 @@ func (bitz *Bitz) GetAccount() (*Account, error) {
 		*/
 		balancemap := v.(map[string]interface{})
 		currencySymbol := balancemap["name"].(string)
-		currency := NewCurrency(currencySymbol)
+		currency := CanonicalCurrency(BITZ, currencySymbol)
 
 		acc.SubAccounts[currency] = SubAccount{
 			Currency:     currency,
@@ -678,7 +678,7 @@ func (bitz *Bitz) placeLimitOrder(amount, price string, pair CurrencyPair, order
 	params["type"] = orderType
 	params["price"] = price
 	params["number"] = amount
-	params["symbol"] = pair.ToLowerSymbol("_")
+	params["symbol"] = ExchangeLowerSymbol(BITZ, pair, "_")
 	params["tradePwd"] = bitz.tradePWD
 
 	respmap, err := bitz.httpPostRequest(requrl, params)
@@ -711,7 +711,7 @@ func (bitz *Bitz) placeMarketOrder(amount string, pair CurrencyPair, orderType s
 	*/
 	requrl := bitz.baseUrl + "Trade/MarketTrade"
 	params := map[string]string{}
-	params["symbol"] = pair.ToLowerSymbol("_")
+	params["symbol"] = ExchangeLowerSymbol(BITZ, pair, "_")
 	params["total"] = amount
 	params["type"] = orderType
 
@@ -64,7 +64,7 @@ func (ws *BitzSpotWs) GetExchangeName() string {
 
 // 格式化流名称
 func (ws *BitzSpotWs) FormatTopicName(topic string, pair CurrencyPair) string {
-	symbol := pair.ToLowerSymbol("_")
+	symbol := ExchangeLowerSymbol(BITZ, pair, "_")
 
 	switch topic {
 	case STREAM_TICKER:
@@ -80,7 +80,7 @@ func (ws *BitzSpotWs) FormatTopicName(topic string, pair CurrencyPair) string {
 
 // 格式化流订阅消息
 func (ws *BitzSpotWs) FormatTopicSubData(topic string, pair CurrencyPair) []byte {
-	symbol := pair.ToLowerSymbol("_")
+	symbol := ExchangeLowerSymbol(BITZ, pair, "_")
 
 	switch topic {
 	case STREAM_TICKER:
@@ -99,7 +99,7 @@ func (ws *BitzSpotWs) FormatTopicSubData(topic string, pair CurrencyPair) []byte
 
 // 格式化流取消订阅消息
 func (ws *BitzSpotWs) FormatTopicUnsubData(topic string, pair CurrencyPair) []byte {
-	symbol := pair.ToLowerSymbol("_")
+	symbol := ExchangeLowerSymbol(BITZ, pair, "_")
 
 	switch topic {
 	case STREAM_TICKER:
@@ -163,7 +163,10 @@ func (ws *BitzSpotWs) OnMessage(data []byte) (err error) {
 			}
 			k, _ := params["symbol"].(string)
 			pushType, _ := params["type"].(string) // 以此字段是否存在来判定是全量还是增量推送,存在这个字段，则是全量
-			pair := NewCurrencyPairFromString(strings.Replace(k, "_", "/", -1))
+			pair, ok := ws.TopicMap.Load(fmt.Sprintf("depth.%v", strings.ToLower(k)))
+			if !ok {
+				return nil
+			}
 			depth := ws.parseDepth(resp.Data, pair, len(pushType) != 0)
 			if depth != nil {
 				depth.TS = resp.Ts
@@ -179,7 +182,10 @@ func (ws *BitzSpotWs) OnMessage(data []byte) (err error) {
 				return nil
 			}
 			k, _ := params["symbol"].(string)
-			pair := NewCurrencyPairFromString(strings.Replace(k, "_", "/", -1))
+			pair, ok := ws.TopicMap.Load(fmt.Sprintf("order.%v", strings.ToLower(k)))
+			if !ok {
+				return nil
+			}
 			trade := ws.parseTrade(resp.Data, pair)
 			ws.OnTrade(trade)
 		}
@@ -194,9 +200,9 @@ func (ws *BitzSpotWs) OnMessage(data []byte) (err error) {
 func (ws *BitzSpotWs) parseTicker(msg interface{}, ts int64) (ticker *Ticker) {
 	tickerMap := msg.(map[string]interface{})
 	for k, v := range tickerMap {
-		pair := NewCurrencyPairFromString(strings.Replace(k, "_", "/", -1))
-		pairExist := ws.GetPairByStream(ws.FormatTopicName(STREAM_TICKER, pair))
-		if pair != pairExist {
+		// 推送所有交易对的行情，只处理已订阅的交易对
+		pair, ok := ws.TopicMap.Load(fmt.Sprintf("market.%v", strings.ToLower(k)))
+		if !ok {
 			continue
 		}
 		obj, _ := v.(map[string]interface{})
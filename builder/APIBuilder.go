@@ -28,6 +28,8 @@ type APIBuilder struct {
 	secretkey     string
 	clientId      string
 	apiPassphrase string
+	tradePassword string
+	accountType   AccountType
 }
 
 func NewAPIBuilder() (builder *APIBuilder) {
@@ -93,13 +95,46 @@ func (builder *APIBuilder) ApiPassphrase(apiPassphrase string) (_builder *APIBui
 	return builder
 }
 
-// 使用默认交易所连接地址构建
+// 资金密码，okex和zb提币时使用
+func (builder *APIBuilder) TradePassword(tradePassword string) (_builder *APIBuilder) {
+	builder.tradePassword = tradePassword
+	return builder
+}
+
+// SpotAPI.GetAccount读取的账户类型，为0时使用现货账户，BuildSpot和BuildTransfer构建时设置
+func (builder *APIBuilder) AccountType(accountType AccountType) (_builder *APIBuilder) {
+	builder.accountType = accountType
+	return builder
+}
+
+// 设置构建的接口的账户类型，交易所不支持时返回false
+func (builder *APIBuilder) setAccountType(api interface{}) bool {
+	if builder.accountType == 0 {
+		return true
+	}
+	a, ok := api.(AccountTypeAPI)
+	return ok && a.SetAccountType(builder.accountType) == nil
+}
+
+// 使用默认交易所连接地址构建，设置了AccountType而交易所不支持时返回nil
 func (builder *APIBuilder) BuildSpot(exName string) (api SpotAPI) {
 	return builder.BuildSpotWithURL(exName, "")
 }
 
 // 使用自定义交易所连接地址构建
 func (builder *APIBuilder) BuildSpotWithURL(exName, wsURL string) (api SpotAPI) {
+	api = builder.newSpot(exName)
+	if api == nil || !builder.setAccountType(api) {
+		return nil
+	}
+	if len(wsURL) > 0 {
+		api.SetURL(wsURL)
+	}
+	return api
+}
+
+// 构建现货接口，不设置账户类型
+func (builder *APIBuilder) newSpot(exName string) (api SpotAPI) {
 	switch exName {
 	case HUOBI:
 		api = huobi.NewSpotAPI(builder.client, builder.apiKey, builder.secretkey)
@@ -126,9 +161,6 @@ func (builder *APIBuilder) BuildSpotWithURL(exName, wsURL string) (api SpotAPI)
 	default:
 		return nil
 	}
-	if len(wsURL) > 0 {
-		api.SetURL(wsURL)
-	}
 	return api
 }
 
@@ -165,16 +197,150 @@ func (builder *APIBuilder) BuildSpotWebsocketWithURL(exName, wsURL, proxyURL str
 	return ws, err
 }
 
-//
-//func (builder *APIBuilder) BuildFuture(exName string) (api FutureRestAPI) {
-//	switch exName {
-//	case OKEX_FUTURE:
-//		return okcoin.NewSpotAPI(builder.client, builder.apiKey, builder.secretkey)
-//	case HBDM:
-//		return huobi.NewHbdm(&APIConfig{HttpClient: builder.client, ApiKey: builder.apiKey, ApiSecretKey: builder.secretkey})
-//	case OKEX_SWAP:
-//		return okex.NewOKExSwap(&APIConfig{HttpClient: builder.client, Endpoint: "https://www.okex.com", ApiKey: builder.apiKey, ApiSecretKey: builder.secretkey, ApiPassphrase: builder.apiPassphrase})
-//	default:
-//		return nil
-//	}
-//}
+// 使用默认交易所连接地址构建交割合约接口
+func (builder *APIBuilder) BuildFuture(exName string) (api FutureRestAPI) {
+	return builder.BuildFutureWithURL(exName, "")
+}
+
+// 使用自定义交易所连接地址构建交割合约接口
+func (builder *APIBuilder) BuildFutureWithURL(exName, exURL string) (api FutureRestAPI) {
+	switch exName {
+	case OKEX_FUTURE:
+		api = okex.NewFutureAPI(builder.client, builder.apiKey, builder.secretkey, builder.apiPassphrase)
+	case HBDM:
+		api = huobi.NewFutureAPI(builder.client, builder.apiKey, builder.secretkey)
+	default:
+		return nil
+	}
+	if len(exURL) > 0 {
+		api.SetURL(exURL)
+	}
+	return api
+}
+
+// 使用默认交易所连接地址构建永续合约接口
+func (builder *APIBuilder) BuildSwap(exName string) (api SwapAPI) {
+	return builder.BuildSwapWithURL(exName, "")
+}
+
+// 使用自定义交易所连接地址构建永续合约接口
+func (builder *APIBuilder) BuildSwapWithURL(exName, exURL string) (api SwapAPI) {
+	switch exName {
+	case OKEX_SWAP:
+		api = okex.NewSwapAPI(builder.client, builder.apiKey, builder.secretkey, builder.apiPassphrase)
+	case HBDM:
+		api = huobi.NewSwapAPI(builder.client, builder.apiKey, builder.secretkey)
+	case BINANCE:
+		api = binance.NewSwapAPI(builder.client, builder.apiKey, builder.secretkey)
+	default:
+		return nil
+	}
+	if len(exURL) > 0 {
+		api.SetURL(exURL)
+	}
+	return api
+}
+
+// 使用默认交易所连接地址构建逐仓杠杆接口
+func (builder *APIBuilder) BuildMargin(exName string) (api MarginAPI) {
+	return builder.BuildMarginWithURL(exName, "")
+}
+
+// 使用自定义交易所连接地址构建逐仓杠杆接口
+func (builder *APIBuilder) BuildMarginWithURL(exName, exURL string) (api MarginAPI) {
+	switch exName {
+	case HUOBI:
+		api = huobi.NewMarginAPI(builder.client, builder.apiKey, builder.secretkey)
+	case BINANCE:
+		api = binance.NewMarginAPI(builder.client, builder.apiKey, builder.secretkey)
+	case OKEX:
+		api = okex.NewMarginAPI(builder.client, builder.apiKey, builder.secretkey, builder.apiPassphrase)
+	default:
+		return nil
+	}
+	if len(exURL) > 0 {
+		api.SetURL(exURL)
+	}
+	return api
+}
+
+// 使用默认交易所连接地址构建充提币接口
+func (builder *APIBuilder) BuildWallet(exName string) (api WalletAPI) {
+	return builder.BuildWalletWithURL(exName, "")
+}
+
+// 使用自定义交易所连接地址构建充提币接口
+func (builder *APIBuilder) BuildWalletWithURL(exName, exURL string) (api WalletAPI) {
+	switch exName {
+	case HUOBI:
+		api = huobi.NewWalletAPI(builder.client, builder.apiKey, builder.secretkey)
+	case BINANCE:
+		api = binance.NewWalletAPI(builder.client, builder.apiKey, builder.secretkey)
+	case OKEX:
+		api = okex.NewWalletAPI(builder.client, builder.apiKey, builder.secretkey, builder.apiPassphrase, builder.tradePassword)
+	case GATE:
+		api = gate.NewWalletAPI(builder.client, builder.apiKey, builder.secretkey)
+	case COINEX:
+		api = coinex.NewWalletAPI(builder.client, builder.apiKey, builder.secretkey)
+	case ZB:
+		api = zb.NewWalletAPI(builder.client, builder.apiKey, builder.secretkey, builder.tradePassword)
+	default:
+		return nil
+	}
+	if len(exURL) > 0 {
+		api.SetURL(exURL)
+	}
+	return api
+}
+
+// 使用默认交易所连接地址构建资金划转接口
+func (builder *APIBuilder) BuildTransfer(exName string) (api TransferAPI) {
+	return builder.BuildTransferWithURL(exName, "")
+}
+
+// 使用自定义交易所连接地址构建资金划转接口
+func (builder *APIBuilder) BuildTransferWithURL(exName, exURL string) (api TransferAPI) {
+	switch exName {
+	case HUOBI:
+		api = huobi.NewTransferAPI(builder.client, builder.apiKey, builder.secretkey)
+	case BINANCE:
+		api = binance.NewTransferAPI(builder.client, builder.apiKey, builder.secretkey)
+	case OKEX:
+		api = okex.NewTransferAPI(builder.client, builder.apiKey, builder.secretkey, builder.apiPassphrase)
+	default:
+		return nil
+	}
+	if !builder.setAccountType(api) {
+		return nil
+	}
+	if len(exURL) > 0 {
+		api.SetURL(exURL)
+	}
+	return api
+}
+
+// 使用默认交易所连接地址构建手续费接口
+func (builder *APIBuilder) BuildFee(exName string) (api FeeAPI) {
+	return builder.BuildFeeWithURL(exName, "")
+}
+
+// 使用自定义交易所连接地址构建手续费接口，所有现货接口都实现了手续费接口
+func (builder *APIBuilder) BuildFeeWithURL(exName, exURL string) (api FeeAPI) {
+	spot := builder.newSpot(exName)
+	if spot == nil {
+		return nil
+	}
+	if len(exURL) > 0 {
+		spot.SetURL(exURL)
+	}
+	api, _ = spot.(FeeAPI)
+	return api
+}
+
+// 获取交易所现货接口的能力描述，不支持的交易所返回nil
+func (builder *APIBuilder) Capabilities(exName string) *Capabilities {
+	if c, ok := builder.newSpot(exName).(CapabilitiesAPI); ok {
+		return c.Capabilities()
+	}
+	return nil
+}
@@ -23,3 +23,77 @@ func TestAPIBuilder_BuildSpotWebsocket(t *testing.T) {
 	//builder.APIKey("").APISecretkey("").BuildSpotWebsocket(exapi.ZB, "")
 	//builder.APIKey("").APISecretkey("").BuildSpotWebsocket(exapi.GATE, "")
 }
+
+func TestAPIBuilder_BuildFuture(t *testing.T) {
+	assert.Equal(t, builder.APIKey("").APISecretkey("").BuildFuture(exapi.OKEX_FUTURE).GetExchangeName(), exapi.OKEX_FUTURE)
+	assert.Equal(t, builder.APIKey("").APISecretkey("").BuildFuture(exapi.HBDM).GetExchangeName(), exapi.HBDM)
+}
+
+func TestAPIBuilder_BuildSwap(t *testing.T) {
+	assert.Equal(t, builder.APIKey("").APISecretkey("").BuildSwap(exapi.OKEX_SWAP).GetExchangeName(), exapi.OKEX_SWAP)
+	assert.Equal(t, builder.APIKey("").APISecretkey("").BuildSwap(exapi.HBDM).GetExchangeName(), exapi.HBDM)
+	assert.Equal(t, builder.APIKey("").APISecretkey("").BuildSwap(exapi.BINANCE).GetExchangeName(), exapi.BINANCE)
+	assert.Nil(t, builder.BuildSwap(exapi.ZB))
+}
+
+func TestAPIBuilder_BuildMargin(t *testing.T) {
+	assert.Equal(t, builder.APIKey("").APISecretkey("").BuildMargin(exapi.HUOBI).GetExchangeName(), exapi.HUOBI)
+	assert.Equal(t, builder.APIKey("").APISecretkey("").BuildMargin(exapi.BINANCE).GetExchangeName(), exapi.BINANCE)
+	assert.Equal(t, builder.APIKey("").APISecretkey("").BuildMargin(exapi.OKEX).GetExchangeName(), exapi.OKEX)
+	assert.Nil(t, builder.BuildMargin(exapi.ZB))
+}
+
+func TestAPIBuilder_BuildWallet(t *testing.T) {
+	for _, exName := range []string{exapi.HUOBI, exapi.BINANCE, exapi.OKEX, exapi.GATE, exapi.COINEX, exapi.ZB} {
+		assert.Equal(t, builder.APIKey("").APISecretkey("").BuildWallet(exName).GetExchangeName(), exName)
+	}
+	assert.Nil(t, builder.BuildWallet(exapi.BITZ))
+}
+
+func TestAPIBuilder_BuildTransfer(t *testing.T) {
+	for _, exName := range []string{exapi.HUOBI, exapi.BINANCE, exapi.OKEX} {
+		api := builder.BuildTransfer(exName)
+		assert.Equal(t, api.GetExchangeName(), exName)
+		assert.Equal(t, api.SetAccountType(exapi.ACCOUNT_MARGIN), exapi.ErrorUnsupported)
+	}
+	assert.Nil(t, builder.BuildTransfer(exapi.ZB))
+}
+
+func TestAPIBuilder_AccountType(t *testing.T) {
+	b := NewAPIBuilder().AccountType(exapi.ACCOUNT_POINT)
+	api, ok := b.BuildSpot(exapi.HUOBI).(exapi.AccountTypeAPI)
+	assert.True(t, ok)
+	assert.Nil(t, api.SetAccountType(exapi.ACCOUNT_SPOT))
+	assert.NotNil(t, b.BuildTransfer(exapi.HUOBI))
+	// 交易所不支持设置的账户类型
+	assert.Nil(t, b.BuildSpot(exapi.OKEX))
+	assert.Nil(t, b.BuildTransfer(exapi.BINANCE))
+	assert.Nil(t, b.BuildSpot(exapi.ZB))
+	assert.NotNil(t, b.BuildFee(exapi.ZB))
+	assert.NotNil(t, b.Capabilities(exapi.ZB))
+	assert.Equal(t, b.AccountType(0).BuildSpot(exapi.ZB).GetExchangeName(), exapi.ZB)
+}
+
+func TestAPIBuilder_BuildFee(t *testing.T) {
+	for _, exName := range []string{exapi.HUOBI, exapi.BINANCE, exapi.OKEX, exapi.ZB, exapi.GATE, exapi.ET,
+		exapi.COINEX, exapi.BITZ, exapi.AOFEX, exapi.JBEX, exapi.UPEX} {
+		assert.Equal(t, builder.BuildFee(exName).GetExchangeName(), exName)
+	}
+}
+
+func TestAPIBuilder_Capabilities(t *testing.T) {
+	for _, exName := range []string{exapi.HUOBI, exapi.BINANCE, exapi.OKEX, exapi.ZB, exapi.GATE, exapi.ET,
+		exapi.COINEX, exapi.BITZ, exapi.AOFEX, exapi.JBEX, exapi.UPEX} {
+		assert.Equal(t, builder.Capabilities(exName).Exchange, exName)
+	}
+
+	hb := builder.Capabilities(exapi.HUOBI)
+	assert.False(t, hb.IsNativeKlinePeriod(exapi.KLINE_H4))
+	assert.True(t, hb.SupportsKlinePeriod(exapi.KLINE_H4))
+	assert.False(t, builder.Capabilities(exapi.UPEX).SupportsKlinePeriod(exapi.KLINE_H4))
+	assert.True(t, hb.SupportsDepthStep(5))
+	assert.False(t, builder.Capabilities(exapi.GATE).SupportsOrderSide(exapi.BUY_MARKET))
+	assert.False(t, builder.Capabilities(exapi.ZB).Supports("GetOrderDeal"))
+	assert.False(t, builder.Capabilities(exapi.AOFEX).SupportsStream(exapi.STREAM_TICKER))
+	assert.Nil(t, builder.Capabilities("unknown"))
+}
@@ -0,0 +1,127 @@
+package exapi
+
+import "reflect"
+
+/*
+交易所现货接口的能力描述。
+调用方可以在调用前判断交易所是否支持某个功能，避免在运行时才得到ErrorUnsupported。
+*/
+type Capabilities struct {
+	Exchange string // 交易所名称
+	// 不支持的SpotAPI方法名，调用时返回ErrorUnsupported
+	UnsupportedMethods []string
+	// 交易所原生支持的K线周期，按周期从小到大排列，其他周期由GetKlineRecords用较小周期合成，
+	// 由适配器的K线周期转换表生成
+	KlinePeriods []KlinePeriod
+	// GetKlineRecords单次最多返回的条数，0表示交易所未公开
+	MaxKlineSize int
+	// GetTrades单次最多返回的条数，0表示交易所未公开
+	MaxTradeSize int
+	// GetDepth的最大挡位，0表示忽略size参数或交易所未公开上限
+	MaxDepthSize int
+	// GetDepth支持的聚合挡位，为空表示忽略step参数
+	DepthSteps []int
+	// 支持的下单类型
+	OrderSides []TradeSide
+	// SpotWebsocket支持的行情推送，为空表示没有websocket接口
+	WebsocketStreams []StreamType
+}
+
+// 能力描述接口，所有现货接口都实现了此接口
+type CapabilitiesAPI interface {
+	Capabilities() *Capabilities
+}
+
+// 返回副本，调用方修改副本不会影响交易所的能力描述
+func (c *Capabilities) Clone() *Capabilities {
+	cc := *c
+	cc.UnsupportedMethods = append([]string(nil), c.UnsupportedMethods...)
+	cc.KlinePeriods = append([]KlinePeriod(nil), c.KlinePeriods...)
+	cc.DepthSteps = append([]int(nil), c.DepthSteps...)
+	cc.OrderSides = append([]TradeSide(nil), c.OrderSides...)
+	cc.WebsocketStreams = append([]StreamType(nil), c.WebsocketStreams...)
+	return &cc
+}
+
+// 是否支持SpotAPI的方法
+func (c *Capabilities) Supports(method string) bool {
+	for _, m := range c.UnsupportedMethods {
+		if m == method {
+			return false
+		}
+	}
+	return true
+}
+
+// 是否支持K线周期，包括可以用较小周期合成的周期
+func (c *Capabilities) SupportsKlinePeriod(period KlinePeriod) bool {
+	if c.IsNativeKlinePeriod(period) {
+		return true
+	}
+	_, ok := ResampleSource(period, c.KlinePeriods)
+	return ok
+}
+
+// 是否为交易所原生支持的K线周期
+func (c *Capabilities) IsNativeKlinePeriod(period KlinePeriod) bool {
+	for _, p := range c.KlinePeriods {
+		if p == period {
+			return true
+		}
+	}
+	return false
+}
+
+// 是否支持下单类型
+func (c *Capabilities) SupportsOrderSide(side TradeSide) bool {
+	for _, s := range c.OrderSides {
+		if s == side {
+			return true
+		}
+	}
+	return false
+}
+
+// 是否支持行情推送
+func (c *Capabilities) SupportsStream(stream StreamType) bool {
+	for _, s := range c.WebsocketStreams {
+		if s == stream {
+			return true
+		}
+	}
+	return false
+}
+
+// 是否支持深度聚合挡位，默认聚合总是支持的
+func (c *Capabilities) SupportsDepthStep(step int) bool {
+	if step == Depth_Aggregate_Default {
+		return true
+	}
+	for _, s := range c.DepthSteps {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+// 所有下单类型
+var AllOrderSides = []TradeSide{BUY, SELL, BUY_MARKET, SELL_MARKET}
+
+// 所有行情推送
+var AllStreams = []StreamType{STREAM_TICKER, STREAM_DEPTH, STREAM_TRADE}
+
+// 所有K线周期，按周期从小到大排列
+var AllKlinePeriods = []KlinePeriod{KLINE_M1, KLINE_M5, KLINE_M15, KLINE_M30, KLINE_H1, KLINE_H4, KLINE_DAY, KLINE_WEEK, KLINE_MONTH}
+
+// 适配器的K线周期转换表中的周期，按周期从小到大排列，converter为key为KlinePeriod的map
+func KlinePeriodsOf(converter interface{}) []KlinePeriod {
+	m := reflect.ValueOf(converter)
+	var periods []KlinePeriod
+	for _, p := range AllKlinePeriods {
+		if m.MapIndex(reflect.ValueOf(p)).IsValid() {
+			periods = append(periods, p)
+		}
+	}
+	return periods
+}
@@ -0,0 +1,20 @@
+package exapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCapabilities(t *testing.T) {
+	converter := map[KlinePeriod]string{KLINE_DAY: "1day", KLINE_M1: "1min", KLINE_H1: "60min"}
+	assert.Equal(t, []KlinePeriod{KLINE_M1, KLINE_H1, KLINE_DAY}, KlinePeriodsOf(converter))
+	assert.Nil(t, KlinePeriodsOf(map[KlinePeriod]int{}))
+
+	caps := &Capabilities{Exchange: "test", KlinePeriods: KlinePeriodsOf(converter), OrderSides: AllOrderSides}
+	c := caps.Clone()
+	c.KlinePeriods[0] = KLINE_WEEK
+	c.OrderSides = append(c.OrderSides[:0], BUY)
+	assert.Equal(t, KLINE_M1, caps.KlinePeriods[0])
+	assert.Equal(t, SELL, AllOrderSides[1])
+	assert.True(t, caps.SupportsKlinePeriod(KLINE_H4))
+}
@@ -21,6 +21,7 @@ type CoinEx struct {
 	accessKey  string
 	secretKey  string
 	baseurl    string
+	symbols    *SymbolIndex // 交易对名称到交易对的索引
 }
 
 func NewSpotAPI(client *http.Client, apiKey, secretKey string) SpotAPI {
@@ -30,6 +31,7 @@ func NewSpotAPI(client *http.Client, apiKey, secretKey string) SpotAPI {
 		secretKey:  secretKey,
 		baseurl:    "https://api.coinex.com/v1/",
 	}
+	coinex.symbols = NewSymbolIndex(COINEX, coinex.GetAllCurrencyPair)
 	return coinex
 }
 
@@ -45,6 +47,18 @@ func (coinex *CoinEx) GetURL() string {
 	return coinex.baseurl
 }
 
+var spotCapabilities = &Capabilities{
+	Exchange:         COINEX,
+	KlinePeriods:     KlinePeriodsOf(_INERNAL_KLINE_PERIOD_CONVERTER),
+	MaxDepthSize:     50,
+	OrderSides:       AllOrderSides,
+	WebsocketStreams: AllStreams,
+}
+
+func (coinex *CoinEx) Capabilities() *Capabilities {
+	return spotCapabilities.Clone()
+}
+
 func checkResult(resp map[string]interface{}) error {
 	code, ok := resp["code"].(float64)
 	if !ok {
@@ -58,19 +72,24 @@ func checkResult(resp map[string]interface{}) error {
 	return fmt.Errorf("code=%v, msg=%v", code, msg)
 }
 
-//
-//type TradeFee struct {
-//	Maker float64 `json:"maker,string"` // 挂单手续费
-//	Taker float64 `json:"taker,string"` // 吃单手续费
-//}
-//
-//func (coinex *CoinEx) GetTradeFee() (tf *TradeFee, err error) {
-//	tf = &TradeFee{
-//		Maker: 0.002,
-//		Taker: 0.002,
-//	}
-//	return tf, nil
-//}
+// coinex没有账户费率查询接口，返回币对公布的费率
+func (coinex *CoinEx) GetTradeFee(pair CurrencyPair) (*TradeFee, error) {
+	tfm, err := coinex.GetTradeFeeMap()
+	if err != nil {
+		return nil, err
+	}
+
+	return TradeFeeOf(tfm, pair)
+}
+
+func (coinex *CoinEx) GetTradeFeeMap() (map[string]TradeFee, error) {
+	ssm, err := coinex.GetAllCurrencyPair()
+	if err != nil {
+		return nil, err
+	}
+
+	return TradeFeeMapOf(ssm), nil
+}
 
 // 获取支持的交易对
 func (coinex *CoinEx) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
@@ -97,19 +116,25 @@ func (coinex *CoinEx) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
 		quote := ToString(obj["pricing_name"])
 
 		symbol := base + "/" + quote
+		pricePrecision := ToInt(obj["pricing_decimal"])
+		amountPrecision := ToInt(obj["trading_decimal"])
 		ssm[symbol] = SymbolSetting{
-			Symbol:      symbol,
-			Base:        base,
-			Quote:       quote,
-			MinSize:     math.Pow10(-ToInt(obj["trading_decimal"])),
-			MinPrice:    math.Pow10(-ToInt(obj["pricing_decimal"])),
-			MinNotional: ToFloat64(obj["min_amount"]),
-			MakerFee:    ToFloat64(obj["maker_fee_rate"]),
-			TakerFee:    ToFloat64(obj["taker_fee_rate"]),
+			Symbol:          symbol,
+			Base:            base,
+			Quote:           quote,
+			MinSize:         math.Pow10(-amountPrecision),
+			MinPrice:        math.Pow10(-pricePrecision),
+			MinNotional:     ToFloat64(obj["min_amount"]),
+			MakerFee:        ToFloat64(obj["maker_fee_rate"]),
+			TakerFee:        ToFloat64(obj["taker_fee_rate"]),
+			TickSize:        math.Pow10(-pricePrecision),
+			StepSize:        math.Pow10(-amountPrecision),
+			PricePrecision:  pricePrecision,
+			AmountPrecision: amountPrecision,
 		}
 	}
 
-	return ssm, nil
+	return CanonicalSymbolSettings(COINEX, ssm), nil
 }
 
 // 获取此币种是否可以充提币
@@ -133,21 +158,39 @@ func (coinex *CoinEx) GetAllCurrencyStatus() (map[string]CurrencyStatus, error)
 		return nil, err
 	}
 
+	// 多链币种按 币种-链 返回，如USDT-ERC20，这里按币种合并
 	all := make(map[string]CurrencyStatus)
 	for k, v := range datamap {
 		obj, _ := v.(map[string]interface{})
-		all[k] = CurrencyStatus{
-			Deposit:  ToBool(obj["can_deposit"]),
-			Withdraw: ToBool(obj["can_withdraw"]),
+		currency := ToString(obj["asset"])
+		if len(currency) == 0 {
+			currency = k
 		}
+
+		cs := ChainStatus{
+			Chain:             ToString(obj["chain"]),
+			IsDefault:         k == currency,
+			Deposit:           ToBool(obj["can_deposit"]),
+			Withdraw:          ToBool(obj["can_withdraw"]),
+			WithdrawFee:       ToFloat64(obj["withdraw_tx_fee"]),
+			MinWithdraw:       ToFloat64(obj["withdraw_least_amount"]),
+			MinDeposit:        ToFloat64(obj["deposit_least_amount"]),
+			WithdrawPrecision: ToInt(obj["withdrawal_precision"]),
+		}
+
+		status := all[currency]
+		status.Deposit = status.Deposit || cs.Deposit
+		status.Withdraw = status.Withdraw || cs.Withdraw
+		status.Chains = append(status.Chains, cs)
+		all[currency] = status
 	}
 
-	return all, nil
+	return CanonicalCurrencyStatus(COINEX, all), nil
 }
 
 func (coinex *CoinEx) GetTicker(pair CurrencyPair) (*Ticker, error) {
 	params := url.Values{}
-	params.Set("market", pair.ToSymbol(""))
+	params.Set("market", ExchangeSymbol(COINEX, pair, ""))
 	datamap, err := coinex.doRequest("GET", "market/ticker", &params)
 	if err != nil {
 		return nil, err
@@ -164,6 +207,11 @@ func (coinex *CoinEx) GetTicker(pair CurrencyPair) (*Ticker, error) {
 }
 
 func (coinex *CoinEx) GetAllTicker() ([]Ticker, error) {
+	// 交易对索引获取失败时无法转换交易对名称
+	if err := coinex.symbols.Load(); err != nil {
+		return nil, err
+	}
+
 	params := url.Values{}
 	datamap, err := coinex.doRequest("GET", "market/ticker/all", &params)
 	if err != nil {
@@ -177,11 +225,10 @@ func (coinex *CoinEx) GetAllTicker() ([]Ticker, error) {
 	for k, v := range tickermap {
 		tm := v.(map[string]interface{})
 		ticker := coinex.parseTicker(tm)
-		base, quote := getSymbol(k)
-		if len(base) == 0 || len(quote) == 0 {
+		pair, err := coinex.symbols.Pair(k)
+		if err != nil {
 			continue
 		}
-		pair := NewCurrencyPairFromString(base + "/" + quote)
 		ticker.Market = pair
 		ticker.Symbol = pair.ToLowerSymbol("/")
 		ticker.TS = ts
@@ -204,22 +251,9 @@ func (coinex *CoinEx) parseTicker(tickermap map[string]interface{}) *Ticker {
 	return ticker
 }
 
-func getSymbol(market string) (base, quote string) {
-	fiatCoin := []string{"BTC", "BCH", "ETH", "CET", "USDT", "USDC", "TUSD", "PAX"}
-
-	for _, v := range fiatCoin {
-		if strings.HasSuffix(market, v) {
-			p := strings.LastIndex(market, v)
-			return market[0:p], v
-		}
-	}
-
-	return "", ""
-}
-
 func (coinex *CoinEx) GetDepth(pair CurrencyPair, size int, step int) (*Depth, error) {
 	params := url.Values{}
-	params.Set("market", pair.ToSymbol(""))
+	params.Set("market", ExchangeSymbol(COINEX, pair, ""))
 	params.Set("merge", "0.00000001")
 	params.Set("limit", fmt.Sprint(size))
 
@@ -257,7 +291,7 @@ func (coinex *CoinEx) GetDepth(pair CurrencyPair, size int, step int) (*Depth, e
 //非个人，整个交易所的交易记录
 func (coinex *CoinEx) GetTrades(pair CurrencyPair, size int) ([]Trade, error) {
 	params := url.Values{}
-	params.Set("market", pair.ToSymbol(""))
+	params.Set("market", ExchangeSymbol(COINEX, pair, ""))
 	//params.Set("limit", fmt.Sprint(size))
 	resp, err := coinex.doRequestInner("GET", "market/deals", &params)
 	if err != nil {
@@ -316,11 +350,14 @@ var _INERNAL_KLINE_PERIOD_CONVERTER = map[KlinePeriod]string{
 func (coinex *CoinEx) GetKlineRecords(pair CurrencyPair, period KlinePeriod, size, since int) ([]Kline, error) {
 	periodS, isOk := _INERNAL_KLINE_PERIOD_CONVERTER[period]
 	if isOk != true {
-		return nil, fmt.Errorf("unsupported %v KlinePeriod:%v", coinex.GetExchangeName(), period)
+		// 交易所不支持的周期，用较小周期的K线合成
+		return GetKlinesWithResample(spotCapabilities, func(p KlinePeriod, n, t int) ([]Kline, error) {
+			return coinex.GetKlineRecords(pair, p, n, t)
+		}, period, size, since)
 	}
 
 	params := url.Values{}
-	params.Set("market", pair.ToSymbol(""))
+	params.Set("market", ExchangeSymbol(COINEX, pair, ""))
 	params.Set("limit", fmt.Sprint(size))
 	params.Set("type", periodS)
 	resp, err := coinex.doRequestInner("GET", "market/kline", &params)
@@ -359,9 +396,16 @@ func (coinex *CoinEx) GetKlineRecords(pair CurrencyPair, period KlinePeriod, siz
 	return klines, nil
 }
 
+// 交易所只能查询最近的K线，范围早于可查询的K线时返回ErrorKlineRangeTooOld
+func (coinex *CoinEx) GetKlineRange(pair CurrencyPair, period KlinePeriod, start, end time.Time) ([]Kline, []KlineGap, error) {
+	return GetLatestKlineRange(spotCapabilities, func(p KlinePeriod, n, t int) ([]Kline, error) {
+		return coinex.GetKlineRecords(pair, p, n, t)
+	}, period, start, end)
+}
+
 func (coinex *CoinEx) placeLimitOrder(side, amount, price string, pair CurrencyPair) (*Order, error) {
 	params := url.Values{}
-	params.Set("market", pair.ToSymbol(""))
+	params.Set("market", ExchangeSymbol(COINEX, pair, ""))
 	params.Set("type", side)
 	params.Set("amount", amount)
 	params.Set("price", price)
@@ -386,7 +430,7 @@ func (coinex *CoinEx) LimitSell(pair CurrencyPair, price, amount string) (*Order
 
 func (coinex *CoinEx) placeMarketOrder(side, amount string, pair CurrencyPair) (*Order, error) {
 	params := url.Values{}
-	params.Set("market", pair.ToSymbol(""))
+	params.Set("market", ExchangeSymbol(COINEX, pair, ""))
 	params.Set("type", side)
 	params.Set("amount", amount)
 
@@ -411,7 +455,7 @@ func (coinex *CoinEx) MarketSell(pair CurrencyPair, amount string) (*Order, erro
 func (coinex *CoinEx) Cancel(orderId string, pair CurrencyPair) (bool, error) {
 	params := url.Values{}
 	params.Set("id", orderId)
-	params.Set("market", pair.ToSymbol(""))
+	params.Set("market", ExchangeSymbol(COINEX, pair, ""))
 	_, err := coinex.doRequest("DELETE", "order/pending", &params)
 	if err != nil {
 		return false, err
@@ -422,7 +466,7 @@ func (coinex *CoinEx) Cancel(orderId string, pair CurrencyPair) (bool, error) {
 func (coinex *CoinEx) GetOrder(orderId string, pair CurrencyPair) (*Order, error) {
 	params := url.Values{}
 	params.Set("id", orderId)
-	params.Set("market", pair.ToSymbol(""))
+	params.Set("market", ExchangeSymbol(COINEX, pair, ""))
 	datamap, err := coinex.doRequest("GET", "order/status", &params)
 	if err != nil {
 		if strings.Contains(err.Error(), "Order not found") {
@@ -438,7 +482,7 @@ func (coinex *CoinEx) GetPendingOrders(pair CurrencyPair) ([]Order, error) {
 	params := url.Values{}
 	params.Set("page", fmt.Sprint(1))
 	params.Set("limit", fmt.Sprint(100))
-	params.Set("market", pair.ToSymbol(""))
+	params.Set("market", ExchangeSymbol(COINEX, pair, ""))
 
 	retmap, err := coinex.doRequest("GET", "order/pending", &params)
 	if err != nil {
@@ -463,7 +507,7 @@ func (coinex *CoinEx) GetFinishedOrders(pair CurrencyPair) ([]Order, error) {
 	params := url.Values{}
 	params.Set("page", fmt.Sprint(1))
 	params.Set("limit", fmt.Sprint(100))
-	params.Set("market", pair.ToSymbol(""))
+	params.Set("market", ExchangeSymbol(COINEX, pair, ""))
 
 	retmap, err := coinex.doRequest("GET", "order/finished", &params)
 	if err != nil {
@@ -528,7 +572,7 @@ func (coinex *CoinEx) GetUserTrades(pair CurrencyPair) ([]Trade, error) {
 	params := url.Values{}
 	params.Set("page", fmt.Sprint(1))
 	params.Set("limit", fmt.Sprint(100))
-	params.Set("market", pair.ToSymbol(""))
+	params.Set("market", ExchangeSymbol(COINEX, pair, ""))
 
 	retmap, err := coinex.doRequest("GET", "order/finished", &params)
 	if err != nil {
@@ -573,7 +617,7 @@ func (coinex *CoinEx) GetAccount() (*Account, error) {
 	acc.Exchange = coinex.GetExchangeName()
 	for c, v := range datamap {
 		vv := v.(map[string]interface{})
-		currency := NewCurrency(c)
+		currency := CanonicalCurrency(COINEX, c)
 		acc.SubAccounts[currency] = SubAccount{
 			Currency:     currency,
 			Amount:       ToFloat64(vv["available"]),
@@ -39,7 +39,7 @@ func (ws *CoinexSpotWs) GetExchangeName() string {
 
 // 格式化流名称
 func (ws *CoinexSpotWs) FormatTopicName(topic string, pair CurrencyPair) string {
-	return ws.formatTopicName(topic, pair.ToSymbol("_"))
+	return ws.formatTopicName(topic, ExchangeSymbol(COINEX, pair, "_"))
 }
 
 // 格式化流名称
@@ -67,7 +67,7 @@ func (ws *CoinexSpotWs) FormatTopicUnsubData(topic string, pair CurrencyPair) []
 }
 
 func (ws *CoinexSpotWs) SubTicker(pair CurrencyPair, cb func(*Ticker) error) (err error) {
-	stream := fmt.Sprintf("ticker_%v", pair.ToLowerSymbol(""))
+	stream := fmt.Sprintf("ticker_%v", ExchangeLowerSymbol(COINEX, pair, ""))
 	s, ok := ws.streamMap[stream]
 	// 取消
 	if cb == nil {
@@ -95,7 +95,7 @@ func (ws *CoinexSpotWs) SubTicker(pair CurrencyPair, cb func(*Ticker) error) (er
 }
 
 func (ws *CoinexSpotWs) SubDepth(pair CurrencyPair, cb func(*Depth) error) (err error) {
-	stream := fmt.Sprintf("depth_%v", pair.ToLowerSymbol(""))
+	stream := fmt.Sprintf("depth_%v", ExchangeLowerSymbol(COINEX, pair, ""))
 	s, ok := ws.streamMap[stream]
 	// 取消
 	if cb == nil {
@@ -123,7 +123,7 @@ func (ws *CoinexSpotWs) SubDepth(pair CurrencyPair, cb func(*Depth) error) (err
 }
 
 func (ws *CoinexSpotWs) SubTrade(pair CurrencyPair, cb func([]Trade) error) (err error) {
-	stream := fmt.Sprintf("trade_%v", pair.ToLowerSymbol(""))
+	stream := fmt.Sprintf("trade_%v", ExchangeLowerSymbol(COINEX, pair, ""))
 	s, ok := ws.streamMap[stream]
 	// 取消
 	if cb == nil {
@@ -38,7 +38,7 @@ func (ws *CoinexSpotWsSingle) GetExchangeName() string {
 
 // 格式化流名称
 func (ws *CoinexSpotWsSingle) FormatTopicName(topic string, pair CurrencyPair) string {
-	symbol := pair.ToSymbol("")
+	symbol := ExchangeSymbol(COINEX, pair, "")
 	switch topic {
 	case STREAM_TICKER:
 		return fmt.Sprintf("%v_ticker", symbol)
@@ -55,11 +55,11 @@ func (ws *CoinexSpotWsSingle) FormatTopicName(topic string, pair CurrencyPair) s
 func (ws *CoinexSpotWsSingle) FormatTopicSubData(topic string, pair CurrencyPair) []byte {
 	switch topic {
 	case STREAM_TICKER:
-		return ws.Pack(map[string]interface{}{"method": "state.subscribe", "params": []interface{}{pair.ToSymbol("")}, "id": time.Now().Unix()})
+		return ws.Pack(map[string]interface{}{"method": "state.subscribe", "params": []interface{}{ExchangeSymbol(COINEX, pair, "")}, "id": time.Now().Unix()})
 	case STREAM_DEPTH:
-		return ws.Pack(map[string]interface{}{"method": "depth.subscribe_full", "params": []interface{}{pair.ToSymbol(""), 20, "0"}, "id": time.Now().Unix()})
+		return ws.Pack(map[string]interface{}{"method": "depth.subscribe_full", "params": []interface{}{ExchangeSymbol(COINEX, pair, ""), 20, "0"}, "id": time.Now().Unix()})
 	case STREAM_TRADE:
-		return ws.Pack(map[string]interface{}{"method": "deals.subscribe", "params": []interface{}{pair.ToSymbol("")}, "id": time.Now().Unix()})
+		return ws.Pack(map[string]interface{}{"method": "deals.subscribe", "params": []interface{}{ExchangeSymbol(COINEX, pair, "")}, "id": time.Now().Unix()})
 	default:
 		return nil
 	}
@@ -69,11 +69,11 @@ func (ws *CoinexSpotWsSingle) FormatTopicSubData(topic string, pair CurrencyPair
 func (ws *CoinexSpotWsSingle) FormatTopicUnsubData(topic string, pair CurrencyPair) []byte {
 	switch topic {
 	case STREAM_TICKER:
-		return ws.Pack(map[string]interface{}{"method": "state.unsubscribe", "params": []interface{}{pair.ToSymbol("")}, "id": time.Now().Unix()})
+		return ws.Pack(map[string]interface{}{"method": "state.unsubscribe", "params": []interface{}{ExchangeSymbol(COINEX, pair, "")}, "id": time.Now().Unix()})
 	case STREAM_DEPTH:
-		return ws.Pack(map[string]interface{}{"method": "depth.unsubscribe_full", "params": []interface{}{pair.ToSymbol("")}, "id": time.Now().Unix()})
+		return ws.Pack(map[string]interface{}{"method": "depth.unsubscribe_full", "params": []interface{}{ExchangeSymbol(COINEX, pair, "")}, "id": time.Now().Unix()})
 	case STREAM_TRADE:
-		return ws.Pack(map[string]interface{}{"method": "deals.unsubscribe", "params": []interface{}{pair.ToSymbol("")}, "id": time.Now().Unix()})
+		return ws.Pack(map[string]interface{}{"method": "deals.unsubscribe", "params": []interface{}{ExchangeSymbol(COINEX, pair, "")}, "id": time.Now().Unix()})
 	default:
 		return nil
 	}
@@ -0,0 +1,153 @@
+package coinex
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/betterjun/exapi"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+/**
+ * deposit and withdrawal
+ */
+func NewWalletAPI(client *http.Client, apiKey, secretKey string) WalletAPI {
+	return NewSpotAPI(client, apiKey, secretKey).(*CoinEx)
+}
+
+// 与doRequest相同，data字段可能为列表
+func (coinex *CoinEx) doRequestData(method, uri string, params *url.Values) (interface{}, error) {
+	resp, err := coinex.doRequestInner(method, uri, params)
+	if err != nil {
+		return nil, err
+	}
+
+	retmap := make(map[string]interface{}, 1)
+	err = json.Unmarshal(resp, &retmap)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkResult(retmap)
+	if err != nil {
+		return nil, err
+	}
+
+	return retmap["data"], nil
+}
+
+// 带标签的地址格式为 地址:标签，chain为智能合约名称，如ERC20、TRC20
+func (coinex *CoinEx) GetDepositAddress(currency Currency, chain string) (*DepositAddress, error) {
+	params := url.Values{}
+	if len(chain) > 0 {
+		params.Set("smart_contract_name", chain)
+	}
+	datamap, err := coinex.doRequest("GET", "balance/deposit/address/"+ExchangeCurrency(COINEX, currency).Symbol(), &params)
+	if err != nil {
+		return nil, err
+	}
+
+	addr := ToString(datamap["coin_address"])
+	if len(addr) == 0 {
+		return nil, errors.New("deposit address not found")
+	}
+
+	da := &DepositAddress{Currency: currency, Chain: chain, Address: addr}
+	if i := strings.Index(addr, ":"); i > 0 {
+		da.Address, da.Tag = addr[:i], addr[i+1:]
+	}
+	return da, nil
+}
+
+func (coinex *CoinEx) Withdraw(currency Currency, amount, address, tag, chain string) (string, error) {
+	if len(tag) > 0 {
+		address = address + ":" + tag
+	}
+
+	params := url.Values{}
+	params.Set("coin_type", ExchangeCurrency(COINEX, currency).Symbol())
+	params.Set("coin_address", address)
+	params.Set("transfer_method", "onchain")
+	params.Set("actual_amount", amount)
+	if len(chain) > 0 {
+		params.Set("smart_contract_name", chain)
+	}
+	datamap, err := coinex.doRequest("POST", "balance/coin/withdraw", &params)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprint(ToInt64(datamap["coin_withdraw_id"])), nil
+}
+
+func (coinex *CoinEx) CancelWithdraw(id string, currency Currency) (bool, error) {
+	params := url.Values{}
+	params.Set("coin_withdraw_id", id)
+	_, err := coinex.doRequestData("DELETE", "balance/coin/withdraw", &params)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (coinex *CoinEx) getWalletRecords(currency Currency, uri string, typ WalletRecordType) ([]WalletRecord, error) {
+	params := url.Values{}
+	params.Set("coin_type", ExchangeCurrency(COINEX, currency).Symbol())
+	params.Set("limit", "100")
+	data, err := coinex.doRequestData("GET", uri, &params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 充币记录分页返回，提币记录直接返回列表
+	list, ok := data.([]interface{})
+	if !ok {
+		datamap, _ := data.(map[string]interface{})
+		list, _ = datamap["data"].([]interface{})
+	}
+
+	records := make([]WalletRecord, 0, len(list))
+	for _, v := range list {
+		obj := v.(map[string]interface{})
+		record := WalletRecord{
+			Type:     typ,
+			Currency: currency,
+			Chain:    ToString(obj["smart_contract_name"]),
+			Amount:   ToFloat64(obj["actual_amount"]),
+			Fee:      ToFloat64(obj["tx_fee"]),
+			Address:  ToString(obj["coin_address"]),
+			TxId:     ToString(obj["tx_id"]),
+			Status:   WALLET_PENDING,
+			TS:       ToInt64(obj["create_time"]) * 1000,
+		}
+		if typ == WALLET_DEPOSIT {
+			record.Id = fmt.Sprint(ToInt64(obj["coin_deposit_id"]))
+		} else {
+			record.Id = fmt.Sprint(ToInt64(obj["coin_withdraw_id"]))
+		}
+		if i := strings.Index(record.Address, ":"); i > 0 {
+			record.Address, record.Tag = record.Address[:i], record.Address[i+1:]
+		}
+
+		switch ToString(obj["status"]) {
+		case "finish":
+			record.Status = WALLET_SUCCESS
+		case "cancel":
+			record.Status = WALLET_CANCELED
+		case "not_pass", "fail", "too_small":
+			record.Status = WALLET_FAILED
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func (coinex *CoinEx) GetDepositHistory(currency Currency) ([]WalletRecord, error) {
+	return coinex.getWalletRecords(currency, "balance/coin/deposit", WALLET_DEPOSIT)
+}
+
+func (coinex *CoinEx) GetWithdrawHistory(currency Currency) ([]WalletRecord, error) {
+	return coinex.getWalletRecords(currency, "balance/coin/withdraw", WALLET_WITHDRAW)
+}
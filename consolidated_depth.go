@@ -0,0 +1,228 @@
+package exapi
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// 合并深度中的一档报价
+type ConsolidatedRecord struct {
+	Exchange string  `json:"exchange"`         // 交易所名字
+	Price    float64 `json:"price,string"`     // 报价，设置了手续费时为计入手续费后的价格
+	RawPrice float64 `json:"raw_price,string"` // 交易所的原始报价
+	Amount   float64 `json:"amount,string"`    // 数量
+}
+
+// 多个交易所同一交易对的合并深度
+type ConsolidatedDepth struct {
+	Market  CurrencyPair         `json:"market"`  // 交易对
+	Symbol  string               `json:"symbol"`  // 交易对
+	TS      int64                `json:"ts"`      // 最新的深度时间，单位为毫秒(millisecond)
+	AskList []ConsolidatedRecord `json:"asks"`    // 卖方订单列表，价格从低到高排序
+	BidList []ConsolidatedRecord `json:"bids"`    // 买方订单列表，价格从高到底排序
+	Sources map[string]int64     `json:"sources"` // 参与合并的交易所及其深度时间
+}
+
+// 转为Depth，相同价格的数量合并，不再区分交易所
+func (cd *ConsolidatedDepth) ToDepth() *Depth {
+	return &Depth{
+		Market:  cd.Market,
+		Symbol:  cd.Symbol,
+		TS:      cd.TS,
+		AskList: mergeConsolidatedRecords(cd.AskList),
+		BidList: mergeConsolidatedRecords(cd.BidList),
+	}
+}
+
+func mergeConsolidatedRecords(records []ConsolidatedRecord) DepthRecords {
+	list := make(DepthRecords, 0, len(records))
+	for _, r := range records {
+		if n := len(list); n > 0 && list[n-1].Price == r.Price {
+			list[n-1].Amount += r.Amount
+			continue
+		}
+		list = append(list, DepthRecord{Price: r.Price, Amount: r.Amount})
+	}
+	return list
+}
+
+type depthSource struct {
+	depth    *Depth
+	received time.Time
+}
+
+/*
+合并多个交易所同一交易对的深度。
+通过Add订阅各交易所的SubDepth，任一交易所的深度更新时回调cb，推送合并后的深度。
+设置了手续费的交易所，卖单价格按(1+taker费率)、买单价格按(1-taker费率)折算，便于直接比较实际成交价。
+*/
+type DepthAggregator struct {
+	sync.Mutex
+	pair    CurrencyPair
+	sources map[string]*depthSource
+	fees    map[string]TradeFee
+	wss     map[string]SpotWebsocket
+	maxAge  time.Duration
+	cb      func(*ConsolidatedDepth) error
+}
+
+func NewDepthAggregator(pair CurrencyPair, cb func(*ConsolidatedDepth) error) *DepthAggregator {
+	return &DepthAggregator{
+		pair:    pair,
+		sources: make(map[string]*depthSource),
+		fees:    make(map[string]TradeFee),
+		wss:     make(map[string]SpotWebsocket),
+		cb:      cb,
+	}
+}
+
+// 设置深度的最长有效时间，超过该时间未更新的交易所不参与合并，默认为0，即不过期
+func (da *DepthAggregator) SetMaxAge(d time.Duration) {
+	da.Lock()
+	da.maxAge = d
+	da.Unlock()
+}
+
+// 设置交易所的手续费，合并时按taker费率折算价格
+func (da *DepthAggregator) SetFee(exchange string, fee TradeFee) {
+	da.Lock()
+	da.fees[exchange] = fee
+	da.Unlock()
+}
+
+// 用api获取交易对的手续费并设置
+func (da *DepthAggregator) SetFeeFromAPI(api FeeAPI) error {
+	fee, err := api.GetTradeFee(da.pair)
+	if err != nil {
+		return err
+	}
+	da.SetFee(api.GetExchangeName(), *fee)
+	return nil
+}
+
+/*
+订阅ws的深度推送。
+SpotWsBase每个连接只保存一个深度回调：订阅新的交易对会替换所有交易对的回调，重复订阅已订阅的交易对不会设置回调。
+同一ws上有多个DepthAggregator时，应由一个回调分发给各个DepthAggregator的OnDepth。
+*/
+func (da *DepthAggregator) Add(ws SpotWebsocket) error {
+	exchange := ws.GetExchangeName()
+	err := ws.SubDepth(da.pair, func(depth *Depth) error {
+		return da.OnDepth(exchange, depth)
+	})
+	if err != nil {
+		return err
+	}
+
+	da.Lock()
+	da.wss[exchange] = ws
+	da.Unlock()
+	return nil
+}
+
+// 取消交易所的深度订阅，并从合并深度中移除
+func (da *DepthAggregator) Remove(exchange string) error {
+	da.Lock()
+	ws := da.wss[exchange]
+	delete(da.wss, exchange)
+	delete(da.sources, exchange)
+	da.Unlock()
+
+	if ws == nil {
+		return nil
+	}
+	return ws.SubDepth(da.pair, nil)
+}
+
+// 取消所有交易所的深度订阅
+func (da *DepthAggregator) Close() (err error) {
+	da.Lock()
+	exchanges := make([]string, 0, len(da.wss))
+	for exchange := range da.wss {
+		exchanges = append(exchanges, exchange)
+	}
+	da.Unlock()
+
+	for _, exchange := range exchanges {
+		if e := da.Remove(exchange); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// 处理交易所推送的深度，可作为SubDepth的回调，其他交易对的深度被忽略
+func (da *DepthAggregator) OnDepth(exchange string, depth *Depth) error {
+	if !depth.Market.Equal(da.pair) {
+		return nil
+	}
+
+	da.Lock()
+	da.sources[exchange] = &depthSource{depth: depth, received: time.Now()}
+	cd := da.consolidate()
+	da.Unlock()
+
+	if da.cb == nil {
+		return nil
+	}
+	return da.cb(cd)
+}
+
+// 当前的合并深度
+func (da *DepthAggregator) Depth() *ConsolidatedDepth {
+	da.Lock()
+	defer da.Unlock()
+	return da.consolidate()
+}
+
+func (da *DepthAggregator) consolidate() *ConsolidatedDepth {
+	cd := &ConsolidatedDepth{
+		Market:  da.pair,
+		Symbol:  da.pair.ToLowerSymbol("/"),
+		Sources: make(map[string]int64),
+	}
+
+	now := time.Now()
+	for exchange, src := range da.sources {
+		if da.maxAge > 0 && now.Sub(src.received) > da.maxAge {
+			continue
+		}
+
+		cd.Sources[exchange] = src.depth.TS
+		if src.depth.TS > cd.TS {
+			cd.TS = src.depth.TS
+		}
+
+		fee, netted := da.fees[exchange]
+		for _, r := range src.depth.AskList {
+			price := r.Price
+			if netted {
+				price *= 1 + fee.TakerRate
+			}
+			cd.AskList = append(cd.AskList, ConsolidatedRecord{Exchange: exchange, Price: price, RawPrice: r.Price, Amount: r.Amount})
+		}
+		for _, r := range src.depth.BidList {
+			price := r.Price
+			if netted {
+				price *= 1 - fee.TakerRate
+			}
+			cd.BidList = append(cd.BidList, ConsolidatedRecord{Exchange: exchange, Price: price, RawPrice: r.Price, Amount: r.Amount})
+		}
+	}
+
+	// 价格相同时按交易所名称排序，保证结果稳定
+	sort.Slice(cd.AskList, func(i, j int) bool {
+		if cd.AskList[i].Price != cd.AskList[j].Price {
+			return cd.AskList[i].Price < cd.AskList[j].Price
+		}
+		return cd.AskList[i].Exchange < cd.AskList[j].Exchange
+	})
+	sort.Slice(cd.BidList, func(i, j int) bool {
+		if cd.BidList[i].Price != cd.BidList[j].Price {
+			return cd.BidList[i].Price > cd.BidList[j].Price
+		}
+		return cd.BidList[i].Exchange < cd.BidList[j].Exchange
+	})
+	return cd
+}
@@ -0,0 +1,63 @@
+package exapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDepthAggregator(t *testing.T) {
+	pair := NewCurrencyPairFromString("BTC/USDT")
+	var pushed *ConsolidatedDepth
+	da := NewDepthAggregator(pair, func(cd *ConsolidatedDepth) error {
+		pushed = cd
+		return nil
+	})
+
+	assert.Nil(t, da.OnDepth("a", &Depth{
+		Market:  pair,
+		TS:      1000,
+		AskList: DepthRecords{{Price: 101, Amount: 1}, {Price: 102, Amount: 2}},
+		BidList: DepthRecords{{Price: 99, Amount: 1}},
+	}))
+	assert.Nil(t, da.OnDepth("b", &Depth{
+		Market:  pair,
+		TS:      2000,
+		AskList: DepthRecords{{Price: 100.5, Amount: 3}, {Price: 101, Amount: 1}},
+		BidList: DepthRecords{{Price: 99.5, Amount: 2}, {Price: 99, Amount: 4}},
+	}))
+
+	// 同一ws上其他交易对的深度被忽略
+	assert.Nil(t, da.OnDepth("b", &Depth{
+		Market:  NewCurrencyPairFromString("ETH/USDT"),
+		TS:      3000,
+		AskList: DepthRecords{{Price: 2000, Amount: 1}},
+	}))
+
+	assert.Equal(t, int64(2000), pushed.TS)
+	assert.Equal(t, map[string]int64{"a": 1000, "b": 2000}, pushed.Sources)
+	assert.Equal(t, []ConsolidatedRecord{
+		{Exchange: "b", Price: 100.5, RawPrice: 100.5, Amount: 3},
+		{Exchange: "a", Price: 101, RawPrice: 101, Amount: 1},
+		{Exchange: "b", Price: 101, RawPrice: 101, Amount: 1},
+		{Exchange: "a", Price: 102, RawPrice: 102, Amount: 2},
+	}, pushed.AskList)
+	assert.Equal(t, "b", pushed.BidList[0].Exchange)
+
+	depth := pushed.ToDepth()
+	assert.Equal(t, DepthRecords{{Price: 100.5, Amount: 3}, {Price: 101, Amount: 2}, {Price: 102, Amount: 2}}, depth.AskList)
+	assert.Equal(t, DepthRecords{{Price: 99.5, Amount: 2}, {Price: 99, Amount: 5}}, depth.BidList)
+
+	// 计入手续费后b的卖单价格高于a
+	da.SetFee("b", TradeFee{TakerRate: 0.01})
+	cd := da.Depth()
+	assert.Equal(t, "a", cd.AskList[0].Exchange)
+	assert.InDelta(t, 100.5*1.01, cd.AskList[1].Price, 1e-9)
+	assert.Equal(t, 100.5, cd.AskList[1].RawPrice)
+	assert.Equal(t, "a", cd.BidList[0].Exchange)
+
+	// 过期的深度不参与合并
+	da.SetMaxAge(time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	assert.Equal(t, 0, len(da.Depth().AskList))
+}
@@ -77,6 +77,16 @@ func (ts TradeStatus) String() string {
 
 var tradeStatusSymbol = [...]string{"UNFINISH", "PART_FINISH", "FINISH", "CANCEL", "REJECT", "CANCEL_ING"}
 
+// 订单是否已结束，不会再有新的成交
+func (ts TradeStatus) IsFinal() bool {
+	switch ts {
+	case ORDER_FINISH, ORDER_CANCEL, ORDER_REJECT, ORDER_FAIL:
+		return true
+	default:
+		return false
+	}
+}
+
 const (
 	ORDER_UNFINISH TradeStatus = iota
 	ORDER_PART_FINISH
@@ -119,6 +129,7 @@ var (
 	THIS_WEEK_CONTRACT = "this_week" //周合约
 	NEXT_WEEK_CONTRACT = "next_week" //次周合约
 	QUARTER_CONTRACT   = "quarter"   //季度合约
+	SWAP_CONTRACT      = "swap"      //永续合约
 )
 
 //exchanges const
@@ -158,5 +169,8 @@ const (
 // 接口暂不支持的错误
 var ErrorUnsupported = errors.New("Unsupported")
 
+// 按区间获取K线时，范围早于交易所可查询的K线
+var ErrorKlineRangeTooOld = errors.New("Kline range too old")
+
 // 币种未找到
 var ErrorAssetNotFound = errors.New("Asset not found")
@@ -3,8 +3,44 @@ package exapi
 import "strings"
 
 type CurrencyStatus struct {
-	Deposit  bool // 是否可以充值
-	Withdraw bool // 是否可以提币
+	Deposit  bool          // 是否可以充值，任一链可以充值即为true
+	Withdraw bool          // 是否可以提币，任一链可以提币即为true
+	Chains   []ChainStatus // 各链的充提信息，交易所不提供时为空
+}
+
+// 币种在某条链上的充提信息，数值为0表示交易所未提供或无限制
+type ChainStatus struct {
+	Chain             string  // 链名称，与WalletAPI中的chain参数一致
+	IsDefault         bool    // 是否为默认链
+	Deposit           bool    // 是否可以充值
+	Withdraw          bool    // 是否可以提币
+	WithdrawFee       float64 // 提币手续费
+	MinWithdraw       float64 // 最小提币数量
+	MaxWithdraw       float64 // 单次最大提币数量
+	MinDeposit        float64 // 最小充值数量
+	Confirmations     int     // 充值到账需要的确认数
+	WithdrawPrecision int     // 提币数量的小数位数
+}
+
+/*
+获取指定链的充提信息，链名称不区分大小写。
+chain为空时返回默认链，没有标记默认链时返回第一条链。
+*/
+func (cs CurrencyStatus) GetChain(chain string) (ChainStatus, bool) {
+	if len(cs.Chains) == 0 {
+		return ChainStatus{}, false
+	}
+
+	for _, c := range cs.Chains {
+		if (len(chain) == 0 && c.IsDefault) || (len(chain) > 0 && strings.EqualFold(c.Chain, chain)) {
+			return c, true
+		}
+	}
+
+	if len(chain) == 0 {
+		return cs.Chains[0], true
+	}
+	return ChainStatus{}, false
 }
 
 func NewCurrency(name string) Currency {
@@ -45,7 +81,7 @@ var (
 	UNKNOWN_PAIR = CurrencyPair{UNKNOWN, UNKNOWN}
 )
 
-// BCC就是BCH的别名，很多交易所已经移除了BCC的叫法。
+// BCC就是BCH的别名，很多交易所已经移除了BCC的叫法。交易所的币种别名可用CurrencyAlias登记。
 func (c Currency) AdaptBchToBcc() Currency {
 	if c.Name == "BCH" || c.Name == "bch" {
 		return BCC
@@ -0,0 +1,195 @@
+package exapi
+
+import (
+	"strings"
+	"sync"
+)
+
+/*
+交易所币种别名表。
+记录exapi统一的币种名称与各交易所使用的名称之间的对应关系，例如kraken把BTC叫做XBT，
+双向转换：请求时把统一名称转换为交易所名称，响应时把交易所名称转换回统一名称。
+没有登记别名的币种保持不变，名称不区分大小写。
+各交易所的适配器在构造请求和解析响应时用全局别名表DefaultCurrencyAlias转换。
+*/
+type CurrencyAlias struct {
+	sync.RWMutex
+	toExchange   map[string]map[string]string // 交易所 -> 统一名称 -> 交易所名称
+	fromExchange map[string]map[string]string // 交易所 -> 交易所名称 -> 统一名称
+}
+
+// 默认的别名，可在运行时用RegisterCurrencyAlias覆盖或用RemoveCurrencyAlias删除
+var defaultCurrencyAliases = map[string]map[string]string{
+	ZB:      {"BCH": "BCC"},
+	HUOBI:   {"HBPOINT": "POINT"},
+	BINANCE: {"BCH": "BCHABC"},
+	KRAKEN:  {"BTC": "XBT", "DOGE": "XDG"},
+	BITMEX:  {"BTC": "XBT"},
+}
+
+// 全局别名表，各交易所的适配器使用此表
+var DefaultCurrencyAlias = NewCurrencyAlias(defaultCurrencyAliases)
+
+// 用aliases创建别名表，aliases的key为交易所名字，value为统一名称到交易所名称的映射
+func NewCurrencyAlias(aliases map[string]map[string]string) *CurrencyAlias {
+	ca := &CurrencyAlias{
+		toExchange:   make(map[string]map[string]string),
+		fromExchange: make(map[string]map[string]string),
+	}
+	for exchange, m := range aliases {
+		for canonical, name := range m {
+			ca.Register(exchange, NewCurrency(canonical), name)
+		}
+	}
+	return ca
+}
+
+// 登记交易所的币种别名，覆盖该币种已有的别名
+func (ca *CurrencyAlias) Register(exchange string, canonical Currency, name string) {
+	ca.Lock()
+	defer ca.Unlock()
+
+	c, n := strings.ToUpper(canonical.Symbol()), strings.ToUpper(name)
+	ca.remove(exchange, c)
+	if ca.toExchange[exchange] == nil {
+		ca.toExchange[exchange] = make(map[string]string)
+		ca.fromExchange[exchange] = make(map[string]string)
+	}
+	ca.toExchange[exchange][c] = n
+	ca.fromExchange[exchange][n] = c
+}
+
+// 删除交易所的币种别名
+func (ca *CurrencyAlias) Remove(exchange string, canonical Currency) {
+	ca.Lock()
+	ca.remove(exchange, strings.ToUpper(canonical.Symbol()))
+	ca.Unlock()
+}
+
+func (ca *CurrencyAlias) remove(exchange, canonical string) {
+	if n, ok := ca.toExchange[exchange][canonical]; ok {
+		delete(ca.toExchange[exchange], canonical)
+		delete(ca.fromExchange[exchange], n)
+	}
+}
+
+// 交易所的所有别名，key为统一名称，value为交易所名称
+func (ca *CurrencyAlias) Aliases(exchange string) map[string]string {
+	ca.RLock()
+	defer ca.RUnlock()
+	m := make(map[string]string, len(ca.toExchange[exchange]))
+	for k, v := range ca.toExchange[exchange] {
+		m[k] = v
+	}
+	return m
+}
+
+// 统一名称转换为交易所名称
+func (ca *CurrencyAlias) ToExchange(exchange string, c Currency) Currency {
+	ca.RLock()
+	defer ca.RUnlock()
+	if n, ok := ca.toExchange[exchange][strings.ToUpper(c.Symbol())]; ok {
+		return Currency{Name: n}
+	}
+	return c
+}
+
+// 交易所名称转换为统一名称
+func (ca *CurrencyAlias) FromExchange(exchange string, c Currency) Currency {
+	ca.RLock()
+	defer ca.RUnlock()
+	if n, ok := ca.fromExchange[exchange][strings.ToUpper(c.Symbol())]; ok {
+		return Currency{Name: n}
+	}
+	return c
+}
+
+// 交易对转换为交易所名称
+func (ca *CurrencyAlias) ToExchangePair(exchange string, pair CurrencyPair) CurrencyPair {
+	return CurrencyPair{ca.ToExchange(exchange, pair.Stock), ca.ToExchange(exchange, pair.Money)}
+}
+
+// 交易对转换为统一名称
+func (ca *CurrencyAlias) FromExchangePair(exchange string, pair CurrencyPair) CurrencyPair {
+	return CurrencyPair{ca.FromExchange(exchange, pair.Stock), ca.FromExchange(exchange, pair.Money)}
+}
+
+// 在全局别名表中登记交易所的币种别名
+func RegisterCurrencyAlias(exchange string, canonical Currency, name string) {
+	DefaultCurrencyAlias.Register(exchange, canonical, name)
+}
+
+// 在全局别名表中删除交易所的币种别名
+func RemoveCurrencyAlias(exchange string, canonical Currency) {
+	DefaultCurrencyAlias.Remove(exchange, canonical)
+}
+
+// 交易对在交易所的名称，币种按全局别名表转换，用于构造请求
+func ExchangeSymbol(exchange string, pair CurrencyPair, sep string) string {
+	return DefaultCurrencyAlias.ToExchangePair(exchange, pair).ToSymbol(sep)
+}
+
+// 交易对在交易所的小写名称，币种按全局别名表转换，用于构造请求
+func ExchangeLowerSymbol(exchange string, pair CurrencyPair, sep string) string {
+	return DefaultCurrencyAlias.ToExchangePair(exchange, pair).ToLowerSymbol(sep)
+}
+
+// 币种在交易所的名称
+func ExchangeCurrency(exchange string, c Currency) Currency {
+	return DefaultCurrencyAlias.ToExchange(exchange, c)
+}
+
+// 交易所返回的币种名称转换为统一的币种
+func CanonicalCurrency(exchange, name string) Currency {
+	return DefaultCurrencyAlias.FromExchange(exchange, NewCurrency(name))
+}
+
+// 交易所返回的交易对转换为统一名称
+func CanonicalPair(exchange string, pair CurrencyPair) CurrencyPair {
+	return DefaultCurrencyAlias.FromExchangePair(exchange, pair)
+}
+
+/*
+交易所返回的交易对信息转换为统一名称，ssm的key为交易所币种名称的BASE/QUOTE格式。
+有别名的交易对重新生成key，Symbol、Base和Quote按原来的大小写替换为统一名称。
+*/
+func CanonicalSymbolSettings(exchange string, ssm map[string]SymbolSetting) map[string]SymbolSetting {
+	result := make(map[string]SymbolSetting, len(ssm))
+	for k, ss := range ssm {
+		pair := NewCurrencyPairFromString(k)
+		if pair.Stock.Symbol() == "" {
+			result[k] = ss
+			continue
+		}
+		canonical := CanonicalPair(exchange, pair)
+		if canonical.Equal(pair) {
+			result[k] = ss
+			continue
+		}
+		ss.Base = matchCase(ss.Base, canonical.Stock.Symbol())
+		ss.Quote = matchCase(ss.Quote, canonical.Money.Symbol())
+		ss.Symbol = matchCase(ss.Symbol, canonical.ToSymbol("/"))
+		result[canonical.ToSymbol("/")] = ss
+	}
+	return result
+}
+
+// 交易所返回的币种状态转换为统一名称，csm的key为币种名称
+func CanonicalCurrencyStatus(exchange string, csm map[string]CurrencyStatus) map[string]CurrencyStatus {
+	result := make(map[string]CurrencyStatus, len(csm))
+	for k, cs := range csm {
+		if c := CanonicalCurrency(exchange, k); !c.Equal(NewCurrency(k)) {
+			k = matchCase(k, c.Symbol())
+		}
+		result[k] = cs
+	}
+	return result
+}
+
+// 原名称为小写时返回s的小写
+func matchCase(orig, s string) string {
+	if orig != "" && orig == strings.ToLower(orig) {
+		return strings.ToLower(s)
+	}
+	return s
+}
@@ -0,0 +1,55 @@
+package exapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCurrencyAlias(t *testing.T) {
+	BTC := NewCurrency("BTC")
+	ca := NewCurrencyAlias(defaultCurrencyAliases)
+	assert.Equal(t, "XBT", ca.ToExchange(KRAKEN, BTC).Symbol())
+	assert.Equal(t, "BTC", ca.FromExchange(KRAKEN, NewCurrency("xbt")).Symbol())
+	assert.Equal(t, "BTC", ca.ToExchange(HUOBI, BTC).Symbol())
+
+	ca.Register(KRAKEN, BTC, "XXBT")
+	assert.Equal(t, "XXBT", ca.ToExchange(KRAKEN, BTC).Symbol())
+	assert.Equal(t, "XBT", ca.FromExchange(KRAKEN, NewCurrency("XBT")).Symbol())
+	ca.Remove(KRAKEN, BTC)
+	assert.Equal(t, "BTC", ca.ToExchange(KRAKEN, BTC).Symbol())
+	assert.Equal(t, map[string]string{"DOGE": "XDG"}, ca.Aliases(KRAKEN))
+}
+
+func TestCurrencyAliasHelpers(t *testing.T) {
+	BCH := NewCurrency("BCH")
+	pair := NewCurrencyPair(BCH, USDT)
+	assert.Equal(t, "bcc_usdt", ExchangeLowerSymbol(ZB, pair, "_"))
+	assert.Equal(t, "BCHUSDT", ExchangeSymbol(HUOBI, pair, ""))
+	assert.Equal(t, "BCC", ExchangeCurrency(ZB, BCH).Symbol())
+	assert.Equal(t, "BCH", CanonicalCurrency(ZB, "bcc").Symbol())
+	assert.Equal(t, "HBPOINT", CanonicalCurrency(HUOBI, "point").Symbol())
+	assert.Equal(t, "BCH/USDT", CanonicalPair(ZB, NewCurrencyPairFromString("BCC/USDT")).ToSymbol("/"))
+
+	ssm := CanonicalSymbolSettings(ZB, map[string]SymbolSetting{
+		"BCC/USDT": {Symbol: "bcc_usdt", Base: "bcc", Quote: "usdt"},
+		"BTC/USDT": {Symbol: "btc_usdt", Base: "btc", Quote: "usdt"},
+	})
+	assert.Equal(t, "bch", ssm["BCH/USDT"].Base)
+	assert.Equal(t, "bch/usdt", ssm["BCH/USDT"].Symbol)
+	assert.Equal(t, "btc_usdt", ssm["BTC/USDT"].Symbol)
+
+	csm := CanonicalCurrencyStatus(ZB, map[string]CurrencyStatus{"BCC": {Deposit: true}, "BTC": {}})
+	assert.True(t, csm["BCH"].Deposit)
+	assert.Equal(t, 2, len(csm))
+
+	// 交易所名称和统一名称都可以查到
+	si := NewSymbolIndex(ZB, func() (map[string]SymbolSetting, error) {
+		return ssm, nil
+	})
+	p, err := si.Pair("bccusdt")
+	assert.Nil(t, err)
+	assert.Equal(t, pair, p)
+	p, err = si.Pair("bch_usdt")
+	assert.Nil(t, err)
+	assert.Equal(t, pair, p)
+}
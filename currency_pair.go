@@ -1,6 +1,7 @@
 package exapi
 
 import (
+	"math"
 	"strings"
 )
 
@@ -14,6 +15,64 @@ type SymbolSetting struct {
 	MinNotional float64 `json:"min_notional"` // 最小成交量，价格和数量相乘必须要大于此值
 	MakerFee    float64 `json:"maker_fee"`    // 挂单手续费
 	TakerFee    float64 `json:"taker_fee"`    // 吃单手续费
+
+	TickSize        float64 `json:"tick_size"`        // 价格最小变动单位
+	StepSize        float64 `json:"step_size"`        // 数量最小变动单位
+	PricePrecision  int     `json:"price_precision"`  // 价格小数位数
+	AmountPrecision int     `json:"amount_precision"` // 数量小数位数
+	MaxSize         float64 `json:"max_size"`         // 最大交易量，0表示不限制
+	MaxPrice        float64 `json:"max_price"`        // 最大价格，0表示不限制
+	MaxNotional     float64 `json:"max_notional"`     // 最大成交额，0表示不限制
+}
+
+// 价格最小变动单位，交易所未提供时按价格精度计算
+func (ss SymbolSetting) PriceTick() float64 {
+	if ss.TickSize > 0 {
+		return ss.TickSize
+	}
+	return math.Pow10(-ss.PricePrecision)
+}
+
+// 数量最小变动单位，交易所未提供时按数量精度计算
+func (ss SymbolSetting) AmountStep() float64 {
+	if ss.StepSize > 0 {
+		return ss.StepSize
+	}
+	return math.Pow10(-ss.AmountPrecision)
+}
+
+// 价格按最小变动单位四舍五入
+func (ss SymbolSetting) RoundPrice(price float64) float64 {
+	tick := ss.PriceTick()
+	return math.Round(price/tick) * tick
+}
+
+// 价格按最小变动单位向下取整，适用于买单
+func (ss SymbolSetting) FloorPrice(price float64) float64 {
+	tick := ss.PriceTick()
+	return math.Floor(price/tick+1e-9) * tick
+}
+
+// 价格按最小变动单位向上取整，适用于卖单
+func (ss SymbolSetting) CeilPrice(price float64) float64 {
+	tick := ss.PriceTick()
+	return math.Ceil(price/tick-1e-9) * tick
+}
+
+// 数量按最小变动单位向下取整，避免超出可用余额
+func (ss SymbolSetting) TruncateAmount(amount float64) float64 {
+	step := ss.AmountStep()
+	return math.Floor(amount/step+1e-9) * step
+}
+
+// 格式化价格，四舍五入到最小变动单位，可直接用于LimitBuy/LimitSell
+func (ss SymbolSetting) FormatPrice(price float64) string {
+	return FloatToString(ss.RoundPrice(price), PrecisionOf(ss.PriceTick()))
+}
+
+// 格式化数量，向下取整到最小变动单位，可直接用于下单
+func (ss SymbolSetting) FormatAmount(amount float64) string {
+	return FloatToString(ss.TruncateAmount(amount), PrecisionOf(ss.AmountStep()))
 }
 
 func GetCurrencyMap(ssm map[string]SymbolSetting) (cm map[string]struct{}) {
@@ -0,0 +1,24 @@
+package exapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSymbolSettingFormat(t *testing.T) {
+	assert.Equal(t, 8, PrecisionOf(0.00000001))
+	assert.Equal(t, 2, PrecisionOf(0.01))
+	assert.Equal(t, 0, PrecisionOf(10))
+
+	ss := SymbolSetting{TickSize: 0.05, StepSize: 0.001}
+	assert.Equal(t, "100.05", ss.FormatPrice(100.049))
+	assert.Equal(t, "100.00", ss.FormatPrice(100.02))
+	assert.Equal(t, "1.234", ss.FormatAmount(1.2349))
+	assert.Equal(t, "0.300", ss.FormatAmount(0.3))
+
+	ss = SymbolSetting{PricePrecision: 2, AmountPrecision: 4}
+	assert.Equal(t, "9.87", ss.FormatPrice(9.8655))
+	assert.Equal(t, "0.1234", ss.FormatAmount(0.12349))
+	assert.Equal(t, "9.86", FloatToString(ss.FloorPrice(9.8699), 2))
+	assert.Equal(t, "9.87", FloatToString(ss.CeilPrice(9.8601), 2))
+}
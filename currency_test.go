@@ -0,0 +1,34 @@
+package exapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCurrencyStatusGetChain(t *testing.T) {
+	cs := CurrencyStatus{}
+	_, ok := cs.GetChain("")
+	assert.False(t, ok)
+
+	cs.Chains = []ChainStatus{
+		{Chain: "OMNI", WithdrawFee: 10},
+		{Chain: "ERC20", IsDefault: true, WithdrawFee: 5},
+		{Chain: "TRC20", WithdrawFee: 1},
+	}
+
+	c, ok := cs.GetChain("")
+	assert.True(t, ok)
+	assert.Equal(t, "ERC20", c.Chain)
+
+	c, ok = cs.GetChain("trc20")
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, c.WithdrawFee)
+
+	_, ok = cs.GetChain("BEP20")
+	assert.False(t, ok)
+
+	cs.Chains[1].IsDefault = false
+	c, ok = cs.GetChain("")
+	assert.True(t, ok)
+	assert.Equal(t, "OMNI", c.Chain)
+}
@@ -56,6 +56,19 @@ func (et *Et) GetURL() string {
 	return et.baseUrl
 }
 
+// 易通的深度挡位和聚合参数原样传给交易所，未公开取值范围
+var spotCapabilities = &Capabilities{
+	Exchange:           ET,
+	UnsupportedMethods: []string{"GetUserTrades"},
+	KlinePeriods:       KlinePeriodsOf(_INERNAL_KLINE_PERIOD_CONVERTER),
+	OrderSides:         AllOrderSides,
+	WebsocketStreams:   AllStreams,
+}
+
+func (et *Et) Capabilities() *Capabilities {
+	return spotCapabilities.Clone()
+}
+
 func (et *Et) buildHeaders() (headers map[string]string) {
 	headers = make(map[string]string)
 	headers["ApiKey"] = et.accessKey
@@ -64,22 +77,23 @@ func (et *Et) buildHeaders() (headers map[string]string) {
 	return headers
 }
 
-/*
-"maker": "0.001",
-"taker": "0.0015",
-"timestamp": "2019-12-05T09:06:20.260Z"
-*/
-type TradeFee struct {
-	Maker float64 `json:"maker,string"` // 挂单手续费
-	Taker float64 `json:"taker,string"` // 吃单手续费
+// 易通没有账户费率查询接口，返回币对公布的费率
+func (et *Et) GetTradeFee(pair CurrencyPair) (*TradeFee, error) {
+	tfm, err := et.GetTradeFeeMap()
+	if err != nil {
+		return nil, err
+	}
+
+	return TradeFeeOf(tfm, pair)
 }
 
-func (et *Et) GetTradeFee() (tf *TradeFee, err error) {
-	tf = &TradeFee{
-		Maker: 0.002,
-		Taker: 0.002,
+func (et *Et) GetTradeFeeMap() (map[string]TradeFee, error) {
+	ssm, err := et.GetAllCurrencyPair()
+	if err != nil {
+		return nil, err
 	}
-	return tf, nil
+
+	return TradeFeeMapOf(ssm), nil
 }
 
 func (et *Et) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
@@ -111,27 +125,27 @@ func (et *Et) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
 		return nil, fmt.Errorf("result code is %v", resp.Code)
 	}
 
-	tf, err := et.GetTradeFee()
-	if err != nil {
-		return nil, err
-	}
 	ssm := make(map[string]SymbolSetting)
 	for _, v := range resp.Data {
 		symbol := v.Name
 		minAmount, _ := strconv.ParseFloat(v.MinAmount, 64)
 		ssm[symbol] = SymbolSetting{
-			Symbol:      symbol,
-			Base:        v.Stock,
-			Quote:       v.Money,
-			MinSize:     math.Pow10(-v.StockPrec),
-			MinPrice:    math.Pow10(-v.MoneyPrec),
-			MinNotional: minAmount,
-			MakerFee:    tf.Maker,
-			TakerFee:    tf.Taker,
+			Symbol:          symbol,
+			Base:            v.Stock,
+			Quote:           v.Money,
+			MinSize:         math.Pow10(-v.StockPrec),
+			MinPrice:        math.Pow10(-v.MoneyPrec),
+			MinNotional:     minAmount,
+			MakerFee:        0.002,
+			TakerFee:        0.002,
+			TickSize:        math.Pow10(-v.MoneyPrec),
+			StepSize:        math.Pow10(-v.StockPrec),
+			PricePrecision:  v.MoneyPrec,
+			AmountPrecision: v.StockPrec,
 		}
 	}
 
-	return ssm, nil
+	return CanonicalSymbolSettings(ET, ssm), nil
 }
 
 func (et *Et) GetCurrencyStatus(currency Currency) (CurrencyStatus, error) {
@@ -167,7 +181,7 @@ func (et *Et) GetAllCurrencyStatus() (all map[string]CurrencyStatus, err error)
 }
 
 func (et *Et) GetTicker(pair CurrencyPair) (*Ticker, error) {
-	symbol := pair.ToSymbol("/")
+	symbol := ExchangeSymbol(ET, pair, "/")
 	resp, err := HttpGet(et.httpClient, et.baseUrl+fmt.Sprintf("/userapi/market/ticker?market=%s", symbol))
 	if err != nil {
 		return nil, err
@@ -220,7 +234,7 @@ func (et *Et) GetAllTicker() ([]Ticker, error) {
 	for _, v := range dataArr {
 		data, _ := v.(map[string]interface{})
 		symbol := ToString(data["name"])
-		pair := NewCurrencyPairFromString(symbol)
+		pair := CanonicalPair(ET, NewCurrencyPairFromString(symbol))
 		ticker := Ticker{}
 		ticker.Market = pair
 		ticker.Symbol = pair.ToLowerSymbol("/")
@@ -240,7 +254,7 @@ func (et *Et) GetAllTicker() ([]Ticker, error) {
 }
 
 func (et *Et) GetDepth(pair CurrencyPair, size int, step int) (*Depth, error) {
-	symbol := pair.ToSymbol("/")
+	symbol := ExchangeSymbol(ET, pair, "/")
 	resp, err := HttpGet(et.httpClient, et.baseUrl+fmt.Sprintf("/userapi/market/depth?market=%s&limit=%d&interval=%d", symbol, size, step))
 	if err != nil {
 		return nil, err
@@ -291,7 +305,7 @@ func (et *Et) GetDepth(pair CurrencyPair, size int, step int) (*Depth, error) {
 }
 
 func (et *Et) GetTrades(pair CurrencyPair, size int) ([]Trade, error) {
-	symbol := pair.ToSymbol("/")
+	symbol := ExchangeSymbol(ET, pair, "/")
 	resp, err := HttpGet(et.httpClient, et.baseUrl+fmt.Sprintf("/userapi/market/trade?market=%v&limit=%v", symbol, size))
 	if err != nil {
 		return nil, err
@@ -335,10 +349,13 @@ func (et *Et) GetTrades(pair CurrencyPair, size int) ([]Trade, error) {
 }
 
 func (et *Et) GetKlineRecords(pair CurrencyPair, period KlinePeriod, size, since int) ([]Kline, error) {
-	symbol := pair.ToSymbol("/")
+	symbol := ExchangeSymbol(ET, pair, "/")
 	periodS, isOk := _INERNAL_KLINE_PERIOD_CONVERTER[period]
 	if isOk != true {
-		return nil, fmt.Errorf("unsupported %v KlinePeriod:%v", et.GetExchangeName(), period)
+		// 交易所不支持的周期，用较小周期的K线合成
+		return GetKlinesWithResample(spotCapabilities, func(p KlinePeriod, n, t int) ([]Kline, error) {
+			return et.GetKlineRecords(pair, p, n, t)
+		}, period, size, since)
 	}
 	resp, err := HttpGet(et.httpClient, et.baseUrl+fmt.Sprintf("/userapi/market/kline?market=%v&interval=%v", symbol, periodS))
 	if err != nil {
@@ -390,6 +407,13 @@ func (et *Et) GetKlineRecords(pair CurrencyPair, period KlinePeriod, size, since
 	return klines, nil
 }
 
+// 交易所只能查询最近的K线，范围早于可查询的K线时返回ErrorKlineRangeTooOld
+func (et *Et) GetKlineRange(pair CurrencyPair, period KlinePeriod, start, end time.Time) ([]Kline, []KlineGap, error) {
+	return GetLatestKlineRange(spotCapabilities, func(p KlinePeriod, n, t int) ([]Kline, error) {
+		return et.GetKlineRecords(pair, p, n, t)
+	}, period, start, end)
+}
+
 func (et *Et) LimitBuy(pair CurrencyPair, price, amount string) (*Order, error) {
 	return et.placeOrder(amount, price, pair, 2)
 }
@@ -409,7 +433,7 @@ func (et *Et) MarketSell(pair CurrencyPair, amount string) (*Order, error) {
 func (et *Et) Cancel(orderId string, pair CurrencyPair) (bool, error) {
 	oid, _ := strconv.ParseFloat(orderId, 64)
 	reqMap := map[string]interface{}{
-		"market":   pair.ToSymbol("/"),
+		"market":   ExchangeSymbol(ET, pair, "/"),
 		"order_id": oid,
 	}
 
@@ -463,7 +487,7 @@ type RspOrders struct {
 }
 
 func (et *Et) GetOrder(orderId string, pair CurrencyPair) (*Order, error) {
-	reqURL := fmt.Sprintf("market=%v&order_id=%v", pair.ToSymbol("/"), orderId)
+	reqURL := fmt.Sprintf("market=%v&order_id=%v", ExchangeSymbol(ET, pair, "/"), orderId)
 	response, err := HttpGet2(et.httpClient, et.baseUrl+"/userapi/order/detail?"+reqURL, et.buildHeaders())
 	if err != nil {
 		if err.Error() == "order not found" { // 易通未成交的订单，撤单后，查询不到
@@ -493,7 +517,7 @@ func (et *Et) GetOrder(orderId string, pair CurrencyPair) (*Order, error) {
 }
 
 func (et *Et) GetPendingOrders(pair CurrencyPair) ([]Order, error) {
-	reqURL := fmt.Sprintf("market=%v&side=%v&limit=%v", pair.ToSymbol("/"), 0, 500)
+	reqURL := fmt.Sprintf("market=%v&side=%v&limit=%v", ExchangeSymbol(ET, pair, "/"), 0, 500)
 	response, err := HttpGet2(et.httpClient, et.baseUrl+"/userapi/order/pending?"+reqURL, et.buildHeaders())
 	if err != nil {
 		fmt.Printf("解析json失败：%+v", err)
@@ -522,7 +546,7 @@ func (et *Et) GetPendingOrders(pair CurrencyPair) ([]Order, error) {
 }
 
 func (et *Et) GetFinishedOrders(pair CurrencyPair) ([]Order, error) {
-	reqURL := fmt.Sprintf("market=%v&side=%v&limit=%v", pair.ToSymbol("/"), 0, 100)
+	reqURL := fmt.Sprintf("market=%v&side=%v&limit=%v", ExchangeSymbol(ET, pair, "/"), 0, 100)
 	response, err := HttpGet2(et.httpClient, et.baseUrl+"/userapi/order/finished?"+reqURL, et.buildHeaders())
 	if err != nil {
 		fmt.Printf("解析json失败：%+v", err)
@@ -630,7 +654,7 @@ func (et *Et) GetAccount() (*Account, error) {
 	for _, v := range balances {
 		obj, _ := v.(map[string]interface{})
 		symbol := ToString(obj["symbol"])
-		currency := NewCurrency(symbol)
+		currency := CanonicalCurrency(ET, symbol)
 		state := ToInt(obj["state"])
 		balance := ToFloat64(obj["balance"])
 
@@ -714,14 +738,14 @@ func parseOrder(order *Order, data map[string]interface{}) {
 		order.Amount = order.DealAmount
 	}
 
-	order.Symbol = strings.ToLower(ToString(data["market"]))
-	order.Market = NewCurrencyPairFromString(order.Symbol)
+	order.Market = CanonicalPair(ET, NewCurrencyPairFromString(ToString(data["market"])))
+	order.Symbol = order.Market.ToLowerSymbol("/")
 }
 
 // side: 1卖单 2买单
 func (et *Et) placeOrder(amount, price string, pair CurrencyPair, side int) (*Order, error) {
 	reqMap := map[string]interface{}{
-		"market": pair.ToSymbol("/"),
+		"market": ExchangeSymbol(ET, pair, "/"),
 		"side":   side,
 		"amount": amount,
 		"price":  price,
@@ -771,7 +795,7 @@ func (et *Et) placeOrder(amount, price string, pair CurrencyPair, side int) (*Or
 // side: 1卖单 2买单
 func (et *Et) placeMarketOrder(amount string, pair CurrencyPair, side int) (*Order, error) {
 	reqMap := map[string]interface{}{
-		"market": pair.ToSymbol("/"),
+		"market": ExchangeSymbol(ET, pair, "/"),
 		"side":   side,
 		"amount": amount,
 	}
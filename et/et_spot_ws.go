@@ -34,7 +34,7 @@ func (ws *EtSpotWs) GetExchangeName() string {
 
 // 格式化流名称
 func (ws *EtSpotWs) FormatTopicName(topic string, pair CurrencyPair) string {
-	return ws.formatTopicName(topic, pair.ToSymbol("_"))
+	return ws.formatTopicName(topic, ExchangeSymbol(ET, pair, "_"))
 }
 
 // 格式化流名称
@@ -62,7 +62,7 @@ func (ws *EtSpotWs) FormatTopicUnsubData(topic string, pair CurrencyPair) []byte
 }
 
 func (ws *EtSpotWs) SubTicker(pair CurrencyPair, cb func(*Ticker) error) (err error) {
-	stream := fmt.Sprintf("ticker_%v", pair.ToLowerSymbol(""))
+	stream := fmt.Sprintf("ticker_%v", ExchangeLowerSymbol(ET, pair, ""))
 	s, ok := ws.streamMap[stream]
 	// 取消
 	if cb == nil {
@@ -90,7 +90,7 @@ func (ws *EtSpotWs) SubTicker(pair CurrencyPair, cb func(*Ticker) error) (err er
 }
 
 func (ws *EtSpotWs) SubDepth(pair CurrencyPair, cb func(*Depth) error) (err error) {
-	stream := fmt.Sprintf("depth_%v", pair.ToLowerSymbol(""))
+	stream := fmt.Sprintf("depth_%v", ExchangeLowerSymbol(ET, pair, ""))
 	s, ok := ws.streamMap[stream]
 	// 取消
 	if cb == nil {
@@ -118,7 +118,7 @@ func (ws *EtSpotWs) SubDepth(pair CurrencyPair, cb func(*Depth) error) (err erro
 }
 
 func (ws *EtSpotWs) SubTrade(pair CurrencyPair, cb func([]Trade) error) (err error) {
-	stream := fmt.Sprintf("trade_%v", pair.ToLowerSymbol(""))
+	stream := fmt.Sprintf("trade_%v", ExchangeLowerSymbol(ET, pair, ""))
 	s, ok := ws.streamMap[stream]
 	// 取消
 	if cb == nil {
@@ -48,7 +48,7 @@ func (ws *EtSpotWsSingle) GetExchangeName() string {
 
 // 格式化流名称
 func (ws *EtSpotWsSingle) FormatTopicName(topic string, pair CurrencyPair) string {
-	symbol := pair.ToLowerSymbol("")
+	symbol := ExchangeLowerSymbol(ET, pair, "")
 	switch topic {
 	case STREAM_TICKER:
 		return fmt.Sprintf("%v_ticker", symbol)
@@ -67,12 +67,12 @@ func (ws *EtSpotWsSingle) FormatTopicSubData(topic string, pair CurrencyPair) []
 	case STREAM_TICKER:
 		return ws.Pack(map[string]interface{}{
 			"method": "today.subscribe",
-			"params": []string{pair.ToSymbol("/")},
+			"params": []string{ExchangeSymbol(ET, pair, "/")},
 			"id":     time.Now().Unix()})
 	case STREAM_DEPTH:
 		return ws.Pack(map[string]interface{}{
 			"method": "depth.subscribe",
-			"params": []interface{}{pair.ToSymbol("/"), 30, "0"},
+			"params": []interface{}{ExchangeSymbol(ET, pair, "/"), 30, "0"},
 			"id":     time.Now().Unix()})
 	case STREAM_TRADE:
 		return nil
@@ -176,7 +176,8 @@ func (ws *EtSpotWsSingle) parseTicker(resp []interface{}) *Ticker {
 		Vol:    ToFloat64(tickerMap["volume"]),
 		TS:     time.Now().UnixNano() / 1000000,
 	}
-	ticker.Market = NewCurrencyPairFromString(ticker.Symbol)
+	ticker.Market = CanonicalPair(ET, NewCurrencyPairFromString(ticker.Symbol))
+	ticker.Symbol = ticker.Market.ToLowerSymbol("/")
 	return ticker
 }
 
@@ -204,10 +205,10 @@ func (ws *EtSpotWsSingle) parseDepth(resp []interface{}) (dep *Depth) {
 	}
 
 	dep = &Depth{
-		Market: NewCurrencyPairFromString(symbol),
-		Symbol: strings.ToLower(symbol),
+		Market: CanonicalPair(ET, NewCurrencyPairFromString(symbol)),
 		TS:     time.Now().UnixNano() / int64(time.Millisecond),
 	}
+	dep.Symbol = dep.Market.ToLowerSymbol("/")
 
 	depMap, ok := ws.spotPairDepthMap[symbol]
 	if !ok {
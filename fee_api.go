@@ -0,0 +1,16 @@
+package exapi
+
+// trade fee api interface
+type FeeAPI interface {
+	// 获取交易所名称
+	GetExchangeName() string
+	// 设置交易所地址，仅在初始化时设置，不支持后续动态更改
+	SetURL(exurl string)
+	// 获取交易所地址
+	GetURL() string
+
+	// 获取账户在单一币种对上的手续费率
+	GetTradeFee(pair CurrencyPair) (*TradeFee, error)
+	// 获取账户在所有币种对上的手续费率，key为BASE/QUOTE格式的币对名称
+	GetTradeFeeMap() (map[string]TradeFee, error)
+}
@@ -0,0 +1,43 @@
+package exapi
+
+// 账户在币种对上的手续费率，交易所没有提供账户费率接口时为公布的费率
+type TradeFee struct {
+	Symbol    string  `json:"symbol"`     // 币对名称，BASE/QUOTE格式
+	MakerRate float64 `json:"maker_rate"` // 挂单手续费率
+	TakerRate float64 `json:"taker_rate"` // 吃单手续费率
+}
+
+// 从币对信息中取出手续费率
+func TradeFeeMapOf(ssm map[string]SymbolSetting) map[string]TradeFee {
+	tfm := make(map[string]TradeFee, len(ssm))
+	for k, v := range ssm {
+		tfm[k] = TradeFee{
+			Symbol:    k,
+			MakerRate: v.MakerFee,
+			TakerRate: v.TakerFee,
+		}
+	}
+	return tfm
+}
+
+// 用手续费率更新币对信息的MakerFee和TakerFee，没有手续费率的币对保持不变
+func ApplyTradeFee(ssm map[string]SymbolSetting, tfm map[string]TradeFee) {
+	for k, tf := range tfm {
+		ss, ok := ssm[k]
+		if !ok {
+			continue
+		}
+		ss.MakerFee = tf.MakerRate
+		ss.TakerFee = tf.TakerRate
+		ssm[k] = ss
+	}
+}
+
+// 从手续费率表中取出单一币种对的手续费率
+func TradeFeeOf(tfm map[string]TradeFee, pair CurrencyPair) (*TradeFee, error) {
+	tf, ok := tfm[pair.ToSymbol("/")]
+	if !ok {
+		return nil, ErrorAssetNotFound
+	}
+	return &tf, nil
+}
@@ -0,0 +1,29 @@
+package exapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestApplyTradeFee(t *testing.T) {
+	ssm := map[string]SymbolSetting{
+		"BTC/USDT": {Symbol: "BTC/USDT", MakerFee: 0.002, TakerFee: 0.002},
+		"ETH/USDT": {Symbol: "ETH/USDT", MakerFee: 0.002, TakerFee: 0.002},
+	}
+	ApplyTradeFee(ssm, map[string]TradeFee{
+		"BTC/USDT": {Symbol: "BTC/USDT", MakerRate: 0.0008, TakerRate: 0.001},
+		"LTC/USDT": {Symbol: "LTC/USDT", MakerRate: 0.0008, TakerRate: 0.001},
+	})
+	assert.Equal(t, 0.0008, ssm["BTC/USDT"].MakerFee)
+	assert.Equal(t, 0.001, ssm["BTC/USDT"].TakerFee)
+	assert.Equal(t, 0.002, ssm["ETH/USDT"].MakerFee)
+	assert.Len(t, ssm, 2)
+
+	tfm := TradeFeeMapOf(ssm)
+	tf, err := TradeFeeOf(tfm, NewCurrencyPairFromString("ETH/USDT"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0.002, tf.TakerRate)
+
+	_, err = TradeFeeOf(tfm, NewCurrencyPairFromString("LTC/USDT"))
+	assert.Equal(t, ErrorAssetNotFound, err)
+}
@@ -0,0 +1,36 @@
+package exapi
+
+// future api interface
+type FutureRestAPI interface {
+	// 获取交易所名称
+	GetExchangeName() string
+	// 设置交易所地址，仅在初始化时设置，不支持后续动态更改
+	SetURL(exurl string)
+	// 获取交易所地址
+	GetURL() string
+
+	// 获取所有交割合约
+	GetFutureContracts() ([]FutureContract, error)
+
+	// 公共行情
+	// 获取合约行情，contractType为THIS_WEEK_CONTRACT/NEXT_WEEK_CONTRACT/QUARTER_CONTRACT
+	GetFutureTicker(pair CurrencyPair, contractType string) (*FutureTicker, error)
+	// 获取合约深度
+	GetFutureDepth(pair CurrencyPair, contractType string, size int) (*Depth, error)
+
+	// 交易相关
+	// 下单，openType为OPEN_BUY/OPEN_SELL/CLOSE_BUY/CLOSE_SELL，matchPrice为1时以对手价成交，忽略price
+	PlaceFutureOrder(pair CurrencyPair, contractType, price, amount string, openType, matchPrice, leverRate int) (*FutureOrder, error)
+	// 撤单
+	CancelFutureOrder(orderId string, pair CurrencyPair, contractType string) (bool, error)
+	// 获取订单详情
+	GetFutureOrder(orderId string, pair CurrencyPair, contractType string) (*FutureOrder, error)
+	// 获取当前未完成订单列表
+	GetUnfinishFutureOrders(pair CurrencyPair, contractType string) ([]FutureOrder, error)
+	// 获取持仓
+	GetFuturePosition(pair CurrencyPair, contractType string) ([]FuturePosition, error)
+	// 获取合约账户权益
+	GetFutureAccount() (*FutureAccount, error)
+	// 设置杠杆倍数
+	SetFutureLeverage(pair CurrencyPair, contractType string, leverRate int) error
+}
@@ -77,3 +77,13 @@ type FuturePosition struct {
 	ContractId     int64
 	ForceLiquPrice float64 //预估爆仓价
 }
+
+// 交割合约信息
+type FutureContract struct {
+	ContractId   string       `json:"contract_id"`   // 合约id，如BTC-USD-200925、BTC200925
+	ContractType string       `json:"contract_type"` // 合约类型，THIS_WEEK_CONTRACT/NEXT_WEEK_CONTRACT/QUARTER_CONTRACT
+	Market       CurrencyPair `json:"market"`        // 交易对
+	ContractVal  float64      `json:"contract_val"`  // 合约面值
+	TickSize     float64      `json:"tick_size"`     // 价格最小变动单位
+	DeliveryDate int64        `json:"delivery_date"` // 交割时间，单位为毫秒(millisecond)
+}
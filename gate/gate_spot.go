@@ -67,6 +67,37 @@ func (gate *Gate) GetURL() string {
 	return gate.baseUrl
 }
 
+var spotCapabilities = &Capabilities{
+	Exchange:           GATE,
+	UnsupportedMethods: []string{"GetFinishedOrders", "MarketBuy", "MarketSell"},
+	KlinePeriods:       KlinePeriodsOf(_INERNAL_KLINE_PERIOD_CONVERTER),
+	OrderSides:         []TradeSide{BUY, SELL},
+	WebsocketStreams:   AllStreams,
+}
+
+func (gate *Gate) Capabilities() *Capabilities {
+	return spotCapabilities.Clone()
+}
+
+// gate没有账户费率查询接口，返回币对公布的费率
+func (gate *Gate) GetTradeFee(pair CurrencyPair) (*TradeFee, error) {
+	tfm, err := gate.GetTradeFeeMap()
+	if err != nil {
+		return nil, err
+	}
+
+	return TradeFeeOf(tfm, pair)
+}
+
+func (gate *Gate) GetTradeFeeMap() (map[string]TradeFee, error) {
+	ssm, err := gate.GetAllCurrencyPair()
+	if err != nil {
+		return nil, err
+	}
+
+	return TradeFeeMapOf(ssm), nil
+}
+
 func (gate *Gate) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
 	reqURL := gate.baseUrl + "marketinfo"
 	resp, err := HttpGet(gate.httpClient, reqURL)
@@ -95,21 +126,27 @@ func (gate *Gate) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
 			if trade_disabled == 1 || buy_disabled == 1 || sell_disabled == 1 {
 				continue
 			}
+			pricePrecision := ToInt(obj["decimal_places"])
+			amountPrecision := ToInt(obj["amount_decimal_places"])
 			ssm[symbol] = SymbolSetting{
 				Symbol: symbol,
 				Base:   currencies[0],
 				Quote:  currencies[1],
 				// 经验证，用下面的数字来计算
-				MinSize:     math.Pow10(-ToInt(obj["amount_decimal_places"])),
-				MinPrice:    math.Pow10(-ToInt(obj["decimal_places"])),
-				MinNotional: ToFloat64(obj["min_amount"]),
-				MakerFee:    ToFloat64(obj["fee"]) / 100.0,
-				TakerFee:    ToFloat64(obj["fee"]) / 100.0,
+				MinSize:         math.Pow10(-amountPrecision),
+				MinPrice:        math.Pow10(-pricePrecision),
+				MinNotional:     ToFloat64(obj["min_amount"]),
+				MakerFee:        ToFloat64(obj["fee"]) / 100.0,
+				TakerFee:        ToFloat64(obj["fee"]) / 100.0,
+				TickSize:        math.Pow10(-pricePrecision),
+				StepSize:        math.Pow10(-amountPrecision),
+				PricePrecision:  pricePrecision,
+				AmountPrecision: amountPrecision,
 			}
 		}
 	}
 
-	return ssm, nil
+	return CanonicalSymbolSettings(GATE, ssm), nil
 }
 
 func (gate *Gate) GetCurrencyStatus(currency Currency) (CurrencyStatus, error) {
@@ -160,11 +197,11 @@ func (gate *Gate) GetAllCurrencyStatus() (all map[string]CurrencyStatus, err err
 		}
 	}
 
-	return all, nil
+	return CanonicalCurrencyStatus(GATE, all), nil
 }
 
 func (gate *Gate) GetTicker(pair CurrencyPair) (*Ticker, error) {
-	symbol := pair.ToLowerSymbol("_")
+	symbol := ExchangeLowerSymbol(GATE, pair, "_")
 	resp, err := HttpGet(gate.httpClient, gate.baseUrl+fmt.Sprintf("ticker/%s", symbol))
 	if err != nil {
 		return nil, err
@@ -207,8 +244,8 @@ func (gate *Gate) GetAllTicker() ([]Ticker, error) {
 		}
 
 		ticker := Ticker{}
-		ticker.Symbol = strings.Replace(k, "_", "/", -1)
-		ticker.Market = NewCurrencyPairFromString(ticker.Symbol)
+		ticker.Market = CanonicalPair(GATE, NewCurrencyPairFromString(strings.Replace(k, "_", "/", -1)))
+		ticker.Symbol = ticker.Market.ToLowerSymbol("/")
 		//ticker.Open
 		ticker.Last, _ = strconv.ParseFloat(tickerMap["last"].(string), 64)
 		ticker.High, _ = strconv.ParseFloat(tickerMap["high24hr"].(string), 64)
@@ -227,7 +264,7 @@ func (gate *Gate) GetAllTicker() ([]Ticker, error) {
 }
 
 func (gate *Gate) GetDepth(pair CurrencyPair, size int, step int) (*Depth, error) {
-	symbol := pair.ToLowerSymbol("_")
+	symbol := ExchangeLowerSymbol(GATE, pair, "_")
 	resp, err := HttpGet(gate.httpClient, gate.baseUrl+fmt.Sprintf("orderBook/%s", symbol))
 	if err != nil {
 		return nil, err
@@ -273,7 +310,7 @@ func (gate *Gate) GetDepth(pair CurrencyPair, size int, step int) (*Depth, error
 }
 
 func (gate *Gate) GetTrades(pair CurrencyPair, size int) ([]Trade, error) {
-	symbol := pair.ToLowerSymbol("_")
+	symbol := ExchangeLowerSymbol(GATE, pair, "_")
 	resp, err := HttpGet(gate.httpClient, gate.baseUrl+fmt.Sprintf("tradeHistory/%s", symbol))
 	if err != nil {
 		return nil, err
@@ -313,9 +350,12 @@ func (gate *Gate) GetTrades(pair CurrencyPair, size int) ([]Trade, error) {
 func (gate *Gate) GetKlineRecords(pair CurrencyPair, period KlinePeriod, size, since int) ([]Kline, error) {
 	periodS, isOk := _INERNAL_KLINE_PERIOD_CONVERTER[period]
 	if isOk != true {
-		return nil, fmt.Errorf("unsupported %v KlinePeriod:%v", gate.GetExchangeName(), period)
+		// 交易所不支持的周期，用较小周期的K线合成
+		return GetKlinesWithResample(spotCapabilities, func(p KlinePeriod, n, t int) ([]Kline, error) {
+			return gate.GetKlineRecords(pair, p, n, t)
+		}, period, size, since)
 	}
-	symbol := pair.ToLowerSymbol("_")
+	symbol := ExchangeLowerSymbol(GATE, pair, "_")
 	resp, err := HttpGet(gate.httpClient, gate.baseUrl+fmt.Sprintf("candlestick2/%s?group_sec=%v&range_hour=8760", symbol, periodS))
 	if err != nil {
 		return nil, err
@@ -350,6 +390,13 @@ func (gate *Gate) GetKlineRecords(pair CurrencyPair, period KlinePeriod, size, s
 	return klines, nil
 }
 
+// 交易所只能查询最近的K线，范围早于可查询的K线时返回ErrorKlineRangeTooOld
+func (gate *Gate) GetKlineRange(pair CurrencyPair, period KlinePeriod, start, end time.Time) ([]Kline, []KlineGap, error) {
+	return GetLatestKlineRange(spotCapabilities, func(p KlinePeriod, n, t int) ([]Kline, error) {
+		return gate.GetKlineRecords(pair, p, n, t)
+	}, period, start, end)
+}
+
 func (gate *Gate) LimitBuy(pair CurrencyPair, price, amount string) (*Order, error) {
 	return gate.placeOrder(amount, price, "buy", pair)
 }
@@ -369,7 +416,7 @@ func (gate *Gate) MarketSell(pair CurrencyPair, amount string) (*Order, error) {
 }
 
 func (gate *Gate) Cancel(orderId string, pair CurrencyPair) (bool, error) {
-	symbol := pair.ToSymbol("_")
+	symbol := ExchangeSymbol(GATE, pair, "_")
 	params := url.Values{}
 	params.Set("orderNumber", orderId)
 	params.Set("currencyPair", symbol)
@@ -396,7 +443,7 @@ func (gate *Gate) Cancel(orderId string, pair CurrencyPair) (bool, error) {
 }
 
 func (gate *Gate) GetOrder(orderId string, pair CurrencyPair) (*Order, error) {
-	symbol := pair.ToSymbol("_")
+	symbol := ExchangeSymbol(GATE, pair, "_")
 	params := url.Values{}
 	params.Set("orderNumber", orderId)
 	params.Set("currencyPair", symbol)
@@ -430,7 +477,7 @@ func (gate *Gate) GetOrder(orderId string, pair CurrencyPair) (*Order, error) {
 }
 
 func (gate *Gate) GetPendingOrders(pair CurrencyPair) ([]Order, error) {
-	symbol := pair.ToSymbol("_")
+	symbol := ExchangeSymbol(GATE, pair, "_")
 	params := url.Values{}
 	params.Set("currencyPair", symbol)
 
@@ -471,7 +518,7 @@ func (gate *Gate) GetFinishedOrders(pair CurrencyPair) ([]Order, error) {
 }
 
 func (gate *Gate) GetOrderDeal(orderId string, pair CurrencyPair) ([]OrderDeal, error) {
-	symbol := pair.ToSymbol("_")
+	symbol := ExchangeSymbol(GATE, pair, "_")
 	params := url.Values{}
 	params.Set("currencyPair", symbol)
 	if len(orderId) > 0 {
@@ -525,7 +572,7 @@ func (gate *Gate) GetOrderDeal(orderId string, pair CurrencyPair) ([]OrderDeal,
 }
 
 func (gate *Gate) GetUserTrades(pair CurrencyPair) ([]Trade, error) {
-	symbol := pair.ToSymbol("_")
+	symbol := ExchangeSymbol(GATE, pair, "_")
 	params := url.Values{}
 	params.Set("currencyPair", symbol)
 
@@ -603,7 +650,7 @@ func (gate *Gate) GetAccount() (*Account, error) {
 	lockedmap := respmap["locked"].(map[string]interface{})
 	for k, v := range availablemap {
 		subAcc := SubAccount{}
-		subAcc.Currency = NewCurrency(k)
+		subAcc.Currency = CanonicalCurrency(GATE, k)
 		subAcc.Amount = ToFloat64(v)
 		subAcc.FrozenAmount = ToFloat64(lockedmap[k])
 		acc.SubAccounts[subAcc.Currency] = subAcc
@@ -642,7 +689,7 @@ func parseOrder(order *Order, ordermap map[string]interface{}) {
 }
 
 func (gate *Gate) placeOrder(amount, price, tradeType string, pair CurrencyPair) (*Order, error) {
-	symbol := pair.ToSymbol("_")
+	symbol := ExchangeSymbol(GATE, pair, "_")
 	params := url.Values{}
 	params.Set("currencyPair", symbol)
 	params.Set("rate", price)
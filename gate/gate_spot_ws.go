@@ -41,7 +41,7 @@ func (ws *GateSpotWs) GetExchangeName() string {
 
 // 格式化流名称
 func (ws *GateSpotWs) FormatTopicName(topic string, pair CurrencyPair) string {
-	return ws.formatTopicName(topic, pair.ToSymbol("_"))
+	return ws.formatTopicName(topic, ExchangeSymbol(GATE, pair, "_"))
 }
 
 // 格式化流名称
@@ -60,7 +60,7 @@ func (ws *GateSpotWs) formatTopicName(topic, symbol string) string {
 
 // 格式化流订阅消息
 func (ws *GateSpotWs) FormatTopicSubData(topic string, pair CurrencyPair) []byte {
-	symbol := pair.ToSymbol("_")
+	symbol := ExchangeSymbol(GATE, pair, "_")
 	switch topic {
 	case STREAM_TICKER:
 		return ws.Pack(map[string]interface{}{
@@ -88,7 +88,7 @@ func (ws *GateSpotWs) FormatTopicUnsubData(topic string, pair CurrencyPair) []by
 }
 
 func (ws *GateSpotWs) SubTicker(pair CurrencyPair, cb func(*Ticker) error) (err error) {
-	stream := fmt.Sprintf("ticker_%v", pair.ToLowerSymbol(""))
+	stream := fmt.Sprintf("ticker_%v", ExchangeLowerSymbol(GATE, pair, ""))
 	s, ok := ws.streamMap[stream]
 	// 取消
 	if cb == nil {
@@ -116,7 +116,7 @@ func (ws *GateSpotWs) SubTicker(pair CurrencyPair, cb func(*Ticker) error) (err
 }
 
 func (ws *GateSpotWs) SubDepth(pair CurrencyPair, cb func(*Depth) error) (err error) {
-	stream := fmt.Sprintf("depth_%v", pair.ToLowerSymbol(""))
+	stream := fmt.Sprintf("depth_%v", ExchangeLowerSymbol(GATE, pair, ""))
 	s, ok := ws.streamMap[stream]
 	// 取消
 	if cb == nil {
@@ -144,7 +144,7 @@ func (ws *GateSpotWs) SubDepth(pair CurrencyPair, cb func(*Depth) error) (err er
 }
 
 func (ws *GateSpotWs) SubTrade(pair CurrencyPair, cb func([]Trade) error) (err error) {
-	stream := fmt.Sprintf("trade_%v", pair.ToLowerSymbol(""))
+	stream := fmt.Sprintf("trade_%v", ExchangeLowerSymbol(GATE, pair, ""))
 	s, ok := ws.streamMap[stream]
 	// 取消
 	if cb == nil {
@@ -47,7 +47,7 @@ func (ws *GateSpotWsSingle) GetExchangeName() string {
 
 // 格式化流名称
 func (ws *GateSpotWsSingle) FormatTopicName(topic string, pair CurrencyPair) string {
-	return ws.formatTopicName(topic, pair.ToSymbol("_"))
+	return ws.formatTopicName(topic, ExchangeSymbol(GATE, pair, "_"))
 }
 
 // 格式化流名称
@@ -66,7 +66,7 @@ func (ws *GateSpotWsSingle) formatTopicName(topic, symbol string) string {
 
 // 格式化流订阅消息
 func (ws *GateSpotWsSingle) FormatTopicSubData(topic string, pair CurrencyPair) []byte {
-	symbol := pair.ToSymbol("_")
+	symbol := ExchangeSymbol(GATE, pair, "_")
 	switch topic {
 	case STREAM_TICKER:
 		return ws.Pack(map[string]interface{}{
@@ -0,0 +1,135 @@
+package gate
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/betterjun/exapi"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+/**
+ * deposit and withdrawal
+ */
+func NewWalletAPI(client *http.Client, apiKey, secretKey string) WalletAPI {
+	return NewSpotAPI(client, apiKey, secretKey).(*Gate)
+}
+
+// 签名的私有接口请求
+func (gate *Gate) doPrivate(path string, params url.Values) (map[string]interface{}, error) {
+	postData := params.Encode()
+	sign := getSign(gate.secretKey, postData)
+	resp, err := HttpPostForm5(gate.httpClient, gate.baseUrl+"private/"+path, postData, map[string]string{"key": gate.accessKey, "sign": sign})
+	if err != nil {
+		return nil, err
+	}
+
+	respmap := make(map[string]interface{})
+	err = json.Unmarshal(resp, &respmap)
+	if err != nil {
+		return nil, err
+	}
+
+	if ToString(respmap["result"]) != "true" {
+		return nil, fmt.Errorf("error occurred, code:%v, message:%v", respmap["code"], respmap["message"])
+	}
+
+	return respmap, nil
+}
+
+// 带标签的地址格式为 地址/标签，gate不区分链
+func (gate *Gate) GetDepositAddress(currency Currency, chain string) (*DepositAddress, error) {
+	params := url.Values{}
+	params.Set("currency", ExchangeCurrency(GATE, currency).LowerSymbol())
+	respmap, err := gate.doPrivate("depositAddress", params)
+	if err != nil {
+		return nil, err
+	}
+
+	addr := ToString(respmap["addr"])
+	if len(addr) == 0 {
+		return nil, errors.New("deposit address not found")
+	}
+
+	da := &DepositAddress{Currency: currency, Address: addr}
+	if i := strings.Index(addr, "/"); i > 0 {
+		da.Address, da.Tag = addr[:i], addr[i+1:]
+	}
+	return da, nil
+}
+
+// gate提币不返回提币id
+func (gate *Gate) Withdraw(currency Currency, amount, address, tag, chain string) (string, error) {
+	if len(tag) > 0 {
+		address = address + "/" + tag
+	}
+
+	params := url.Values{}
+	params.Set("currency", ExchangeCurrency(GATE, currency).LowerSymbol())
+	params.Set("amount", amount)
+	params.Set("address", address)
+	_, err := gate.doPrivate("withdraw", params)
+	if err != nil {
+		return "", err
+	}
+
+	return "", nil
+}
+
+// gate不支持撤销提币
+func (gate *Gate) CancelWithdraw(id string, currency Currency) (bool, error) {
+	return false, ErrorUnsupported
+}
+
+// 获取最近30天的充提币记录
+func (gate *Gate) getWalletRecords(currency Currency, key string, typ WalletRecordType) ([]WalletRecord, error) {
+	now := time.Now()
+	params := url.Values{}
+	params.Set("start", fmt.Sprint(now.AddDate(0, 0, -30).Unix()))
+	params.Set("end", fmt.Sprint(now.Unix()))
+	respmap, err := gate.doPrivate("depositsWithdrawals", params)
+	if err != nil {
+		return nil, err
+	}
+
+	list, _ := respmap[key].([]interface{})
+	records := make([]WalletRecord, 0, len(list))
+	// 交易所返回按时间升序排列
+	for i := len(list) - 1; i >= 0; i-- {
+		obj := list[i].(map[string]interface{})
+		if !CanonicalCurrency(GATE, ToString(obj["currency"])).Equal(currency) {
+			continue
+		}
+
+		record := WalletRecord{
+			Id:       ToString(obj["id"]),
+			Type:     typ,
+			Currency: currency,
+			Amount:   ToFloat64(obj["amount"]),
+			Fee:      ToFloat64(obj["fee"]),
+			Address:  ToString(obj["address"]),
+			TxId:     ToString(obj["txid"]),
+			Status:   WALLET_PENDING,
+			TS:       ToInt64(obj["timestamp"]) * 1000,
+		}
+		switch ToString(obj["status"]) {
+		case "DONE":
+			record.Status = WALLET_SUCCESS
+		case "CANCEL":
+			record.Status = WALLET_CANCELED
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func (gate *Gate) GetDepositHistory(currency Currency) ([]WalletRecord, error) {
+	return gate.getWalletRecords(currency, "deposits", WALLET_DEPOSIT)
+}
+
+func (gate *Gate) GetWithdrawHistory(currency Currency) ([]WalletRecord, error) {
+	return gate.getWalletRecords(currency, "withdraws", WALLET_WITHDRAW)
+}
@@ -0,0 +1,440 @@
+package huobi
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/betterjun/exapi"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// 火币交割合约类型与行情代码后缀的对应
+var _INERNAL_FUTURE_CONTRACT_CONVERTER = map[string]string{
+	THIS_WEEK_CONTRACT: "CW",
+	NEXT_WEEK_CONTRACT: "NW",
+	QUARTER_CONTRACT:   "CQ",
+}
+
+type Hbdm struct {
+	// 签名规则与现货相同，复用现货的签名
+	hb *HuoBiPro
+}
+
+/**
+ * future
+ */
+func NewHbdm(client *http.Client, apikey, secretkey string) *Hbdm {
+	hb := NewHuoBiPro(client, apikey, secretkey, "")
+	hb.baseUrl = "https://api.hbdm.com"
+	return &Hbdm{hb: hb}
+}
+
+func NewFutureAPI(client *http.Client, apikey, secretkey string) FutureRestAPI {
+	return NewHbdm(client, apikey, secretkey)
+}
+
+func (hbdm *Hbdm) GetExchangeName() string {
+	return HBDM
+}
+
+func (hbdm *Hbdm) SetURL(exurl string) {
+	hbdm.hb.baseUrl = exurl
+}
+
+func (hbdm *Hbdm) GetURL() string {
+	return hbdm.hb.baseUrl
+}
+
+// 私有接口请求，返回data字段
+func (hbdm *Hbdm) doPost(path string, params map[string]interface{}) (interface{}, error) {
+	signParams := url.Values{}
+	hbdm.hb.buildPostForm("POST", path, &signParams)
+
+	resp, err := HttpPostForm6(hbdm.hb.httpClient, hbdm.hb.baseUrl+path+"?"+signParams.Encode(), params,
+		map[string]string{"Accept-Language": "zh-cn"})
+	if err != nil {
+		return nil, err
+	}
+
+	var respmap map[string]interface{}
+	err = json.Unmarshal(resp, &respmap)
+	if err != nil {
+		return nil, err
+	}
+
+	// a failure example:
+	// {"status":"error","err_code":1014,"err_msg":"This contract doesnt exist.","ts":1490759594752}
+	if ToString(respmap["status"]) != "ok" {
+		return nil, fmt.Errorf("code:%v, msg:%v", respmap["err_code"], respmap["err_msg"])
+	}
+
+	return respmap["data"], nil
+}
+
+func (hbdm *Hbdm) getContractCode(pair CurrencyPair, contractType string) (string, error) {
+	suffix, ok := _INERNAL_FUTURE_CONTRACT_CONVERTER[contractType]
+	if !ok {
+		return "", fmt.Errorf("unsupported %v contract type:%v", hbdm.GetExchangeName(), contractType)
+	}
+	return ExchangeCurrency(HUOBI, pair.Stock).Symbol() + "_" + suffix, nil
+}
+
+func (hbdm *Hbdm) GetFutureContracts() ([]FutureContract, error) {
+	respmap, err := HttpGet(hbdm.hb.httpClient, hbdm.hb.baseUrl+"/api/v1/contract_contract_info")
+	if err != nil {
+		return nil, err
+	}
+
+	if ToString(respmap["status"]) != "ok" {
+		return nil, fmt.Errorf("code:%v, msg:%v", respmap["err_code"], respmap["err_msg"])
+	}
+
+	dataArr, ok := respmap["data"].([]interface{})
+	if !ok {
+		return nil, errors.New("data assert error")
+	}
+
+	contracts := make([]FutureContract, 0, len(dataArr))
+	for _, v := range dataArr {
+		obj := v.(map[string]interface{})
+		delivery, _ := time.Parse("20060102", ToString(obj["delivery_date"]))
+		contracts = append(contracts, FutureContract{
+			ContractId:   ToString(obj["contract_code"]),
+			ContractType: ToString(obj["contract_type"]),
+			Market:       NewCurrencyPair(CanonicalCurrency(HUOBI, ToString(obj["symbol"])), USD),
+			ContractVal:  ToFloat64(obj["contract_size"]),
+			TickSize:     ToFloat64(obj["price_tick"]),
+			DeliveryDate: delivery.UnixNano() / int64(time.Millisecond),
+		})
+	}
+
+	return contracts, nil
+}
+
+func (hbdm *Hbdm) GetFutureTicker(pair CurrencyPair, contractType string) (*FutureTicker, error) {
+	symbol, err := hbdm.getContractCode(pair, contractType)
+	if err != nil {
+		return nil, err
+	}
+
+	respmap, err := HttpGet(hbdm.hb.httpClient, hbdm.hb.baseUrl+"/market/detail/merged?symbol="+symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if ToString(respmap["status"]) != "ok" {
+		return nil, fmt.Errorf("code:%v, msg:%v", respmap["err-code"], respmap["err-msg"])
+	}
+
+	tickmap, ok := respmap["tick"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("tick assert error")
+	}
+
+	ticker := &Ticker{
+		Market: pair,
+		Symbol: pair.ToLowerSymbol("/"),
+		Open:   ToFloat64(tickmap["open"]),
+		Last:   ToFloat64(tickmap["close"]),
+		High:   ToFloat64(tickmap["high"]),
+		Low:    ToFloat64(tickmap["low"]),
+		Vol:    ToFloat64(tickmap["amount"]),
+		TS:     ToInt64(respmap["ts"]),
+	}
+	if bid, ok := tickmap["bid"].([]interface{}); ok && len(bid) > 0 {
+		ticker.Buy = ToFloat64(bid[0])
+	}
+	if ask, ok := tickmap["ask"].([]interface{}); ok && len(ask) > 0 {
+		ticker.Sell = ToFloat64(ask[0])
+	}
+
+	return &FutureTicker{Ticker: ticker, ContractType: contractType}, nil
+}
+
+func (hbdm *Hbdm) GetFutureDepth(pair CurrencyPair, contractType string, size int) (*Depth, error) {
+	symbol, err := hbdm.getContractCode(pair, contractType)
+	if err != nil {
+		return nil, err
+	}
+
+	respmap, err := HttpGet(hbdm.hb.httpClient, hbdm.hb.baseUrl+"/market/depth?type=step0&symbol="+symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if ToString(respmap["status"]) != "ok" {
+		return nil, fmt.Errorf("code:%v, msg:%v", respmap["err-code"], respmap["err-msg"])
+	}
+
+	tick, _ := respmap["tick"].(map[string]interface{})
+	dep := hbdm.hb.parseDepthData(tick)
+	dep.ContractType = contractType
+	dep.Market = pair
+	dep.Symbol = pair.ToLowerSymbol("/")
+	dep.TS = ToInt64(respmap["ts"])
+
+	if size > 0 {
+		if len(dep.AskList) > size {
+			dep.AskList = dep.AskList[:size]
+		}
+		if len(dep.BidList) > size {
+			dep.BidList = dep.BidList[:size]
+		}
+	}
+
+	return dep, nil
+}
+
+func (hbdm *Hbdm) PlaceFutureOrder(pair CurrencyPair, contractType, price, amount string, openType, matchPrice, leverRate int) (*FutureOrder, error) {
+	params := map[string]interface{}{
+		"symbol":        ExchangeCurrency(HUOBI, pair.Stock).Symbol(),
+		"contract_type": contractType,
+		"volume":        amount,
+		"lever_rate":    leverRate,
+	}
+
+	switch openType {
+	case OPEN_BUY:
+		params["direction"], params["offset"] = "buy", "open"
+	case OPEN_SELL:
+		params["direction"], params["offset"] = "sell", "open"
+	case CLOSE_BUY:
+		params["direction"], params["offset"] = "sell", "close"
+	case CLOSE_SELL:
+		params["direction"], params["offset"] = "buy", "close"
+	default:
+		return nil, fmt.Errorf("unsupported open type:%v", openType)
+	}
+
+	if matchPrice == 1 {
+		params["order_price_type"] = "opponent"
+	} else {
+		params["order_price_type"] = "limit"
+		params["price"] = price
+	}
+
+	data, err := hbdm.doPost("/api/v1/contract_order", params)
+	if err != nil {
+		return nil, err
+	}
+
+	datamap, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("data assert error")
+	}
+
+	return &FutureOrder{
+		OrderID2:     ToString(datamap["order_id_str"]),
+		OrderID:      ToInt64(datamap["order_id"]),
+		Price:        ToFloat64(price),
+		Amount:       ToFloat64(amount),
+		OrderTime:    time.Now().UnixNano() / int64(time.Millisecond),
+		Status:       ORDER_UNFINISH,
+		Currency:     pair,
+		OType:        openType,
+		LeverRate:    leverRate,
+		ContractName: contractType,
+	}, nil
+}
+
+func (hbdm *Hbdm) CancelFutureOrder(orderId string, pair CurrencyPair, contractType string) (bool, error) {
+	data, err := hbdm.doPost("/api/v1/contract_cancel", map[string]interface{}{
+		"order_id": orderId,
+		"symbol":   ExchangeCurrency(HUOBI, pair.Stock).Symbol(),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	// {"errors":[{"order_id":"161251","err_code":200417,"err_msg":"invalid symbol"}],"successes":"161256"}
+	datamap, _ := data.(map[string]interface{})
+	if errs, ok := datamap["errors"].([]interface{}); ok && len(errs) > 0 {
+		e, _ := errs[0].(map[string]interface{})
+		return false, fmt.Errorf("code:%v, msg:%v", e["err_code"], e["err_msg"])
+	}
+
+	return true, nil
+}
+
+func (hbdm *Hbdm) adaptOrderState(status int) TradeStatus {
+	switch status {
+	case 1, 2, 3:
+		return ORDER_UNFINISH
+	case 4:
+		return ORDER_PART_FINISH
+	case 5, 7:
+		return ORDER_CANCEL
+	case 6:
+		return ORDER_FINISH
+	case 11:
+		return ORDER_CANCEL_ING
+	}
+	return ORDER_UNFINISH
+}
+
+func (hbdm *Hbdm) parseOrder(obj map[string]interface{}, pair CurrencyPair) FutureOrder {
+	ord := FutureOrder{
+		OrderID2:     ToString(obj["order_id_str"]),
+		OrderID:      ToInt64(obj["order_id"]),
+		Price:        ToFloat64(obj["price"]),
+		Amount:       ToFloat64(obj["volume"]),
+		AvgPrice:     ToFloat64(obj["trade_avg_price"]),
+		DealAmount:   ToFloat64(obj["trade_volume"]),
+		OrderTime:    ToInt64(obj["created_at"]),
+		Status:       hbdm.adaptOrderState(ToInt(obj["status"])),
+		Currency:     pair,
+		LeverRate:    ToInt(obj["lever_rate"]),
+		Fee:          ToFloat64(obj["fee"]),
+		ContractName: ToString(obj["contract_code"]),
+	}
+	if len(ord.OrderID2) == 0 {
+		ord.OrderID2 = fmt.Sprint(ord.OrderID)
+	}
+
+	direction := ToString(obj["direction"])
+	offset := ToString(obj["offset"])
+	switch {
+	case direction == "buy" && offset == "open":
+		ord.OType = OPEN_BUY
+	case direction == "sell" && offset == "open":
+		ord.OType = OPEN_SELL
+	case direction == "sell" && offset == "close":
+		ord.OType = CLOSE_BUY
+	case direction == "buy" && offset == "close":
+		ord.OType = CLOSE_SELL
+	}
+
+	return ord
+}
+
+func (hbdm *Hbdm) GetFutureOrder(orderId string, pair CurrencyPair, contractType string) (*FutureOrder, error) {
+	data, err := hbdm.doPost("/api/v1/contract_order_info", map[string]interface{}{
+		"order_id": orderId,
+		"symbol":   ExchangeCurrency(HUOBI, pair.Stock).Symbol(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dataArr, ok := data.([]interface{})
+	if !ok || len(dataArr) == 0 {
+		return nil, errors.New("order not found")
+	}
+
+	ord := hbdm.parseOrder(dataArr[0].(map[string]interface{}), pair)
+	return &ord, nil
+}
+
+func (hbdm *Hbdm) GetUnfinishFutureOrders(pair CurrencyPair, contractType string) ([]FutureOrder, error) {
+	data, err := hbdm.doPost("/api/v1/contract_openorders", map[string]interface{}{
+		"symbol":    ExchangeCurrency(HUOBI, pair.Stock).Symbol(),
+		"page_size": 50,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	datamap, _ := data.(map[string]interface{})
+	ordArr, _ := datamap["orders"].([]interface{})
+	orders := make([]FutureOrder, 0, len(ordArr))
+	for _, v := range ordArr {
+		obj := v.(map[string]interface{})
+		if ToString(obj["contract_type"]) != contractType {
+			continue
+		}
+		orders = append(orders, hbdm.parseOrder(obj, pair))
+	}
+
+	return orders, nil
+}
+
+func (hbdm *Hbdm) GetFuturePosition(pair CurrencyPair, contractType string) ([]FuturePosition, error) {
+	data, err := hbdm.doPost("/api/v1/contract_position_info", map[string]interface{}{
+		"symbol": ExchangeCurrency(HUOBI, pair.Stock).Symbol(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dataArr, _ := data.([]interface{})
+
+	// 火币的多空持仓分为两条记录，按合约合并为一条
+	posmap := make(map[string]*FuturePosition)
+	var codes []string
+	for _, v := range dataArr {
+		obj := v.(map[string]interface{})
+		if ToString(obj["contract_type"]) != contractType {
+			continue
+		}
+
+		code := ToString(obj["contract_code"])
+		pos, ok := posmap[code]
+		if !ok {
+			pos = &FuturePosition{
+				Symbol:       pair,
+				ContractType: contractType,
+				ContractId:   ToInt64(strings.TrimPrefix(code, ExchangeCurrency(HUOBI, pair.Stock).Symbol())),
+				LeverRate:    ToInt(obj["lever_rate"]),
+			}
+			posmap[code] = pos
+			codes = append(codes, code)
+		}
+
+		switch ToString(obj["direction"]) {
+		case "buy":
+			pos.BuyAmount = ToFloat64(obj["volume"])
+			pos.BuyAvailable = ToFloat64(obj["available"])
+			pos.BuyPriceAvg = ToFloat64(obj["cost_open"])
+			pos.BuyPriceCost = ToFloat64(obj["cost_hold"])
+			pos.BuyProfitReal = ToFloat64(obj["profit"])
+		case "sell":
+			pos.SellAmount = ToFloat64(obj["volume"])
+			pos.SellAvailable = ToFloat64(obj["available"])
+			pos.SellPriceAvg = ToFloat64(obj["cost_open"])
+			pos.SellPriceCost = ToFloat64(obj["cost_hold"])
+			pos.SellProfitReal = ToFloat64(obj["profit"])
+		}
+	}
+
+	positions := make([]FuturePosition, 0, len(codes))
+	for _, code := range codes {
+		positions = append(positions, *posmap[code])
+	}
+
+	return positions, nil
+}
+
+func (hbdm *Hbdm) GetFutureAccount() (*FutureAccount, error) {
+	data, err := hbdm.doPost("/api/v1/contract_account_info", map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	dataArr, _ := data.([]interface{})
+	acc := &FutureAccount{FutureSubAccounts: make(map[Currency]FutureSubAccount, len(dataArr))}
+	for _, v := range dataArr {
+		obj := v.(map[string]interface{})
+		currency := CanonicalCurrency(HUOBI, ToString(obj["symbol"]))
+		acc.FutureSubAccounts[currency] = FutureSubAccount{
+			Currency:      currency,
+			AccountRights: ToFloat64(obj["margin_balance"]),
+			KeepDeposit:   ToFloat64(obj["margin_position"]),
+			ProfitReal:    ToFloat64(obj["profit_real"]),
+			ProfitUnreal:  ToFloat64(obj["profit_unreal"]),
+			RiskRate:      ToFloat64(obj["risk_rate"]),
+		}
+	}
+
+	return acc, nil
+}
+
+// 切换杠杆倍数，对该品种所有合约生效，有持仓或挂单时可能失败
+func (hbdm *Hbdm) SetFutureLeverage(pair CurrencyPair, contractType string, leverRate int) error {
+	_, err := hbdm.doPost("/api/v1/contract_switch_lever_rate", map[string]interface{}{
+		"symbol":     ExchangeCurrency(HUOBI, pair.Stock).Symbol(),
+		"lever_rate": leverRate,
+	})
+	return err
+}
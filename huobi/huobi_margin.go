@@ -0,0 +1,300 @@
+package huobi
+
+import (
+	"fmt"
+	. "github.com/betterjun/exapi"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	HB_MARGIN_ACCOUNT = "margin"
+)
+
+/**
+ * isolated margin
+ */
+func NewMarginAPI(client *http.Client, apikey, secretkey string) MarginAPI {
+	return NewHuoBiPro(client, apikey, secretkey, "")
+}
+
+// 签名的GET请求，返回data字段
+func (hbpro *HuoBiPro) doGet(path string, params url.Values) (interface{}, error) {
+	hbpro.buildPostForm("GET", path, &params)
+	respmap, err := HttpGet(hbpro.httpClient, hbpro.baseUrl+path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	if ToString(respmap["status"]) != "ok" {
+		return nil, fmt.Errorf("code:%v, msg:%v", respmap["err-code"], respmap["err-msg"])
+	}
+
+	return respmap["data"], nil
+}
+
+// 签名的POST请求，参数以json格式放在body中，返回data字段
+func (hbpro *HuoBiPro) doPost(path string, body map[string]interface{}) (interface{}, error) {
+	params := url.Values{}
+	hbpro.buildPostForm("POST", path, &params)
+	resp, err := HttpPostForm6(hbpro.httpClient, hbpro.baseUrl+path+"?"+params.Encode(), body,
+		map[string]string{"Accept-Language": "zh-cn"})
+	if err != nil {
+		return nil, err
+	}
+
+	var respmap map[string]interface{}
+	err = json.Unmarshal(resp, &respmap)
+	if err != nil {
+		return nil, err
+	}
+
+	if ToString(respmap["status"]) != "ok" {
+		return nil, fmt.Errorf("code:%v, msg:%v", respmap["err-code"], respmap["err-msg"])
+	}
+
+	return respmap["data"], nil
+}
+
+// 获取交易对的逐仓杠杆账户id
+func (hbpro *HuoBiPro) getMarginAccountId(pair CurrencyPair) (string, error) {
+	symbol := strings.ToLower(ExchangeSymbol(HUOBI, pair, ""))
+
+	hbpro.mutex.Lock()
+	id, ok := hbpro.marginAccountIds[symbol]
+	hbpro.mutex.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	data, err := hbpro.doGet("/v1/account/accounts", url.Values{})
+	if err != nil {
+		return "", err
+	}
+
+	dataArr, _ := data.([]interface{})
+	hbpro.mutex.Lock()
+	defer hbpro.mutex.Unlock()
+	if hbpro.marginAccountIds == nil {
+		hbpro.marginAccountIds = make(map[string]string)
+	}
+	for _, v := range dataArr {
+		obj := v.(map[string]interface{})
+		if ToString(obj["type"]) == HB_MARGIN_ACCOUNT {
+			hbpro.marginAccountIds[ToString(obj["subtype"])] = fmt.Sprintf("%.0f", ToFloat64(obj["id"]))
+		}
+	}
+
+	id, ok = hbpro.marginAccountIds[symbol]
+	if !ok {
+		return "", fmt.Errorf("margin account not found, pair:%v", pair)
+	}
+	return id, nil
+}
+
+func (hbpro *HuoBiPro) GetMarginAccount(pair CurrencyPair) (*MarginAccount, error) {
+	params := url.Values{}
+	params.Set("symbol", strings.ToLower(ExchangeSymbol(HUOBI, pair, "")))
+	data, err := hbpro.doGet("/v1/margin/accounts/balance", params)
+	if err != nil {
+		return nil, err
+	}
+
+	dataArr, ok := data.([]interface{})
+	if !ok || len(dataArr) == 0 {
+		return nil, fmt.Errorf("margin account not found, pair:%v", pair)
+	}
+	datamap := dataArr[0].(map[string]interface{})
+
+	acc := &MarginAccount{
+		Account: Account{
+			Exchange:    hbpro.GetExchangeName(),
+			SubAccounts: make(map[Currency]SubAccount, 2),
+		},
+		Market:           pair,
+		Symbol:           pair.ToLowerSymbol("/"),
+		RiskRate:         ToFloat64(datamap["risk-rate"]),
+		LiquidationPrice: ToFloat64(datamap["fl-price"]),
+	}
+
+	list, _ := datamap["list"].([]interface{})
+	for _, v := range list {
+		balancemap := v.(map[string]interface{})
+		currency := CanonicalCurrency(HUOBI, ToString(balancemap["currency"]))
+		// 借币和利息余额为负数
+		balance := math.Abs(ToFloat64(balancemap["balance"]))
+		sub := acc.SubAccounts[currency]
+		sub.Currency = currency
+		switch ToString(balancemap["type"]) {
+		case "trade":
+			sub.Amount = balance
+		case "frozen":
+			sub.FrozenAmount = balance
+		case "loan", "interest":
+			sub.LoanAmount += balance
+		}
+		acc.SubAccounts[currency] = sub
+	}
+
+	ticker, err := hbpro.GetTicker(pair)
+	if err != nil {
+		return nil, err
+	}
+	acc.Valuate(ticker.Last)
+
+	return acc, nil
+}
+
+func (hbpro *HuoBiPro) GetMaxBorrowable(pair CurrencyPair, currency Currency) (float64, error) {
+	params := url.Values{}
+	params.Set("symbols", strings.ToLower(ExchangeSymbol(HUOBI, pair, "")))
+	data, err := hbpro.doGet("/v1/margin/loan-info", params)
+	if err != nil {
+		return 0, err
+	}
+
+	dataArr, _ := data.([]interface{})
+	for _, v := range dataArr {
+		currencies, _ := v.(map[string]interface{})["currencies"].([]interface{})
+		for _, c := range currencies {
+			obj := c.(map[string]interface{})
+			if CanonicalCurrency(HUOBI, ToString(obj["currency"])).Equal(currency) {
+				return ToFloat64(obj["loanable-amt"]), nil
+			}
+		}
+	}
+
+	return 0, ErrorAssetNotFound
+}
+
+func (hbpro *HuoBiPro) Borrow(pair CurrencyPair, currency Currency, amount string) (string, error) {
+	data, err := hbpro.doPost("/v1/margin/orders", map[string]interface{}{
+		"symbol":   strings.ToLower(ExchangeSymbol(HUOBI, pair, "")),
+		"currency": strings.ToLower(ExchangeCurrency(HUOBI, currency).Symbol()),
+		"amount":   amount,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%.0f", ToFloat64(data)), nil
+}
+
+// 火币按借币记录还币，loanId为空时从最早的借币记录开始归还
+func (hbpro *HuoBiPro) Repay(pair CurrencyPair, currency Currency, amount, loanId string) error {
+	if len(loanId) > 0 {
+		_, err := hbpro.doPost(fmt.Sprintf("/v1/margin/orders/%s/repay", loanId), map[string]interface{}{
+			"amount": amount,
+		})
+		return err
+	}
+
+	records, err := hbpro.GetLoanRecords(pair, currency)
+	if err != nil {
+		return err
+	}
+
+	left := ToFloat64(amount)
+	for i := len(records) - 1; i >= 0 && left > 0; i-- {
+		if records[i].Status != LOAN_ACTIVE {
+			continue
+		}
+
+		repay := math.Min(left, records[i].Balance+records[i].InterestBalance)
+		err = hbpro.Repay(pair, currency, FloatToString(repay, 8), records[i].LoanId)
+		if err != nil {
+			return err
+		}
+		left -= repay
+	}
+
+	return nil
+}
+
+func (hbpro *HuoBiPro) GetLoanRecords(pair CurrencyPair, currency Currency) ([]LoanRecord, error) {
+	params := url.Values{}
+	params.Set("symbol", strings.ToLower(ExchangeSymbol(HUOBI, pair, "")))
+	params.Set("currency", strings.ToLower(ExchangeCurrency(HUOBI, currency).Symbol()))
+	data, err := hbpro.doGet("/v1/margin/loan-orders", params)
+	if err != nil {
+		return nil, err
+	}
+
+	dataArr, _ := data.([]interface{})
+	records := make([]LoanRecord, 0, len(dataArr))
+	for _, v := range dataArr {
+		obj := v.(map[string]interface{})
+		record := LoanRecord{
+			LoanId:          fmt.Sprintf("%.0f", ToFloat64(obj["id"])),
+			Market:          pair,
+			Symbol:          pair.ToLowerSymbol("/"),
+			Currency:        currency,
+			Amount:          ToFloat64(obj["loan-amount"]),
+			Balance:         ToFloat64(obj["loan-balance"]),
+			InterestRate:    ToFloat64(obj["interest-rate"]),
+			Interest:        ToFloat64(obj["interest-amount"]),
+			InterestBalance: ToFloat64(obj["interest-balance"]),
+			TS:              ToInt64(obj["created-at"]),
+		}
+		switch ToString(obj["state"]) {
+		case "created", "accrual":
+			record.Status = LOAN_ACTIVE
+		case "cleared":
+			record.Status = LOAN_CLEARED
+		default:
+			record.Status = LOAN_FAILED
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// 使用逐仓杠杆账户下单
+func (hbpro *HuoBiPro) placeMarginOrder(amount, price string, pair CurrencyPair, orderType string, side TradeSide) (*Order, error) {
+	accountId, err := hbpro.getMarginAccountId(pair)
+	if err != nil {
+		return nil, err
+	}
+
+	orderId, err := hbpro.submitOrder(accountId, "margin-api", amount, price, pair, orderType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Order{
+		Market:  pair,
+		Symbol:  pair.ToLowerSymbol("/"),
+		OrderID: orderId,
+		Amount:  ToFloat64(amount),
+		Price:   ToFloat64(price),
+		Side:    side,
+	}, nil
+}
+
+func (hbpro *HuoBiPro) MarginLimitBuy(pair CurrencyPair, price, amount string) (*Order, error) {
+	return hbpro.placeMarginOrder(amount, price, pair, "buy-limit", BUY)
+}
+
+func (hbpro *HuoBiPro) MarginLimitSell(pair CurrencyPair, price, amount string) (*Order, error) {
+	return hbpro.placeMarginOrder(amount, price, pair, "sell-limit", SELL)
+}
+
+func (hbpro *HuoBiPro) MarginMarketBuy(pair CurrencyPair, amount string) (*Order, error) {
+	return hbpro.placeMarginOrder(amount, "", pair, "buy-market", BUY_MARKET)
+}
+
+func (hbpro *HuoBiPro) MarginMarketSell(pair CurrencyPair, amount string) (*Order, error) {
+	return hbpro.placeMarginOrder(amount, "", pair, "sell-market", SELL_MARKET)
+}
+
+// 杠杆订单与现货订单共用撤单和查询接口
+func (hbpro *HuoBiPro) CancelMarginOrder(orderId string, pair CurrencyPair) (bool, error) {
+	return hbpro.Cancel(orderId, pair)
+}
+
+func (hbpro *HuoBiPro) GetMarginOrder(orderId string, pair CurrencyPair) (*Order, error) {
+	return hbpro.GetOrder(orderId, pair)
+}
@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -48,6 +49,16 @@ type HuoBiPro struct {
 	accessKey  string
 	secretKey  string
 	//ECDSAPrivateKey string
+
+	// 逐仓杠杆账户id，每个交易对一个
+	mutex            sync.Mutex
+	marginAccountIds map[string]string
+
+	// GetAccount读取的账户类型
+	accountType AccountType
+
+	// 交易对名称到交易对的索引
+	symbols *SymbolIndex
 }
 
 type HuoBiProSymbol struct {
@@ -66,6 +77,7 @@ func NewHuoBiPro(client *http.Client, apikey, secretkey, accountId string) *HuoB
 	hbpro.accessKey = apikey
 	hbpro.secretKey = secretkey
 	hbpro.accountId = accountId
+	hbpro.symbols = NewSymbolIndex(HUOBI, hbpro.GetAllCurrencyPair)
 	return hbpro
 }
 
@@ -100,91 +112,144 @@ func (hbpro *HuoBiPro) GetURL() string {
 	return hbpro.baseUrl
 }
 
+// 火币的深度固定返回150档，支持step0到step5的聚合
+var spotCapabilities = &Capabilities{
+	Exchange:           HUOBI,
+	UnsupportedMethods: []string{"GetUserTrades"},
+	KlinePeriods:       KlinePeriodsOf(_INERNAL_KLINE_PERIOD_CONVERTER),
+	MaxKlineSize:       2000,
+	MaxTradeSize:       2000,
+	DepthSteps:         []int{1, 2, 3, 4, 5},
+	OrderSides:         AllOrderSides,
+	WebsocketStreams:   AllStreams,
+}
+
+func (hbpro *HuoBiPro) Capabilities() *Capabilities {
+	return spotCapabilities.Clone()
+}
+
+// 火币的手续费率查询一次最多支持10个币种对
+const HB_FEE_BATCH_SIZE = 10
+
+func (hbpro *HuoBiPro) GetTradeFee(pair CurrencyPair) (*TradeFee, error) {
+	tfm, err := hbpro.getTradeFee(map[string]string{ExchangeLowerSymbol(HUOBI, pair, ""): pair.ToSymbol("/")})
+	if err != nil {
+		return nil, err
+	}
+
+	return TradeFeeOf(tfm, pair)
+}
+
+func (hbpro *HuoBiPro) GetTradeFeeMap() (map[string]TradeFee, error) {
+	ssm, err := hbpro.getSymbolSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	return hbpro.getTradeFeeMap(ssm)
+}
+
+// 按币对信息分批查询手续费率
+func (hbpro *HuoBiPro) getTradeFeeMap(ssm map[string]SymbolSetting) (map[string]TradeFee, error) {
+	tfm := make(map[string]TradeFee, len(ssm))
+	batch := make(map[string]string)
+	for k := range ssm {
+		batch[ExchangeLowerSymbol(HUOBI, NewCurrencyPairFromString(k), "")] = k
+		if len(batch) < HB_FEE_BATCH_SIZE {
+			continue
+		}
+
+		fees, err := hbpro.getTradeFee(batch)
+		if err != nil {
+			return nil, err
+		}
+		for k1, v1 := range fees {
+			tfm[k1] = v1
+		}
+		batch = make(map[string]string)
+	}
+
+	if len(batch) > 0 {
+		fees, err := hbpro.getTradeFee(batch)
+		if err != nil {
+			return nil, err
+		}
+		for k1, v1 := range fees {
+			tfm[k1] = v1
+		}
+	}
+
+	return tfm, nil
+}
+
 /*
+symbols的key为火币的币对名称，value为BASE/QUOTE格式的币对名称。
 "symbol": "btcusdt",
 "makerFeeRate":"0.002",
 "takerFeeRate":"0.002",
 "actualMakerRate": "0.002",
 "actualTakerRate":"0.002
 */
-type TradeFee struct {
-	Symbol          string  `json:"symbol"`
-	ActualMakerRate float64 `json:"actualMakerRate,string"` // 挂单手续费
-	ActualTakerRate float64 `json:"actualTakerRate,string"` // 吃单手续费
-}
+func (hbpro *HuoBiPro) getTradeFee(symbols map[string]string) (map[string]TradeFee, error) {
+	names := make([]string, 0, len(symbols))
+	for k := range symbols {
+		names = append(names, k)
+	}
 
-func (hbpro *HuoBiPro) GetTradeFee(symbols string) (tf *TradeFee, err error) {
 	path := "/v2/reference/transact-fee-rate"
 	params := &url.Values{}
-	params.Set("symbols", symbols)
+	params.Set("symbols", strings.Join(names, ","))
 	hbpro.buildPostForm("GET", path, params)
 	respmap, err := HttpGet(hbpro.httpClient, hbpro.baseUrl+path+"?"+params.Encode())
 	if err != nil {
 		return nil, err
 	}
 
-	// a failure example:
-	// {"ts":1579423405172,"status":"error","err-code":"invalid-parameter","err-msg":"invalid symbol"}
-	if respmap["code"].(float64) != 200 {
-		return nil, errors.New(respmap["message"].(string))
+	if ToInt(respmap["code"]) != 200 {
+		return nil, fmt.Errorf("code:%v, msg:%v", respmap["code"], respmap["message"])
 	}
 
-	// a success example:
-	// {"status":"ok","ch":"market.btcusdt.detail.merged","ts":1579423189207,"tick":{"amount":36731.43723323591,"open":8843.22,"close":9078.0,"high":9198.96,"id":208132871759,"count":295788,"low":8843.22,"version":208132871759,"ask":[9078.0,4.109982687155761],"vol":3.310314436109146E8,"bid":[9077.81,1.06]}}
 	dataArr, ok := respmap["data"].([]interface{})
 	if !ok {
 		return nil, errors.New("data assert error")
 	}
 
-	tf = &TradeFee{}
+	tfm := make(map[string]TradeFee)
 	for _, v := range dataArr {
 		obj := v.(map[string]interface{})
-		tf.ActualMakerRate = ToFloat64(obj["actualMakerRate"])
-		tf.ActualTakerRate = ToFloat64(obj["actualTakerRate"])
-		break
+		symbol, ok := symbols[ToString(obj["symbol"])]
+		if !ok {
+			continue
+		}
+		tfm[symbol] = TradeFee{
+			Symbol:    symbol,
+			MakerRate: ToFloat64(obj["actualMakerRate"]),
+			TakerRate: ToFloat64(obj["actualTakerRate"]),
+		}
 	}
 
-	return tf, nil
+	return tfm, nil
 }
 
-func (hbpro *HuoBiPro) GetTradeFeeMap() (tfmap map[string]TradeFee, err error) {
-	path := "/v2/reference/transact-fee-rate"
-	params := &url.Values{}
-	//params.Set("symbols", symbols) // 火币目前只支持一次最多查询10个交易对
-	hbpro.buildPostForm("GET", path, params)
-	respmap, err := HttpGet(hbpro.httpClient, hbpro.baseUrl+path+"?"+params.Encode())
+// MakerFee和TakerFee为账户的实际费率，未设置apikey或查询费率失败时为公布的费率
+func (hbpro *HuoBiPro) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
+	ssm, err := hbpro.getSymbolSettings()
 	if err != nil {
 		return nil, err
 	}
 
-	// a failure example:
-	// {"ts":1579423405172,"status":"error","err-code":"invalid-parameter","err-msg":"invalid symbol"}
-	if respmap["code"].(float64) != 200 {
-		return nil, errors.New(respmap["message"].(string))
-	}
-
-	// a success example:
-	// {"status":"ok","ch":"market.btcusdt.detail.merged","ts":1579423189207,"tick":{"amount":36731.43723323591,"open":8843.22,"close":9078.0,"high":9198.96,"id":208132871759,"count":295788,"low":8843.22,"version":208132871759,"ask":[9078.0,4.109982687155761],"vol":3.310314436109146E8,"bid":[9077.81,1.06]}}
-	dataArr, ok := respmap["data"].([]interface{})
-	if !ok {
-		return nil, errors.New("data assert error")
-	}
-
-	tfmap = make(map[string]TradeFee)
-	for _, v := range dataArr {
-		obj := v.(map[string]interface{})
-		symbol := ToString(obj["symbol"])
-		tfmap[symbol] = TradeFee{
-			Symbol:          symbol,
-			ActualMakerRate: ToFloat64(obj["actualMakerRate"]),
-			ActualTakerRate: ToFloat64(obj["actualTakerRate"]),
+	if len(hbpro.accessKey) > 0 {
+		tfm, err := hbpro.getTradeFeeMap(ssm)
+		if err == nil {
+			ApplyTradeFee(ssm, tfm)
 		}
 	}
 
-	return tfmap, nil
+	return ssm, nil
 }
 
-func (hbpro *HuoBiPro) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
+// 获取币对信息，账户费率由GetAllCurrencyPair填入
+func (hbpro *HuoBiPro) getSymbolSettings() (map[string]SymbolSetting, error) {
 	url := hbpro.baseUrl + "/v1/common/symbols"
 	respmap, err := HttpGet(hbpro.httpClient, url)
 	if err != nil {
@@ -204,38 +269,36 @@ func (hbpro *HuoBiPro) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
 		return nil, errors.New("data assert error")
 	}
 
-	// TODO FIXME: 火币的费率查询，目前最多支持10个币种对。这里用了一个tricky，查一个币种费率，所有币种都用一个费率。
-	tf, err := hbpro.GetTradeFee("btcusdt")
-	if err != nil {
-		tf = &TradeFee{
-			ActualMakerRate: 0.002,
-			ActualTakerRate: 0.002,
-		}
-	}
-
 	ssm := make(map[string]SymbolSetting)
 	for _, v := range dataArr {
 		obj := v.(map[string]interface{})
 		base := strings.ToUpper(ToString(obj["base-currency"]))
 		quote := strings.ToUpper(ToString(obj["quote-currency"]))
 		symbol := base + "/" + quote
+		pricePrecision := ToInt(obj["price-precision"])
+		amountPrecision := ToInt(obj["amount-precision"])
 		ssm[symbol] = SymbolSetting{
-			Symbol:      symbol,
-			Base:        base,
-			Quote:       quote,
-			MinSize:     math.Pow10(-ToInt(obj["amount-precision"])),
-			MinPrice:    math.Pow10(-ToInt(obj["price-precision"])),
-			MinNotional: ToFloat64(obj["min-order-value"]),
-			MakerFee:    tf.ActualMakerRate,
-			TakerFee:    tf.ActualTakerRate,
+			Symbol:          symbol,
+			Base:            base,
+			Quote:           quote,
+			MinSize:         math.Pow10(-amountPrecision),
+			MinPrice:        math.Pow10(-pricePrecision),
+			MinNotional:     ToFloat64(obj["min-order-value"]),
+			MakerFee:        0.002,
+			TakerFee:        0.002,
+			TickSize:        math.Pow10(-pricePrecision),
+			StepSize:        math.Pow10(-amountPrecision),
+			PricePrecision:  pricePrecision,
+			AmountPrecision: amountPrecision,
+			MaxSize:         ToFloat64(obj["max-order-amt"]),
 		}
 	}
 
-	return ssm, nil
+	return CanonicalSymbolSettings(HUOBI, ssm), nil
 }
 
 func (hbpro *HuoBiPro) GetCurrencyStatus(currency Currency) (CurrencyStatus, error) {
-	url := hbpro.baseUrl + "/v2/reference/currencies?currency=" + currency.LowerSymbol()
+	url := hbpro.baseUrl + "/v2/reference/currencies?currency=" + ExchangeCurrency(HUOBI, currency).LowerSymbol()
 	respmap, err := HttpGet(hbpro.httpClient, url)
 	if err != nil {
 		return CurrencyStatus{}, err
@@ -253,23 +316,12 @@ func (hbpro *HuoBiPro) GetCurrencyStatus(currency Currency) (CurrencyStatus, err
 	for _, v := range dataArr {
 		obj := v.(map[string]interface{})
 		symbol := ToString(obj["currency"])
-		if strings.ToUpper(symbol) == currency.Symbol() {
-			chains, ok := obj["chains"].([]interface{})
-			if !ok {
+		if strings.ToUpper(symbol) == ExchangeCurrency(HUOBI, currency).Symbol() {
+			if _, ok := obj["chains"].([]interface{}); !ok {
 				return CurrencyStatus{}, errors.New("chains assert error")
 			}
 
-			cs := CurrencyStatus{}
-			for _, c := range chains {
-				info := c.(map[string]interface{})
-				ds := ToString(info["depositStatus"])
-				ws := ToString(info["withdrawStatus"])
-
-				cs.Deposit = cs.Deposit || ds == "allowed"
-				cs.Withdraw = cs.Withdraw || ws == "allowed"
-			}
-
-			return cs, nil
+			return parseCurrencyStatus(obj), nil
 		}
 	}
 
@@ -295,29 +347,56 @@ func (hbpro *HuoBiPro) GetAllCurrencyStatus() (all map[string]CurrencyStatus, er
 	all = make(map[string]CurrencyStatus)
 	for _, v := range dataArr {
 		obj := v.(map[string]interface{})
-		chains, ok := obj["chains"].([]interface{})
-		if !ok {
+		if _, ok := obj["chains"].([]interface{}); !ok {
 			continue
 		}
 
-		cs := CurrencyStatus{}
-		for _, c := range chains {
-			info := c.(map[string]interface{})
-			ds := ToString(info["depositStatus"])
-			ws := ToString(info["withdrawStatus"])
+		all[strings.ToUpper(ToString(obj["currency"]))] = parseCurrencyStatus(obj)
+	}
 
-			cs.Deposit = cs.Deposit || ds == "allowed"
-			cs.Withdraw = cs.Withdraw || ws == "allowed"
-		}
+	return CanonicalCurrencyStatus(HUOBI, all), nil
+}
 
-		all[strings.ToUpper(ToString(obj["currency"]))] = cs
-	}
+// 解析/v2/reference/currencies返回的币种信息，链名称与币种相同的为默认链
+func parseCurrencyStatus(obj map[string]interface{}) CurrencyStatus {
+	currency := ToString(obj["currency"])
+	chains, _ := obj["chains"].([]interface{})
+
+	cs := CurrencyStatus{}
+	for _, c := range chains {
+		info := c.(map[string]interface{})
+		ds := ToString(info["depositStatus"])
+		ws := ToString(info["withdrawStatus"])
+
+		// 固定手续费的币种返回transactFeeWithdraw，其他返回minTransactFeeWithdraw
+		fee := ToFloat64(info["transactFeeWithdraw"])
+		if fee == 0 {
+			fee = ToFloat64(info["minTransactFeeWithdraw"])
+		}
 
-	return all, nil
+		chain := ToString(info["chain"])
+		cs.Chains = append(cs.Chains, ChainStatus{
+			Chain:             chain,
+			IsDefault:         strings.EqualFold(chain, currency),
+			Deposit:           ds == "allowed",
+			Withdraw:          ws == "allowed",
+			WithdrawFee:       fee,
+			MinWithdraw:       ToFloat64(info["minWithdrawAmt"]),
+			MaxWithdraw:       ToFloat64(info["maxWithdrawAmt"]),
+			MinDeposit:        ToFloat64(info["minDepositAmt"]),
+			Confirmations:     ToInt(info["numOfConfirmations"]),
+			WithdrawPrecision: ToInt(info["withdrawPrecision"]),
+		})
+
+		cs.Deposit = cs.Deposit || ds == "allowed"
+		cs.Withdraw = cs.Withdraw || ws == "allowed"
+	}
+
+	return cs
 }
 
 func (hbpro *HuoBiPro) GetTicker(pair CurrencyPair) (*Ticker, error) {
-	url := hbpro.baseUrl + "/market/detail/merged?symbol=" + pair.ToLowerSymbol("")
+	url := hbpro.baseUrl + "/market/detail/merged?symbol=" + ExchangeLowerSymbol(HUOBI, pair, "")
 	respmap, err := HttpGet(hbpro.httpClient, url)
 	if err != nil {
 		return nil, err
@@ -360,6 +439,11 @@ func (hbpro *HuoBiPro) GetTicker(pair CurrencyPair) (*Ticker, error) {
 }
 
 func (hbpro *HuoBiPro) GetAllTicker() ([]Ticker, error) {
+	// 交易对索引获取失败时无法转换交易对名称
+	if err := hbpro.symbols.Load(); err != nil {
+		return nil, err
+	}
+
 	url := hbpro.baseUrl + "/market/tickers"
 	respmap, err := HttpGet(hbpro.httpClient, url)
 	if err != nil {
@@ -388,13 +472,13 @@ func (hbpro *HuoBiPro) GetAllTicker() ([]Ticker, error) {
 			continue
 		}
 
-		base, quote := getSymbol(ToString(tickmap["symbol"]))
-		if len(base) == 0 || len(quote) == 0 {
+		pair, err := hbpro.symbols.Pair(ToString(tickmap["symbol"]))
+		if err != nil {
 			continue
 		}
 		ticker := Ticker{}
-		ticker.Symbol = base + "/" + quote
-		ticker.Market = NewCurrencyPairFromString(ticker.Symbol)
+		ticker.Symbol = pair.ToLowerSymbol("/")
+		ticker.Market = pair
 		ticker.Open = ToFloat64(tickmap["open"])
 		ticker.Last = ToFloat64(tickmap["close"])
 		ticker.High = ToFloat64(tickmap["high"])
@@ -409,19 +493,6 @@ func (hbpro *HuoBiPro) GetAllTicker() ([]Ticker, error) {
 	return tickers, nil
 }
 
-func getSymbol(market string) (base, quote string) {
-	fiatCoin := []string{"usdt", "husd", "btc", "eth", "ht", "trx"}
-
-	for _, v := range fiatCoin {
-		if strings.HasSuffix(market, v) {
-			p := strings.LastIndex(market, v)
-			return market[0:p], v
-		}
-	}
-
-	return "", ""
-}
-
 /*
 取值	说明
 step0	无聚合
@@ -432,7 +503,7 @@ step4	聚合度为报价精度*10000
 step5	聚合度为报价精度*100000
 */
 func (hbpro *HuoBiPro) GetDepth(pair CurrencyPair, size int, step int) (*Depth, error) {
-	url := hbpro.baseUrl + "/market/depth?symbol=" + strings.ToLower(pair.ToSymbol(""))
+	url := hbpro.baseUrl + "/market/depth?symbol=" + strings.ToLower(ExchangeSymbol(HUOBI, pair, ""))
 	//if size != 0 {
 	//	url += fmt.Sprintf("&depth=%v", size)
 	//}
@@ -476,7 +547,7 @@ func (hbpro *HuoBiPro) GetTrades(pair CurrencyPair, size int) ([]Trade, error) {
 		}
 	)
 
-	url := fmt.Sprintf(hbpro.baseUrl+"/market/history/trade?size=%v&symbol=%v", size, pair.ToLowerSymbol(""))
+	url := fmt.Sprintf(hbpro.baseUrl+"/market/history/trade?size=%v&symbol=%v", size, ExchangeLowerSymbol(HUOBI, pair, ""))
 	err := HttpGet4(hbpro.httpClient, url, map[string]string{}, &ret)
 	if err != nil {
 		return nil, err
@@ -507,10 +578,13 @@ func (hbpro *HuoBiPro) GetTrades(pair CurrencyPair, size int) ([]Trade, error) {
 func (hbpro *HuoBiPro) GetKlineRecords(pair CurrencyPair, period KlinePeriod, size, since int) ([]Kline, error) {
 	periodS, isOk := _INERNAL_KLINE_PERIOD_CONVERTER[period]
 	if isOk != true {
-		return nil, fmt.Errorf("unsupported %v KlinePeriod:%v", hbpro.GetExchangeName(), period)
+		// 交易所不支持的周期，用较小周期的K线合成
+		return GetKlinesWithResample(spotCapabilities, func(p KlinePeriod, n, t int) ([]Kline, error) {
+			return hbpro.GetKlineRecords(pair, p, n, t)
+		}, period, size, since)
 	}
 	url := hbpro.baseUrl + "/market/history/kline?period=%s&size=%d&symbol=%s"
-	symbol := pair.ToLowerSymbol("")
+	symbol := ExchangeLowerSymbol(HUOBI, pair, "")
 	ret, err := HttpGet(hbpro.httpClient, fmt.Sprintf(url, periodS, size, symbol))
 	if err != nil {
 		return nil, err
@@ -537,6 +611,13 @@ func (hbpro *HuoBiPro) GetKlineRecords(pair CurrencyPair, period KlinePeriod, si
 	return klines, nil
 }
 
+// 交易所只能查询最近的K线，范围早于可查询的K线时返回ErrorKlineRangeTooOld
+func (hbpro *HuoBiPro) GetKlineRange(pair CurrencyPair, period KlinePeriod, start, end time.Time) ([]Kline, []KlineGap, error) {
+	return GetLatestKlineRange(spotCapabilities, func(p KlinePeriod, n, t int) ([]Kline, error) {
+		return hbpro.GetKlineRecords(pair, p, n, t)
+	}, period, start, end)
+}
+
 func (hbpro *HuoBiPro) LimitBuy(pair CurrencyPair, price, amount string) (*Order, error) {
 	orderId, err := hbpro.placeOrder(amount, price, pair, "buy-limit")
 	if err != nil {
@@ -681,6 +762,8 @@ func (hbpro *HuoBiPro) GetOrderDeal(orderId string, pair CurrencyPair) ([]OrderD
 			FilledAmount: ToFloat64(obj["filled-amount"]),
 			Market:       pair,
 			Symbol:       pair.ToLowerSymbol("/"),
+			Fee:          ToFloat64(obj["filled-fees"]),
+			FeeCurrency:  CanonicalCurrency(HUOBI, ToString(obj["fee-currency"])),
 		}
 
 		deal.FilledCashAmount = deal.Price * deal.FilledAmount
@@ -705,11 +788,18 @@ func (hbpro *HuoBiPro) GetUserTrades(pair CurrencyPair) ([]Trade, error) {
 	return nil, ErrorUnsupported
 }
 
+// 读取SetAccountType设置的账户，默认为现货账户
 func (hbpro *HuoBiPro) GetAccount() (*Account, error) {
-	hbpro.updateAccountID()
-	path := fmt.Sprintf("/v1/account/accounts/%s/balance", hbpro.accountId)
+	if hbpro.accountType == 0 {
+		return hbpro.GetAccountByType(ACCOUNT_SPOT)
+	}
+	return hbpro.GetAccountByType(hbpro.accountType)
+}
+
+func (hbpro *HuoBiPro) getAccount(accountId string) (*Account, error) {
+	path := fmt.Sprintf("/v1/account/accounts/%s/balance", accountId)
 	params := &url.Values{}
-	params.Set("accountId-id", hbpro.accountId)
+	params.Set("accountId-id", accountId)
 	hbpro.buildPostForm("GET", path, params)
 
 	urlStr := hbpro.baseUrl + path + "?" + params.Encode()
@@ -739,7 +829,7 @@ func (hbpro *HuoBiPro) GetAccount() (*Account, error) {
 	for _, v := range list {
 		balancemap := v.(map[string]interface{})
 		currencySymbol := balancemap["currency"].(string)
-		currency := NewCurrency(currencySymbol)
+		currency := CanonicalCurrency(HUOBI, currencySymbol)
 		typeStr := balancemap["type"].(string)
 		balance := ToFloat64(balancemap["balance"])
 		if subAccMap[currency] == nil {
@@ -763,12 +853,20 @@ func (hbpro *HuoBiPro) GetAccount() (*Account, error) {
 
 func (hbpro *HuoBiPro) placeOrder(amount, price string, pair CurrencyPair, orderType string) (string, error) {
 	hbpro.updateAccountID()
+	return hbpro.submitOrder(hbpro.accountId, "", amount, price, pair, orderType)
+}
+
+// 在指定账户下单，source为空时为现货订单
+func (hbpro *HuoBiPro) submitOrder(accountId, source, amount, price string, pair CurrencyPair, orderType string) (string, error) {
 	path := "/v1/order/orders/place"
 	params := url.Values{}
-	params.Set("account-id", hbpro.accountId)
+	params.Set("account-id", accountId)
 	params.Set("amount", amount)
-	params.Set("symbol", strings.ToLower(pair.ToSymbol("")))
+	params.Set("symbol", strings.ToLower(ExchangeSymbol(HUOBI, pair, "")))
 	params.Set("type", orderType)
+	if len(source) > 0 {
+		params.Set("source", source)
+	}
 
 	switch orderType {
 	case "buy-limit", "sell-limit":
@@ -856,7 +954,7 @@ type queryOrdersParams struct {
 func (hbpro *HuoBiPro) getOrders(queryparams queryOrdersParams) ([]Order, error) {
 	path := "/v1/order/orders"
 	params := url.Values{}
-	params.Set("symbol", strings.ToLower(queryparams.pair.ToSymbol("")))
+	params.Set("symbol", strings.ToLower(ExchangeSymbol(HUOBI, queryparams.pair, "")))
 	params.Set("states", queryparams.states)
 
 	if queryparams.direct != "" {
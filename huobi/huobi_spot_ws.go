@@ -113,13 +113,23 @@ func NewSpotWebsocket(wsURL, proxyURL string) (sw SpotWebsocket, err error) {
 	return ws, err
 }
 
+/*
+创建不连接网络的火币websocket，只解析传入OnMessage的原始帧，
+用作SpotWsReplay的decoder回放录制的火币行情。
+*/
+func NewSpotWsDecoder() SpotWebsocket {
+	ws := &HuobiSpotWs{}
+	ws.SpotWsBase.SpotWebsocket = ws
+	return ws
+}
+
 func (ws *HuobiSpotWs) GetExchangeName() string {
 	return HUOBI
 }
 
 // 格式化流名称
 func (ws *HuobiSpotWs) FormatTopicName(topic string, pair CurrencyPair) string {
-	symbol := pair.ToLowerSymbol("")
+	symbol := ExchangeLowerSymbol(HUOBI, pair, "")
 	switch topic {
 	case STREAM_TICKER:
 		return fmt.Sprintf("market.%s.detail", symbol)
@@ -135,7 +145,7 @@ func (ws *HuobiSpotWs) FormatTopicName(topic string, pair CurrencyPair) string {
 
 // 格式化流订阅消息
 func (ws *HuobiSpotWs) FormatTopicSubData(topic string, pair CurrencyPair) []byte {
-	symbol := pair.ToLowerSymbol("")
+	symbol := ExchangeLowerSymbol(HUOBI, pair, "")
 	stream := ws.FormatTopicName(topic, pair)
 	switch topic {
 	case STREAM_TICKER:
@@ -151,7 +161,7 @@ func (ws *HuobiSpotWs) FormatTopicSubData(topic string, pair CurrencyPair) []byt
 
 // 格式化流取消订阅消息
 func (ws *HuobiSpotWs) FormatTopicUnsubData(topic string, pair CurrencyPair) []byte {
-	symbol := pair.ToLowerSymbol("")
+	symbol := ExchangeLowerSymbol(HUOBI, pair, "")
 	stream := ws.FormatTopicName(topic, pair)
 	switch topic {
 	case STREAM_TICKER:
@@ -197,7 +207,10 @@ func (ws *HuobiSpotWs) OnMessage(data []byte) (err error) {
 			Pong int64 `json:"pong"`
 		}{ping.Ping}
 
-		ws.Conn.SendMessage(ws.Pack(pong))
+		// 回放时没有连接，不需要回复
+		if ws.Conn != nil {
+			ws.Conn.SendMessage(ws.Pack(pong))
+		}
 		return nil
 	}
 
@@ -0,0 +1,64 @@
+package huobi
+
+import (
+	"bytes"
+	"compress/gzip"
+	. "github.com/betterjun/exapi"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func gzipFrame(s string) []byte {
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+	w.Write([]byte(s))
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestSpotWsReplayRaw(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rw := NewReplayWriter(buf)
+	assert.Nil(t, rw.WriteRaw(gzipFrame(`{"ping":1}`)))
+	assert.Nil(t, rw.WriteRaw(gzipFrame(`{"ch":"market.btcusdt.detail","ts":1000,"tick":{"open":1,"close":2,"high":3,"low":0.5,"vol":10}}`)))
+	assert.Nil(t, rw.WriteRaw(gzipFrame(`{"ch":"market.btcusdt.trade.detail","ts":1000,"tick":{"data":[{"tradeId":7,"price":2,"amount":0.1,"direction":"buy","ts":1000}]}}`)))
+	assert.Nil(t, rw.WriteRaw(gzipFrame(`{"ch":"market.ethusdt.detail","ts":1000,"tick":{"close":100}}`)))
+
+	f, err := ioutil.TempFile("", "replay_*.jsonl")
+	assert.Nil(t, err)
+	defer os.Remove(f.Name())
+	f.Write(buf.Bytes())
+	f.Close()
+
+	decoder := NewSpotWsDecoder().(*HuobiSpotWs)
+	ws, err := NewSpotWsReplay([]string{f.Name()}, REPLAY_SPEED_FASTEST, decoder)
+	assert.Nil(t, err)
+	assert.Equal(t, HUOBI, ws.GetExchangeName())
+
+	// 回放没有连接，直接登记订阅的流
+	pair := NewCurrencyPairFromString("BTC/USDT")
+	var tickers []*Ticker
+	var trades []Trade
+	decoder.TopicMap.Store(decoder.FormatTopicName(STREAM_TICKER, pair), pair)
+	decoder.TopicMap.Store(decoder.FormatTopicName(STREAM_TRADE, pair), pair)
+	decoder.OnTicker = func(ticker *Ticker) error {
+		tickers = append(tickers, ticker)
+		return nil
+	}
+	decoder.OnTrade = func(t []Trade) error {
+		trades = append(trades, t...)
+		return nil
+	}
+
+	assert.Nil(t, ws.Play())
+	assert.Equal(t, 2, len(tickers))
+	assert.Equal(t, pair, tickers[0].Market)
+	assert.Equal(t, 2.0, tickers[0].Last)
+	// 未订阅的交易对解析不到币对
+	assert.Equal(t, CurrencyPair{}, tickers[1].Market)
+	assert.Equal(t, 1, len(trades))
+	assert.Equal(t, int64(7), trades[0].Tid)
+	assert.Equal(t, BUY, trades[0].Side)
+}
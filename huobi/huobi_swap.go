@@ -0,0 +1,463 @@
+package huobi
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/betterjun/exapi"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type HbdmSwap struct {
+	// 签名、订单解析与交割合约相同
+	hbdm *Hbdm
+
+	// 下单需要指定杠杆倍数，缓存每个合约当前的杠杆倍数
+	mutex      sync.Mutex
+	leverRates map[string]int
+}
+
+/**
+ * perpetual swap
+ */
+func NewHbdmSwap(client *http.Client, apikey, secretkey string) *HbdmSwap {
+	return &HbdmSwap{
+		hbdm:       NewHbdm(client, apikey, secretkey),
+		leverRates: make(map[string]int),
+	}
+}
+
+func NewSwapAPI(client *http.Client, apikey, secretkey string) SwapAPI {
+	return NewHbdmSwap(client, apikey, secretkey)
+}
+
+func (swap *HbdmSwap) GetExchangeName() string {
+	return HBDM
+}
+
+func (swap *HbdmSwap) SetURL(exurl string) {
+	swap.hbdm.SetURL(exurl)
+}
+
+func (swap *HbdmSwap) GetURL() string {
+	return swap.hbdm.GetURL()
+}
+
+// 获取合约代码，如BTC-USD
+func (swap *HbdmSwap) getContractCode(pair CurrencyPair) string {
+	return ExchangeSymbol(HUOBI, pair, "-")
+}
+
+// 公共接口请求，返回data字段
+func (swap *HbdmSwap) doGet(path string) (interface{}, error) {
+	respmap, err := HttpGet(swap.hbdm.hb.httpClient, swap.hbdm.hb.baseUrl+path)
+	if err != nil {
+		return nil, err
+	}
+
+	if ToString(respmap["status"]) != "ok" {
+		return nil, fmt.Errorf("code:%v, msg:%v", respmap["err_code"], respmap["err_msg"])
+	}
+
+	return respmap["data"], nil
+}
+
+func (swap *HbdmSwap) GetSwapContracts() ([]FutureContract, error) {
+	data, err := swap.doGet("/swap-api/v1/swap_contract_info")
+	if err != nil {
+		return nil, err
+	}
+
+	dataArr, ok := data.([]interface{})
+	if !ok {
+		return nil, errors.New("data assert error")
+	}
+
+	contracts := make([]FutureContract, 0, len(dataArr))
+	for _, v := range dataArr {
+		obj := v.(map[string]interface{})
+		contracts = append(contracts, FutureContract{
+			ContractId:   ToString(obj["contract_code"]),
+			ContractType: SWAP_CONTRACT,
+			Market:       NewCurrencyPair(CanonicalCurrency(HUOBI, ToString(obj["symbol"])), USD),
+			ContractVal:  ToFloat64(obj["contract_size"]),
+			TickSize:     ToFloat64(obj["price_tick"]),
+		})
+	}
+
+	return contracts, nil
+}
+
+func (swap *HbdmSwap) GetSwapTicker(pair CurrencyPair) (*Ticker, error) {
+	url := swap.hbdm.hb.baseUrl + "/swap-ex/market/detail/merged?contract_code=" + swap.getContractCode(pair)
+	respmap, err := HttpGet(swap.hbdm.hb.httpClient, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if ToString(respmap["status"]) != "ok" {
+		return nil, fmt.Errorf("code:%v, msg:%v", respmap["err-code"], respmap["err-msg"])
+	}
+
+	tickmap, ok := respmap["tick"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("tick assert error")
+	}
+
+	ticker := &Ticker{
+		Market: pair,
+		Symbol: pair.ToLowerSymbol("/"),
+		Open:   ToFloat64(tickmap["open"]),
+		Last:   ToFloat64(tickmap["close"]),
+		High:   ToFloat64(tickmap["high"]),
+		Low:    ToFloat64(tickmap["low"]),
+		Vol:    ToFloat64(tickmap["amount"]),
+		TS:     ToInt64(respmap["ts"]),
+	}
+	if bid, ok := tickmap["bid"].([]interface{}); ok && len(bid) > 0 {
+		ticker.Buy = ToFloat64(bid[0])
+	}
+	if ask, ok := tickmap["ask"].([]interface{}); ok && len(ask) > 0 {
+		ticker.Sell = ToFloat64(ask[0])
+	}
+
+	return ticker, nil
+}
+
+func (swap *HbdmSwap) GetSwapDepth(pair CurrencyPair, size int) (*Depth, error) {
+	url := swap.hbdm.hb.baseUrl + "/swap-ex/market/depth?type=step0&contract_code=" + swap.getContractCode(pair)
+	respmap, err := HttpGet(swap.hbdm.hb.httpClient, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if ToString(respmap["status"]) != "ok" {
+		return nil, fmt.Errorf("code:%v, msg:%v", respmap["err-code"], respmap["err-msg"])
+	}
+
+	tick, _ := respmap["tick"].(map[string]interface{})
+	dep := swap.hbdm.hb.parseDepthData(tick)
+	dep.ContractType = SWAP_CONTRACT
+	dep.Market = pair
+	dep.Symbol = pair.ToLowerSymbol("/")
+	dep.TS = ToInt64(respmap["ts"])
+
+	if size > 0 {
+		if len(dep.AskList) > size {
+			dep.AskList = dep.AskList[:size]
+		}
+		if len(dep.BidList) > size {
+			dep.BidList = dep.BidList[:size]
+		}
+	}
+
+	return dep, nil
+}
+
+func (swap *HbdmSwap) GetFundingRate(pair CurrencyPair) (*FundingRate, error) {
+	data, err := swap.doGet("/swap-api/v1/swap_funding_rate?contract_code=" + swap.getContractCode(pair))
+	if err != nil {
+		return nil, err
+	}
+
+	datamap, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("data assert error")
+	}
+
+	return &FundingRate{
+		Market:          pair,
+		Symbol:          pair.ToLowerSymbol("/"),
+		Rate:            ToFloat64(datamap["funding_rate"]),
+		EstimatedRate:   ToFloat64(datamap["estimated_rate"]),
+		FundingTime:     ToInt64(datamap["funding_time"]),
+		NextFundingTime: ToInt64(datamap["next_funding_time"]),
+	}, nil
+}
+
+func (swap *HbdmSwap) GetFundingRateHistory(pair CurrencyPair, size int) ([]FundingRate, error) {
+	data, err := swap.doGet(fmt.Sprintf("/swap-api/v1/swap_historical_funding_rate?contract_code=%s&page_size=%d",
+		swap.getContractCode(pair), size))
+	if err != nil {
+		return nil, err
+	}
+
+	datamap, _ := data.(map[string]interface{})
+	dataArr, _ := datamap["data"].([]interface{})
+
+	// 交易所返回按时间降序排列
+	rates := make([]FundingRate, 0, len(dataArr))
+	for i := len(dataArr) - 1; i >= 0; i-- {
+		obj := dataArr[i].(map[string]interface{})
+		rates = append(rates, FundingRate{
+			Market:      pair,
+			Symbol:      pair.ToLowerSymbol("/"),
+			Rate:        ToFloat64(obj["realized_rate"]),
+			FundingTime: ToInt64(obj["funding_time"]),
+		})
+	}
+
+	return rates, nil
+}
+
+func (swap *HbdmSwap) GetSwapPrice(pair CurrencyPair) (*SwapPrice, error) {
+	code := swap.getContractCode(pair)
+	data, err := swap.doGet("/swap-api/v1/swap_index?contract_code=" + code)
+	if err != nil {
+		return nil, err
+	}
+
+	dataArr, ok := data.([]interface{})
+	if !ok || len(dataArr) == 0 {
+		return nil, errors.New("index not found")
+	}
+	index := dataArr[0].(map[string]interface{})
+
+	// 标记价格取最新一根1分钟标记价格k线的收盘价
+	url := swap.hbdm.hb.baseUrl + "/index/market/history/swap_mark_price_kline?period=1min&size=1&contract_code=" + code
+	respmap, err := HttpGet(swap.hbdm.hb.httpClient, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if ToString(respmap["status"]) != "ok" {
+		return nil, fmt.Errorf("code:%v, msg:%v", respmap["err-code"], respmap["err-msg"])
+	}
+
+	klines, ok := respmap["data"].([]interface{})
+	if !ok || len(klines) == 0 {
+		return nil, errors.New("mark price not found")
+	}
+	kline := klines[len(klines)-1].(map[string]interface{})
+
+	return &SwapPrice{
+		Market:     pair,
+		Symbol:     pair.ToLowerSymbol("/"),
+		MarkPrice:  ToFloat64(kline["close"]),
+		IndexPrice: ToFloat64(index["index_price"]),
+		TS:         ToInt64(respmap["ts"]),
+	}, nil
+}
+
+// 获取合约当前的杠杆倍数，未缓存时从账户信息中查询
+func (swap *HbdmSwap) getLeverRate(code string) (int, error) {
+	swap.mutex.Lock()
+	leverRate, ok := swap.leverRates[code]
+	swap.mutex.Unlock()
+	if ok {
+		return leverRate, nil
+	}
+
+	data, err := swap.hbdm.doPost("/swap-api/v1/swap_account_info", map[string]interface{}{
+		"contract_code": code,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	dataArr, ok := data.([]interface{})
+	if !ok || len(dataArr) == 0 {
+		return 0, errors.New("account not found")
+	}
+
+	leverRate = ToInt(dataArr[0].(map[string]interface{})["lever_rate"])
+	swap.mutex.Lock()
+	swap.leverRates[code] = leverRate
+	swap.mutex.Unlock()
+	return leverRate, nil
+}
+
+func (swap *HbdmSwap) PlaceSwapOrder(pair CurrencyPair, price, amount string, openType, matchPrice int) (*FutureOrder, error) {
+	code := swap.getContractCode(pair)
+	leverRate, err := swap.getLeverRate(code)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]interface{}{
+		"contract_code": code,
+		"volume":        amount,
+		"lever_rate":    leverRate,
+	}
+
+	switch openType {
+	case OPEN_BUY:
+		params["direction"], params["offset"] = "buy", "open"
+	case OPEN_SELL:
+		params["direction"], params["offset"] = "sell", "open"
+	case CLOSE_BUY:
+		params["direction"], params["offset"] = "sell", "close"
+	case CLOSE_SELL:
+		params["direction"], params["offset"] = "buy", "close"
+	default:
+		return nil, fmt.Errorf("unsupported open type:%v", openType)
+	}
+
+	if matchPrice == 1 {
+		params["order_price_type"] = "opponent"
+	} else {
+		params["order_price_type"] = "limit"
+		params["price"] = price
+	}
+
+	data, err := swap.hbdm.doPost("/swap-api/v1/swap_order", params)
+	if err != nil {
+		return nil, err
+	}
+
+	datamap, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("data assert error")
+	}
+
+	return &FutureOrder{
+		OrderID2:     ToString(datamap["order_id_str"]),
+		OrderID:      ToInt64(datamap["order_id"]),
+		Price:        ToFloat64(price),
+		Amount:       ToFloat64(amount),
+		OrderTime:    time.Now().UnixNano() / int64(time.Millisecond),
+		Status:       ORDER_UNFINISH,
+		Currency:     pair,
+		OType:        openType,
+		LeverRate:    leverRate,
+		ContractName: code,
+	}, nil
+}
+
+func (swap *HbdmSwap) CancelSwapOrder(orderId string, pair CurrencyPair) (bool, error) {
+	data, err := swap.hbdm.doPost("/swap-api/v1/swap_cancel", map[string]interface{}{
+		"order_id":      orderId,
+		"contract_code": swap.getContractCode(pair),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	datamap, _ := data.(map[string]interface{})
+	if errs, ok := datamap["errors"].([]interface{}); ok && len(errs) > 0 {
+		e, _ := errs[0].(map[string]interface{})
+		return false, fmt.Errorf("code:%v, msg:%v", e["err_code"], e["err_msg"])
+	}
+
+	return true, nil
+}
+
+func (swap *HbdmSwap) GetSwapOrder(orderId string, pair CurrencyPair) (*FutureOrder, error) {
+	data, err := swap.hbdm.doPost("/swap-api/v1/swap_order_info", map[string]interface{}{
+		"order_id":      orderId,
+		"contract_code": swap.getContractCode(pair),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dataArr, ok := data.([]interface{})
+	if !ok || len(dataArr) == 0 {
+		return nil, errors.New("order not found")
+	}
+
+	ord := swap.hbdm.parseOrder(dataArr[0].(map[string]interface{}), pair)
+	return &ord, nil
+}
+
+func (swap *HbdmSwap) GetUnfinishSwapOrders(pair CurrencyPair) ([]FutureOrder, error) {
+	data, err := swap.hbdm.doPost("/swap-api/v1/swap_openorders", map[string]interface{}{
+		"contract_code": swap.getContractCode(pair),
+		"page_size":     50,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	datamap, _ := data.(map[string]interface{})
+	ordArr, _ := datamap["orders"].([]interface{})
+	orders := make([]FutureOrder, 0, len(ordArr))
+	for _, v := range ordArr {
+		orders = append(orders, swap.hbdm.parseOrder(v.(map[string]interface{}), pair))
+	}
+
+	return orders, nil
+}
+
+func (swap *HbdmSwap) GetSwapPosition(pair CurrencyPair) ([]SwapPosition, error) {
+	data, err := swap.hbdm.doPost("/swap-api/v1/swap_position_info", map[string]interface{}{
+		"contract_code": swap.getContractCode(pair),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dataArr, _ := data.([]interface{})
+	positions := make([]SwapPosition, 0, len(dataArr))
+	for _, v := range dataArr {
+		obj := v.(map[string]interface{})
+		side := POSITION_LONG
+		if ToString(obj["direction"]) == "sell" {
+			side = POSITION_SHORT
+		}
+		positions = append(positions, SwapPosition{
+			Market:        pair,
+			Symbol:        pair.ToLowerSymbol("/"),
+			Side:          side,
+			Amount:        ToFloat64(obj["volume"]),
+			Available:     ToFloat64(obj["available"]),
+			AvgPrice:      ToFloat64(obj["cost_open"]),
+			Leverage:      ToInt(obj["lever_rate"]),
+			MarginMode:    MARGIN_ISOLATED,
+			Margin:        ToFloat64(obj["position_margin"]),
+			UnrealizedPnl: ToFloat64(obj["profit_unreal"]),
+		})
+	}
+
+	return positions, nil
+}
+
+func (swap *HbdmSwap) GetSwapAccount() (*FutureAccount, error) {
+	data, err := swap.hbdm.doPost("/swap-api/v1/swap_account_info", map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	dataArr, _ := data.([]interface{})
+	acc := &FutureAccount{FutureSubAccounts: make(map[Currency]FutureSubAccount, len(dataArr))}
+	for _, v := range dataArr {
+		obj := v.(map[string]interface{})
+		currency := CanonicalCurrency(HUOBI, ToString(obj["symbol"]))
+		acc.FutureSubAccounts[currency] = FutureSubAccount{
+			Currency:      currency,
+			AccountRights: ToFloat64(obj["margin_balance"]),
+			KeepDeposit:   ToFloat64(obj["margin_position"]),
+			ProfitReal:    ToFloat64(obj["profit_real"]),
+			ProfitUnreal:  ToFloat64(obj["profit_unreal"]),
+			RiskRate:      ToFloat64(obj["risk_rate"]),
+		}
+	}
+
+	return acc, nil
+}
+
+// 切换杠杆倍数，有持仓或挂单时可能失败
+func (swap *HbdmSwap) SetSwapLeverage(pair CurrencyPair, leverRate int) error {
+	code := swap.getContractCode(pair)
+	_, err := swap.hbdm.doPost("/swap-api/v1/swap_switch_lever_rate", map[string]interface{}{
+		"contract_code": code,
+		"lever_rate":    leverRate,
+	})
+	if err != nil {
+		return err
+	}
+
+	swap.mutex.Lock()
+	swap.leverRates[code] = leverRate
+	swap.mutex.Unlock()
+	return nil
+}
+
+// 火币币本位永续合约只支持逐仓模式
+func (swap *HbdmSwap) SetSwapMarginMode(pair CurrencyPair, mode MarginMode) error {
+	if mode == MARGIN_ISOLATED {
+		return nil
+	}
+	return ErrorUnsupported
+}
@@ -0,0 +1,97 @@
+package huobi
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/betterjun/exapi"
+	"net/http"
+)
+
+/**
+ * asset transfer
+ */
+func NewTransferAPI(client *http.Client, apikey, secretkey string) TransferAPI {
+	return NewHuoBiPro(client, apikey, secretkey, "")
+}
+
+// 火币的现货接口支持现货账户和点卡账户
+func (hbpro *HuoBiPro) SetAccountType(accountType AccountType) error {
+	if accountType != ACCOUNT_SPOT && accountType != ACCOUNT_POINT {
+		return ErrorUnsupported
+	}
+
+	hbpro.accountType = accountType
+	return nil
+}
+
+func (hbpro *HuoBiPro) GetAccountByType(accountType AccountType) (*Account, error) {
+	switch accountType {
+	case ACCOUNT_SPOT:
+		hbpro.updateAccountID()
+		return hbpro.getAccount(hbpro.accountId)
+	case ACCOUNT_POINT:
+		info, err := hbpro.GetAccountInfo(HB_POINT_ACCOUNT)
+		if err != nil {
+			return nil, err
+		}
+		if len(info.Id) == 0 {
+			return nil, errors.New("point account not found")
+		}
+		return hbpro.getAccount(info.Id)
+	default:
+		return nil, ErrorUnsupported
+	}
+}
+
+// 火币只支持现货账户与逐仓杠杆账户、交割合约账户之间的划转
+func (hbpro *HuoBiPro) Transfer(currency Currency, amount string, from, to AccountType, pair CurrencyPair) (string, error) {
+	var path string
+	body := map[string]interface{}{
+		"currency": ExchangeCurrency(HUOBI, currency).LowerSymbol(),
+		"amount":   amount,
+	}
+
+	switch {
+	case from == ACCOUNT_SPOT && to == ACCOUNT_MARGIN:
+		path = "/v1/dw/transfer-in/margin"
+		body["symbol"] = ExchangeLowerSymbol(HUOBI, pair, "")
+	case from == ACCOUNT_MARGIN && to == ACCOUNT_SPOT:
+		path = "/v1/dw/transfer-out/margin"
+		body["symbol"] = ExchangeLowerSymbol(HUOBI, pair, "")
+	case from == ACCOUNT_SPOT && to == ACCOUNT_FUTURE:
+		path = "/v1/futures/transfer"
+		body["type"] = "pro-to-futures"
+	case from == ACCOUNT_FUTURE && to == ACCOUNT_SPOT:
+		path = "/v1/futures/transfer"
+		body["type"] = "futures-to-pro"
+	default:
+		return "", ErrorUnsupported
+	}
+
+	data, err := hbpro.doPost(path, body)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%.0f", ToFloat64(data)), nil
+}
+
+// 火币的子账户为子用户的uid
+func (hbpro *HuoBiPro) SubAccountTransfer(subAccount string, currency Currency, amount string, toSub bool) (string, error) {
+	transferType := "master-transfer-in"
+	if toSub {
+		transferType = "master-transfer-out"
+	}
+
+	data, err := hbpro.doPost("/v1/subuser/transfer", map[string]interface{}{
+		"sub-uid":  ToInt64(subAccount),
+		"currency": ExchangeCurrency(HUOBI, currency).LowerSymbol(),
+		"amount":   amount,
+		"type":     transferType,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%.0f", ToFloat64(data)), nil
+}
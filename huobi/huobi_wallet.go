@@ -0,0 +1,174 @@
+package huobi
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/betterjun/exapi"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+/**
+ * deposit and withdrawal
+ */
+func NewWalletAPI(client *http.Client, apikey, secretkey string) WalletAPI {
+	return NewHuoBiPro(client, apikey, secretkey, "")
+}
+
+// 获取币种的链信息，chain为空时返回默认链，默认链的名称与币种相同
+func (hbpro *HuoBiPro) getChainInfo(currency Currency, chain string) (map[string]interface{}, error) {
+	respmap, err := HttpGet(hbpro.httpClient, hbpro.baseUrl+"/v2/reference/currencies?currency="+ExchangeCurrency(HUOBI, currency).LowerSymbol())
+	if err != nil {
+		return nil, err
+	}
+
+	if ToInt(respmap["code"]) != 200 {
+		return nil, fmt.Errorf("code:%v, msg:%v", respmap["code"], respmap["message"])
+	}
+
+	dataArr, _ := respmap["data"].([]interface{})
+	for _, v := range dataArr {
+		chains, _ := v.(map[string]interface{})["chains"].([]interface{})
+		var first map[string]interface{}
+		for _, c := range chains {
+			info := c.(map[string]interface{})
+			if first == nil {
+				first = info
+			}
+			name := ToString(info["chain"])
+			if (len(chain) == 0 && name == ExchangeCurrency(HUOBI, currency).LowerSymbol()) || (len(chain) > 0 && strings.EqualFold(name, chain)) {
+				return info, nil
+			}
+		}
+		if len(chain) == 0 && first != nil {
+			return first, nil
+		}
+	}
+
+	return nil, ErrorAssetNotFound
+}
+
+func (hbpro *HuoBiPro) GetDepositAddress(currency Currency, chain string) (*DepositAddress, error) {
+	params := url.Values{}
+	params.Set("currency", ExchangeCurrency(HUOBI, currency).LowerSymbol())
+	path := "/v2/account/deposit/address"
+	hbpro.buildPostForm("GET", path, &params)
+	respmap, err := HttpGet(hbpro.httpClient, hbpro.baseUrl+path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	if ToInt(respmap["code"]) != 200 {
+		return nil, fmt.Errorf("code:%v, msg:%v", respmap["code"], respmap["message"])
+	}
+
+	dataArr, _ := respmap["data"].([]interface{})
+	for _, v := range dataArr {
+		obj := v.(map[string]interface{})
+		name := ToString(obj["chain"])
+		if (len(chain) == 0 && name == ExchangeCurrency(HUOBI, currency).LowerSymbol()) || (len(chain) > 0 && strings.EqualFold(name, chain)) ||
+			len(dataArr) == 1 {
+			return &DepositAddress{
+				Currency: currency,
+				Chain:    name,
+				Address:  ToString(obj["address"]),
+				Tag:      ToString(obj["addressTag"]),
+			}, nil
+		}
+	}
+
+	return nil, errors.New("deposit address not found")
+}
+
+// 火币提币需要指定手续费，使用链的固定手续费
+func (hbpro *HuoBiPro) Withdraw(currency Currency, amount, address, tag, chain string) (string, error) {
+	info, err := hbpro.getChainInfo(currency, chain)
+	if err != nil {
+		return "", err
+	}
+
+	fee := ToString(info["transactFeeWithdraw"])
+	if len(fee) == 0 {
+		fee = ToString(info["minTransactFeeWithdraw"])
+	}
+
+	body := map[string]interface{}{
+		"address":  address,
+		"amount":   amount,
+		"currency": ExchangeCurrency(HUOBI, currency).LowerSymbol(),
+		"fee":      fee,
+		"chain":    ToString(info["chain"]),
+	}
+	if len(tag) > 0 {
+		body["addr-tag"] = tag
+	}
+
+	data, err := hbpro.doPost("/v1/dw/withdraw/api/create", body)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%.0f", ToFloat64(data)), nil
+}
+
+func (hbpro *HuoBiPro) CancelWithdraw(id string, currency Currency) (bool, error) {
+	_, err := hbpro.doPost(fmt.Sprintf("/v1/dw/withdraw-virtual/%s/cancel", id), map[string]interface{}{})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (hbpro *HuoBiPro) getWalletRecords(currency Currency, typ string) ([]WalletRecord, error) {
+	params := url.Values{}
+	params.Set("currency", ExchangeCurrency(HUOBI, currency).LowerSymbol())
+	params.Set("type", typ)
+	params.Set("size", "100")
+	data, err := hbpro.doGet("/v1/query/deposit-withdraw", params)
+	if err != nil {
+		return nil, err
+	}
+
+	dataArr, _ := data.([]interface{})
+	records := make([]WalletRecord, 0, len(dataArr))
+	for _, v := range dataArr {
+		obj := v.(map[string]interface{})
+		record := WalletRecord{
+			Id:       fmt.Sprintf("%.0f", ToFloat64(obj["id"])),
+			Type:     WALLET_DEPOSIT,
+			Currency: currency,
+			Chain:    ToString(obj["chain"]),
+			Amount:   ToFloat64(obj["amount"]),
+			Fee:      ToFloat64(obj["fee"]),
+			Address:  ToString(obj["address"]),
+			Tag:      ToString(obj["address-tag"]),
+			TxId:     ToString(obj["tx-hash"]),
+			Status:   WALLET_PENDING,
+			TS:       ToInt64(obj["created-at"]),
+		}
+		if typ == "withdraw" {
+			record.Type = WALLET_WITHDRAW
+		}
+
+		switch ToString(obj["state"]) {
+		case "safe", "confirmed":
+			record.Status = WALLET_SUCCESS
+		case "orphan", "reject", "wallet-reject", "confirm-error", "repealed":
+			record.Status = WALLET_FAILED
+		case "canceled":
+			record.Status = WALLET_CANCELED
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func (hbpro *HuoBiPro) GetDepositHistory(currency Currency) ([]WalletRecord, error) {
+	return hbpro.getWalletRecords(currency, "deposit")
+}
+
+func (hbpro *HuoBiPro) GetWithdrawHistory(currency Currency) ([]WalletRecord, error) {
+	return hbpro.getWalletRecords(currency, "withdraw")
+}
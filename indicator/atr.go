@@ -0,0 +1,75 @@
+package indicator
+
+import (
+	. "github.com/betterjun/exapi"
+	"math"
+)
+
+// 平均真实波幅，使用Wilder平滑，第n个值为前n个真实波幅的简单平均
+func ATR(klines []Kline, n int) []float64 {
+	s := NewATRStream(n)
+	result := make([]float64, len(klines))
+	for i, k := range klines {
+		result[i] = s.Push(k)
+	}
+	return result
+}
+
+type ATRStream struct {
+	n         int
+	count     int     // 已结束的K线个数
+	atr       float64 // 已结束的平均真实波幅，不足n个时为真实波幅之和
+	prevClose float64
+	last      Kline
+	hasLast   bool
+}
+
+func NewATRStream(n int) *ATRStream {
+	if n < 1 {
+		n = 1
+	}
+	return &ATRStream{n: n}
+}
+
+func (s *ATRStream) Push(k Kline) float64 {
+	if s.hasLast {
+		s.atr = s.average()
+		s.count++
+		s.prevClose = s.last.Close
+	}
+	s.last, s.hasLast = k, true
+	return s.Value()
+}
+
+func (s *ATRStream) Update(k Kline) float64 {
+	if !s.hasLast {
+		return s.Push(k)
+	}
+	s.last = k
+	return s.Value()
+}
+
+func (s *ATRStream) Value() float64 {
+	if !s.hasLast || s.count+1 < s.n {
+		return math.NaN()
+	}
+	return s.average()
+}
+
+// 计算包含最后一根K线的平均真实波幅
+func (s *ATRStream) average() float64 {
+	tr := s.last.High - s.last.Low
+	if s.count > 0 {
+		tr = math.Max(tr, math.Max(math.Abs(s.last.High-s.prevClose), math.Abs(s.last.Low-s.prevClose)))
+	}
+
+	n := float64(s.n)
+	switch {
+	case s.count+1 < s.n:
+		return s.atr + tr
+	case s.count+1 == s.n:
+		return (s.atr + tr) / n
+	default:
+		return (s.atr*(n-1) + tr) / n
+	}
+}
@@ -0,0 +1,47 @@
+package indicator
+
+import "math"
+
+// 布林带，中轨为n周期简单移动平均，上下轨为中轨加减k倍标准差
+func BOLL(data []float64, n int, k float64) (mid, upper, lower []float64) {
+	s := NewBOLLStream(n, k)
+	mid = make([]float64, len(data))
+	upper = make([]float64, len(data))
+	lower = make([]float64, len(data))
+	for i, v := range data {
+		mid[i], upper[i], lower[i] = s.Push(v)
+	}
+	return mid, upper, lower
+}
+
+type BOLLStream struct {
+	w *window
+	k float64
+}
+
+func NewBOLLStream(n int, k float64) *BOLLStream {
+	return &BOLLStream{w: newWindow(n), k: k}
+}
+
+func (s *BOLLStream) Push(v float64) (mid, upper, lower float64) {
+	s.w.push(v)
+	return s.Value()
+}
+
+func (s *BOLLStream) Update(v float64) (mid, upper, lower float64) {
+	s.w.update(v)
+	return s.Value()
+}
+
+func (s *BOLLStream) Value() (mid, upper, lower float64) {
+	if !s.w.full() {
+		return math.NaN(), math.NaN(), math.NaN()
+	}
+	mid = s.w.sum() / float64(s.w.n)
+	var variance float64
+	for _, v := range s.w.values {
+		variance += (v - mid) * (v - mid)
+	}
+	std := math.Sqrt(variance / float64(s.w.n))
+	return mid, mid + s.k*std, mid - s.k*std
+}
@@ -0,0 +1,141 @@
+package indicator
+
+import (
+	. "github.com/betterjun/exapi"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+var testKlines = []Kline{
+	{Open: 10, High: 11, Low: 9, Close: 10.5, Vol: 100},
+	{Open: 10.5, High: 12, Low: 10, Close: 11.5, Vol: 120},
+	{Open: 11.5, High: 11.8, Low: 10.8, Close: 11, Vol: 80},
+	{Open: 11, High: 11.2, Low: 10.1, Close: 10.2, Vol: 150},
+	{Open: 10.2, High: 10.9, Low: 10, Close: 10.8, Vol: 90},
+	{Open: 10.8, High: 12.5, Low: 10.7, Close: 12.2, Vol: 200},
+	{Open: 12.2, High: 12.6, Low: 11.9, Close: 12, Vol: 110},
+	{Open: 12, High: 12.1, Low: 11.2, Close: 11.4, Vol: 130},
+}
+
+func TestMovingAverage(t *testing.T) {
+	data := []float64{1, 2, 3, 4, 5}
+	assertSeries(t, []float64{math.NaN(), math.NaN(), 2, 3, 4}, SMA(data, 3))
+	assertSeries(t, []float64{math.NaN(), math.NaN(), 2, 3, 4}, EMA(data, 3))
+	assertSeries(t, []float64{math.NaN(), math.NaN(), 14.0 / 6, 20.0 / 6, 26.0 / 6}, WMA(data, 3))
+}
+
+func TestRSI(t *testing.T) {
+	assertSeries(t, []float64{math.NaN(), math.NaN(), 100, 100}, RSI([]float64{1, 2, 3, 4}, 2))
+	// 涨跌幅相同时为50
+	assertSeries(t, []float64{math.NaN(), math.NaN(), 50}, RSI([]float64{1, 2, 1}, 2))
+}
+
+func TestBOLL(t *testing.T) {
+	mid, upper, lower := BOLL([]float64{1, 3, 1, 3}, 2, 2)
+	assertSeries(t, []float64{math.NaN(), 2, 2, 2}, mid)
+	assertSeries(t, []float64{math.NaN(), 4, 4, 4}, upper)
+	assertSeries(t, []float64{math.NaN(), 0, 0, 0}, lower)
+}
+
+func TestKlineIndicators(t *testing.T) {
+	assertSeries(t, []float64{0, 120, 40, -110, -20, 180, 70, -60}, OBV(testKlines))
+
+	atr := ATR(testKlines, 3)
+	assert.True(t, math.IsNaN(atr[1]))
+	assert.InDelta(t, (2+2+1)/3.0, atr[2], 1e-9)
+	assert.InDelta(t, (atr[2]*2+1.1)/3, atr[3], 1e-9)
+
+	// Vol为成交额，按基础币成交量加权
+	vwap := VWAP(testKlines[:2])
+	assert.InDelta(t, 220/(100/((11+9+10.5)/3)+120/((12+10+11.5)/3)), vwap[1], 1e-9)
+	// 成交额相同时，低价K线成交的基础币更多，权重更大
+	vwap = VWAP([]Kline{{High: 10, Low: 10, Close: 10, Vol: 1000}, {High: 20, Low: 20, Close: 20, Vol: 1000}})
+	assert.InDelta(t, 2000/150.0, vwap[1], 1e-9)
+
+	k, d, j := KDJ(testKlines, 3, 3, 3)
+	assert.True(t, math.IsNaN(k[1]))
+	rsv := (11 - 9) / (12 - 9.0) * 100
+	assert.InDelta(t, (50*2+rsv)/3, k[2], 1e-9)
+	assert.InDelta(t, (50*2+k[2])/3, d[2], 1e-9)
+	assert.InDelta(t, 3*k[2]-2*d[2], j[2], 1e-9)
+}
+
+// 流式计算时先推入错误的值再更新，结果应与整个序列的计算一致
+func TestStreamUpdate(t *testing.T) {
+	closes := Close(testKlines)
+
+	check := func(expected []float64, push, update func(v float64) float64) {
+		for i, v := range closes {
+			push(v * 2)
+			update(v + 1)
+			assertValue(t, expected[i], update(v))
+		}
+	}
+	sma := NewSMAStream(3)
+	check(SMA(closes, 3), sma.Push, sma.Update)
+	ema := NewEMAStream(3)
+	check(EMA(closes, 3), ema.Push, ema.Update)
+	wma := NewWMAStream(3)
+	check(WMA(closes, 3), wma.Push, wma.Update)
+	rsi := NewRSIStream(3)
+	check(RSI(closes, 3), rsi.Push, rsi.Update)
+
+	dif, dea, macd := MACD(closes, 2, 3, 2)
+	ms := NewMACDStream(2, 3, 2)
+	for i, v := range closes {
+		ms.Push(v * 2)
+		ms.Update(v + 1)
+		a, b, c := ms.Update(v)
+		assertValue(t, dif[i], a)
+		assertValue(t, dea[i], b)
+		assertValue(t, macd[i], c)
+	}
+
+	mid, upper, _ := BOLL(closes, 3, 2)
+	bs := NewBOLLStream(3, 2)
+	for i, v := range closes {
+		bs.Push(v * 2)
+		m, u, _ := bs.Update(v)
+		assertValue(t, mid[i], m)
+		assertValue(t, upper[i], u)
+	}
+
+	wrong := func(k Kline) Kline {
+		k.High, k.Low, k.Close, k.Vol = k.High*2, k.Low/2, k.Close*3, k.Vol*2
+		return k
+	}
+	atr := ATR(testKlines, 3)
+	obv := OBV(testKlines)
+	vwap := VWAP(testKlines)
+	kk, dd, jj := KDJ(testKlines, 3, 3, 3)
+	as, os, vs, ks := NewATRStream(3), NewOBVStream(), NewVWAPStream(), NewKDJStream(3, 3, 3)
+	for i, k := range testKlines {
+		as.Push(wrong(k))
+		os.Push(wrong(k))
+		vs.Push(wrong(k))
+		ks.Push(wrong(k))
+		assertValue(t, atr[i], as.Update(k))
+		assertValue(t, obv[i], os.Update(k))
+		assertValue(t, vwap[i], vs.Update(k))
+		a, b, c := ks.Update(k)
+		assertValue(t, kk[i], a)
+		assertValue(t, dd[i], b)
+		assertValue(t, jj[i], c)
+	}
+}
+
+func assertSeries(t *testing.T, expected, actual []float64) {
+	assert.Equal(t, len(expected), len(actual))
+	for i := range expected {
+		assertValue(t, expected[i], actual[i])
+	}
+}
+
+func assertValue(t *testing.T, expected, actual float64) {
+	if math.IsNaN(expected) {
+		assert.True(t, math.IsNaN(actual), "expected NaN, actual %v", actual)
+		return
+	}
+	assert.InDelta(t, expected, actual, 1e-9)
+}
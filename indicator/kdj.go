@@ -0,0 +1,78 @@
+package indicator
+
+import (
+	. "github.com/betterjun/exapi"
+	"math"
+)
+
+/*
+随机指标，常用参数为(9, 3, 3)。
+RSV为收盘价在n周期最高价和最低价之间的位置，K为RSV的m1周期平滑，D为K的m2周期平滑，J=3K-2D。
+K和D的初始值为50，第n个值开始有效。
+*/
+func KDJ(klines []Kline, n, m1, m2 int) (k, d, j []float64) {
+	s := NewKDJStream(n, m1, m2)
+	k = make([]float64, len(klines))
+	d = make([]float64, len(klines))
+	j = make([]float64, len(klines))
+	for i, kline := range klines {
+		k[i], d[i], j[i] = s.Push(kline)
+	}
+	return k, d, j
+}
+
+type KDJStream struct {
+	highs   *window
+	lows    *window
+	m1      float64
+	m2      float64
+	k       float64 // 已结束的K值
+	d       float64 // 已结束的D值
+	last    Kline
+	hasLast bool
+}
+
+func NewKDJStream(n, m1, m2 int) *KDJStream {
+	if m1 < 1 {
+		m1 = 1
+	}
+	if m2 < 1 {
+		m2 = 1
+	}
+	return &KDJStream{highs: newWindow(n), lows: newWindow(n), m1: float64(m1), m2: float64(m2), k: 50, d: 50}
+}
+
+func (s *KDJStream) Push(kline Kline) (k, d, j float64) {
+	if s.hasLast && s.highs.full() {
+		s.k, s.d, _ = s.Value()
+	}
+	s.highs.push(kline.High)
+	s.lows.push(kline.Low)
+	s.last, s.hasLast = kline, true
+	return s.Value()
+}
+
+func (s *KDJStream) Update(kline Kline) (k, d, j float64) {
+	if !s.hasLast {
+		return s.Push(kline)
+	}
+	s.highs.update(kline.High)
+	s.lows.update(kline.Low)
+	s.last = kline
+	return s.Value()
+}
+
+func (s *KDJStream) Value() (k, d, j float64) {
+	if !s.highs.full() {
+		return math.NaN(), math.NaN(), math.NaN()
+	}
+
+	rsv := 50.0
+	high, low := s.highs.max(), s.lows.min()
+	if high > low {
+		rsv = (s.last.Close - low) / (high - low) * 100
+	}
+	k = (s.k*(s.m1-1) + rsv) / s.m1
+	d = (s.d*(s.m2-1) + k) / s.m2
+	return k, d, 3*k - 2*d
+}
@@ -0,0 +1,126 @@
+/*
+技术指标。
+每个指标提供两种用法：对整个序列计算的函数，返回与输入等长的序列，数据不足的位置为NaN；
+以及流式计算的XxxStream，用Push追加一根已结束的K线，用Update更新最后一根未结束的K线，
+可以直接使用websocket推送的K线，无需重新计算整个序列。
+*/
+package indicator
+
+import "math"
+
+// 简单移动平均
+func SMA(data []float64, n int) []float64 {
+	return series(data, NewSMAStream(n).Push)
+}
+
+type SMAStream struct {
+	w *window
+}
+
+func NewSMAStream(n int) *SMAStream {
+	return &SMAStream{w: newWindow(n)}
+}
+
+func (s *SMAStream) Push(v float64) float64 {
+	s.w.push(v)
+	return s.Value()
+}
+
+func (s *SMAStream) Update(v float64) float64 {
+	s.w.update(v)
+	return s.Value()
+}
+
+func (s *SMAStream) Value() float64 {
+	if !s.w.full() {
+		return math.NaN()
+	}
+	return s.w.sum() / float64(s.w.n)
+}
+
+// 指数移动平均，平滑系数为2/(n+1)，第n个值为前n个值的简单平均
+func EMA(data []float64, n int) []float64 {
+	return series(data, NewEMAStream(n).Push)
+}
+
+type EMAStream struct {
+	n       int
+	alpha   float64
+	count   int     // 已结束的值的个数
+	sum     float64 // 前n-1个值的和，用于计算第一个值
+	ema     float64 // 已结束的值的指数移动平均
+	last    float64
+	hasLast bool
+}
+
+func NewEMAStream(n int) *EMAStream {
+	if n < 1 {
+		n = 1
+	}
+	return &EMAStream{n: n, alpha: 2 / float64(n+1)}
+}
+
+func (s *EMAStream) Push(v float64) float64 {
+	if s.hasLast {
+		s.ema = s.Value()
+		if s.count < s.n {
+			s.sum += s.last
+		}
+		s.count++
+	}
+	s.last, s.hasLast = v, true
+	return s.Value()
+}
+
+func (s *EMAStream) Update(v float64) float64 {
+	if !s.hasLast {
+		return s.Push(v)
+	}
+	s.last = v
+	return s.Value()
+}
+
+func (s *EMAStream) Value() float64 {
+	switch {
+	case !s.hasLast || s.count+1 < s.n:
+		return math.NaN()
+	case s.count+1 == s.n:
+		return (s.sum + s.last) / float64(s.n)
+	default:
+		return s.ema + s.alpha*(s.last-s.ema)
+	}
+}
+
+// 加权移动平均，权重从旧到新为1到n
+func WMA(data []float64, n int) []float64 {
+	return series(data, NewWMAStream(n).Push)
+}
+
+type WMAStream struct {
+	w *window
+}
+
+func NewWMAStream(n int) *WMAStream {
+	return &WMAStream{w: newWindow(n)}
+}
+
+func (s *WMAStream) Push(v float64) float64 {
+	s.w.push(v)
+	return s.Value()
+}
+
+func (s *WMAStream) Update(v float64) float64 {
+	s.w.update(v)
+	return s.Value()
+}
+
+func (s *WMAStream) Value() float64 {
+	if !s.w.full() {
+		return math.NaN()
+	}
+	var sum float64
+	for i, v := range s.w.values {
+		sum += float64(i+1) * v
+	}
+	return sum / float64(s.w.n*(s.w.n+1)/2)
+}
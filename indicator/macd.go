@@ -0,0 +1,63 @@
+package indicator
+
+import "math"
+
+// 平滑异同移动平均，返回DIF、DEA和MACD柱，MACD柱为2*(DIF-DEA)
+func MACD(data []float64, fast, slow, signal int) (dif, dea, macd []float64) {
+	s := NewMACDStream(fast, slow, signal)
+	dif = make([]float64, len(data))
+	dea = make([]float64, len(data))
+	macd = make([]float64, len(data))
+	for i, v := range data {
+		dif[i], dea[i], macd[i] = s.Push(v)
+	}
+	return dif, dea, macd
+}
+
+type MACDStream struct {
+	fast   *EMAStream
+	slow   *EMAStream
+	signal *EMAStream
+	pushed bool // 最后一个DIF是否已推入signal
+}
+
+func NewMACDStream(fast, slow, signal int) *MACDStream {
+	return &MACDStream{
+		fast:   NewEMAStream(fast),
+		slow:   NewEMAStream(slow),
+		signal: NewEMAStream(signal),
+	}
+}
+
+func (s *MACDStream) Push(v float64) (dif, dea, macd float64) {
+	dif = s.fast.Push(v) - s.slow.Push(v)
+	s.pushed = false
+	return s.result(dif)
+}
+
+func (s *MACDStream) Update(v float64) (dif, dea, macd float64) {
+	dif = s.fast.Update(v) - s.slow.Update(v)
+	return s.result(dif)
+}
+
+func (s *MACDStream) Value() (dif, dea, macd float64) {
+	dif = s.fast.Value() - s.slow.Value()
+	dea = s.signal.Value()
+	if !s.pushed {
+		dea = math.NaN()
+	}
+	return dif, dea, 2 * (dif - dea)
+}
+
+func (s *MACDStream) result(dif float64) (float64, float64, float64) {
+	// DIF有效后才计算DEA
+	if !math.IsNaN(dif) {
+		if s.pushed {
+			s.signal.Update(dif)
+		} else {
+			s.signal.Push(dif)
+			s.pushed = true
+		}
+	}
+	return s.Value()
+}
@@ -0,0 +1,56 @@
+package indicator
+
+import . "github.com/betterjun/exapi"
+
+// 能量潮，收盘价上涨时累加成交量，下跌时减去成交量，第一个值为0
+func OBV(klines []Kline) []float64 {
+	s := NewOBVStream()
+	result := make([]float64, len(klines))
+	for i, k := range klines {
+		result[i] = s.Push(k)
+	}
+	return result
+}
+
+type OBVStream struct {
+	obv       float64 // 已结束的K线的能量潮
+	prevClose float64
+	hasPrev   bool
+	last      Kline
+	hasLast   bool
+}
+
+func NewOBVStream() *OBVStream {
+	return &OBVStream{}
+}
+
+func (s *OBVStream) Push(k Kline) float64 {
+	if s.hasLast {
+		s.obv = s.Value()
+		s.prevClose, s.hasPrev = s.last.Close, true
+	}
+	s.last, s.hasLast = k, true
+	return s.Value()
+}
+
+func (s *OBVStream) Update(k Kline) float64 {
+	if !s.hasLast {
+		return s.Push(k)
+	}
+	s.last = k
+	return s.Value()
+}
+
+func (s *OBVStream) Value() float64 {
+	if !s.hasPrev {
+		return s.obv
+	}
+	switch {
+	case s.last.Close > s.prevClose:
+		return s.obv + s.last.Vol
+	case s.last.Close < s.prevClose:
+		return s.obv - s.last.Vol
+	default:
+		return s.obv
+	}
+}
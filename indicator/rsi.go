@@ -0,0 +1,80 @@
+package indicator
+
+import "math"
+
+// 相对强弱指标，使用Wilder平滑，第n+1个值开始有效
+func RSI(data []float64, n int) []float64 {
+	return series(data, NewRSIStream(n).Push)
+}
+
+type RSIStream struct {
+	n         int
+	count     int     // 已结束的涨跌幅个数
+	avgGain   float64 // 已结束的平均涨幅，不足n个时为涨幅之和
+	avgLoss   float64 // 已结束的平均跌幅，不足n个时为跌幅之和
+	prevClose float64
+	hasPrev   bool
+	last      float64
+	hasLast   bool
+}
+
+func NewRSIStream(n int) *RSIStream {
+	if n < 1 {
+		n = 1
+	}
+	return &RSIStream{n: n}
+}
+
+func (s *RSIStream) Push(v float64) float64 {
+	if s.hasLast {
+		if s.hasPrev {
+			s.avgGain, s.avgLoss = s.average()
+			s.count++
+		}
+		s.prevClose, s.hasPrev = s.last, true
+	}
+	s.last, s.hasLast = v, true
+	return s.Value()
+}
+
+func (s *RSIStream) Update(v float64) float64 {
+	if !s.hasLast {
+		return s.Push(v)
+	}
+	s.last = v
+	return s.Value()
+}
+
+func (s *RSIStream) Value() float64 {
+	if !s.hasPrev || s.count+1 < s.n {
+		return math.NaN()
+	}
+	gain, loss := s.average()
+	if loss == 0 {
+		if gain == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+gain/loss)
+}
+
+// 计算包含最后一个值的平均涨跌幅
+func (s *RSIStream) average() (gain, loss float64) {
+	change := s.last - s.prevClose
+	if change > 0 {
+		gain = change
+	} else {
+		loss = -change
+	}
+
+	n := float64(s.n)
+	switch {
+	case s.count+1 < s.n:
+		return s.avgGain + gain, s.avgLoss + loss
+	case s.count+1 == s.n:
+		return (s.avgGain + gain) / n, (s.avgLoss + loss) / n
+	default:
+		return (s.avgGain*(n-1) + gain) / n, (s.avgLoss*(n-1) + loss) / n
+	}
+}
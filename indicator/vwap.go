@@ -0,0 +1,78 @@
+package indicator
+
+import (
+	. "github.com/betterjun/exapi"
+	"math"
+)
+
+/*
+成交量加权平均价，从第一根K线开始累计，价格取(最高价+最低价+收盘价)/3，以K线的基础币成交量为权重。
+K线的Vol为成交额，基础币成交量按Vol/价格估算，即VWAP为成交额之和除以基础币成交量之和。
+按交易日计算时，只传入当日的K线，流式计算时在新交易日开始时调用Reset。
+*/
+func VWAP(klines []Kline) []float64 {
+	s := NewVWAPStream()
+	result := make([]float64, len(klines))
+	for i, k := range klines {
+		result[i] = s.Push(k)
+	}
+	return result
+}
+
+type VWAPStream struct {
+	amount  float64 // 已结束的K线的成交额之和
+	vol     float64 // 已结束的K线的基础币成交量之和
+	last    Kline
+	hasLast bool
+}
+
+func NewVWAPStream() *VWAPStream {
+	return &VWAPStream{}
+}
+
+func (s *VWAPStream) Push(k Kline) float64 {
+	if s.hasLast {
+		s.amount += typicalPrice(s.last) * baseVolume(s.last)
+		s.vol += baseVolume(s.last)
+	}
+	s.last, s.hasLast = k, true
+	return s.Value()
+}
+
+func (s *VWAPStream) Update(k Kline) float64 {
+	if !s.hasLast {
+		return s.Push(k)
+	}
+	s.last = k
+	return s.Value()
+}
+
+func (s *VWAPStream) Value() float64 {
+	amount, vol := s.amount, s.vol
+	if s.hasLast {
+		amount += typicalPrice(s.last) * baseVolume(s.last)
+		vol += baseVolume(s.last)
+	}
+	if vol == 0 {
+		return math.NaN()
+	}
+	return amount / vol
+}
+
+// 清空累计值，重新开始计算
+func (s *VWAPStream) Reset() {
+	*s = VWAPStream{}
+}
+
+func typicalPrice(k Kline) float64 {
+	return (k.High + k.Low + k.Close) / 3
+}
+
+// 按成交额和价格估算的基础币成交量，价格为0时不计入
+func baseVolume(k Kline) float64 {
+	p := typicalPrice(k)
+	if p == 0 {
+		return 0
+	}
+	return k.Vol / p
+}
@@ -0,0 +1,67 @@
+package indicator
+
+import "math"
+
+// 固定长度的滑动窗口，最后一个值对应未结束的K线，可以被更新
+type window struct {
+	n      int
+	values []float64
+}
+
+func newWindow(n int) *window {
+	if n < 1 {
+		n = 1
+	}
+	return &window{n: n}
+}
+
+func (w *window) push(v float64) {
+	w.values = append(w.values, v)
+	if len(w.values) > w.n {
+		w.values = w.values[1:]
+	}
+}
+
+func (w *window) update(v float64) {
+	if len(w.values) == 0 {
+		w.push(v)
+		return
+	}
+	w.values[len(w.values)-1] = v
+}
+
+func (w *window) full() bool {
+	return len(w.values) == w.n
+}
+
+func (w *window) sum() (s float64) {
+	for _, v := range w.values {
+		s += v
+	}
+	return s
+}
+
+func (w *window) max() float64 {
+	m := math.Inf(-1)
+	for _, v := range w.values {
+		m = math.Max(m, v)
+	}
+	return m
+}
+
+func (w *window) min() float64 {
+	m := math.Inf(1)
+	for _, v := range w.values {
+		m = math.Min(m, v)
+	}
+	return m
+}
+
+// 用流式指标计算整个序列
+func series(data []float64, push func(v float64) float64) []float64 {
+	result := make([]float64, len(data))
+	for i, v := range data {
+		result[i] = push(v)
+	}
+	return result
+}
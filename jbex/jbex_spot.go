@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -31,6 +32,7 @@ type JbexSpot struct {
 	baseUrl    string
 	accessKey  string
 	secretKey  string
+	symbols    *SymbolIndex // 交易对名称到交易对的索引
 }
 
 /**
@@ -42,6 +44,7 @@ func NewSpotAPI(client *http.Client, apikey, secretkey string) SpotAPI {
 	jbex.baseUrl = "https://api.jbex.com/"
 	jbex.accessKey = apikey
 	jbex.secretKey = secretkey
+	jbex.symbols = NewSymbolIndex(JBEX, jbex.GetAllCurrencyPair)
 	return jbex
 }
 
@@ -57,9 +60,36 @@ func (jbex *JbexSpot) GetURL() string {
 	return jbex.baseUrl
 }
 
-type TradeFee struct {
-	Maker float64 `json:"maker,string"` // 挂单手续费
-	Taker float64 `json:"taker,string"` // 吃单手续费
+var spotCapabilities = &Capabilities{
+	Exchange:           JBEX,
+	UnsupportedMethods: []string{"GetOrderDeal", "GetUserTrades"},
+	KlinePeriods:       KlinePeriodsOf(_INERNAL_KLINE_PERIOD_CONVERTER),
+	MaxKlineSize:       1000,
+	OrderSides:         AllOrderSides,
+	WebsocketStreams:   AllStreams,
+}
+
+func (jbex *JbexSpot) Capabilities() *Capabilities {
+	return spotCapabilities.Clone()
+}
+
+// jbex没有账户费率查询接口，返回币对公布的费率
+func (jbex *JbexSpot) GetTradeFee(pair CurrencyPair) (*TradeFee, error) {
+	tfm, err := jbex.GetTradeFeeMap()
+	if err != nil {
+		return nil, err
+	}
+
+	return TradeFeeOf(tfm, pair)
+}
+
+func (jbex *JbexSpot) GetTradeFeeMap() (map[string]TradeFee, error) {
+	ssm, err := jbex.GetAllCurrencyPair()
+	if err != nil {
+		return nil, err
+	}
+
+	return TradeFeeMapOf(ssm), nil
 }
 
 func (jbex *JbexSpot) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
@@ -80,11 +110,6 @@ func (jbex *JbexSpot) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
 		return nil, errors.New("symbols assert error")
 	}
 
-	tf := TradeFee{
-		Maker: 0.002,
-		Taker: 0.002,
-	}
-
 	ssm := make(map[string]SymbolSetting)
 	for _, v := range symbolArr {
 		d := v.(map[string]interface{})
@@ -105,8 +130,8 @@ func (jbex *JbexSpot) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
 			Symbol:   symbol,
 			Base:     base,
 			Quote:    quote,
-			MakerFee: tf.Maker,
-			TakerFee: tf.Taker,
+			MakerFee: 0.002,
+			TakerFee: 0.002,
 		}
 
 		for _, f := range filters {
@@ -115,17 +140,23 @@ func (jbex *JbexSpot) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
 			switch filterType {
 			case "LOT_SIZE":
 				ss.MinSize = ToFloat64(obj["minQty"])
+				ss.MaxSize = ToFloat64(obj["maxQty"])
+				ss.StepSize = ToFloat64(obj["stepSize"])
 			case "PRICE_FILTER":
 				ss.MinPrice = ToFloat64(obj["minPrice"])
+				ss.MaxPrice = ToFloat64(obj["maxPrice"])
+				ss.TickSize = ToFloat64(obj["tickSize"])
 			case "MIN_NOTIONAL":
 				ss.MinNotional = ToFloat64(obj["minNotional"])
 			}
 		}
+		ss.PricePrecision = PrecisionOf(ss.TickSize)
+		ss.AmountPrecision = PrecisionOf(ss.StepSize)
 
 		ssm[symbol] = ss
 	}
 
-	return ssm, nil
+	return CanonicalSymbolSettings(JBEX, ssm), nil
 }
 
 func (jbex *JbexSpot) GetCurrencyStatus(currency Currency) (CurrencyStatus, error) {
@@ -161,7 +192,7 @@ func (jbex *JbexSpot) GetAllCurrencyStatus() (all map[string]CurrencyStatus, err
 }
 
 func (jbex *JbexSpot) GetTicker(pair CurrencyPair) (*Ticker, error) {
-	url := jbex.baseUrl + "openapi/quote/v1/ticker/24hr?symbol=" + pair.ToSymbol("")
+	url := jbex.baseUrl + "openapi/quote/v1/ticker/24hr?symbol=" + ExchangeSymbol(JBEX, pair, "")
 	respmap, err := HttpGet(jbex.httpClient, url)
 	if err != nil {
 		return nil, err
@@ -173,10 +204,15 @@ func (jbex *JbexSpot) GetTicker(pair CurrencyPair) (*Ticker, error) {
 		return nil, fmt.Errorf("code:%v, msg:%v", respmap["code"], msg)
 	}
 
-	return jbex.parseTicker(respmap)
+	return jbex.parseTicker(pair, respmap), nil
 }
 
 func (jbex *JbexSpot) GetAllTicker() ([]Ticker, error) {
+	// 交易对索引获取失败时无法转换交易对名称
+	if err := jbex.symbols.Load(); err != nil {
+		return nil, err
+	}
+
 	url := jbex.baseUrl + "openapi/quote/v1/ticker/24hr"
 	tickerArr, err := HttpGet3(jbex.httpClient, url, nil)
 	if err != nil {
@@ -189,24 +225,20 @@ func (jbex *JbexSpot) GetAllTicker() ([]Ticker, error) {
 		if !ok {
 			continue
 		}
-		ticker, err := jbex.parseTicker(obj)
-		if err == nil {
-			tickers = append(tickers, *ticker)
+		pair, err := jbex.symbols.Pair(ToString(obj["symbol"]))
+		if err != nil {
+			continue
 		}
+		tickers = append(tickers, *jbex.parseTicker(pair, obj))
 	}
 
 	return tickers, nil
 }
 
-func (jbex *JbexSpot) parseTicker(tickmap map[string]interface{}) (*Ticker, error) {
-	base, quote := getSymbol(ToString(tickmap["symbol"]))
-	if len(base) == 0 || len(quote) == 0 {
-		return nil, fmt.Errorf("invalid symbol %v", tickmap["symbol"])
-	}
-
+func (jbex *JbexSpot) parseTicker(pair CurrencyPair, tickmap map[string]interface{}) *Ticker {
 	ticker := new(Ticker)
-	ticker.Symbol = strings.ToLower(base + "/" + quote)
-	ticker.Market = NewCurrencyPairFromString(ticker.Symbol)
+	ticker.Symbol = pair.ToLowerSymbol("/")
+	ticker.Market = pair
 	ticker.Open = ToFloat64(tickmap["openPrice"])
 	ticker.Last = ToFloat64(tickmap["lastPrice"])
 	ticker.High = ToFloat64(tickmap["highPrice"])
@@ -215,24 +247,11 @@ func (jbex *JbexSpot) parseTicker(tickmap map[string]interface{}) (*Ticker, erro
 	//ticker.Buy = ToFloat64(tickmap["bestBidPrice"])
 	//ticker.Sell = ToFloat64(tickmap["bestAskPrice"])
 	ticker.TS = ToInt64(tickmap["time"])
-	return ticker, nil
-}
-
-func getSymbol(market string) (base, quote string) {
-	fiatCoin := []string{"USDT", "BTC"}
-
-	for _, v := range fiatCoin {
-		if strings.HasSuffix(market, v) {
-			p := strings.LastIndex(market, v)
-			return market[0:p], v
-		}
-	}
-
-	return "", ""
+	return ticker
 }
 
 func (jbex *JbexSpot) GetDepth(pair CurrencyPair, size int, step int) (*Depth, error) {
-	url := jbex.baseUrl + "openapi/quote/v1/depth?symbol=" + pair.ToSymbol("")
+	url := jbex.baseUrl + "openapi/quote/v1/depth?symbol=" + ExchangeSymbol(JBEX, pair, "")
 	respmap, err := HttpGet(jbex.httpClient, url)
 	if err != nil {
 		return nil, err
@@ -252,7 +271,7 @@ func (jbex *JbexSpot) GetDepth(pair CurrencyPair, size int, step int) (*Depth, e
 }
 
 func (jbex *JbexSpot) GetTrades(pair CurrencyPair, size int) ([]Trade, error) {
-	url := jbex.baseUrl + "openapi/quote/v1/trades?symbol=" + pair.ToSymbol("")
+	url := jbex.baseUrl + "openapi/quote/v1/trades?symbol=" + ExchangeSymbol(JBEX, pair, "")
 	tradeArr, err := HttpGet3(jbex.httpClient, url, nil)
 	if err != nil {
 		return nil, err
@@ -289,11 +308,35 @@ func (jbex *JbexSpot) GetTrades(pair CurrencyPair, size int) ([]Trade, error) {
 func (jbex *JbexSpot) GetKlineRecords(pair CurrencyPair, period KlinePeriod, size, since int) ([]Kline, error) {
 	periodS, isOk := _INERNAL_KLINE_PERIOD_CONVERTER[period]
 	if isOk != true {
-		return nil, fmt.Errorf("unsupported %v KlinePeriod:%v", jbex.GetExchangeName(), period)
-	}
-	url := jbex.baseUrl + "openapi/quote/v1/klines?interval=%s&limit=%d&symbol=%s"
-	symbol := pair.ToSymbol("")
-	klineArr, err := HttpGet3(jbex.httpClient, fmt.Sprintf(url, periodS, size, symbol), nil)
+		// 交易所不支持的周期，用较小周期的K线合成
+		return GetKlinesWithResample(spotCapabilities, func(p KlinePeriod, n, t int) ([]Kline, error) {
+			return jbex.GetKlineRecords(pair, p, n, t)
+		}, period, size, since)
+	}
+	params := url.Values{}
+	params.Set("symbol", ExchangeSymbol(JBEX, pair, ""))
+	params.Set("interval", periodS)
+	params.Set("limit", fmt.Sprint(size))
+	return jbex.getKlines(pair, params)
+}
+
+// jbex按区间获取K线时的请求间隔，以免超过频率限制
+const JBEX_KLINE_RANGE_INTERVAL = 100 * time.Millisecond
+
+func (jbex *JbexSpot) GetKlineRange(pair CurrencyPair, period KlinePeriod, start, end time.Time) ([]Kline, []KlineGap, error) {
+	return FetchKlineRange(spotCapabilities, func(p KlinePeriod, from, to int64) ([]Kline, error) {
+		params := url.Values{}
+		params.Set("symbol", ExchangeSymbol(JBEX, pair, ""))
+		params.Set("interval", _INERNAL_KLINE_PERIOD_CONVERTER[p])
+		params.Set("startTime", strconv.FormatInt(from*1000, 10))
+		params.Set("endTime", strconv.FormatInt(to*1000-1, 10))
+		params.Set("limit", strconv.Itoa(spotCapabilities.MaxKlineSize))
+		return jbex.getKlines(pair, params)
+	}, period, start, end, JBEX_KLINE_RANGE_INTERVAL)
+}
+
+func (jbex *JbexSpot) getKlines(pair CurrencyPair, params url.Values) ([]Kline, error) {
+	klineArr, err := HttpGet3(jbex.httpClient, jbex.baseUrl+"openapi/quote/v1/klines?"+params.Encode(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -415,7 +458,7 @@ func (jbex *JbexSpot) GetPendingOrders(pair CurrencyPair) ([]Order, error) {
 	requrl := jbex.baseUrl + "openapi/v1/openOrders"
 	params := map[string]string{}
 	params["timestamp"] = fmt.Sprint(time.Now().Unix() * 1000)
-	params["symbol"] = pair.ToSymbol("")
+	params["symbol"] = ExchangeSymbol(JBEX, pair, "")
 	respArr := make([]map[string]interface{}, 0)
 	err := jbex.httpGet(requrl, params, &respArr)
 	if err != nil {
@@ -438,7 +481,7 @@ func (jbex *JbexSpot) GetFinishedOrders(pair CurrencyPair) ([]Order, error) {
 	requrl := jbex.baseUrl + "openapi/v1/historyOrders"
 	params := map[string]string{}
 	params["timestamp"] = fmt.Sprint(time.Now().Unix() * 1000)
-	params["symbol"] = pair.ToSymbol("")
+	params["symbol"] = ExchangeSymbol(JBEX, pair, "")
 	respArr := make([]map[string]interface{}, 0)
 	err := jbex.httpGet(requrl, params, &respArr)
 	if err != nil {
@@ -483,7 +526,7 @@ func (jbex *JbexSpot) GetAccount() (*Account, error) {
 	for _, v := range list {
 		balancemap := v.(map[string]interface{})
 		currencySymbol := balancemap["asset"].(string)
-		currency := NewCurrency(currencySymbol)
+		currency := CanonicalCurrency(JBEX, currencySymbol)
 
 		acc.SubAccounts[currency] = SubAccount{
 			Currency:     currency,
@@ -579,7 +622,7 @@ func (jbex *JbexSpot) parseDepthData(tick map[string]interface{}) *Depth {
 func (jbex *JbexSpot) placeOrder(amount, price string, pair CurrencyPair, side, orderType string) (string, error) {
 	requrl := jbex.baseUrl + "openapi/v1/order"
 	params := map[string]string{}
-	params["symbol"] = pair.ToSymbol("")
+	params["symbol"] = ExchangeSymbol(JBEX, pair, "")
 	params["side"] = side
 	params["type"] = orderType
 	params["quantity"] = amount
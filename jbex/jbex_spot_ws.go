@@ -50,7 +50,7 @@ func (ws *JBEXWs) GetExchangeName() string {
 
 // 格式化流名称
 func (ws *JBEXWs) FormatTopicName(topic string, pair CurrencyPair) string {
-	symbol := pair.ToSymbol("")
+	symbol := ExchangeSymbol(JBEX, pair, "")
 	switch topic {
 	case STREAM_TICKER:
 		return fmt.Sprintf("%s@ticker", symbol)
@@ -65,7 +65,7 @@ func (ws *JBEXWs) FormatTopicName(topic string, pair CurrencyPair) string {
 
 // 格式化流订阅消息
 func (ws *JBEXWs) FormatTopicSubData(topic string, pair CurrencyPair) []byte {
-	symbol := pair.ToSymbol("")
+	symbol := ExchangeSymbol(JBEX, pair, "")
 	switch topic {
 	case STREAM_TICKER:
 		return ws.Pack(map[string]interface{}{"symbol": symbol, "topic": "realtimes", "event": "sub", "params": map[string]interface{}{"binary": false}})
@@ -80,7 +80,7 @@ func (ws *JBEXWs) FormatTopicSubData(topic string, pair CurrencyPair) []byte {
 
 // 格式化流取消订阅消息
 func (ws *JBEXWs) FormatTopicUnsubData(topic string, pair CurrencyPair) []byte {
-	symbol := pair.ToSymbol("")
+	symbol := ExchangeSymbol(JBEX, pair, "")
 	switch topic {
 	case STREAM_TICKER:
 		return ws.Pack(map[string]interface{}{"symbol": symbol, "topic": "realtimes", "event": "cancel", "params": map[string]interface{}{"binary": false}})
@@ -0,0 +1,289 @@
+package exapi
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+/*
+用实时成交合成K线，用于没有K线推送的交易所。
+通过OnTrades接收SubTrade推送的成交，K线有变化时回调cb，closed为true表示该K线已结束，不会再更新。
+K线在结束时间之后再等待lateDelay才结束，期间到达的迟到成交仍计入原K线，之后到达的迟到成交被丢弃并计数。
+没有成交的周期生成成交量为0的K线，价格均为上一根K线的收盘价。
+成交量为报价币种成交量，即成交价乘以成交数量。
+*/
+type KlineBuilder struct {
+	sync.Mutex
+	pair      CurrencyPair
+	period    KlinePeriod
+	offset    int64 // 日线及以上周期的时区偏移，单位为秒
+	lateDelay int64 // 允许成交迟到的时间，单位为毫秒
+	seedAt    int64 // 初始化K线的时间，单位为毫秒，之前的成交已包含在初始化的K线中
+
+	open       []openKline // 尚未结束的K线，按时间升序
+	last       Kline       // 最后一根K线，用于补齐没有成交的周期
+	hasLast    bool
+	lateTrades int64
+
+	cb     func(kline *Kline, closed bool) error
+	emitMu sync.Mutex // 保证回调按K线变化的顺序执行
+}
+
+// 尚未结束的K线，记录已计入的最早和最晚成交时间，用于处理乱序成交
+type openKline struct {
+	Kline
+	traded  bool
+	firstTS int64
+	lastTS  int64
+}
+
+type klineUpdate struct {
+	kline  Kline
+	closed bool
+}
+
+func NewKlineBuilder(pair CurrencyPair, period KlinePeriod, cb func(kline *Kline, closed bool) error) (*KlineBuilder, error) {
+	if period.Seconds() == 0 {
+		return nil, fmt.Errorf("unsupported KlinePeriod:%v", period)
+	}
+	return &KlineBuilder{pair: pair, period: period, cb: cb}, nil
+}
+
+// 设置允许成交迟到的时间，默认为0，即收到下一个周期的成交时立即结束K线
+func (kb *KlineBuilder) SetLateDelay(d time.Duration) {
+	kb.Lock()
+	kb.lateDelay = int64(d / time.Millisecond)
+	kb.Unlock()
+}
+
+/*
+设置日线及以上周期的时区偏移，即交易所日线开盘时间与UTC零点的差值，例如UTC+8为-8小时。
+用Seed初始化时会根据K线的开盘时间自动设置。
+*/
+func (kb *KlineBuilder) SetOffset(d time.Duration) {
+	kb.Lock()
+	kb.offset = (int64(-d/time.Second)%86400 + 86400) % 86400
+	kb.Unlock()
+}
+
+/*
+订阅ws的成交推送。
+SpotWsBase每个连接只保存一个成交回调：订阅新的交易对会替换所有交易对的回调，重复订阅已订阅的交易对不会设置回调。
+同一ws上有多个KlineBuilder时，应由一个回调分发给各个KlineBuilder的OnTrades。
+*/
+func (kb *KlineBuilder) Subscribe(ws SpotWebsocket) error {
+	return ws.SubTrade(kb.pair, kb.OnTrades)
+}
+
+/*
+用交易所的K线初始化，使启动时的当前K线包含启动之前的成交。
+at为获取K线的时间，早于at的成交被认为已包含在K线中而忽略。
+*/
+func (kb *KlineBuilder) Seed(klines []Kline, at time.Time) error {
+	klines = sortKlines(klines)
+	if len(klines) == 0 {
+		return nil
+	}
+
+	kb.Lock()
+	last := klines[len(klines)-1]
+	if kb.period == KLINE_DAY || kb.period == KLINE_WEEK || kb.period == KLINE_MONTH {
+		kb.offset = (-last.TS%86400 + 86400) % 86400
+	}
+	kb.seedAt = at.UnixNano() / int64(time.Millisecond)
+	kb.open = nil
+	if kb.bucket(kb.seedAt) == last.TS {
+		// 初始化的K线已包含seedAt之前的成交，开盘价不再更新
+		kb.open = append(kb.open, openKline{Kline: last, traded: true, firstTS: last.TS * 1000, lastTS: kb.seedAt})
+	}
+	kb.last, kb.hasLast = last, true
+	updates := kb.closeUpTo(kb.seedAt)
+	return kb.unlockAndEmit(updates)
+}
+
+// 用api.GetKlineRecords获取最近的K线初始化
+func (kb *KlineBuilder) SeedFromAPI(api SpotAPI) error {
+	at := time.Now()
+	klines, err := api.GetKlineRecords(kb.pair, kb.period, 2, 0)
+	if err != nil {
+		return err
+	}
+	return kb.Seed(klines, at)
+}
+
+// 处理推送的成交，可作为SubTrade的回调，其他交易对的成交被忽略
+func (kb *KlineBuilder) OnTrades(trades []Trade) error {
+	sorted := make([]Trade, 0, len(trades))
+	for _, t := range trades {
+		if t.Market.Equal(kb.pair) {
+			sorted = append(sorted, t)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].TS < sorted[j].TS
+	})
+
+	kb.Lock()
+	var updates []klineUpdate
+	for _, t := range sorted {
+		updates = append(updates, kb.addTrade(t)...)
+		updates = append(updates, kb.closeUpTo(t.TS)...)
+	}
+	return kb.unlockAndEmit(updates)
+}
+
+/*
+推进时间，结束已超过迟到时间的K线，并补齐没有成交的周期。
+没有成交时K线不会自动结束，需要定时调用。
+*/
+func (kb *KlineBuilder) Tick(now time.Time) error {
+	ms := now.UnixNano() / int64(time.Millisecond)
+
+	kb.Lock()
+	updates := kb.fill(nextKlineBucket(kb.bucket(ms), kb.period, kb.offset))
+	updates = append(updates, kb.closeUpTo(ms)...)
+	return kb.unlockAndEmit(updates)
+}
+
+// 尚未结束的最新K线
+func (kb *KlineBuilder) Current() (Kline, bool) {
+	kb.Lock()
+	defer kb.Unlock()
+	if len(kb.open) == 0 {
+		return Kline{}, false
+	}
+	return kb.open[len(kb.open)-1].Kline, true
+}
+
+// 因迟到而被丢弃的成交数
+func (kb *KlineBuilder) LateTrades() int64 {
+	kb.Lock()
+	defer kb.Unlock()
+	return kb.lateTrades
+}
+
+func (kb *KlineBuilder) addTrade(t Trade) []klineUpdate {
+	if t.TS < kb.seedAt {
+		return nil
+	}
+
+	b := kb.bucket(t.TS)
+	for i := range kb.open {
+		if kb.open[i].TS == b {
+			k := &kb.open[i]
+			if !k.traded {
+				k.Open, k.High, k.Low, k.Close = t.Price, t.Price, t.Price, t.Price
+				k.traded, k.firstTS, k.lastTS = true, t.TS, t.TS
+			}
+			if t.TS < k.firstTS {
+				k.Open, k.firstTS = t.Price, t.TS
+			}
+			if t.TS >= k.lastTS {
+				k.Close, k.lastTS = t.Price, t.TS
+			}
+			if t.Price > k.High {
+				k.High = t.Price
+			}
+			if t.Price < k.Low {
+				k.Low = t.Price
+			}
+			k.Vol += t.Price * t.Amount
+			if b == kb.last.TS {
+				kb.last = k.Kline
+			}
+			return []klineUpdate{{kline: k.Kline}}
+		}
+	}
+
+	if kb.hasLast && b <= kb.last.TS {
+		kb.lateTrades++
+		return nil
+	}
+
+	updates := kb.fill(b)
+	k := Kline{
+		Market: kb.pair,
+		Symbol: kb.pair.ToLowerSymbol("/"),
+		TS:     b,
+		Open:   t.Price,
+		Close:  t.Price,
+		High:   t.Price,
+		Low:    t.Price,
+		Vol:    t.Price * t.Amount,
+	}
+	kb.open = append(kb.open, openKline{Kline: k, traded: true, firstTS: t.TS, lastTS: t.TS})
+	kb.last, kb.hasLast = k, true
+	return append(updates, klineUpdate{kline: k})
+}
+
+// 在最后一根K线和bucket之间补齐没有成交的周期
+func (kb *KlineBuilder) fill(bucket int64) []klineUpdate {
+	if !kb.hasLast {
+		return nil
+	}
+
+	var updates []klineUpdate
+	for b := nextKlineBucket(kb.last.TS, kb.period, kb.offset); b < bucket; b = nextKlineBucket(b, kb.period, kb.offset) {
+		k := kb.emptyKline(b)
+		kb.open = append(kb.open, openKline{Kline: k})
+		kb.last = k
+		updates = append(updates, klineUpdate{kline: k})
+	}
+	return updates
+}
+
+// 结束在ms之前已超过迟到时间的K线
+func (kb *KlineBuilder) closeUpTo(ms int64) []klineUpdate {
+	var updates []klineUpdate
+	for len(kb.open) > 0 {
+		end := nextKlineBucket(kb.open[0].TS, kb.period, kb.offset) * 1000
+		if end+kb.lateDelay > ms {
+			break
+		}
+		updates = append(updates, klineUpdate{kline: kb.open[0].Kline, closed: true})
+		kb.open = kb.open[1:]
+	}
+	return updates
+}
+
+func (kb *KlineBuilder) emptyKline(ts int64) Kline {
+	return Kline{
+		Market: kb.pair,
+		Symbol: kb.pair.ToLowerSymbol("/"),
+		TS:     ts,
+		Open:   kb.last.Close,
+		Close:  kb.last.Close,
+		High:   kb.last.Close,
+		Low:    kb.last.Close,
+	}
+}
+
+// 成交时间所在周期的开盘时间，ms单位为毫秒，返回值单位为秒
+func (kb *KlineBuilder) bucket(ms int64) int64 {
+	return klineBucket(ms/1000, kb.period, kb.offset)
+}
+
+/*
+在释放状态锁之前取得回调锁，使并发调用的回调按K线变化的顺序执行，
+不会在K线结束的回调之后再收到它的更新。回调中不能再调用KlineBuilder的方法。
+*/
+func (kb *KlineBuilder) unlockAndEmit(updates []klineUpdate) error {
+	kb.emitMu.Lock()
+	defer kb.emitMu.Unlock()
+	kb.Unlock()
+	return kb.emit(updates)
+}
+
+func (kb *KlineBuilder) emit(updates []klineUpdate) error {
+	if kb.cb == nil {
+		return nil
+	}
+	for i := range updates {
+		if err := kb.cb(&updates[i].kline, updates[i].closed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package exapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestKlineBuilder(t *testing.T) {
+	var updates []Kline
+	var closed []Kline
+	kb, err := NewKlineBuilder(NewCurrencyPairFromString("BTC/USDT"), KLINE_M1, func(k *Kline, c bool) error {
+		if c {
+			closed = append(closed, *k)
+		} else {
+			updates = append(updates, *k)
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	kb.SetLateDelay(5 * time.Second)
+
+	// 乱序的成交
+	assert.Nil(t, kb.OnTrades(btcTrades([]Trade{
+		{Price: 12, Amount: 1, TS: 10000},
+		{Price: 10, Amount: 2, TS: 5000},
+		{Price: 11, Amount: 1, TS: 30000},
+	})))
+	assert.Equal(t, 3, len(updates))
+	assert.Equal(t, 0, len(closed))
+	k, ok := kb.Current()
+	assert.True(t, ok)
+	assert.Equal(t, Kline{Market: k.Market, Symbol: "btc/usdt", TS: 0, Open: 10, Close: 11, High: 12, Low: 10, Vol: 43}, k)
+
+	// 迟到时间内的成交仍计入上一根K线
+	assert.Nil(t, kb.OnTrades(btcTrades([]Trade{{Price: 13, Amount: 1, TS: 61000}, {Price: 9, Amount: 1, TS: 59000}})))
+	assert.Equal(t, 0, len(closed))
+
+	// 超过迟到时间后结束K线，之后的迟到成交被丢弃
+	assert.Nil(t, kb.OnTrades(btcTrades([]Trade{{Price: 14, Amount: 1, TS: 65000}})))
+	assert.Equal(t, 1, len(closed))
+	assert.Equal(t, 9.0, closed[0].Close)
+	assert.Equal(t, 9.0, closed[0].Low)
+	assert.Nil(t, kb.OnTrades(btcTrades([]Trade{{Price: 1, Amount: 1, TS: 50000}})))
+	assert.Equal(t, int64(1), kb.LateTrades())
+
+	// 没有成交的周期补齐为空K线
+	assert.Nil(t, kb.OnTrades(btcTrades([]Trade{{Price: 15, Amount: 1, TS: 190000}})))
+	assert.Nil(t, kb.Tick(time.Unix(250, 0)))
+	assert.Equal(t, []int64{0, 60, 120, 180}, klineTimes(closed))
+	assert.Equal(t, 14.0, closed[2].Open)
+	assert.Equal(t, 0.0, closed[2].Vol)
+	k, _ = kb.Current()
+	assert.Equal(t, int64(240), k.TS)
+	assert.Equal(t, 15.0, k.Close)
+}
+
+func TestKlineBuilderOutOfOrder(t *testing.T) {
+	kb, _ := NewKlineBuilder(NewCurrencyPairFromString("BTC/USDT"), KLINE_M1, nil)
+
+	// 分批到达的乱序成交，开盘价和收盘价按成交时间而不是到达顺序
+	assert.Nil(t, kb.OnTrades(btcTrades([]Trade{{Price: 10, Amount: 1, TS: 5000}, {Price: 11, Amount: 1, TS: 30000}})))
+	assert.Nil(t, kb.OnTrades(btcTrades([]Trade{{Price: 99, Amount: 1, TS: 20000}})))
+	assert.Nil(t, kb.OnTrades(btcTrades([]Trade{{Price: 1, Amount: 1, TS: 1000}})))
+	k, _ := kb.Current()
+	assert.Equal(t, 1.0, k.Open)
+	assert.Equal(t, 11.0, k.Close)
+	assert.Equal(t, 99.0, k.High)
+	assert.Equal(t, 1.0, k.Low)
+}
+
+func TestKlineBuilderSeed(t *testing.T) {
+	kb, _ := NewKlineBuilder(NewCurrencyPairFromString("BTC/USDT"), KLINE_DAY, nil)
+	now := time.Now()
+	offset := int64(8 * 3600)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Unix() - offset
+	if today+86400 <= now.Unix() {
+		today += 86400
+	}
+
+	assert.Nil(t, kb.Seed([]Kline{
+		{TS: today, Open: 100, High: 110, Low: 90, Close: 105, Vol: 1000},
+		{TS: today - 86400, Open: 95, High: 101, Low: 94, Close: 100, Vol: 900},
+	}, now))
+
+	// 初始化之前的成交已包含在K线中
+	ms := now.UnixNano() / int64(time.Millisecond)
+	assert.Nil(t, kb.OnTrades(btcTrades([]Trade{{Price: 120, Amount: 1, TS: ms - 1000}, {Price: 108, Amount: 1, TS: ms + 1000}})))
+	k, ok := kb.Current()
+	assert.True(t, ok)
+	assert.Equal(t, today, k.TS)
+	assert.Equal(t, 100.0, k.Open)
+	assert.Equal(t, 110.0, k.High)
+	assert.Equal(t, 108.0, k.Close)
+	assert.Equal(t, 1108.0, k.Vol)
+}
+
+func TestKlineBuilderOtherPair(t *testing.T) {
+	kb, _ := NewKlineBuilder(NewCurrencyPairFromString("BTC/USDT"), KLINE_M1, nil)
+
+	// 同一ws上其他交易对的成交被忽略
+	eth := NewCurrencyPairFromString("ETH/USDT")
+	assert.Nil(t, kb.OnTrades([]Trade{{Market: eth, Price: 2000, Amount: 1, TS: 1000}}))
+	_, ok := kb.Current()
+	assert.False(t, ok)
+	assert.Nil(t, kb.OnTrades(btcTrades([]Trade{{Price: 10, Amount: 1, TS: 2000}, {Market: eth, Price: 2000, Amount: 1, TS: 3000}})))
+	k, _ := kb.Current()
+	assert.Equal(t, 10.0, k.High)
+}
+
+// 设置成交的交易对为BTC/USDT，已设置的保持不变
+func btcTrades(trades []Trade) []Trade {
+	for i := range trades {
+		if trades[i].Market.Stock.Symbol() == "" {
+			trades[i].Market = NewCurrencyPairFromString("BTC/USDT")
+		}
+	}
+	return trades
+}
+
+func klineTimes(klines []Kline) []int64 {
+	var ts []int64
+	for _, k := range klines {
+		ts = append(ts, k.TS)
+	}
+	return ts
+}
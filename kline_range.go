@@ -0,0 +1,186 @@
+package exapi
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// 交易所未给出K线单次返回上限时，按区间获取K线使用的每页数量
+const KLINE_RANGE_DEFAULT_SIZE = 200
+
+// K线缺口，[Start, End)范围内交易所没有数据，单位为秒
+type KlineGap struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+}
+
+// 按时间区间获取K线
+type KlineRangeAPI interface {
+	/*
+		获取[start, end)范围内的K线，按时间升序返回，并去除重复的K线。
+		交易所没有数据的范围作为缺口返回，例如停牌或上线之前。
+		只能查询最近K线的交易所，范围早于可查询的K线时返回ErrorKlineRangeTooOld。
+	*/
+	GetKlineRange(pair CurrencyPair, period KlinePeriod, start, end time.Time) ([]Kline, []KlineGap, error)
+}
+
+/*
+分页获取[start, end)范围内的K线，用于支持按开始和结束时间查询K线的交易所。
+fetch获取[start, end)范围内交易所原生周期的K线，时间单位为秒，每次请求的范围不超过caps.MaxKlineSize个周期。
+两次请求之间间隔interval，以免超过交易所的频率限制。
+交易所不支持period时，用较小周期的K线合成。
+*/
+func FetchKlineRange(caps *Capabilities, fetch func(period KlinePeriod, start, end int64) ([]Kline, error),
+	period KlinePeriod, start, end time.Time, interval time.Duration) ([]Kline, []KlineGap, error) {
+	if !caps.SupportsKlinePeriod(period) {
+		return nil, nil, fmt.Errorf("unsupported %v KlinePeriod:%v", caps.Exchange, period)
+	}
+
+	source := period
+	from := start.Unix()
+	if !caps.IsNativeKlinePeriod(period) {
+		source, _ = ResampleSource(period, caps.KlinePeriods)
+		// 多取一个周期，以合成第一个完整周期
+		from -= period.Seconds()
+	}
+
+	size := caps.MaxKlineSize
+	if size <= 0 {
+		size = KLINE_RANGE_DEFAULT_SIZE
+	}
+	step := int64(size) * source.Seconds()
+
+	var klines []Kline
+	for cursor := from; cursor < end.Unix(); cursor += step {
+		if cursor != from && interval > 0 {
+			time.Sleep(interval)
+		}
+
+		to := cursor + step
+		if to > end.Unix() {
+			to = end.Unix()
+		}
+		page, err := fetch(source, cursor, to)
+		if err != nil {
+			return nil, nil, err
+		}
+		klines = append(klines, page...)
+	}
+
+	if source != period {
+		var err error
+		klines, err = ResampleKlines(sortKlines(klines), source, period)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	result, gaps := FilterKlineRange(klines, period, start, end)
+	return result, gaps, nil
+}
+
+/*
+获取[start, end)范围内的K线，用于只能查询最近K线的交易所。
+fetch为交易所的GetKlineRecords，按caps.MaxKlineSize获取一页尽量多的K线后截取范围。
+这一页已满且范围的开始早于这一页最早的K线时，无法区分交易所没有数据还是超出可查询范围，返回ErrorKlineRangeTooOld；
+这一页不满时交易所已返回全部K线，缺失的部分作为缺口返回。
+*/
+func GetLatestKlineRange(caps *Capabilities, fetch func(period KlinePeriod, size, since int) ([]Kline, error),
+	period KlinePeriod, start, end time.Time) ([]Kline, []KlineGap, error) {
+	if !caps.SupportsKlinePeriod(period) {
+		return nil, nil, fmt.Errorf("unsupported %v KlinePeriod:%v", caps.Exchange, period)
+	}
+
+	size := caps.MaxKlineSize
+	if size <= 0 {
+		size = KLINE_RANGE_DEFAULT_SIZE
+	}
+	klines, err := fetch(period, size, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, gaps := FilterKlineRange(klines, period, start, end)
+	if len(gaps) > 0 {
+		sorted := sortKlines(klines)
+		if len(klines) >= size && gaps[0].Start < sorted[0].TS {
+			return nil, nil, ErrorKlineRangeTooOld
+		}
+	}
+	return result, gaps, nil
+}
+
+/*
+整理K线：按时间升序排列，时间相同的K线保留后出现的一根，截取[start, end)范围，
+并找出范围内缺失的周期。范围的结束时间晚于当前时间时，只检查到当前时间为止。
+*/
+func FilterKlineRange(klines []Kline, period KlinePeriod, start, end time.Time) ([]Kline, []KlineGap) {
+	var result []Kline
+	for _, k := range sortKlines(klines) {
+		if k.TS >= start.Unix() && k.TS < end.Unix() {
+			result = append(result, k)
+		}
+	}
+
+	if period.Seconds() == 0 {
+		return result, nil
+	}
+
+	// 日线及以上周期按交易所的时区对齐
+	var offset int64
+	if len(result) > 0 && (period == KLINE_DAY || period == KLINE_WEEK || period == KLINE_MONTH) {
+		offset = (-result[0].TS%86400 + 86400) % 86400
+	}
+
+	limit := end.Unix()
+	if now := time.Now().Unix(); now < limit {
+		limit = now
+	}
+
+	expected := klineBucket(start.Unix(), period, offset)
+	if expected < start.Unix() {
+		expected = nextKlineBucket(expected, period, offset)
+	}
+
+	var gaps []KlineGap
+	for _, k := range result {
+		if k.TS > expected {
+			gaps = append(gaps, KlineGap{Start: expected, End: k.TS})
+		}
+		expected = nextKlineBucket(k.TS, period, offset)
+	}
+	if expected < limit {
+		gaps = append(gaps, KlineGap{Start: expected, End: limit})
+	}
+
+	return result, gaps
+}
+
+// 按时间升序排列，并去除时间重复的K线，保留后出现的一根
+func sortKlines(klines []Kline) []Kline {
+	sorted := make([]Kline, len(klines))
+	copy(sorted, klines)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].TS < sorted[j].TS
+	})
+
+	var result []Kline
+	for _, k := range sorted {
+		if n := len(result); n > 0 && result[n-1].TS == k.TS {
+			result[n-1] = k
+			continue
+		}
+		result = append(result, k)
+	}
+	return result
+}
+
+// 计算ts所在周期的下一个周期的开始时间
+func nextKlineBucket(ts int64, period KlinePeriod, offset int64) int64 {
+	b := klineBucket(ts, period, offset)
+	if period == KLINE_MONTH {
+		return time.Unix(b+offset, 0).UTC().AddDate(0, 1, 0).Unix() - offset
+	}
+	return b + period.Seconds()
+}
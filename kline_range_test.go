@@ -0,0 +1,104 @@
+package exapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFetchKlineRange(t *testing.T) {
+	caps := &Capabilities{
+		Exchange:     "test",
+		KlinePeriods: []KlinePeriod{KLINE_M1, KLINE_H1},
+		MaxKlineSize: 3,
+	}
+
+	// 第6、7分钟没有数据
+	var requests [][2]int64
+	fetch := func(period KlinePeriod, start, end int64) ([]Kline, error) {
+		requests = append(requests, [2]int64{start, end})
+		var klines []Kline
+		// 多返回一根前一页的K线，模拟交易所返回重叠的数据
+		for ts := start - 60; ts < end; ts += 60 {
+			if ts < 0 || ts == 360 || ts == 420 {
+				continue
+			}
+			klines = append(klines, Kline{TS: ts, Close: float64(ts)})
+		}
+		return klines, nil
+	}
+
+	klines, gaps, err := FetchKlineRange(caps, fetch, KLINE_M1, time.Unix(0, 0), time.Unix(600, 0), 0)
+	assert.Nil(t, err)
+	assert.Equal(t, [][2]int64{{0, 180}, {180, 360}, {360, 540}, {540, 600}}, requests)
+	assert.Equal(t, 8, len(klines))
+	for i := 1; i < len(klines); i++ {
+		assert.True(t, klines[i-1].TS < klines[i].TS)
+	}
+	assert.Equal(t, []KlineGap{{Start: 360, End: 480}}, gaps)
+
+	// H4由H1合成
+	klines, gaps, err = FetchKlineRange(caps, func(period KlinePeriod, start, end int64) ([]Kline, error) {
+		assert.Equal(t, KLINE_H1, period)
+		var klines []Kline
+		for ts := start; ts < end; ts += 3600 {
+			klines = append(klines, Kline{TS: ts, Vol: 1})
+		}
+		return klines, nil
+	}, KLINE_H4, time.Unix(0, 0), time.Unix(86400, 0), 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(klines))
+	assert.Equal(t, 4.0, klines[0].Vol)
+	assert.True(t, klines[0].Resampled)
+	assert.Nil(t, gaps)
+
+	_, _, err = FetchKlineRange(caps, fetch, KLINE_WEEK, time.Unix(0, 0), time.Unix(600, 0), 0)
+	assert.NotNil(t, err)
+}
+
+func TestFilterKlineRange(t *testing.T) {
+	klines := []Kline{{TS: 180, Close: 1}, {TS: 60}, {TS: 180, Close: 2}, {TS: 600}}
+	result, gaps := FilterKlineRange(klines, KLINE_M1, time.Unix(30, 0), time.Unix(600, 0))
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, 2.0, result[1].Close)
+	assert.Equal(t, []KlineGap{{Start: 120, End: 180}, {Start: 240, End: 600}}, gaps)
+
+	// 没有数据时整个范围都是缺口
+	result, gaps = FilterKlineRange(nil, KLINE_H1, time.Unix(0, 0), time.Unix(7200, 0))
+	assert.Nil(t, result)
+	assert.Equal(t, []KlineGap{{Start: 0, End: 7200}}, gaps)
+
+	// 月线按自然月检查缺口，UTC+8的交易所
+	offset := int64(8 * 3600)
+	jan := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).Unix() - offset
+	feb := time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC).Unix() - offset
+	apr := time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC).Unix() - offset
+	may := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC).Unix() - offset
+	mar := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC).Unix() - offset
+	result, gaps = FilterKlineRange([]Kline{{TS: jan}, {TS: feb}, {TS: apr}}, KLINE_MONTH, time.Unix(jan, 0), time.Unix(may, 0))
+	assert.Equal(t, 3, len(result))
+	assert.Equal(t, []KlineGap{{Start: mar, End: apr}}, gaps)
+}
+
+func TestGetLatestKlineRange(t *testing.T) {
+	caps := &Capabilities{Exchange: "test", KlinePeriods: []KlinePeriod{KLINE_M1}, MaxKlineSize: 3}
+	fetch := func(period KlinePeriod, size, since int) ([]Kline, error) {
+		return []Kline{{TS: 240}, {TS: 360}, {TS: 420}}, nil
+	}
+
+	klines, gaps, err := GetLatestKlineRange(caps, fetch, KLINE_M1, time.Unix(240, 0), time.Unix(480, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(klines))
+	assert.Equal(t, []KlineGap{{Start: 300, End: 360}}, gaps)
+
+	// 范围早于最近一页的K线，不能当作缺口返回
+	_, _, err = GetLatestKlineRange(caps, fetch, KLINE_M1, time.Unix(0, 0), time.Unix(480, 0))
+	assert.Equal(t, ErrorKlineRangeTooOld, err)
+
+	// 这一页不满时交易所已返回全部K线，缺失的部分是缺口
+	caps.MaxKlineSize = 10
+	klines, gaps, err = GetLatestKlineRange(caps, fetch, KLINE_M1, time.Unix(120, 0), time.Unix(480, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(klines))
+	assert.Equal(t, []KlineGap{{Start: 120, End: 240}, {Start: 300, End: 360}}, gaps)
+}
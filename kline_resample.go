@@ -0,0 +1,177 @@
+package exapi
+
+import (
+	"fmt"
+	"time"
+)
+
+// K线周期的秒数，月线按31天计算，仅用于估算数量
+func (p KlinePeriod) Seconds() int64 {
+	switch p {
+	case KLINE_M1:
+		return 60
+	case KLINE_M5:
+		return 300
+	case KLINE_M15:
+		return 900
+	case KLINE_M30:
+		return 1800
+	case KLINE_H1:
+		return 3600
+	case KLINE_H4:
+		return 14400
+	case KLINE_DAY:
+		return 86400
+	case KLINE_WEEK:
+		return 604800
+	case KLINE_MONTH:
+		return 2678400
+	default:
+		return 0
+	}
+}
+
+/*
+从交易所原生支持的周期中，选择可以合成period的最大周期。
+日内周期要求能整除，周线和月线只能用日线合成，以保证和交易所的日期划分一致。
+*/
+func ResampleSource(period KlinePeriod, natives []KlinePeriod) (KlinePeriod, bool) {
+	target := period.Seconds()
+	if target == 0 {
+		return 0, false
+	}
+
+	var source KlinePeriod
+	found := false
+	for _, p := range natives {
+		sec := p.Seconds()
+		if sec == 0 || sec >= target {
+			continue
+		}
+		if period == KLINE_WEEK || period == KLINE_MONTH {
+			if p != KLINE_DAY {
+				continue
+			}
+		} else if target%sec != 0 {
+			continue
+		}
+		if !found || sec > source.Seconds() {
+			source, found = p, true
+		}
+	}
+	return source, found
+}
+
+/*
+用from周期的K线合成to周期的K线，klines须按时间升序排列。
+开盘价取第一根，收盘价取最后一根，最高价和最低价取极值，成交量累加。
+日内周期按UTC对齐，周线(从周一开始)和月线按日线的开盘时间对齐，即使用交易所自己的时区。
+第一个周期的数据不完整时会被丢弃，最后一个周期可能是尚未结束的K线。
+合成的K线Resampled为true。
+*/
+func ResampleKlines(klines []Kline, from, to KlinePeriod) ([]Kline, error) {
+	if from == to {
+		return klines, nil
+	}
+	if s, ok := ResampleSource(to, []KlinePeriod{from}); !ok || s != from {
+		return nil, fmt.Errorf("can not resample KlinePeriod %v to %v", from, to)
+	}
+	if len(klines) == 0 {
+		return nil, nil
+	}
+
+	// 交易所的时区偏移，日线开盘时间加上偏移为UTC零点
+	var offset int64
+	if from == KLINE_DAY {
+		offset = (-klines[0].TS%86400 + 86400) % 86400
+	}
+
+	// 第一个周期不完整时跳过
+	first := klineBucket(klines[0].TS, to, offset)
+	partial := first != klines[0].TS
+
+	var result []Kline
+	for _, k := range klines {
+		b := klineBucket(k.TS, to, offset)
+		if partial && b == first {
+			continue
+		}
+
+		if n := len(result); n > 0 && result[n-1].TS == b {
+			last := &result[n-1]
+			last.Close = k.Close
+			if k.High > last.High {
+				last.High = k.High
+			}
+			if k.Low < last.Low {
+				last.Low = k.Low
+			}
+			last.Vol += k.Vol
+			continue
+		}
+
+		k.TS = b
+		k.Resampled = true
+		result = append(result, k)
+	}
+
+	return result, nil
+}
+
+// 计算ts所在周期的开始时间
+func klineBucket(ts int64, period KlinePeriod, offset int64) int64 {
+	switch period {
+	case KLINE_DAY, KLINE_WEEK, KLINE_MONTH:
+		t := time.Unix(ts+offset, 0).UTC()
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		switch period {
+		case KLINE_DAY:
+			return day.Unix() - offset
+		case KLINE_MONTH:
+			return day.AddDate(0, 0, 1-t.Day()).Unix() - offset
+		}
+		weekday := (int(t.Weekday()) + 6) % 7 // 周一为0
+		return day.AddDate(0, 0, -weekday).Unix() - offset
+	default:
+		sec := period.Seconds()
+		return ts - (ts%sec+sec)%sec
+	}
+}
+
+/*
+获取K线，交易所原生支持period时直接调用fetch，否则用较小周期的K线合成。
+fetch为交易所原生的K线获取函数，按时间升序返回。
+合成时按caps.MaxKlineSize限制获取的数量，返回的条数可能少于size。
+*/
+func GetKlinesWithResample(caps *Capabilities, fetch func(period KlinePeriod, size, since int) ([]Kline, error),
+	period KlinePeriod, size, since int) ([]Kline, error) {
+	if caps.IsNativeKlinePeriod(period) {
+		return fetch(period, size, since)
+	}
+
+	source, ok := ResampleSource(period, caps.KlinePeriods)
+	if !ok {
+		return nil, fmt.Errorf("unsupported %v KlinePeriod:%v", caps.Exchange, period)
+	}
+
+	// 多取一个周期，以弥补被丢弃的第一个不完整周期
+	ratio := int(period.Seconds() / source.Seconds())
+	n := (size + 1) * ratio
+	if caps.MaxKlineSize > 0 && n > caps.MaxKlineSize {
+		n = caps.MaxKlineSize
+	}
+
+	klines, err := fetch(source, n, since)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := ResampleKlines(klines, source, period)
+	if err != nil {
+		return nil, err
+	}
+	if size > 0 && len(result) > size {
+		result = result[len(result)-size:]
+	}
+	return result, nil
+}
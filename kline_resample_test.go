@@ -0,0 +1,88 @@
+package exapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestResampleKlines(t *testing.T) {
+	// 2020-01-01 01:00:00 UTC开始的9根小时线，第一个4小时周期不完整
+	start := time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC).Unix()
+	var klines []Kline
+	for i := 0; i < 9; i++ {
+		p := float64(i + 1)
+		klines = append(klines, Kline{TS: start + int64(i)*3600, Open: p, Close: p + 0.5, High: p + 1, Low: p - 1, Vol: 1})
+	}
+
+	result, err := ResampleKlines(klines, KLINE_H1, KLINE_H4)
+	assert.Nil(t, err)
+	assert.Len(t, result, 2)
+
+	h4 := result[0]
+	assert.Equal(t, time.Date(2020, 1, 1, 4, 0, 0, 0, time.UTC).Unix(), h4.TS)
+	assert.Equal(t, 4.0, h4.Open)
+	assert.Equal(t, 7.5, h4.Close)
+	assert.Equal(t, 8.0, h4.High)
+	assert.Equal(t, 3.0, h4.Low)
+	assert.Equal(t, 4.0, h4.Vol)
+	assert.True(t, h4.Resampled)
+
+	// 最后一个周期尚未结束
+	assert.Equal(t, 2.0, result[1].Vol)
+
+	_, err = ResampleKlines(klines, KLINE_H1, KLINE_WEEK)
+	assert.NotNil(t, err)
+}
+
+func TestResampleKlinesWeek(t *testing.T) {
+	// UTC+8的日线，2020-01-06为周一
+	loc := time.FixedZone("UTC+8", 8*3600)
+	var klines []Kline
+	for i := 0; i < 10; i++ {
+		ts := time.Date(2020, 1, 6+i, 0, 0, 0, 0, loc).Unix()
+		klines = append(klines, Kline{TS: ts, Open: 1, Close: 1, High: float64(i), Low: 1, Vol: 1})
+	}
+
+	result, err := ResampleKlines(klines, KLINE_DAY, KLINE_WEEK)
+	assert.Nil(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, klines[0].TS, result[0].TS)
+	assert.Equal(t, 7.0, result[0].Vol)
+	assert.Equal(t, 6.0, result[0].High)
+	assert.Equal(t, klines[7].TS, result[1].TS)
+
+	result, err = ResampleKlines(klines, KLINE_DAY, KLINE_MONTH)
+	assert.Nil(t, err)
+	assert.Len(t, result, 0)
+}
+
+func TestGetKlinesWithResample(t *testing.T) {
+	caps := &Capabilities{Exchange: "test", KlinePeriods: []KlinePeriod{KLINE_M1, KLINE_H1, KLINE_DAY}, MaxKlineSize: 100}
+
+	var fetched KlinePeriod
+	var fetchedSize int
+	fetch := func(period KlinePeriod, size, since int) ([]Kline, error) {
+		fetched, fetchedSize = period, size
+		var klines []Kline
+		for i := 0; i < size; i++ {
+			klines = append(klines, Kline{TS: int64(i) * period.Seconds(), Vol: 1})
+		}
+		return klines, nil
+	}
+
+	result, err := GetKlinesWithResample(caps, fetch, KLINE_H4, 5, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, KLINE_H1, fetched)
+	assert.Equal(t, 24, fetchedSize)
+	assert.Len(t, result, 5)
+	assert.Equal(t, 4.0, result[0].Vol)
+
+	_, err = GetKlinesWithResample(caps, fetch, KLINE_H1, 5, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, fetchedSize)
+
+	caps.KlinePeriods = []KlinePeriod{KLINE_H1}
+	_, err = GetKlinesWithResample(caps, fetch, KLINE_MONTH, 5, 0)
+	assert.NotNil(t, err)
+}
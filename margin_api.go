@@ -0,0 +1,33 @@
+package exapi
+
+// isolated margin api interface
+type MarginAPI interface {
+	// 获取交易所名称
+	GetExchangeName() string
+	// 设置交易所地址，仅在初始化时设置，不支持后续动态更改
+	SetURL(exurl string)
+	// 获取交易所地址
+	GetURL() string
+
+	// 账户相关
+	// 获取交易对的逐仓杠杆账户，同时计算总资产和净资产
+	GetMarginAccount(pair CurrencyPair) (*MarginAccount, error)
+	// 获取最大可借数量
+	GetMaxBorrowable(pair CurrencyPair, currency Currency) (float64, error)
+	// 借币，返回借币记录id
+	Borrow(pair CurrencyPair, currency Currency, amount string) (string, error)
+	// 还币，loanId为空时按借币时间先后归还
+	Repay(pair CurrencyPair, currency Currency, amount, loanId string) error
+	// 获取借币记录，按时间降序排列
+	GetLoanRecords(pair CurrencyPair, currency Currency) ([]LoanRecord, error)
+
+	// 交易相关，参数与SpotAPI相同
+	MarginLimitBuy(pair CurrencyPair, price, amount string) (*Order, error)
+	MarginLimitSell(pair CurrencyPair, price, amount string) (*Order, error)
+	MarginMarketBuy(pair CurrencyPair, amount string) (*Order, error)
+	MarginMarketSell(pair CurrencyPair, amount string) (*Order, error)
+	// 撤单
+	CancelMarginOrder(orderId string, pair CurrencyPair) (bool, error)
+	// 获取订单详情
+	GetMarginOrder(orderId string, pair CurrencyPair) (*Order, error)
+}
@@ -0,0 +1,65 @@
+package exapi
+
+// 借币记录状态
+type LoanStatus int
+
+const (
+	LOAN_ACTIVE  LoanStatus = 1 + iota // 未还清
+	LOAN_CLEARED                       // 已还清
+	LOAN_FAILED                        // 借币失败
+)
+
+func (ls LoanStatus) String() string {
+	switch ls {
+	case LOAN_ACTIVE:
+		return "ACTIVE"
+	case LOAN_CLEARED:
+		return "CLEARED"
+	case LOAN_FAILED:
+		return "FAILED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// 借币记录
+type LoanRecord struct {
+	LoanId          string       `json:"loan_id"`                 // 借币记录id
+	Market          CurrencyPair `json:"market"`                  // 交易对
+	Symbol          string       `json:"symbol"`                  // 交易对
+	Currency        Currency     `json:"currency"`                // 借入币种
+	Amount          float64      `json:"amount,string"`           // 借币数量
+	Balance         float64      `json:"balance,string"`          // 未还数量
+	InterestRate    float64      `json:"interest_rate,string"`    // 利率
+	Interest        float64      `json:"interest,string"`         // 累计利息
+	InterestBalance float64      `json:"interest_balance,string"` // 未还利息
+	Status          LoanStatus   `json:"status"`                  // 状态
+	TS              int64        `json:"ts"`                      // 借币时间，单位为毫秒(millisecond)
+}
+
+/*
+逐仓杠杆账户，每个交易对一个账户。
+SubAccounts中LoanAmount为未还的借币数量与利息之和，
+Asset和NetAsset以交易对的计价货币计价。
+*/
+type MarginAccount struct {
+	Account
+	Market           CurrencyPair `json:"market"`                   // 交易对
+	Symbol           string       `json:"symbol"`                   // 交易对
+	RiskRate         float64      `json:"risk_rate,string"`         // 风险率
+	LiquidationPrice float64      `json:"liquidation_price,string"` // 爆仓价
+}
+
+// 按基础货币的价格计算总资产和净资产，price为1个基础货币兑换的计价货币数量
+func (ma *MarginAccount) Valuate(price float64) {
+	ma.Asset, ma.NetAsset = 0, 0
+	for currency, sub := range ma.SubAccounts {
+		rate := 1.0
+		if currency.Equal(ma.Market.Stock) {
+			rate = price
+		}
+		total := (sub.Amount + sub.FrozenAmount) * rate
+		ma.Asset += total
+		ma.NetAsset += total - sub.LoanAmount*rate
+	}
+}
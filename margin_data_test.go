@@ -0,0 +1,22 @@
+package exapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMarginAccountValuate(t *testing.T) {
+	btc := NewCurrency("BTC")
+	pair := NewCurrencyPair(btc, USDT)
+	ma := &MarginAccount{
+		Account: Account{SubAccounts: map[Currency]SubAccount{
+			btc:  {Currency: btc, Amount: 1.5, FrozenAmount: 0.5, LoanAmount: 1},
+			USDT: {Currency: USDT, Amount: 1000, LoanAmount: 200},
+		}},
+		Market: pair,
+	}
+
+	ma.Valuate(10000)
+	assert.InDelta(t, 21000, ma.Asset, 1e-9)
+	assert.InDelta(t, 10800, ma.NetAsset, 1e-9)
+}
@@ -0,0 +1,106 @@
+package exapi
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// 测试用的现货接口，只实现了测试用到的方法，按字段配置返回的数据
+type mockSpotAPI struct {
+	SpotAPI
+	sync.Mutex
+	name      string
+	ssm       map[string]SymbolSetting
+	depth     *Depth
+	acc       *Account
+	fillRatio float64 // 下单后立即成交的比例
+	reject    bool    // 下单返回错误
+	placed    int     // 下单次数
+	orders    map[string]*Order
+	deals     []OrderDeal
+	dealDelay time.Duration // GetOrderDeal的耗时
+}
+
+func newMockSpotAPI(name string) *mockSpotAPI {
+	return &mockSpotAPI{name: name, orders: make(map[string]*Order)}
+}
+
+func (api *mockSpotAPI) GetExchangeName() string {
+	return api.name
+}
+
+func (api *mockSpotAPI) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
+	return api.ssm, nil
+}
+
+func (api *mockSpotAPI) GetDepth(pair CurrencyPair, size int, step int) (*Depth, error) {
+	return api.depth, nil
+}
+
+func (api *mockSpotAPI) GetAccount() (*Account, error) {
+	return api.acc, nil
+}
+
+func (api *mockSpotAPI) LimitBuy(pair CurrencyPair, price, amount string) (*Order, error) {
+	return api.place(&Order{Market: pair, Price: ToFloat64(price), Amount: ToFloat64(amount), Side: BUY})
+}
+
+func (api *mockSpotAPI) MarketBuy(pair CurrencyPair, amount string) (*Order, error) {
+	return api.place(&Order{Market: pair, Amount: ToFloat64(amount), Side: BUY_MARKET})
+}
+
+// 按fillRatio成交，订单id从1开始递增
+func (api *mockSpotAPI) place(o *Order) (*Order, error) {
+	api.Lock()
+	defer api.Unlock()
+	if api.reject {
+		return nil, errors.New("insufficient balance")
+	}
+	api.placed++
+	o.OrderID = ToString(len(api.orders) + 1)
+	o.DealAmount, o.AvgPrice = o.Amount*api.fillRatio, o.Price
+	switch {
+	case api.fillRatio >= 1:
+		o.Status = ORDER_FINISH
+	case api.fillRatio > 0:
+		o.Status = ORDER_PART_FINISH
+	default:
+		o.Status = ORDER_UNFINISH
+	}
+	api.orders[o.OrderID] = o
+	c := *o
+	return &c, nil
+}
+
+func (api *mockSpotAPI) GetOrder(orderId string, pair CurrencyPair) (*Order, error) {
+	api.Lock()
+	defer api.Unlock()
+	o := *api.orders[orderId]
+	return &o, nil
+}
+
+func (api *mockSpotAPI) Cancel(orderId string, pair CurrencyPair) (bool, error) {
+	api.Lock()
+	defer api.Unlock()
+	api.orders[orderId].Status = ORDER_CANCEL
+	return true, nil
+}
+
+// dealDelay模拟请求耗时，使并发的更新同时查询成交明细
+func (api *mockSpotAPI) GetOrderDeal(orderId string, pair CurrencyPair) ([]OrderDeal, error) {
+	time.Sleep(api.dealDelay)
+	api.Lock()
+	defer api.Unlock()
+	return append([]OrderDeal(nil), api.deals...), nil
+}
+
+// 订单成交amount，更新均价和状态
+func (api *mockSpotAPI) fill(orderId string, amount, price float64, status TradeStatus) {
+	api.Lock()
+	defer api.Unlock()
+	o := api.orders[orderId]
+	o.AvgPrice = (o.DealAmount*o.AvgPrice + amount*price) / (o.DealAmount + amount)
+	o.DealAmount += amount
+	o.Status = status
+}
@@ -0,0 +1,449 @@
+package okex
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/betterjun/exapi"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// okex交割合约别名与合约类型的对应
+var _INERNAL_FUTURE_ALIAS_CONVERTER = map[string]string{
+	"this_week":  THIS_WEEK_CONTRACT,
+	"next_week":  NEXT_WEEK_CONTRACT,
+	"quarter":    QUARTER_CONTRACT,
+	"bi_quarter": "bi_quarter",
+}
+
+type OKExFuture struct {
+	spot *OKExSpot
+
+	// 合约类型到合约id的缓存，合约交割后需要刷新
+	mutex     sync.Mutex
+	contracts []FutureContract
+	updatedAt time.Time
+}
+
+/**
+ * future
+ */
+func NewFutureAPI(client *http.Client, apiKey, secretKey, apiPass string) FutureRestAPI {
+	return &OKExFuture{
+		spot: &OKExSpot{
+			HttpClient:    client,
+			Endpoint:      "https://www.okex.com",
+			ApiKey:        apiKey,
+			ApiSecretKey:  secretKey,
+			ApiPassphrase: apiPass,
+		},
+	}
+}
+
+func (ok *OKExFuture) GetExchangeName() string {
+	return OKEX_FUTURE
+}
+
+func (ok *OKExFuture) SetURL(exurl string) {
+	ok.spot.Endpoint = exurl
+}
+
+func (ok *OKExFuture) GetURL() string {
+	return ok.spot.Endpoint
+}
+
+func (ok *OKExFuture) GetFutureContracts() ([]FutureContract, error) {
+	urlPath := "/api/futures/v3/instruments"
+	var response []struct {
+		InstrumentId    string  `json:"instrument_id"`
+		UnderlyingIndex string  `json:"underlying_index"`
+		QuoteCurrency   string  `json:"quote_currency"`
+		TickSize        float64 `json:"tick_size,string"`
+		ContractVal     float64 `json:"contract_val,string"`
+		Delivery        string  `json:"delivery"`
+		Alias           string  `json:"alias"`
+	}
+	err := ok.spot.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	contracts := make([]FutureContract, 0, len(response))
+	for _, v := range response {
+		delivery, _ := time.Parse("2006-01-02", v.Delivery)
+		contracts = append(contracts, FutureContract{
+			ContractId:   v.InstrumentId,
+			ContractType: _INERNAL_FUTURE_ALIAS_CONVERTER[v.Alias],
+			Market:       NewCurrencyPair(CanonicalCurrency(OKEX, v.UnderlyingIndex), CanonicalCurrency(OKEX, v.QuoteCurrency)),
+			ContractVal:  v.ContractVal,
+			TickSize:     v.TickSize,
+			DeliveryDate: delivery.UnixNano() / int64(time.Millisecond),
+		})
+	}
+
+	ok.mutex.Lock()
+	ok.contracts = contracts
+	ok.updatedAt = time.Now()
+	ok.mutex.Unlock()
+
+	return contracts, nil
+}
+
+// 获取合约id，如BTC-USD-200925
+func (ok *OKExFuture) getInstrumentId(pair CurrencyPair, contractType string) (string, error) {
+	ok.mutex.Lock()
+	contracts := ok.contracts
+	// 合约每周五交割，缓存一小时
+	expired := contracts == nil || time.Since(ok.updatedAt) > time.Hour
+	ok.mutex.Unlock()
+
+	if expired {
+		var err error
+		contracts, err = ok.GetFutureContracts()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	for _, c := range contracts {
+		if c.Market.Equal(pair) && c.ContractType == contractType {
+			return c.ContractId, nil
+		}
+	}
+
+	return "", fmt.Errorf("contract not found, pair:%v, contract type:%v", pair, contractType)
+}
+
+func (ok *OKExFuture) GetFutureTicker(pair CurrencyPair, contractType string) (*FutureTicker, error) {
+	instrumentId, err := ok.getInstrumentId(pair, contractType)
+	if err != nil {
+		return nil, err
+	}
+
+	urlPath := fmt.Sprintf("/api/futures/v3/instruments/%s/ticker", instrumentId)
+	var response struct {
+		Last      float64 `json:"last,string"`
+		High24h   float64 `json:"high_24h,string"`
+		Low24h    float64 `json:"low_24h,string"`
+		BestBid   float64 `json:"best_bid,string"`
+		BestAsk   float64 `json:"best_ask,string"`
+		Volume24h float64 `json:"volume_24h,string"`
+		Timestamp string  `json:"timestamp"`
+	}
+	err = ok.spot.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	date, _ := time.Parse(time.RFC3339, response.Timestamp)
+	return &FutureTicker{
+		Ticker: &Ticker{
+			Market: pair,
+			Symbol: pair.ToLowerSymbol("/"),
+			Last:   response.Last,
+			High:   response.High24h,
+			Low:    response.Low24h,
+			Vol:    response.Volume24h,
+			Buy:    response.BestBid,
+			Sell:   response.BestAsk,
+			TS:     date.UnixNano() / int64(time.Millisecond)},
+		ContractType: contractType,
+	}, nil
+}
+
+func (ok *OKExFuture) GetFutureDepth(pair CurrencyPair, contractType string, size int) (*Depth, error) {
+	instrumentId, err := ok.getInstrumentId(pair, contractType)
+	if err != nil {
+		return nil, err
+	}
+
+	urlPath := fmt.Sprintf("/api/futures/v3/instruments/%s/book?size=%d", instrumentId, size)
+	var response struct {
+		Asks      [][]interface{} `json:"asks"`
+		Bids      [][]interface{} `json:"bids"`
+		Timestamp string          `json:"timestamp"`
+	}
+	err = ok.spot.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	dep := new(Depth)
+	dep.ContractType = contractType
+	dep.Market = pair
+	dep.Symbol = pair.ToLowerSymbol("/")
+	t, _ := time.Parse(time.RFC3339, response.Timestamp)
+	dep.TS = t.UnixNano() / int64(time.Millisecond)
+
+	for _, itm := range response.Asks {
+		dep.AskList = append(dep.AskList, DepthRecord{
+			Price:  ToFloat64(itm[0]),
+			Amount: ToFloat64(itm[1]),
+		})
+	}
+
+	for _, itm := range response.Bids {
+		dep.BidList = append(dep.BidList, DepthRecord{
+			Price:  ToFloat64(itm[0]),
+			Amount: ToFloat64(itm[1]),
+		})
+	}
+
+	return dep, nil
+}
+
+func (ok *OKExFuture) PlaceFutureOrder(pair CurrencyPair, contractType, price, amount string, openType, matchPrice, leverRate int) (*FutureOrder, error) {
+	instrumentId, err := ok.getInstrumentId(pair, contractType)
+	if err != nil {
+		return nil, err
+	}
+
+	param := struct {
+		ClientOid    string `json:"client_oid"`
+		InstrumentId string `json:"instrument_id"`
+		Type         string `json:"type"`
+		Price        string `json:"price,omitempty"`
+		Size         string `json:"size"`
+		MatchPrice   string `json:"match_price"`
+		Leverage     string `json:"leverage,omitempty"`
+	}{
+		ClientOid:    ok.spot.uuid(),
+		InstrumentId: instrumentId,
+		Type:         fmt.Sprint(openType),
+		Price:        price,
+		Size:         amount,
+		MatchPrice:   fmt.Sprint(matchPrice),
+	}
+	// 杠杆倍数随订单发送，为0时不发送，使用账户设置的杠杆倍数
+	if leverRate > 0 {
+		param.Leverage = fmt.Sprint(leverRate)
+	}
+
+	var response placeOrderResponse
+	jsonStr, _, _ := ok.spot.buildRequestBody(param)
+	err = ok.spot.doRequest("POST", "/api/futures/v3/order", jsonStr, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if !response.Result {
+		return nil, errors.New(response.ErrorMessage)
+	}
+
+	return &FutureOrder{
+		OrderID2:     response.OrderId,
+		OrderID:      ToInt64(response.OrderId),
+		Price:        ToFloat64(price),
+		Amount:       ToFloat64(amount),
+		OrderTime:    time.Now().UnixNano() / int64(time.Millisecond),
+		Status:       ORDER_UNFINISH,
+		Currency:     pair,
+		OType:        openType,
+		LeverRate:    leverRate,
+		ContractName: instrumentId,
+	}, nil
+}
+
+func (ok *OKExFuture) CancelFutureOrder(orderId string, pair CurrencyPair, contractType string) (bool, error) {
+	instrumentId, err := ok.getInstrumentId(pair, contractType)
+	if err != nil {
+		return false, err
+	}
+
+	urlPath := fmt.Sprintf("/api/futures/v3/cancel_order/%s/%s", instrumentId, orderId)
+	var response placeOrderResponse
+	err = ok.spot.doRequest("POST", urlPath, "{}", &response)
+	if err != nil {
+		return false, err
+	}
+
+	if !response.Result {
+		return false, errors.New(response.ErrorMessage)
+	}
+
+	return true, nil
+}
+
+type futureOrderResponse struct {
+	InstrumentId string  `json:"instrument_id"`
+	OrderId      string  `json:"order_id"`
+	Price        float64 `json:"price,string"`
+	PriceAvg     float64 `json:"price_avg,string"`
+	Size         float64 `json:"size,string"`
+	FilledQty    float64 `json:"filled_qty,string"`
+	Fee          float64 `json:"fee,string"`
+	Type         int     `json:"type,string"`
+	State        int     `json:"state,string"`
+	Leverage     float64 `json:"leverage,string"`
+	Timestamp    string  `json:"timestamp"`
+}
+
+func (ok *OKExFuture) adaptOrder(response *futureOrderResponse, pair CurrencyPair) FutureOrder {
+	date, _ := time.Parse(time.RFC3339, response.Timestamp)
+	return FutureOrder{
+		OrderID2:     response.OrderId,
+		OrderID:      ToInt64(response.OrderId),
+		Price:        response.Price,
+		Amount:       response.Size,
+		AvgPrice:     response.PriceAvg,
+		DealAmount:   response.FilledQty,
+		OrderTime:    date.UnixNano() / int64(time.Millisecond),
+		Status:       ok.spot.adaptOrderState(response.State),
+		Currency:     pair,
+		OType:        response.Type,
+		LeverRate:    int(response.Leverage),
+		Fee:          response.Fee,
+		ContractName: response.InstrumentId,
+	}
+}
+
+func (ok *OKExFuture) GetFutureOrder(orderId string, pair CurrencyPair, contractType string) (*FutureOrder, error) {
+	instrumentId, err := ok.getInstrumentId(pair, contractType)
+	if err != nil {
+		return nil, err
+	}
+
+	urlPath := fmt.Sprintf("/api/futures/v3/orders/%s/%s", instrumentId, orderId)
+	var response futureOrderResponse
+	err = ok.spot.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	ord := ok.adaptOrder(&response, pair)
+	return &ord, nil
+}
+
+func (ok *OKExFuture) GetUnfinishFutureOrders(pair CurrencyPair, contractType string) ([]FutureOrder, error) {
+	instrumentId, err := ok.getInstrumentId(pair, contractType)
+	if err != nil {
+		return nil, err
+	}
+
+	// state=6 未完成（等待成交+部分成交）
+	urlPath := fmt.Sprintf("/api/futures/v3/orders/%s?state=6", instrumentId)
+	var response struct {
+		Result    bool                  `json:"result"`
+		OrderInfo []futureOrderResponse `json:"order_info"`
+	}
+	err = ok.spot.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]FutureOrder, 0, len(response.OrderInfo))
+	for i := range response.OrderInfo {
+		orders = append(orders, ok.adaptOrder(&response.OrderInfo[i], pair))
+	}
+
+	return orders, nil
+}
+
+func (ok *OKExFuture) GetFuturePosition(pair CurrencyPair, contractType string) ([]FuturePosition, error) {
+	instrumentId, err := ok.getInstrumentId(pair, contractType)
+	if err != nil {
+		return nil, err
+	}
+
+	urlPath := fmt.Sprintf("/api/futures/v3/%s/position", instrumentId)
+	var response struct {
+		Result  bool `json:"result"`
+		Holding []struct {
+			InstrumentId     string  `json:"instrument_id"`
+			LongQty          float64 `json:"long_qty,string"`
+			LongAvailQty     float64 `json:"long_avail_qty,string"`
+			LongAvgCost      float64 `json:"long_avg_cost,string"`
+			LongPnl          float64 `json:"long_pnl,string"`
+			ShortQty         float64 `json:"short_qty,string"`
+			ShortAvailQty    float64 `json:"short_avail_qty,string"`
+			ShortAvgCost     float64 `json:"short_avg_cost,string"`
+			ShortPnl         float64 `json:"short_pnl,string"`
+			LiquidationPrice float64 `json:"liquidation_price,string"`
+			Leverage         float64 `json:"leverage,string"`
+			CreatedAt        string  `json:"created_at"`
+		} `json:"holding"`
+	}
+	err = ok.spot.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make([]FuturePosition, 0, len(response.Holding))
+	for _, v := range response.Holding {
+		date, _ := time.Parse(time.RFC3339, v.CreatedAt)
+		positions = append(positions, FuturePosition{
+			BuyAmount:      v.LongQty,
+			BuyAvailable:   v.LongAvailQty,
+			BuyPriceAvg:    v.LongAvgCost,
+			BuyPriceCost:   v.LongAvgCost,
+			BuyProfitReal:  v.LongPnl,
+			SellAmount:     v.ShortQty,
+			SellAvailable:  v.ShortAvailQty,
+			SellPriceAvg:   v.ShortAvgCost,
+			SellPriceCost:  v.ShortAvgCost,
+			SellProfitReal: v.ShortPnl,
+			CreateDate:     date.UnixNano() / int64(time.Millisecond),
+			LeverRate:      int(v.Leverage),
+			Symbol:         pair,
+			ContractType:   contractType,
+			ForceLiquPrice: v.LiquidationPrice,
+		})
+	}
+
+	return positions, nil
+}
+
+func (ok *OKExFuture) GetFutureAccount() (*FutureAccount, error) {
+	urlPath := "/api/futures/v3/accounts"
+	var response struct {
+		Info map[string]struct {
+			Equity        float64 `json:"equity,string"`
+			Margin        float64 `json:"margin,string"`
+			RealizedPnl   float64 `json:"realized_pnl,string"`
+			UnrealizedPnl float64 `json:"unrealized_pnl,string"`
+			MarginRatio   float64 `json:"margin_ratio,string"`
+		} `json:"info"`
+	}
+	err := ok.spot.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	acc := &FutureAccount{FutureSubAccounts: make(map[Currency]FutureSubAccount, len(response.Info))}
+	for k, v := range response.Info {
+		// 键可能为币种btc，也可能为标的指数btc-usd
+		currency := CanonicalCurrency(OKEX, strings.Split(k, "-")[0])
+		acc.FutureSubAccounts[currency] = FutureSubAccount{
+			Currency:      currency,
+			AccountRights: v.Equity,
+			KeepDeposit:   v.Margin,
+			ProfitReal:    v.RealizedPnl,
+			ProfitUnreal:  v.UnrealizedPnl,
+			RiskRate:      v.MarginRatio,
+		}
+	}
+
+	return acc, nil
+}
+
+// 全仓模式下设置杠杆倍数，对标的下所有合约生效
+func (ok *OKExFuture) SetFutureLeverage(pair CurrencyPair, contractType string, leverRate int) error {
+	urlPath := fmt.Sprintf("/api/futures/v3/accounts/%s/leverage", ExchangeLowerSymbol(OKEX, pair, "-"))
+	var response struct {
+		Result    string `json:"result"`
+		ErrorCode string `json:"error_code"`
+		ErrorMsg  string `json:"error_message"`
+	}
+	err := ok.spot.doRequest("POST", urlPath, fmt.Sprintf(`{"leverage":"%d"}`, leverRate), &response)
+	if err != nil {
+		return err
+	}
+
+	if response.Result != "true" {
+		return fmt.Errorf("code:%v, msg:%v", response.ErrorCode, response.ErrorMsg)
+	}
+
+	return nil
+}
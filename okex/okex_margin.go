@@ -0,0 +1,239 @@
+package okex
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/betterjun/exapi"
+	"net/http"
+	"strings"
+	"time"
+)
+
+/**
+ * isolated margin
+ */
+func NewMarginAPI(client *http.Client, apiKey, secretKey, apiPass string) MarginAPI {
+	return NewSpotAPI(client, apiKey, secretKey, apiPass).(*OKExSpot)
+}
+
+func (ok *OKExSpot) GetMarginAccount(pair CurrencyPair) (*MarginAccount, error) {
+	urlPath := "/api/margin/v3/accounts/" + ExchangeSymbol(OKEX, pair, "-")
+	// 币种余额的键为currency:BTC
+	var response map[string]interface{}
+	err := ok.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	acc := &MarginAccount{
+		Account: Account{
+			Exchange:    ok.GetExchangeName(),
+			SubAccounts: make(map[Currency]SubAccount, 2),
+		},
+		Market:           pair,
+		Symbol:           pair.ToLowerSymbol("/"),
+		RiskRate:         ToFloat64(response["risk_rate"]),
+		LiquidationPrice: ToFloat64(response["liquidation_price"]),
+	}
+	for k, v := range response {
+		if !strings.HasPrefix(k, "currency:") {
+			continue
+		}
+
+		obj, _ := v.(map[string]interface{})
+		currency := CanonicalCurrency(OKEX, strings.TrimPrefix(k, "currency:"))
+		acc.SubAccounts[currency] = SubAccount{
+			Currency:     currency,
+			Amount:       ToFloat64(obj["available"]),
+			FrozenAmount: ToFloat64(obj["hold"]),
+			LoanAmount:   ToFloat64(obj["borrowed"]) + ToFloat64(obj["lending_fee"]),
+		}
+	}
+
+	ticker, err := ok.GetTicker(pair)
+	if err != nil {
+		return nil, err
+	}
+	acc.Valuate(ticker.Last)
+
+	return acc, nil
+}
+
+func (ok *OKExSpot) GetMaxBorrowable(pair CurrencyPair, currency Currency) (float64, error) {
+	urlPath := fmt.Sprintf("/api/margin/v3/accounts/%s/availability", ExchangeSymbol(OKEX, pair, "-"))
+	var response []map[string]interface{}
+	err := ok.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, v := range response {
+		if obj, exist := v["currency:"+ExchangeCurrency(OKEX, currency).Symbol()].(map[string]interface{}); exist {
+			return ToFloat64(obj["available"]), nil
+		}
+	}
+
+	return 0, ErrorAssetNotFound
+}
+
+func (ok *OKExSpot) Borrow(pair CurrencyPair, currency Currency, amount string) (string, error) {
+	param := struct {
+		InstrumentId string `json:"instrument_id"`
+		Currency     string `json:"currency"`
+		Amount       string `json:"amount"`
+	}{ExchangeSymbol(OKEX, pair, "-"), ExchangeCurrency(OKEX, currency).Symbol(), amount}
+
+	var response struct {
+		BorrowId string `json:"borrow_id"`
+		Result   bool   `json:"result"`
+	}
+	reqBody, _, _ := ok.buildRequestBody(param)
+	err := ok.doRequest("POST", "/api/margin/v3/accounts/borrow", reqBody, &response)
+	if err != nil {
+		return "", err
+	}
+
+	if !response.Result {
+		return "", errors.New("borrow failed")
+	}
+
+	return response.BorrowId, nil
+}
+
+// loanId为空时由交易所按借币时间先后归还
+func (ok *OKExSpot) Repay(pair CurrencyPair, currency Currency, amount, loanId string) error {
+	param := struct {
+		BorrowId     string `json:"borrow_id,omitempty"`
+		InstrumentId string `json:"instrument_id"`
+		Currency     string `json:"currency"`
+		Amount       string `json:"amount"`
+	}{loanId, ExchangeSymbol(OKEX, pair, "-"), ExchangeCurrency(OKEX, currency).Symbol(), amount}
+
+	var response struct {
+		Result bool `json:"result"`
+	}
+	reqBody, _, _ := ok.buildRequestBody(param)
+	err := ok.doRequest("POST", "/api/margin/v3/accounts/repayment", reqBody, &response)
+	if err != nil {
+		return err
+	}
+
+	if !response.Result {
+		return errors.New("repay failed")
+	}
+
+	return nil
+}
+
+func (ok *OKExSpot) GetLoanRecords(pair CurrencyPair, currency Currency) ([]LoanRecord, error) {
+	urlPath := fmt.Sprintf("/api/margin/v3/accounts/%s/borrowed", ExchangeSymbol(OKEX, pair, "-"))
+	var response []struct {
+		BorrowId        string  `json:"borrow_id"`
+		Currency        string  `json:"currency"`
+		Amount          float64 `json:"amount,string"`
+		RepayedAmount   float64 `json:"repayed_amount,string"`
+		Interest        float64 `json:"interest,string"`
+		RepayedInterest float64 `json:"repayed_interest,string"`
+		Rate            float64 `json:"rate,string"`
+		CreatedAt       string  `json:"created_at"`
+	}
+	err := ok.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]LoanRecord, 0, len(response))
+	for _, v := range response {
+		if !CanonicalCurrency(OKEX, v.Currency).Equal(currency) {
+			continue
+		}
+
+		date, _ := time.Parse(time.RFC3339, v.CreatedAt)
+		record := LoanRecord{
+			LoanId:          v.BorrowId,
+			Market:          pair,
+			Symbol:          pair.ToLowerSymbol("/"),
+			Currency:        currency,
+			Amount:          v.Amount,
+			Balance:         v.Amount - v.RepayedAmount,
+			InterestRate:    v.Rate,
+			Interest:        v.Interest,
+			InterestBalance: v.Interest - v.RepayedInterest,
+			Status:          LOAN_ACTIVE,
+			TS:              date.UnixNano() / int64(time.Millisecond),
+		}
+		if record.Balance <= 0 && record.InterestBalance <= 0 {
+			record.Status = LOAN_CLEARED
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func (ok *OKExSpot) MarginLimitBuy(pair CurrencyPair, price, amount string) (*Order, error) {
+	return ok.submitOrder("/api/margin/v3/orders", "2", "limit", &Order{
+		Price:  ToFloat64(price),
+		Amount: ToFloat64(amount),
+		Market: pair,
+		Symbol: pair.ToLowerSymbol("/"),
+		Side:   BUY,
+	})
+}
+
+func (ok *OKExSpot) MarginLimitSell(pair CurrencyPair, price, amount string) (*Order, error) {
+	return ok.submitOrder("/api/margin/v3/orders", "2", "limit", &Order{
+		Price:  ToFloat64(price),
+		Amount: ToFloat64(amount),
+		Market: pair,
+		Symbol: pair.ToLowerSymbol("/"),
+		Side:   SELL,
+	})
+}
+
+func (ok *OKExSpot) MarginMarketBuy(pair CurrencyPair, amount string) (*Order, error) {
+	return ok.submitOrder("/api/margin/v3/orders", "2", "market", &Order{
+		Amount: ToFloat64(amount),
+		Market: pair,
+		Symbol: pair.ToLowerSymbol("/"),
+		Side:   BUY_MARKET,
+	})
+}
+
+func (ok *OKExSpot) MarginMarketSell(pair CurrencyPair, amount string) (*Order, error) {
+	return ok.submitOrder("/api/margin/v3/orders", "2", "market", &Order{
+		Amount: ToFloat64(amount),
+		Market: pair,
+		Symbol: pair.ToLowerSymbol("/"),
+		Side:   SELL_MARKET,
+	})
+}
+
+func (ok *OKExSpot) CancelMarginOrder(orderId string, pair CurrencyPair) (bool, error) {
+	urlPath := "/api/margin/v3/cancel_orders/" + orderId
+	param := struct {
+		InstrumentId string `json:"instrument_id"`
+	}{ExchangeLowerSymbol(OKEX, pair, "-")}
+	reqBody, _, _ := ok.buildRequestBody(param)
+	var response struct {
+		OrderId string `json:"order_id"`
+		Result  bool   `json:"result"`
+	}
+	err := ok.doRequest("POST", urlPath, reqBody, &response)
+	if err != nil {
+		return false, err
+	}
+
+	return response.Result, nil
+}
+
+func (ok *OKExSpot) GetMarginOrder(orderId string, pair CurrencyPair) (*Order, error) {
+	urlPath := "/api/margin/v3/orders/" + orderId + "?instrument_id=" + ExchangeSymbol(OKEX, pair, "-")
+	var response map[string]interface{}
+	err := ok.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return ok.parseOrder(response, pair), nil
+}
@@ -33,6 +33,9 @@ type OKExSpot struct {
 	ApiKey        string
 	ApiSecretKey  string
 	ApiPassphrase string //for okex.com v3 api
+	TradePassword string //资金密码，提币时使用
+
+	accountType AccountType // GetAccount读取的账户类型
 }
 
 type placeOrderParam struct {
@@ -145,28 +148,95 @@ func (ok *OKExSpot) GetURL() string {
 	return ok.Endpoint
 }
 
+var spotCapabilities = &Capabilities{
+	Exchange:           OKEX,
+	UnsupportedMethods: []string{"GetUserTrades"},
+	KlinePeriods:       KlinePeriodsOf(_INERNAL_KLINE_PERIOD_CONVERTER),
+	MaxKlineSize:       200,
+	MaxTradeSize:       100,
+	MaxDepthSize:       200,
+	OrderSides:         AllOrderSides,
+	WebsocketStreams:   AllStreams,
+}
+
+func (ok *OKExSpot) Capabilities() *Capabilities {
+	return spotCapabilities.Clone()
+}
+
 /*
 "maker": "0.001",
 "taker": "0.0015",
 "timestamp": "2019-12-05T09:06:20.260Z"
 */
-type TradeFee struct {
+type tradeFeeResponse struct {
 	Maker float64 `json:"maker,string"` // 挂单手续费
 	Taker float64 `json:"taker,string"` // 吃单手续费
 }
 
-func (ok *OKExSpot) GetTradeFee() (tf *TradeFee, err error) {
-	urlPath := "/api/spot/v3/trade_fee"
+func (ok *OKExSpot) GetTradeFee(pair CurrencyPair) (*TradeFee, error) {
+	urlPath := "/api/spot/v3/trade_fee?instrument_id=" + ExchangeSymbol(OKEX, pair, "-")
+
+	var response tradeFeeResponse
+	err := ok.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TradeFee{
+		Symbol:    pair.ToSymbol("/"),
+		MakerRate: response.Maker,
+		TakerRate: response.Taker,
+	}, nil
+}
+
+// okex按账户等级收取手续费，所有币对使用账户等级对应的费率
+func (ok *OKExSpot) GetTradeFeeMap() (map[string]TradeFee, error) {
+	ssm, err := ok.getSymbolSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	return ok.getTradeFeeMap(ssm)
+}
 
-	tf = &TradeFee{}
-	err = ok.doRequest("GET", urlPath, "", tf)
+func (ok *OKExSpot) getTradeFeeMap(ssm map[string]SymbolSetting) (map[string]TradeFee, error) {
+	var response tradeFeeResponse
+	err := ok.doRequest("GET", "/api/spot/v3/trade_fee", "", &response)
 	if err != nil {
 		return nil, err
 	}
-	return tf, nil
+
+	tfm := make(map[string]TradeFee, len(ssm))
+	for k := range ssm {
+		tfm[k] = TradeFee{
+			Symbol:    k,
+			MakerRate: response.Maker,
+			TakerRate: response.Taker,
+		}
+	}
+
+	return tfm, nil
 }
 
+// MakerFee和TakerFee为账户的实际费率，未设置apikey或查询费率失败时为公布的费率
 func (ok *OKExSpot) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
+	ssm, err := ok.getSymbolSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ok.ApiKey) > 0 {
+		tfm, err := ok.getTradeFeeMap(ssm)
+		if err == nil {
+			ApplyTradeFee(ssm, tfm)
+		}
+	}
+
+	return ssm, nil
+}
+
+// 获取币对信息，账户费率由GetAllCurrencyPair填入
+func (ok *OKExSpot) getSymbolSettings() (map[string]SymbolSetting, error) {
 	urlPath := "/api/spot/v3/instruments"
 	var response []struct {
 		InstrumentId  string `json:"instrument_id"`
@@ -181,62 +251,55 @@ func (ok *OKExSpot) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
 		return nil, err
 	}
 
-	tf, err := ok.GetTradeFee()
-	if err != nil {
-		tf = &TradeFee{
-			Maker: 0.001,
-			Taker: 0.0015,
-		}
-	}
 	ssm := make(map[string]SymbolSetting)
 	for _, v := range response {
 		symbol := strings.Replace(v.InstrumentId, "-", "/", -1)
+		tickSize := ToFloat64(v.TickSize)
+		stepSize := ToFloat64(v.SizeIncrement)
 		ssm[symbol] = SymbolSetting{
-			Symbol:      symbol,
-			Base:        v.BaseCurrency,
-			Quote:       v.QuoteCurrency,
-			MinSize:     ToFloat64(v.SizeIncrement),
-			MinPrice:    ToFloat64(v.TickSize),
-			MinNotional: ToFloat64(v.MinSize),
-			MakerFee:    tf.Maker,
-			TakerFee:    tf.Taker,
+			Symbol:          symbol,
+			Base:            v.BaseCurrency,
+			Quote:           v.QuoteCurrency,
+			MinSize:         stepSize,
+			MinPrice:        tickSize,
+			MinNotional:     ToFloat64(v.MinSize),
+			MakerFee:        0.001,
+			TakerFee:        0.0015,
+			TickSize:        tickSize,
+			StepSize:        stepSize,
+			PricePrecision:  PrecisionOf(tickSize),
+			AmountPrecision: PrecisionOf(stepSize),
 		}
 	}
 
-	return ssm, nil
+	return CanonicalSymbolSettings(OKEX, ssm), nil
 }
 
 func (ok *OKExSpot) GetCurrencyStatus(currency Currency) (CurrencyStatus, error) {
-	urlPath := "/api/account/v3/currencies"
-	var response []struct {
-		Currency    string `json:"currency"`
-		CanDeposit  string `json:"can_deposit"`  // 是否可充值，0表示不可充值，1表示可以充值
-		CanWithdraw string `json:"can_withdraw"` // 是否可提币，0表示不可提币，1表示可以提币
-	}
-
-	err := ok.doRequest("GET", urlPath, "", &response)
+	all, err := ok.GetAllCurrencyStatus()
 	if err != nil {
 		return CurrencyStatus{}, err
 	}
 
-	for _, v := range response {
-		if v.Currency == currency.Symbol() {
-			return CurrencyStatus{
-				Deposit:  ToBool(v.CanDeposit),
-				Withdraw: ToBool(v.CanWithdraw),
-			}, nil
-		}
+	cs, exist := all[currency.Symbol()]
+	if !exist {
+		return CurrencyStatus{}, errors.New("Asset not found")
 	}
 
-	return CurrencyStatus{}, errors.New("Asset not found")
+	return cs, nil
 }
 
+/*
+币种信息中，多链币种除了币种本身外还有 币种-链 的记录，如USDT-ERC20，
+提币手续费同样按 币种-链 返回，这里按币种合并为各链的充提信息。
+*/
 func (ok *OKExSpot) GetAllCurrencyStatus() (all map[string]CurrencyStatus, err error) {
 	urlPath := "/api/account/v3/currencies"
 	var response []struct {
-		Currency    string `json:"currency"`
-		CanDeposit  string `json:"can_deposit"`  // 是否可充值，0表示不可充值，1表示可以充值
-		CanWithdraw string `json:"can_withdraw"` // 是否可提币，0表示不可提币，1表示可以提币
+		Currency      string  `json:"currency"`
+		CanDeposit    string  `json:"can_deposit"`  // 是否可充值，0表示不可充值，1表示可以充值
+		CanWithdraw   string  `json:"can_withdraw"` // 是否可提币，0表示不可提币，1表示可以提币
+		MinWithdrawal float64 `json:"min_withdrawal,string"`
 	}
 
 	err = ok.doRequest("GET", urlPath, "", &response)
@@ -244,19 +307,49 @@ func (ok *OKExSpot) GetAllCurrencyStatus() (all map[string]CurrencyStatus, err e
 		return nil, err
 	}
 
+	var fees []struct {
+		Currency string  `json:"currency"`
+		MinFee   float64 `json:"min_fee,string"`
+	}
+	err = ok.doRequest("GET", "/api/account/v3/withdrawal/fee", "", &fees)
+	if err != nil {
+		return nil, err
+	}
+
+	feeMap := make(map[string]float64)
+	for _, v := range fees {
+		feeMap[v.Currency] = v.MinFee
+	}
+
 	all = make(map[string]CurrencyStatus)
 	for _, v := range response {
-		all[v.Currency] = CurrencyStatus{
-			Deposit:  ToBool(v.CanDeposit),
-			Withdraw: ToBool(v.CanWithdraw),
+		cs := ChainStatus{
+			Chain:       v.Currency,
+			Deposit:     ToBool(v.CanDeposit),
+			Withdraw:    ToBool(v.CanWithdraw),
+			WithdrawFee: feeMap[v.Currency],
+			MinWithdraw: v.MinWithdrawal,
+		}
+
+		currency := v.Currency
+		if i := strings.Index(v.Currency, "-"); i > 0 {
+			currency = v.Currency[:i]
+		} else {
+			cs.IsDefault = true
 		}
+
+		status := all[currency]
+		status.Deposit = status.Deposit || cs.Deposit
+		status.Withdraw = status.Withdraw || cs.Withdraw
+		status.Chains = append(status.Chains, cs)
+		all[currency] = status
 	}
 
-	return all, nil
+	return CanonicalCurrencyStatus(OKEX, all), nil
 }
 
 func (ok *OKExSpot) GetTicker(pair CurrencyPair) (*Ticker, error) {
-	urlPath := fmt.Sprintf("/api/spot/v3/instruments/%s/ticker", pair.ToSymbol("-"))
+	urlPath := fmt.Sprintf("/api/spot/v3/instruments/%s/ticker", ExchangeSymbol(OKEX, pair, "-"))
 	var response struct {
 		Open24h       float64 `json:"open_24h,string"`
 		Last          float64 `json:"last,string"`
@@ -309,7 +402,7 @@ func (ok *OKExSpot) GetAllTicker() ([]Ticker, error) {
 	for _, res := range responses {
 		date, _ := time.Parse(time.RFC3339, res.Timestamp)
 		arr := strings.Split(res.InstrumentID, "-")
-		pair := NewCurrencyPairFromString(strings.Join(arr, "/"))
+		pair := CanonicalPair(OKEX, NewCurrencyPairFromString(strings.Join(arr, "/")))
 		tickers = append(tickers, Ticker{
 			Market: pair,
 			Symbol: pair.ToLowerSymbol("/"),
@@ -327,7 +420,7 @@ func (ok *OKExSpot) GetAllTicker() ([]Ticker, error) {
 }
 
 func (ok *OKExSpot) GetDepth(pair CurrencyPair, size int, step int) (*Depth, error) {
-	urlPath := fmt.Sprintf("/api/spot/v3/instruments/%s/book?size=%d", pair.ToSymbol("-"), size)
+	urlPath := fmt.Sprintf("/api/spot/v3/instruments/%s/book?size=%d", ExchangeSymbol(OKEX, pair, "-"), size)
 
 	var response struct {
 		Asks      [][]interface{} `json:"asks"`
@@ -366,7 +459,7 @@ func (ok *OKExSpot) GetDepth(pair CurrencyPair, size int, step int) (*Depth, err
 }
 
 func (ok *OKExSpot) GetTrades(pair CurrencyPair, size int) ([]Trade, error) {
-	urlPath := fmt.Sprintf("/api/spot/v3/instruments/%s/trades", pair.ToSymbol("-"))
+	urlPath := fmt.Sprintf("/api/spot/v3/instruments/%s/trades", ExchangeSymbol(OKEX, pair, "-"))
 	var response []struct {
 		Time      string  `json:"time"`
 		Timestamp string  `json:"timestamp"`
@@ -406,7 +499,10 @@ func (ok *OKExSpot) GetTrades(pair CurrencyPair, size int) ([]Trade, error) {
 func (ok *OKExSpot) GetKlineRecords(pair CurrencyPair, period KlinePeriod, size, since int) ([]Kline, error) {
 	granularity, isOk := _INERNAL_KLINE_PERIOD_CONVERTER[period]
 	if isOk != true {
-		return nil, fmt.Errorf("unsupported %v KlinePeriod:%v", ok.GetExchangeName(), period)
+		// 交易所不支持的周期，用较小周期的K线合成
+		return GetKlinesWithResample(spotCapabilities, func(p KlinePeriod, n, t int) ([]Kline, error) {
+			return ok.GetKlineRecords(pair, p, n, t)
+		}, period, size, since)
 	}
 
 	urlPath := "/api/spot/v3/instruments/%s/candles?granularity=%d"
@@ -419,8 +515,25 @@ func (ok *OKExSpot) GetKlineRecords(pair CurrencyPair, period KlinePeriod, size,
 		urlPath += "&start=" + sinceTime.Format(time.RFC3339)
 	}
 
+	return ok.getKlines(pair, fmt.Sprintf(urlPath, ExchangeSymbol(OKEX, pair, "-"), granularity))
+}
+
+// OKEx按区间获取K线时的请求间隔，行情接口限速为2秒20次
+const OK_KLINE_RANGE_INTERVAL = 100 * time.Millisecond
+
+func (ok *OKExSpot) GetKlineRange(pair CurrencyPair, period KlinePeriod, start, end time.Time) ([]Kline, []KlineGap, error) {
+	return FetchKlineRange(spotCapabilities, func(p KlinePeriod, from, to int64) ([]Kline, error) {
+		// end为闭区间，减去1秒以免包含下一页的第一根K线
+		urlPath := fmt.Sprintf("/api/spot/v3/instruments/%s/candles?granularity=%d&start=%s&end=%s",
+			ExchangeSymbol(OKEX, pair, "-"), _INERNAL_KLINE_PERIOD_CONVERTER[p],
+			time.Unix(from, 0).UTC().Format(time.RFC3339), time.Unix(to-1, 0).UTC().Format(time.RFC3339))
+		return ok.getKlines(pair, urlPath)
+	}, period, start, end, OK_KLINE_RANGE_INTERVAL)
+}
+
+func (ok *OKExSpot) getKlines(pair CurrencyPair, urlPath string) ([]Kline, error) {
 	var response [][]interface{}
-	err := ok.doRequest("GET", fmt.Sprintf(urlPath, pair.ToSymbol("-"), granularity), "", &response)
+	err := ok.doRequest("GET", urlPath, "", &response)
 	if err != nil {
 		return nil, err
 	}
@@ -485,7 +598,7 @@ func (ok *OKExSpot) Cancel(orderId string, pair CurrencyPair) (bool, error) {
 	urlPath := "/api/spot/v3/cancel_orders/" + orderId
 	param := struct {
 		InstrumentId string `json:"instrument_id"`
-	}{pair.ToLowerSymbol("-")}
+	}{ExchangeLowerSymbol(OKEX, pair, "-")}
 	reqBody, _, _ := ok.buildRequestBody(param)
 	var response struct {
 		ClientOid string `json:"client_oid"`
@@ -503,7 +616,7 @@ func (ok *OKExSpot) Cancel(orderId string, pair CurrencyPair) (bool, error) {
 }
 
 func (ok *OKExSpot) GetOrder(orderId string, pair CurrencyPair) (*Order, error) {
-	urlPath := "/api/spot/v3/orders/" + orderId + "?instrument_id=" + pair.ToSymbol("-")
+	urlPath := "/api/spot/v3/orders/" + orderId + "?instrument_id=" + ExchangeSymbol(OKEX, pair, "-")
 	//param := struct {
 	//	InstrumentId string `json:"instrument_id"`
 	//}{pair.AdaptUsdToUsdt().ToLower().ToSymbol("-")}
@@ -520,7 +633,7 @@ func (ok *OKExSpot) GetOrder(orderId string, pair CurrencyPair) (*Order, error)
 }
 
 func (ok *OKExSpot) GetPendingOrders(pair CurrencyPair) ([]Order, error) {
-	urlPath := fmt.Sprintf("/api/spot/v3/orders_pending?instrument_id=%s", pair.ToSymbol("-"))
+	urlPath := fmt.Sprintf("/api/spot/v3/orders_pending?instrument_id=%s", ExchangeSymbol(OKEX, pair, "-"))
 	var response []map[string]interface{}
 	err := ok.doRequest("GET", urlPath, "", &response)
 	if err != nil {
@@ -581,7 +694,7 @@ func (ok *OKExSpot) parseOrder(orderMap map[string]interface{}, pair CurrencyPai
 
 func (ok *OKExSpot) GetFinishedOrders(pair CurrencyPair) ([]Order, error) {
 	// 查询完全成交的订单
-	urlPath := "/api/spot/v3/orders/" + "?instrument_id=" + pair.ToSymbol("-") + "&state=2"
+	urlPath := "/api/spot/v3/orders/" + "?instrument_id=" + ExchangeSymbol(OKEX, pair, "-") + "&state=2"
 
 	var response []map[string]interface{}
 	err := ok.doRequest("GET", urlPath, "", &response)
@@ -600,7 +713,7 @@ func (ok *OKExSpot) GetFinishedOrders(pair CurrencyPair) ([]Order, error) {
 
 func (ok *OKExSpot) GetOrderDeal(orderId string, pair CurrencyPair) ([]OrderDeal, error) {
 	// 查询完全成交的订单
-	urlPath := "/api/spot/v3/fills?order_id=" + orderId + "&instrument_id=" + pair.ToSymbol("-")
+	urlPath := "/api/spot/v3/fills?order_id=" + orderId + "&instrument_id=" + ExchangeSymbol(OKEX, pair, "-")
 
 	var response []dealResponse
 	err := ok.doRequest("GET", urlPath, "", &response)
@@ -611,7 +724,7 @@ func (ok *OKExSpot) GetOrderDeal(orderId string, pair CurrencyPair) ([]OrderDeal
 	deals := make([]OrderDeal, 0, len(response))
 	for _, v := range response {
 		// 一笔成交，会返回两条数据
-		if strings.ToUpper(v.Currency) == pair.Stock.Symbol() {
+		if strings.ToUpper(v.Currency) == ExchangeCurrency(OKEX, pair.Stock).Symbol() {
 			deal := ok.adaptDeal(v)
 			deal.Market = pair
 			deal.Symbol = pair.ToLowerSymbol("/")
@@ -626,7 +739,15 @@ func (ok *OKExSpot) GetUserTrades(currencyPair CurrencyPair) ([]Trade, error) {
 	return nil, ErrorUnsupported
 }
 
+// 读取SetAccountType设置的账户，默认为现货账户
 func (ok *OKExSpot) GetAccount() (*Account, error) {
+	if ok.accountType == 0 {
+		return ok.GetAccountByType(ACCOUNT_SPOT)
+	}
+	return ok.GetAccountByType(ok.accountType)
+}
+
+func (ok *OKExSpot) getSpotAccount() (*Account, error) {
 	urlPath := "/api/spot/v3/accounts"
 	var response []struct {
 		Frozen    float64 `json:"frozen,string"`
@@ -647,7 +768,7 @@ func (ok *OKExSpot) GetAccount() (*Account, error) {
 		SubAccounts: make(map[Currency]SubAccount, 2)}
 
 	for _, itm := range response {
-		currency := NewCurrency(itm.Currency)
+		currency := CanonicalCurrency(OKEX, itm.Currency)
 		account.SubAccounts[currency] = SubAccount{
 			Currency:     currency,
 			FrozenAmount: itm.Hold,
@@ -746,7 +867,7 @@ func (ok *OKExSpot) BatchPlaceOrders(orders []Order) ([]placeOrderResponse, erro
 
 	for _, ord := range orders {
 		param = append(param, placeOrderParam{
-			InstrumentId: ord.Market.ToSymbol("-"),
+			InstrumentId: ExchangeSymbol(OKEX, ord.Market, "-"),
 			//ClientOid:    ord.Cid,
 			Side:  strings.ToLower(ord.Side.String()),
 			Size:  ord.Amount,
@@ -771,10 +892,15 @@ func (ok *OKExSpot) BatchPlaceOrders(orders []Order) ([]placeOrderResponse, erro
 }
 
 func (ok *OKExSpot) placeOrder(ty string, ord *Order) (*Order, error) {
-	urlPath := "/api/spot/v3/orders"
+	return ok.submitOrder("/api/spot/v3/orders", "", ty, ord)
+}
+
+// 下单，marginTrading为空时为币币交易，为2时为币币杠杆交易
+func (ok *OKExSpot) submitOrder(urlPath, marginTrading, ty string, ord *Order) (*Order, error) {
 	param := placeOrderParam{
-		ClientOid:    ok.uuid(),
-		InstrumentId: ord.Market.ToLowerSymbol("-"),
+		ClientOid:     ok.uuid(),
+		InstrumentId:  ExchangeLowerSymbol(OKEX, ord.Market, "-"),
+		MarginTrading: marginTrading,
 	}
 
 	var response placeOrderResponse
@@ -42,7 +42,7 @@ func (ws *OkexSpotWs) GetExchangeName() string {
 
 // 格式化流名称
 func (ws *OkexSpotWs) FormatTopicName(topic string, pair CurrencyPair) string {
-	symbol := pair.ToSymbol("-")
+	symbol := ExchangeSymbol(OKEX, pair, "-")
 	switch topic {
 	case STREAM_TICKER:
 		return fmt.Sprintf("spot/ticker:%v", symbol)
@@ -256,14 +256,17 @@ func (ws *OkexSpotWs) parseKline(data []interface{}) (klines []Kline) {
 	return klines
 }
 
-// "instrument_id":"ETH-USDT",
+/*
+"instrument_id":"ETH-USDT"，币种之间总有分隔符，可以直接拆分，不需要查交易对索引。
+K线推送没有登记在订阅的流中，因此不从流名称查找交易对。
+*/
 func toSymbol(si interface{}) (market CurrencyPair) {
 	symbolStr, _ := si.(string)
 	sA := strings.Split(symbolStr, "-")
 	if len(sA) != 2 {
 		return
 	}
-	return NewCurrencyPairFromString(strings.Join(sA, "/"))
+	return CanonicalPair(OKEX, NewCurrencyPairFromString(strings.Join(sA, "/")))
 }
 
 // "timestamp":"2019-04-16T11:03:03.712Z"
@@ -0,0 +1,500 @@
+package okex
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/betterjun/exapi"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type OKExSwap struct {
+	spot *OKExSpot
+}
+
+/**
+ * perpetual swap
+ */
+func NewSwapAPI(client *http.Client, apiKey, secretKey, apiPass string) SwapAPI {
+	return &OKExSwap{
+		spot: &OKExSpot{
+			HttpClient:    client,
+			Endpoint:      Endpoint,
+			ApiKey:        apiKey,
+			ApiSecretKey:  secretKey,
+			ApiPassphrase: apiPass,
+		},
+	}
+}
+
+func (ok *OKExSwap) GetExchangeName() string {
+	return OKEX_SWAP
+}
+
+func (ok *OKExSwap) SetURL(exurl string) {
+	ok.spot.Endpoint = exurl
+}
+
+func (ok *OKExSwap) GetURL() string {
+	return ok.spot.Endpoint
+}
+
+// 获取合约id，如BTC-USD-SWAP
+func (ok *OKExSwap) getInstrumentId(pair CurrencyPair) string {
+	return ExchangeSymbol(OKEX, pair, "-") + "-SWAP"
+}
+
+// 将RFC3339格式的时间转换为毫秒时间戳
+func parseMillisecond(timestamp string) int64 {
+	t, _ := time.Parse(time.RFC3339, timestamp)
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func (ok *OKExSwap) GetSwapContracts() ([]FutureContract, error) {
+	urlPath := "/api/swap/v3/instruments"
+	var response []struct {
+		InstrumentId    string  `json:"instrument_id"`
+		UnderlyingIndex string  `json:"underlying_index"`
+		QuoteCurrency   string  `json:"quote_currency"`
+		TickSize        float64 `json:"tick_size,string"`
+		ContractVal     float64 `json:"contract_val,string"`
+	}
+	err := ok.spot.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	contracts := make([]FutureContract, 0, len(response))
+	for _, v := range response {
+		contracts = append(contracts, FutureContract{
+			ContractId:   v.InstrumentId,
+			ContractType: SWAP_CONTRACT,
+			Market:       NewCurrencyPair(CanonicalCurrency(OKEX, v.UnderlyingIndex), CanonicalCurrency(OKEX, v.QuoteCurrency)),
+			ContractVal:  v.ContractVal,
+			TickSize:     v.TickSize,
+		})
+	}
+
+	return contracts, nil
+}
+
+func (ok *OKExSwap) GetSwapTicker(pair CurrencyPair) (*Ticker, error) {
+	urlPath := fmt.Sprintf(GET_TICKER, ok.getInstrumentId(pair))
+	var response struct {
+		Last      float64 `json:"last,string"`
+		High24h   float64 `json:"high_24h,string"`
+		Low24h    float64 `json:"low_24h,string"`
+		BestBid   float64 `json:"best_bid,string"`
+		BestAsk   float64 `json:"best_ask,string"`
+		Volume24h float64 `json:"volume_24h,string"`
+		Timestamp string  `json:"timestamp"`
+	}
+	err := ok.spot.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ticker{
+		Market: pair,
+		Symbol: pair.ToLowerSymbol("/"),
+		Last:   response.Last,
+		High:   response.High24h,
+		Low:    response.Low24h,
+		Vol:    response.Volume24h,
+		Buy:    response.BestBid,
+		Sell:   response.BestAsk,
+		TS:     parseMillisecond(response.Timestamp),
+	}, nil
+}
+
+func (ok *OKExSwap) GetSwapDepth(pair CurrencyPair, size int) (*Depth, error) {
+	urlPath := fmt.Sprintf(GET_DEPTH, ok.getInstrumentId(pair), size)
+	var response struct {
+		Asks [][]interface{} `json:"asks"`
+		Bids [][]interface{} `json:"bids"`
+		Time string          `json:"time"`
+	}
+	err := ok.spot.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	dep := new(Depth)
+	dep.ContractType = SWAP_CONTRACT
+	dep.Market = pair
+	dep.Symbol = pair.ToLowerSymbol("/")
+	dep.TS = parseMillisecond(response.Time)
+
+	for _, itm := range response.Asks {
+		dep.AskList = append(dep.AskList, DepthRecord{
+			Price:  ToFloat64(itm[0]),
+			Amount: ToFloat64(itm[1]),
+		})
+	}
+
+	for _, itm := range response.Bids {
+		dep.BidList = append(dep.BidList, DepthRecord{
+			Price:  ToFloat64(itm[0]),
+			Amount: ToFloat64(itm[1]),
+		})
+	}
+
+	return dep, nil
+}
+
+func (ok *OKExSwap) GetFundingRate(pair CurrencyPair) (*FundingRate, error) {
+	urlPath := fmt.Sprintf("/api/swap/v3/instruments/%s/funding_time", ok.getInstrumentId(pair))
+	var response struct {
+		FundingRate    float64 `json:"funding_rate,string"`
+		EstimatedRate  float64 `json:"estimated_rate,string"`
+		FundingTime    string  `json:"funding_time"`
+		SettlementTime string  `json:"settlement_time"`
+	}
+	err := ok.spot.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	// okex每8小时收取一次资金费用
+	fundingTime := parseMillisecond(response.FundingTime)
+	return &FundingRate{
+		Market:          pair,
+		Symbol:          pair.ToLowerSymbol("/"),
+		Rate:            response.FundingRate,
+		EstimatedRate:   response.EstimatedRate,
+		FundingTime:     fundingTime,
+		NextFundingTime: fundingTime + int64(8*time.Hour/time.Millisecond),
+	}, nil
+}
+
+func (ok *OKExSwap) GetFundingRateHistory(pair CurrencyPair, size int) ([]FundingRate, error) {
+	urlPath := fmt.Sprintf("/api/swap/v3/instruments/%s/historical_funding_rate?limit=%d", ok.getInstrumentId(pair), size)
+	var response []struct {
+		RealizedRate float64 `json:"realized_rate,string"`
+		FundingTime  string  `json:"funding_time"`
+	}
+	err := ok.spot.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	// 交易所返回按时间降序排列
+	rates := make([]FundingRate, 0, len(response))
+	for i := len(response) - 1; i >= 0; i-- {
+		rates = append(rates, FundingRate{
+			Market:      pair,
+			Symbol:      pair.ToLowerSymbol("/"),
+			Rate:        response[i].RealizedRate,
+			FundingTime: parseMillisecond(response[i].FundingTime),
+		})
+	}
+
+	return rates, nil
+}
+
+func (ok *OKExSwap) GetSwapPrice(pair CurrencyPair) (*SwapPrice, error) {
+	instrumentId := ok.getInstrumentId(pair)
+	var markPrice struct {
+		MarkPrice float64 `json:"mark_price,string"`
+		Timestamp string  `json:"timestamp"`
+	}
+	err := ok.spot.doRequest("GET", fmt.Sprintf("/api/swap/v3/instruments/%s/mark_price", instrumentId), "", &markPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	var index struct {
+		Index float64 `json:"index,string"`
+	}
+	err = ok.spot.doRequest("GET", fmt.Sprintf("/api/swap/v3/instruments/%s/index", instrumentId), "", &index)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SwapPrice{
+		Market:     pair,
+		Symbol:     pair.ToLowerSymbol("/"),
+		MarkPrice:  markPrice.MarkPrice,
+		IndexPrice: index.Index,
+		TS:         parseMillisecond(markPrice.Timestamp),
+	}, nil
+}
+
+func (ok *OKExSwap) PlaceSwapOrder(pair CurrencyPair, price, amount string, openType, matchPrice int) (*FutureOrder, error) {
+	instrumentId := ok.getInstrumentId(pair)
+	param := struct {
+		ClientOid    string `json:"client_oid"`
+		InstrumentId string `json:"instrument_id"`
+		Type         string `json:"type"`
+		Price        string `json:"price,omitempty"`
+		Size         string `json:"size"`
+		MatchPrice   string `json:"match_price"`
+	}{
+		ClientOid:    ok.spot.uuid(),
+		InstrumentId: instrumentId,
+		Type:         fmt.Sprint(openType),
+		Price:        price,
+		Size:         amount,
+		MatchPrice:   fmt.Sprint(matchPrice),
+	}
+
+	var response placeOrderResponse
+	jsonStr, _, _ := ok.spot.buildRequestBody(param)
+	err := ok.spot.doRequest("POST", PLACE_ORDER, jsonStr, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if !response.Result {
+		return nil, errors.New(response.ErrorMessage)
+	}
+
+	return &FutureOrder{
+		OrderID2:     response.OrderId,
+		OrderID:      ToInt64(response.OrderId),
+		Price:        ToFloat64(price),
+		Amount:       ToFloat64(amount),
+		OrderTime:    time.Now().UnixNano() / int64(time.Millisecond),
+		Status:       ORDER_UNFINISH,
+		Currency:     pair,
+		OType:        openType,
+		ContractName: instrumentId,
+	}, nil
+}
+
+func (ok *OKExSwap) CancelSwapOrder(orderId string, pair CurrencyPair) (bool, error) {
+	urlPath := fmt.Sprintf(CANCEL_ORDER, ok.getInstrumentId(pair), orderId)
+	var response placeOrderResponse
+	err := ok.spot.doRequest("POST", urlPath, "{}", &response)
+	if err != nil {
+		return false, err
+	}
+
+	if !response.Result {
+		return false, errors.New(response.ErrorMessage)
+	}
+
+	return true, nil
+}
+
+// 永续合约订单与交割合约订单结构相同
+func (ok *OKExSwap) adaptOrder(response *futureOrderResponse, pair CurrencyPair) FutureOrder {
+	return FutureOrder{
+		OrderID2:     response.OrderId,
+		OrderID:      ToInt64(response.OrderId),
+		Price:        response.Price,
+		Amount:       response.Size,
+		AvgPrice:     response.PriceAvg,
+		DealAmount:   response.FilledQty,
+		OrderTime:    parseMillisecond(response.Timestamp),
+		Status:       ok.spot.adaptOrderState(response.State),
+		Currency:     pair,
+		OType:        response.Type,
+		LeverRate:    int(response.Leverage),
+		Fee:          response.Fee,
+		ContractName: response.InstrumentId,
+	}
+}
+
+func (ok *OKExSwap) GetSwapOrder(orderId string, pair CurrencyPair) (*FutureOrder, error) {
+	urlPath := fmt.Sprintf(GET_ORDER, ok.getInstrumentId(pair), orderId)
+	var response futureOrderResponse
+	err := ok.spot.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	ord := ok.adaptOrder(&response, pair)
+	return &ord, nil
+}
+
+func (ok *OKExSwap) GetUnfinishSwapOrders(pair CurrencyPair) ([]FutureOrder, error) {
+	// state=6 未完成（等待成交+部分成交）
+	urlPath := fmt.Sprintf("/api/swap/v3/orders/%s?state=6", ok.getInstrumentId(pair))
+	var response struct {
+		OrderInfo []futureOrderResponse `json:"order_info"`
+	}
+	err := ok.spot.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]FutureOrder, 0, len(response.OrderInfo))
+	for i := range response.OrderInfo {
+		orders = append(orders, ok.adaptOrder(&response.OrderInfo[i], pair))
+	}
+
+	return orders, nil
+}
+
+func adaptMarginMode(mode string) MarginMode {
+	if mode == "fixed" {
+		return MARGIN_ISOLATED
+	}
+	return MARGIN_CROSSED
+}
+
+func (ok *OKExSwap) GetSwapPosition(pair CurrencyPair) ([]SwapPosition, error) {
+	urlPath := fmt.Sprintf(GET_POSITION, ok.getInstrumentId(pair))
+	var response struct {
+		MarginMode string `json:"margin_mode"`
+		Holding    []struct {
+			Side             string  `json:"side"`
+			Position         float64 `json:"position,string"`
+			AvailPosition    float64 `json:"avail_position,string"`
+			AvgCost          float64 `json:"avg_cost,string"`
+			LiquidationPrice float64 `json:"liquidation_price,string"`
+			Leverage         float64 `json:"leverage,string"`
+			Margin           float64 `json:"margin,string"`
+			RealizedPnl      float64 `json:"realized_pnl,string"`
+			UnrealizedPnl    float64 `json:"unrealized_pnl,string"`
+		} `json:"holding"`
+	}
+	err := ok.spot.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make([]SwapPosition, 0, len(response.Holding))
+	for _, v := range response.Holding {
+		side := POSITION_LONG
+		if v.Side == "short" {
+			side = POSITION_SHORT
+		}
+		positions = append(positions, SwapPosition{
+			Market:           pair,
+			Symbol:           pair.ToLowerSymbol("/"),
+			Side:             side,
+			Amount:           v.Position,
+			Available:        v.AvailPosition,
+			AvgPrice:         v.AvgCost,
+			LiquidationPrice: v.LiquidationPrice,
+			Leverage:         int(v.Leverage),
+			MarginMode:       adaptMarginMode(response.MarginMode),
+			Margin:           v.Margin,
+			RealizedPnl:      v.RealizedPnl,
+			UnrealizedPnl:    v.UnrealizedPnl,
+		})
+	}
+
+	return positions, nil
+}
+
+func (ok *OKExSwap) GetSwapAccount() (*FutureAccount, error) {
+	var response struct {
+		Info []struct {
+			InstrumentId  string  `json:"instrument_id"`
+			Currency      string  `json:"currency"`
+			Equity        float64 `json:"equity,string"`
+			Margin        float64 `json:"margin,string"`
+			RealizedPnl   float64 `json:"realized_pnl,string"`
+			UnrealizedPnl float64 `json:"unrealized_pnl,string"`
+			MarginRatio   float64 `json:"margin_ratio,string"`
+		} `json:"info"`
+	}
+	err := ok.spot.doRequest("GET", GET_ACCOUNTS, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	// 每个合约一个账户，币本位合约以基础币种为保证金，USDT合约以USDT为保证金，同币种合并
+	acc := &FutureAccount{FutureSubAccounts: make(map[Currency]FutureSubAccount, len(response.Info))}
+	for _, v := range response.Info {
+		code := v.Currency
+		if code == "" {
+			code = strings.Split(v.InstrumentId, "-")[0]
+		}
+		currency := CanonicalCurrency(OKEX, code)
+		sub := acc.FutureSubAccounts[currency]
+		sub.Currency = currency
+		sub.AccountRights += v.Equity
+		sub.KeepDeposit += v.Margin
+		sub.ProfitReal += v.RealizedPnl
+		sub.ProfitUnreal += v.UnrealizedPnl
+		sub.RiskRate = v.MarginRatio
+		acc.FutureSubAccounts[currency] = sub
+	}
+
+	return acc, nil
+}
+
+type swapSettingsResponse struct {
+	InstrumentId  string  `json:"instrument_id"`
+	LongLeverage  float64 `json:"long_leverage,string"`
+	ShortLeverage float64 `json:"short_leverage,string"`
+	MarginMode    string  `json:"margin_mode"`
+	ErrorCode     string  `json:"error_code"`
+	ErrorMessage  string  `json:"error_message"`
+}
+
+func (ok *OKExSwap) getSettings(instrumentId string) (*swapSettingsResponse, error) {
+	urlPath := fmt.Sprintf("/api/swap/v3/accounts/%s/settings", instrumentId)
+	var response swapSettingsResponse
+	err := ok.spot.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+/*
+设置杠杆倍数，side为1时设置逐仓多仓，为2时设置逐仓空仓，为3时设置全仓。
+okex通过此接口同时切换保证金模式。
+*/
+func (ok *OKExSwap) setLeverage(instrumentId string, leverRate, side int) error {
+	urlPath := fmt.Sprintf("/api/swap/v3/accounts/%s/leverage", instrumentId)
+	var response swapSettingsResponse
+	err := ok.spot.doRequest("POST", urlPath, fmt.Sprintf(`{"leverage":"%d","side":"%d"}`, leverRate, side), &response)
+	if err != nil {
+		return err
+	}
+
+	if response.ErrorCode != "" && response.ErrorCode != "0" {
+		return fmt.Errorf("code:%v, msg:%v", response.ErrorCode, response.ErrorMessage)
+	}
+
+	return nil
+}
+
+// 按当前保证金模式设置杠杆倍数，逐仓时多空仓同时设置
+func (ok *OKExSwap) SetSwapLeverage(pair CurrencyPair, leverRate int) error {
+	instrumentId := ok.getInstrumentId(pair)
+	settings, err := ok.getSettings(instrumentId)
+	if err != nil {
+		return err
+	}
+
+	if adaptMarginMode(settings.MarginMode) == MARGIN_CROSSED {
+		return ok.setLeverage(instrumentId, leverRate, 3)
+	}
+
+	err = ok.setLeverage(instrumentId, leverRate, 1)
+	if err != nil {
+		return err
+	}
+	return ok.setLeverage(instrumentId, leverRate, 2)
+}
+
+// 切换保证金模式，保持当前杠杆倍数不变
+func (ok *OKExSwap) SetSwapMarginMode(pair CurrencyPair, mode MarginMode) error {
+	instrumentId := ok.getInstrumentId(pair)
+	settings, err := ok.getSettings(instrumentId)
+	if err != nil {
+		return err
+	}
+
+	leverRate := int(settings.LongLeverage)
+	switch mode {
+	case MARGIN_CROSSED:
+		return ok.setLeverage(instrumentId, leverRate, 3)
+	case MARGIN_ISOLATED:
+		err = ok.setLeverage(instrumentId, leverRate, 1)
+		if err != nil {
+			return err
+		}
+		return ok.setLeverage(instrumentId, int(settings.ShortLeverage), 2)
+	default:
+		return ErrorUnsupported
+	}
+}
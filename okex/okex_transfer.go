@@ -0,0 +1,136 @@
+package okex
+
+import (
+	"errors"
+	. "github.com/betterjun/exapi"
+	"net/http"
+)
+
+/**
+ * asset transfer
+ */
+func NewTransferAPI(client *http.Client, apiKey, secretKey, apiPass string) TransferAPI {
+	return NewSpotAPI(client, apiKey, secretKey, apiPass).(*OKExSpot)
+}
+
+// okex的现货接口支持币币账户和资金账户
+func (ok *OKExSpot) SetAccountType(accountType AccountType) error {
+	if accountType != ACCOUNT_SPOT && accountType != ACCOUNT_FUNDING {
+		return ErrorUnsupported
+	}
+
+	ok.accountType = accountType
+	return nil
+}
+
+func (ok *OKExSpot) GetAccountByType(accountType AccountType) (*Account, error) {
+	switch accountType {
+	case ACCOUNT_SPOT:
+		return ok.getSpotAccount()
+	case ACCOUNT_FUNDING:
+		var response []struct {
+			Currency  string  `json:"currency"`
+			Balance   float64 `json:"balance,string"`
+			Hold      float64 `json:"hold,string"`
+			Available float64 `json:"available,string"`
+		}
+		err := ok.doRequest("GET", "/api/account/v3/wallet", "", &response)
+		if err != nil {
+			return nil, err
+		}
+
+		acc := &Account{
+			Exchange:    ok.GetExchangeName(),
+			SubAccounts: make(map[Currency]SubAccount, 2),
+		}
+		for _, v := range response {
+			currency := CanonicalCurrency(OKEX, v.Currency)
+			acc.SubAccounts[currency] = SubAccount{
+				Currency:     currency,
+				Amount:       v.Available,
+				FrozenAmount: v.Hold,
+			}
+		}
+		return acc, nil
+	default:
+		return nil, ErrorUnsupported
+	}
+}
+
+// 资金划转的账户类型，0为子账户
+var _TRANSFER_ACCOUNT_CONVERTER = map[AccountType]string{
+	ACCOUNT_SPOT:    "1",
+	ACCOUNT_FUTURE:  "3",
+	ACCOUNT_MARGIN:  "5",
+	ACCOUNT_FUNDING: "6",
+	ACCOUNT_SWAP:    "9",
+}
+
+type transferParam struct {
+	Currency       string `json:"currency"`
+	Amount         string `json:"amount"`
+	From           string `json:"from"`
+	To             string `json:"to"`
+	Type           string `json:"type,omitempty"` // 0:账户内划转，1:母账户转子账户，2:子账户转母账户
+	SubAccount     string `json:"sub_account,omitempty"`
+	InstrumentId   string `json:"instrument_id,omitempty"`    // 转出的杠杆币对
+	ToInstrumentId string `json:"to_instrument_id,omitempty"` // 转入的杠杆币对
+}
+
+func (ok *OKExSpot) Transfer(currency Currency, amount string, from, to AccountType, pair CurrencyPair) (string, error) {
+	fromCode, ok1 := _TRANSFER_ACCOUNT_CONVERTER[from]
+	toCode, ok2 := _TRANSFER_ACCOUNT_CONVERTER[to]
+	if !ok1 || !ok2 || from == to {
+		return "", ErrorUnsupported
+	}
+
+	param := transferParam{
+		Currency: ExchangeCurrency(OKEX, currency).LowerSymbol(),
+		Amount:   amount,
+		From:     fromCode,
+		To:       toCode,
+	}
+	if from == ACCOUNT_MARGIN {
+		param.InstrumentId = ExchangeSymbol(OKEX, pair, "-")
+	}
+	if to == ACCOUNT_MARGIN {
+		param.ToInstrumentId = ExchangeSymbol(OKEX, pair, "-")
+	}
+
+	return ok.doTransfer(param)
+}
+
+// okex的子账户为子账户名称，在母子账户的资金账户之间划转
+func (ok *OKExSpot) SubAccountTransfer(subAccount string, currency Currency, amount string, toSub bool) (string, error) {
+	param := transferParam{
+		Currency:   ExchangeCurrency(OKEX, currency).LowerSymbol(),
+		Amount:     amount,
+		From:       "6",
+		To:         "6",
+		Type:       "2",
+		SubAccount: subAccount,
+	}
+	if toSub {
+		param.Type = "1"
+	}
+
+	return ok.doTransfer(param)
+}
+
+func (ok *OKExSpot) doTransfer(param transferParam) (string, error) {
+	var response struct {
+		TransferId string `json:"transfer_id"`
+		Result     bool   `json:"result"`
+	}
+	reqBody, _, _ := ok.buildRequestBody(param)
+	err := ok.doRequest("POST", "/api/account/v3/transfer", reqBody, &response)
+	if err != nil {
+		return "", err
+	}
+
+	if !response.Result {
+		return "", errors.New("transfer failed")
+	}
+
+	return response.TransferId, nil
+}
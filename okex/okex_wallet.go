@@ -0,0 +1,214 @@
+package okex
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/betterjun/exapi"
+	"net/http"
+	"strings"
+	"time"
+)
+
+/**
+ * deposit and withdrawal
+ */
+func NewWalletAPI(client *http.Client, apiKey, secretKey, apiPass, tradePwd string) WalletAPI {
+	ok := NewSpotAPI(client, apiKey, secretKey, apiPass).(*OKExSpot)
+	ok.TradePassword = tradePwd
+	return ok
+}
+
+// okex的链名称为币种-链，如USDT-ERC20，默认链与币种相同
+func (ok *OKExSpot) getChainName(currency Currency, chain string) string {
+	if len(chain) == 0 || strings.Contains(chain, "-") || strings.EqualFold(chain, ExchangeCurrency(OKEX, currency).Symbol()) {
+		return chain
+	}
+	return ExchangeCurrency(OKEX, currency).Symbol() + "-" + chain
+}
+
+func (ok *OKExSpot) GetDepositAddress(currency Currency, chain string) (*DepositAddress, error) {
+	urlPath := "/api/account/v3/deposit/address?currency=" + ExchangeCurrency(OKEX, currency).LowerSymbol()
+	var response []struct {
+		Address string `json:"address"`
+		Tag     string `json:"tag"`
+		Memo    string `json:"memo"`
+		Chain   string `json:"chain"`
+	}
+	err := ok.doRequest("GET", urlPath, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	name := ok.getChainName(currency, chain)
+	for _, v := range response {
+		if len(name) == 0 || strings.EqualFold(v.Chain, name) || len(response) == 1 {
+			tag := v.Tag
+			if len(tag) == 0 {
+				tag = v.Memo
+			}
+			return &DepositAddress{
+				Currency: currency,
+				Chain:    v.Chain,
+				Address:  v.Address,
+				Tag:      tag,
+			}, nil
+		}
+	}
+
+	return nil, errors.New("deposit address not found")
+}
+
+// okex提币需要指定手续费，使用最低手续费
+func (ok *OKExSpot) Withdraw(currency Currency, amount, address, tag, chain string) (string, error) {
+	var fees []struct {
+		Currency string  `json:"currency"`
+		MinFee   float64 `json:"min_fee,string"`
+	}
+	err := ok.doRequest("GET", "/api/account/v3/withdrawal/fee?currency="+ExchangeCurrency(OKEX, currency).LowerSymbol(), "", &fees)
+	if err != nil {
+		return "", err
+	}
+
+	if len(fees) == 0 {
+		return "", ErrorAssetNotFound
+	}
+
+	// 带标签的地址格式为 地址:标签
+	toAddress := address
+	if len(tag) > 0 {
+		toAddress = address + ":" + tag
+	}
+
+	param := struct {
+		Currency    string `json:"currency"`
+		Amount      string `json:"amount"`
+		Destination string `json:"destination"`
+		ToAddress   string `json:"to_address"`
+		TradePwd    string `json:"trade_pwd"`
+		Fee         string `json:"fee"`
+		Chain       string `json:"chain,omitempty"`
+	}{
+		Currency:    ExchangeCurrency(OKEX, currency).LowerSymbol(),
+		Amount:      amount,
+		Destination: "4", // 4:数字货币地址
+		ToAddress:   toAddress,
+		TradePwd:    ok.TradePassword,
+		Fee:         FloatToString(fees[0].MinFee, 8),
+		Chain:       ok.getChainName(currency, chain),
+	}
+
+	var response struct {
+		WithdrawalId string `json:"withdrawal_id"`
+		Result       bool   `json:"result"`
+	}
+	reqBody, _, _ := ok.buildRequestBody(param)
+	err = ok.doRequest("POST", "/api/account/v3/withdrawal", reqBody, &response)
+	if err != nil {
+		return "", err
+	}
+
+	if !response.Result {
+		return "", errors.New("withdraw failed")
+	}
+
+	return response.WithdrawalId, nil
+}
+
+func (ok *OKExSpot) CancelWithdraw(id string, currency Currency) (bool, error) {
+	var response struct {
+		WithdrawalId string `json:"withdrawal_id"`
+	}
+	err := ok.doRequest("POST", "/api/account/v3/withdrawal/cancellation", fmt.Sprintf(`{"withdrawal_id":"%s"}`, id), &response)
+	if err != nil {
+		return false, err
+	}
+
+	return response.WithdrawalId == id, nil
+}
+
+func (ok *OKExSpot) GetDepositHistory(currency Currency) ([]WalletRecord, error) {
+	var response []struct {
+		DepositId string  `json:"deposit_id"`
+		Amount    float64 `json:"amount,string"`
+		TxId      string  `json:"txid"`
+		To        string  `json:"to"`
+		Status    string  `json:"status"`
+		Timestamp string  `json:"timestamp"`
+	}
+	err := ok.doRequest("GET", "/api/account/v3/deposit/history/"+ExchangeCurrency(OKEX, currency).LowerSymbol(), "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]WalletRecord, 0, len(response))
+	for _, v := range response {
+		date, _ := time.Parse(time.RFC3339, v.Timestamp)
+		record := WalletRecord{
+			Id:       v.DepositId,
+			Type:     WALLET_DEPOSIT,
+			Currency: currency,
+			Amount:   v.Amount,
+			Address:  v.To,
+			TxId:     v.TxId,
+			Status:   WALLET_PENDING,
+			TS:       date.UnixNano() / int64(time.Millisecond),
+		}
+		// 0:等待确认 1:确认到账 2:充值成功
+		if v.Status == "2" {
+			record.Status = WALLET_SUCCESS
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func (ok *OKExSpot) GetWithdrawHistory(currency Currency) ([]WalletRecord, error) {
+	var response []struct {
+		WithdrawalId string  `json:"withdrawal_id"`
+		Amount       float64 `json:"amount,string"`
+		Fee          string  `json:"fee"`
+		To           string  `json:"to"`
+		Tag          string  `json:"tag"`
+		TxId         string  `json:"txid"`
+		Chain        string  `json:"chain"`
+		Status       string  `json:"status"`
+		Timestamp    string  `json:"timestamp"`
+	}
+	err := ok.doRequest("GET", "/api/account/v3/withdrawal/history/"+ExchangeCurrency(OKEX, currency).LowerSymbol(), "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]WalletRecord, 0, len(response))
+	for _, v := range response {
+		date, _ := time.Parse(time.RFC3339, v.Timestamp)
+		record := WalletRecord{
+			Id:       v.WithdrawalId,
+			Type:     WALLET_WITHDRAW,
+			Currency: currency,
+			Chain:    v.Chain,
+			Amount:   v.Amount,
+			// 手续费格式为 0.01btc
+			Fee:     ToFloat64(strings.TrimRight(v.Fee, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")),
+			Address: v.To,
+			Tag:     v.Tag,
+			TxId:    v.TxId,
+			TS:      date.UnixNano() / int64(time.Millisecond),
+		}
+		// -3:撤销中 -2:已撤销 -1:失败 0:等待提现 1:提现中 2:已汇出 3:邮箱确认 4:人工审核中 5:等待身份认证
+		switch v.Status {
+		case "-2":
+			record.Status = WALLET_CANCELED
+		case "-1":
+			record.Status = WALLET_FAILED
+		case "2":
+			record.Status = WALLET_SUCCESS
+		default:
+			record.Status = WALLET_PENDING
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,491 @@
+package exapi
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 订单事件类型
+type OrderEventType int
+
+const (
+	ORDER_EVENT_NEW          OrderEventType = 1 + iota // 下单成功
+	ORDER_EVENT_PARTIAL_FILL                           // 部分成交
+	ORDER_EVENT_FILL                                   // 完全成交
+	ORDER_EVENT_CANCEL                                 // 已撤单
+	ORDER_EVENT_REJECT                                 // 下单失败或被拒绝
+)
+
+func (t OrderEventType) String() string {
+	switch t {
+	case ORDER_EVENT_NEW:
+		return "NEW"
+	case ORDER_EVENT_PARTIAL_FILL:
+		return "PARTIAL_FILL"
+	case ORDER_EVENT_FILL:
+		return "FILL"
+	case ORDER_EVENT_CANCEL:
+		return "CANCEL"
+	case ORDER_EVENT_REJECT:
+		return "REJECT"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// 订单事件
+type OrderEvent struct {
+	Type     OrderEventType `json:"type"`      // 事件类型
+	ClientID string         `json:"client_id"` // 客户端订单id
+	Order    Order          `json:"order"`     // 事件发生后的订单状态
+	Deal     *OrderDeal     `json:"deal"`      // 本次新增的成交，仅成交事件有
+	Err      error          `json:"-"`         // 下单失败的错误，仅拒绝事件有
+}
+
+// OMS管理的订单
+type ManagedOrder struct {
+	ClientID  string      `json:"client_id"`  // 客户端订单id
+	Order     Order       `json:"order"`      // 最新的订单状态
+	Deals     []OrderDeal `json:"deals"`      // 已知的成交明细
+	CreatedAt int64       `json:"created_at"` // 创建时间，单位为毫秒(millisecond)
+	UpdatedAt int64       `json:"updated_at"` // 最近更新时间，单位为毫秒(millisecond)
+}
+
+// 订单查询条件，字段为空时不限制
+type OrderFilter struct {
+	Pair     *CurrencyPair // 交易对
+	Statuses []TradeStatus // 订单状态
+}
+
+/*
+本地订单管理。
+通过OMS下的订单由OMS跟踪，按TradeStatus维护本地状态，只接受合法的状态变化，已结束的订单不再更新，
+成交量只增不减，以忽略过期的数据。
+订单状态可通过Reconcile或Start定时调用GetOrder轮询，也可由私有websocket推送后调用OnOrderUpdate更新。
+成交量增加时回调部分成交或完全成交事件，附带本次新增的成交明细，撤单和拒绝时回调相应事件。
+同一订单的更新串行处理，事件按顺序回调，回调中不能同步调用同一订单的Cancel或OnOrderUpdate。
+*/
+type OMS struct {
+	sync.Mutex
+	api        SpotAPI
+	orders     map[string]*ManagedOrder // 按客户端订单id
+	byOrderID  map[string]string        // 交易所订单id到客户端订单id
+	orderMu    map[string]*sync.Mutex   // 按客户端订单id，更新订单和回调事件时持有
+	fetchDeals bool
+	seq        int64
+	stop       chan struct{}
+	cb         func(*OrderEvent) error
+}
+
+func NewOMS(api SpotAPI, cb func(*OrderEvent) error) *OMS {
+	return &OMS{
+		api:       api,
+		orders:    make(map[string]*ManagedOrder),
+		byOrderID: make(map[string]string),
+		orderMu:   make(map[string]*sync.Mutex),
+		cb:        cb,
+	}
+}
+
+// 设置成交时是否调用GetOrderDeal获取成交明细，默认按订单的成交量和均价计算
+func (oms *OMS) SetFetchDeals(fetch bool) {
+	oms.Lock()
+	oms.fetchDeals = fetch
+	oms.Unlock()
+}
+
+// 限价买入，clientID为空时自动生成
+func (oms *OMS) LimitBuy(clientID string, pair CurrencyPair, price, amount string) (*ManagedOrder, error) {
+	return oms.place(clientID, pair, BUY, price, amount, func() (*Order, error) {
+		return oms.api.LimitBuy(pair, price, amount)
+	})
+}
+
+// 限价卖出，clientID为空时自动生成
+func (oms *OMS) LimitSell(clientID string, pair CurrencyPair, price, amount string) (*ManagedOrder, error) {
+	return oms.place(clientID, pair, SELL, price, amount, func() (*Order, error) {
+		return oms.api.LimitSell(pair, price, amount)
+	})
+}
+
+// 市价买入，amount为报价币种金额，clientID为空时自动生成
+func (oms *OMS) MarketBuy(clientID string, pair CurrencyPair, amount string) (*ManagedOrder, error) {
+	return oms.place(clientID, pair, BUY_MARKET, "0", amount, func() (*Order, error) {
+		return oms.api.MarketBuy(pair, amount)
+	})
+}
+
+// 市价卖出，amount为基础币种数量，clientID为空时自动生成
+func (oms *OMS) MarketSell(clientID string, pair CurrencyPair, amount string) (*ManagedOrder, error) {
+	return oms.place(clientID, pair, SELL_MARKET, "0", amount, func() (*Order, error) {
+		return oms.api.MarketSell(pair, amount)
+	})
+}
+
+func (oms *OMS) place(clientID string, pair CurrencyPair, side TradeSide, price, amount string,
+	submit func() (*Order, error)) (*ManagedOrder, error) {
+	oms.Lock()
+	if clientID == "" {
+		oms.seq++
+		clientID = fmt.Sprintf("oms-%d-%d", time.Now().UnixNano(), oms.seq)
+	}
+	if _, ok := oms.orders[clientID]; ok {
+		oms.Unlock()
+		return nil, fmt.Errorf("duplicate client id:%v", clientID)
+	}
+	now := nowMillisecond()
+	mo := &ManagedOrder{
+		ClientID: clientID,
+		Order: Order{
+			Price:  ToFloat64(price),
+			Amount: ToFloat64(amount),
+			TS:     now,
+			Status: ORDER_UNFINISH,
+			Market: pair,
+			Symbol: pair.ToLowerSymbol("/"),
+			Side:   side,
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	oms.orders[clientID] = mo
+	mu := &sync.Mutex{}
+	oms.orderMu[clientID] = mu
+	oms.Unlock()
+
+	order, err := submit()
+
+	// 下单事件先于推送的成交事件回调
+	mu.Lock()
+	oms.Lock()
+	var event *OrderEvent
+	if err != nil {
+		mo.Order.Status = ORDER_REJECT
+		event = &OrderEvent{Type: ORDER_EVENT_REJECT, ClientID: clientID, Order: mo.Order, Err: err}
+	} else {
+		mo.Order.OrderID = order.OrderID
+		oms.byOrderID[order.OrderID] = clientID
+		event = &OrderEvent{Type: ORDER_EVENT_NEW, ClientID: clientID, Order: mo.Order}
+	}
+	result := *mo
+	oms.Unlock()
+
+	if e := oms.emit([]*OrderEvent{event}); e != nil && err == nil {
+		err = e
+	}
+	mu.Unlock()
+	if err == nil && order != nil {
+		// 下单返回的订单可能已有成交
+		err = oms.OnOrderUpdate(order)
+		if m, ok := oms.Get(clientID); ok {
+			result = m
+		}
+	}
+	return &result, err
+}
+
+// 撤单
+func (oms *OMS) Cancel(clientID string) error {
+	mo, ok := oms.Get(clientID)
+	if !ok {
+		return fmt.Errorf("order not found:%v", clientID)
+	}
+	if mo.Order.Status.IsFinal() {
+		return nil
+	}
+	if mo.Order.OrderID == "" {
+		return errors.New("order not submitted")
+	}
+
+	if _, err := oms.api.Cancel(mo.Order.OrderID, mo.Order.Market); err != nil {
+		return err
+	}
+	order, err := oms.api.GetOrder(mo.Order.OrderID, mo.Order.Market)
+	if err != nil {
+		return err
+	}
+	return oms.OnOrderUpdate(order)
+}
+
+// 按客户端订单id查询
+func (oms *OMS) Get(clientID string) (ManagedOrder, bool) {
+	oms.Lock()
+	defer oms.Unlock()
+	mo, ok := oms.orders[clientID]
+	if !ok {
+		return ManagedOrder{}, false
+	}
+	return copyManagedOrder(mo), true
+}
+
+// 按交易所订单id查询
+func (oms *OMS) GetByOrderID(orderID string) (ManagedOrder, bool) {
+	oms.Lock()
+	clientID, ok := oms.byOrderID[orderID]
+	oms.Unlock()
+	if !ok {
+		return ManagedOrder{}, false
+	}
+	return oms.Get(clientID)
+}
+
+// 按条件查询，按创建时间排序
+func (oms *OMS) Query(filter OrderFilter) []ManagedOrder {
+	oms.Lock()
+	defer oms.Unlock()
+
+	var result []ManagedOrder
+	for _, mo := range oms.orders {
+		if filter.Pair != nil && !mo.Order.Market.Equal(*filter.Pair) {
+			continue
+		}
+		if len(filter.Statuses) > 0 {
+			matched := false
+			for _, s := range filter.Statuses {
+				if mo.Order.Status == s {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+		}
+		result = append(result, copyManagedOrder(mo))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreatedAt != result[j].CreatedAt {
+			return result[i].CreatedAt < result[j].CreatedAt
+		}
+		return result[i].ClientID < result[j].ClientID
+	})
+	return result
+}
+
+// 未结束的订单
+func (oms *OMS) OpenOrders() []ManagedOrder {
+	return oms.Query(OrderFilter{Statuses: []TradeStatus{ORDER_UNFINISH, ORDER_PART_FINISH, ORDER_CANCEL_ING}})
+}
+
+// 用GetOrder查询所有未结束的订单并更新
+func (oms *OMS) Reconcile() (err error) {
+	for _, mo := range oms.OpenOrders() {
+		if mo.Order.OrderID == "" {
+			continue
+		}
+		order, e := oms.api.GetOrder(mo.Order.OrderID, mo.Order.Market)
+		if e == nil {
+			e = oms.OnOrderUpdate(order)
+		}
+		if e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// 每隔interval调用一次Reconcile，直到Stop
+func (oms *OMS) Start(interval time.Duration) {
+	oms.Lock()
+	if oms.stop != nil {
+		oms.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	oms.stop = stop
+	oms.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				oms.Reconcile()
+			}
+		}
+	}()
+}
+
+// 停止定时轮询
+func (oms *OMS) Stop() {
+	oms.Lock()
+	if oms.stop != nil {
+		close(oms.stop)
+		oms.stop = nil
+	}
+	oms.Unlock()
+}
+
+/*
+用交易所返回或推送的订单更新本地状态，不是通过OMS下的订单被忽略。
+可作为私有websocket订单推送的回调。
+*/
+func (oms *OMS) OnOrderUpdate(order *Order) error {
+	oms.Lock()
+	clientID, ok := oms.byOrderID[order.OrderID]
+	mu := oms.orderMu[clientID]
+	oms.Unlock()
+	if !ok {
+		return nil
+	}
+
+	// 持有订单锁直到事件回调完成，避免并发的更新重复记录成交或乱序回调
+	mu.Lock()
+	defer mu.Unlock()
+
+	oms.Lock()
+	mo := oms.orders[clientID]
+	prev := mo.Order
+	if prev.Status.IsFinal() || !validTransition(prev.Status, order.Status) || order.DealAmount < prev.DealAmount {
+		oms.Unlock()
+		return nil
+	}
+
+	cur := mergeOrder(prev, order)
+	mo.Order = cur
+	mo.UpdatedAt = nowMillisecond()
+	fetch := oms.fetchDeals
+	oms.Unlock()
+
+	var deals []OrderDeal
+	if cur.DealAmount > prev.DealAmount {
+		deals = oms.newDeals(clientID, prev, cur, fetch)
+	}
+
+	oms.Lock()
+	var events []*OrderEvent
+	for i := range deals {
+		t := ORDER_EVENT_PARTIAL_FILL
+		if cur.Status == ORDER_FINISH && i == len(deals)-1 {
+			t = ORDER_EVENT_FILL
+		}
+		mo.Deals = append(mo.Deals, deals[i])
+		events = append(events, &OrderEvent{Type: t, ClientID: clientID, Order: cur, Deal: &deals[i]})
+	}
+	if cur.Status != prev.Status {
+		switch cur.Status {
+		case ORDER_CANCEL:
+			events = append(events, &OrderEvent{Type: ORDER_EVENT_CANCEL, ClientID: clientID, Order: cur})
+		case ORDER_REJECT, ORDER_FAIL:
+			events = append(events, &OrderEvent{Type: ORDER_EVENT_REJECT, ClientID: clientID, Order: cur})
+		}
+	}
+	oms.Unlock()
+
+	return oms.emit(events)
+}
+
+// 订单状态是否可以从from变为to
+func validTransition(from, to TradeStatus) bool {
+	if from == to {
+		return true
+	}
+	switch from {
+	case ORDER_UNFINISH:
+		return true
+	case ORDER_PART_FINISH:
+		return to != ORDER_UNFINISH && to != ORDER_REJECT
+	case ORDER_CANCEL_ING:
+		return to == ORDER_CANCEL || to == ORDER_FINISH || to == ORDER_PART_FINISH
+	default:
+		return false
+	}
+}
+
+// 保留本地的下单信息，交易所未返回的字段不覆盖
+func mergeOrder(local Order, remote *Order) Order {
+	merged := *remote
+	if merged.Price == 0 {
+		merged.Price = local.Price
+	}
+	if merged.Amount == 0 {
+		merged.Amount = local.Amount
+	}
+	if merged.Market.Stock.Symbol() == "" {
+		merged.Market, merged.Symbol = local.Market, local.Symbol
+	}
+	if merged.Side == 0 {
+		merged.Side = local.Side
+	}
+	if merged.TS == 0 {
+		merged.TS = local.TS
+	}
+	// 撤单中仍有成交时保持撤单中
+	if local.Status == ORDER_CANCEL_ING && merged.Status == ORDER_PART_FINISH {
+		merged.Status = ORDER_CANCEL_ING
+	}
+	return merged
+}
+
+/*
+计算本次新增的成交明细。
+fetch为true时调用GetOrderDeal，返回尚未记录的成交，失败或明细不全时按订单的成交量和均价估算，
+估算的成交明细没有DealID，PnLTracker据此与交易所的成交明细按订单去重。
+*/
+func (oms *OMS) newDeals(clientID string, prev, cur Order, fetch bool) []OrderDeal {
+	if fetch {
+		if all, err := oms.api.GetOrderDeal(cur.OrderID, cur.Market); err == nil && len(all) > 0 {
+			oms.Lock()
+			known := make(map[string]bool)
+			for _, d := range oms.orders[clientID].Deals {
+				known[d.DealID] = true
+			}
+			oms.Unlock()
+
+			var deals []OrderDeal
+			var filled float64
+			for _, d := range all {
+				filled += d.FilledAmount
+				if !known[d.DealID] {
+					deals = append(deals, d)
+				}
+			}
+			// 已记录的成交明细包含了本次成交时，不再按均价计算
+			if len(deals) > 0 || filled >= cur.DealAmount-1e-9 {
+				return deals
+			}
+		}
+	}
+
+	amount := cur.DealAmount - prev.DealAmount
+	cash := cur.DealAmount*cur.AvgPrice - prev.DealAmount*prev.AvgPrice
+	price := cur.AvgPrice
+	if amount > 0 && cash > 0 {
+		price = cash / amount
+	}
+	return []OrderDeal{{
+		OrderID:          cur.OrderID,
+		TS:               nowMillisecond(),
+		Price:            price,
+		FilledAmount:     amount,
+		FilledCashAmount: amount * price,
+		UnFilledAmount:   cur.Amount - cur.DealAmount,
+		Side:             cur.Side,
+		Market:           cur.Market,
+		Symbol:           cur.Symbol,
+	}}
+}
+
+func copyManagedOrder(mo *ManagedOrder) ManagedOrder {
+	c := *mo
+	c.Deals = append([]OrderDeal(nil), mo.Deals...)
+	return c
+}
+
+func (oms *OMS) emit(events []*OrderEvent) error {
+	if oms.cb == nil {
+		return nil
+	}
+	for _, e := range events {
+		if err := oms.cb(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
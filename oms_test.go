@@ -0,0 +1,98 @@
+package exapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOMS(t *testing.T) {
+	pair := NewCurrencyPairFromString("BTC/USDT")
+	api := newMockSpotAPI("a")
+	var events []OrderEvent
+	oms := NewOMS(api, func(e *OrderEvent) error {
+		events = append(events, *e)
+		return nil
+	})
+
+	mo, err := oms.LimitBuy("c1", pair, "100", "2")
+	assert.Nil(t, err)
+	assert.Equal(t, "1", mo.Order.OrderID)
+	assert.Equal(t, 2.0, mo.Order.Amount)
+	_, err = oms.LimitBuy("c1", pair, "100", "2")
+	assert.NotNil(t, err)
+
+	api.fill("1", 0.5, 100, ORDER_PART_FINISH)
+	assert.Nil(t, oms.Reconcile())
+	api.fill("1", 1.5, 98, ORDER_FINISH)
+	assert.Nil(t, oms.Reconcile())
+
+	// 过期的推送被忽略
+	assert.Nil(t, oms.OnOrderUpdate(&Order{OrderID: "1", Status: ORDER_PART_FINISH, DealAmount: 0.5}))
+
+	assert.Equal(t, 3, len(events))
+	assert.Equal(t, ORDER_EVENT_NEW, events[0].Type)
+	assert.Equal(t, ORDER_EVENT_PARTIAL_FILL, events[1].Type)
+	assert.Equal(t, 0.5, events[1].Deal.FilledAmount)
+	assert.Equal(t, ORDER_EVENT_FILL, events[2].Type)
+	assert.InDelta(t, 1.5, events[2].Deal.FilledAmount, 1e-9)
+	assert.InDelta(t, 98, events[2].Deal.Price, 1e-9)
+
+	mo2, err := oms.LimitBuy("c2", pair, "90", "1")
+	assert.Nil(t, err)
+	assert.Nil(t, oms.Cancel("c2"))
+	assert.Equal(t, ORDER_EVENT_CANCEL, events[len(events)-1].Type)
+
+	api.reject = true
+	_, err = oms.LimitBuy("", pair, "90", "1")
+	assert.NotNil(t, err)
+	assert.Equal(t, ORDER_EVENT_REJECT, events[len(events)-1].Type)
+
+	got, ok := oms.GetByOrderID(mo2.Order.OrderID)
+	assert.True(t, ok)
+	assert.Equal(t, "c2", got.ClientID)
+	assert.Equal(t, 1, len(oms.Query(OrderFilter{Statuses: []TradeStatus{ORDER_FINISH}})))
+	assert.Equal(t, 3, len(oms.Query(OrderFilter{Pair: &pair})))
+	assert.Equal(t, 0, len(oms.OpenOrders()))
+	c1, _ := oms.Get("c1")
+	assert.Equal(t, 2, len(c1.Deals))
+}
+
+func TestOMSConcurrentUpdate(t *testing.T) {
+	pair := NewCurrencyPairFromString("BTC/USDT")
+	api := newMockSpotAPI("a")
+	api.dealDelay = 10 * time.Millisecond
+	var mu sync.Mutex
+	var events []OrderEvent
+	oms := NewOMS(api, func(e *OrderEvent) error {
+		mu.Lock()
+		events = append(events, *e)
+		mu.Unlock()
+		return nil
+	})
+	oms.SetFetchDeals(true)
+
+	_, err := oms.LimitBuy("c1", pair, "100", "2")
+	assert.Nil(t, err)
+	api.deals = []OrderDeal{{DealID: "d1", FilledAmount: 1}, {DealID: "d2", FilledAmount: 1}}
+
+	// 轮询和推送同时收到不同进度的成交，成交明细只记录一次
+	var wg sync.WaitGroup
+	for _, amount := range []float64{1, 2} {
+		wg.Add(1)
+		go func(amount float64) {
+			defer wg.Done()
+			oms.OnOrderUpdate(&Order{OrderID: "1", Status: ORDER_PART_FINISH, DealAmount: amount, AvgPrice: 100})
+		}(amount)
+		time.Sleep(time.Millisecond)
+	}
+	wg.Wait()
+
+	c1, _ := oms.Get("c1")
+	assert.Equal(t, 2, len(c1.Deals))
+	assert.Equal(t, 3, len(events))
+	assert.Equal(t, ORDER_EVENT_NEW, events[0].Type)
+	assert.Equal(t, "d1", events[1].Deal.DealID)
+	assert.Equal(t, "d2", events[2].Deal.DealID)
+}
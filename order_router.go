@@ -0,0 +1,389 @@
+package exapi
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 路由到某个交易所的子订单
+type RouteAllocation struct {
+	Exchange string  `json:"exchange"`      // 交易所名字
+	Price    float64 `json:"price,string"`  // 限价，为分配到的最差一档价格
+	Amount   float64 `json:"amount,string"` // 数量，基础币种
+	Cost     float64 `json:"cost,string"`   // 按深度预计的成交金额，不含手续费
+	Fee      float64 `json:"fee,string"`    // 按taker费率预计的手续费，报价币种
+
+	venue int // 在路由的交易所列表中的序号，同一交易所可有多个账户
+}
+
+// 路由计划
+type RoutePlan struct {
+	Market      CurrencyPair      `json:"market"`          // 交易对
+	Side        TradeSide         `json:"side"`            // 交易方向，BUY或SELL
+	Amount      float64           `json:"amount,string"`   // 目标数量
+	Allocations []RouteAllocation `json:"allocations"`     // 各交易所的子订单
+	Unfilled    float64           `json:"unfilled,string"` // 深度或余额不足而无法分配的数量
+	Errors      map[string]error  `json:"-"`               // 获取数据失败的交易所，不参与分配
+}
+
+// 子订单的执行结果
+type RoutedOrder struct {
+	RouteAllocation
+	Order *Order `json:"order"` // 最终的订单状态，下单失败时为nil
+	Err   error  `json:"-"`     // 下单或查询的错误
+}
+
+// 路由下单的汇总结果
+type RouteResult struct {
+	Market     CurrencyPair  `json:"market"`             // 交易对
+	Side       TradeSide     `json:"side"`               // 交易方向
+	Amount     float64       `json:"amount,string"`      // 目标数量
+	DealAmount float64       `json:"deal_amount,string"` // 总成交量
+	AvgPrice   float64       `json:"avg_price,string"`   // 成交均价
+	Fee        float64       `json:"fee,string"`         // 各交易所返回的手续费之和，币种以交易所为准
+	Orders     []RoutedOrder `json:"orders"`             // 子订单
+}
+
+type routeVenue struct {
+	api     SpotAPI
+	setting SymbolSetting
+	depth   *Depth
+	balance float64 // 买入时为报价币种余额，卖出时为基础币种余额
+}
+
+type routeLevel struct {
+	venue     int
+	price     float64
+	effective float64 // 计入手续费后的价格
+	amount    float64
+}
+
+/*
+跨交易所的智能下单。
+按各交易所的深度、taker手续费和账户可用余额，把目标数量分配到计入手续费后价格最优的交易所，
+并发下限价单，价格为分配到的最差一档，等待成交后撤销未成交的部分，汇总成交结果。
+*/
+type OrderRouter struct {
+	apis         []SpotAPI
+	depthSize    int
+	timeout      time.Duration
+	pollInterval time.Duration
+	cancelRest   bool
+}
+
+func NewOrderRouter(apis ...SpotAPI) *OrderRouter {
+	return &OrderRouter{
+		apis:         apis,
+		depthSize:    20,
+		timeout:      3 * time.Second,
+		pollInterval: 500 * time.Millisecond,
+		cancelRest:   true,
+	}
+}
+
+// 设置获取深度的档数，默认为20
+func (r *OrderRouter) SetDepthSize(size int) {
+	r.depthSize = size
+}
+
+// 设置等待子订单成交的时间和查询间隔，默认为3秒和500毫秒
+func (r *OrderRouter) SetTimeout(timeout, pollInterval time.Duration) {
+	r.timeout = timeout
+	r.pollInterval = pollInterval
+}
+
+// 设置超时后是否撤销未成交的部分，默认撤销
+func (r *OrderRouter) SetCancelRemaining(cancel bool) {
+	r.cancelRest = cancel
+}
+
+// 计算路由计划，不下单
+func (r *OrderRouter) Plan(pair CurrencyPair, side TradeSide, amount float64) (*RoutePlan, error) {
+	plan, _, err := r.plan(pair, side, amount)
+	return plan, err
+}
+
+// 计算路由计划并并发下单，返回汇总的成交结果
+func (r *OrderRouter) Execute(pair CurrencyPair, side TradeSide, amount float64) (*RouteResult, error) {
+	plan, venues, err := r.plan(pair, side, amount)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.Allocations) == 0 {
+		return nil, errors.New("no executable allocation")
+	}
+
+	orders := make([]RoutedOrder, len(plan.Allocations))
+	var wg sync.WaitGroup
+	for i := range plan.Allocations {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			alloc := plan.Allocations[i]
+			v := venues[alloc.venue]
+			order, err := r.execute(v.api, v.setting, pair, side, alloc)
+			orders[i] = RoutedOrder{RouteAllocation: alloc, Order: order, Err: err}
+		}(i)
+	}
+	wg.Wait()
+
+	result := &RouteResult{Market: pair, Side: side, Amount: amount, Orders: orders}
+	var value float64
+	for _, o := range orders {
+		if o.Order == nil {
+			continue
+		}
+		result.DealAmount += o.Order.DealAmount
+		value += o.Order.DealAmount * o.Order.AvgPrice
+		result.Fee += o.Order.Fee
+	}
+	if result.DealAmount > 0 {
+		result.AvgPrice = value / result.DealAmount
+	}
+	return result, nil
+}
+
+// 下单并等待成交，超时后按设置撤销未成交的部分
+func (r *OrderRouter) execute(api SpotAPI, ss SymbolSetting, pair CurrencyPair, side TradeSide, alloc RouteAllocation) (*Order, error) {
+	price := FloatToString(alloc.Price, PrecisionOf(alloc.Price))
+	amount := FloatToString(alloc.Amount, PrecisionOf(alloc.Amount))
+	if ss.TickSize > 0 || ss.PricePrecision > 0 {
+		price = ss.FormatPrice(alloc.Price)
+	}
+	if ss.StepSize > 0 || ss.AmountPrecision > 0 {
+		amount = ss.FormatAmount(alloc.Amount)
+	}
+
+	var order *Order
+	var err error
+	if side == BUY {
+		order, err = api.LimitBuy(pair, price, amount)
+	} else {
+		order, err = api.LimitSell(pair, price, amount)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(r.timeout)
+	for {
+		o, err := api.GetOrder(order.OrderID, pair)
+		if err == nil {
+			order = o
+			if order.Status.IsFinal() {
+				return order, nil
+			}
+		}
+		if !time.Now().Before(deadline) {
+			break
+		}
+		time.Sleep(r.pollInterval)
+	}
+
+	if !r.cancelRest {
+		return order, nil
+	}
+	if _, err := api.Cancel(order.OrderID, pair); err != nil {
+		return order, err
+	}
+	if o, err := api.GetOrder(order.OrderID, pair); err == nil {
+		order = o
+	}
+	return order, nil
+}
+
+func (r *OrderRouter) plan(pair CurrencyPair, side TradeSide, amount float64) (*RoutePlan, []routeVenue, error) {
+	if side != BUY && side != SELL {
+		return nil, nil, fmt.Errorf("unsupported TradeSide:%v", side)
+	}
+	if amount <= 0 {
+		return nil, nil, fmt.Errorf("invalid amount:%v", amount)
+	}
+
+	venues, errs := r.loadVenues(pair, side)
+	if len(venues) == 0 {
+		return nil, nil, errors.New("no available exchange")
+	}
+
+	plan := allocateRoute(venues, pair, side, amount)
+	plan.Errors = errs
+	return plan, venues, nil
+}
+
+// 并发获取各交易所的交易对信息、深度和余额
+func (r *OrderRouter) loadVenues(pair CurrencyPair, side TradeSide) ([]routeVenue, map[string]error) {
+	venues := make([]*routeVenue, len(r.apis))
+	errs := make([]error, len(r.apis))
+	var wg sync.WaitGroup
+	for i, api := range r.apis {
+		wg.Add(1)
+		go func(i int, api SpotAPI) {
+			defer wg.Done()
+			venues[i], errs[i] = r.loadVenue(api, pair, side)
+		}(i, api)
+	}
+	wg.Wait()
+
+	var result []routeVenue
+	failed := make(map[string]error)
+	for i, v := range venues {
+		if errs[i] != nil {
+			failed[r.apis[i].GetExchangeName()] = errs[i]
+			continue
+		}
+		result = append(result, *v)
+	}
+	return result, failed
+}
+
+func (r *OrderRouter) loadVenue(api SpotAPI, pair CurrencyPair, side TradeSide) (*routeVenue, error) {
+	ssm, err := api.GetAllCurrencyPair()
+	if err != nil {
+		return nil, err
+	}
+	setting, ok := ssm[pair.ToSymbol("/")]
+	if !ok {
+		return nil, ErrorAssetNotFound
+	}
+
+	depth, err := api.GetDepth(pair, r.depthSize, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := api.GetAccount()
+	if err != nil {
+		return nil, err
+	}
+	currency := pair.Money
+	if side == SELL {
+		currency = pair.Stock
+	}
+	return &routeVenue{api: api, setting: setting, depth: depth, balance: account.SubAccounts[currency].Amount}, nil
+}
+
+/*
+按计入taker手续费后的价格从优到劣逐档分配数量，受各交易所可用余额限制，
+每个交易所的数量按精度向下取整，不满足最小下单量的交易所不分配。
+取整或不分配的数量按价格从优到劣逐个交易所重新分配，直到分配结果不再变化。
+*/
+func allocateRoute(venues []routeVenue, pair CurrencyPair, side TradeSide, amount float64) *RoutePlan {
+	var levels []routeLevel
+	for i, v := range venues {
+		records := v.depth.AskList
+		factor := 1 + v.setting.TakerFee
+		if side == SELL {
+			records = v.depth.BidList
+			factor = 1 - v.setting.TakerFee
+		}
+		for _, rec := range records {
+			levels = append(levels, routeLevel{venue: i, price: rec.Price, effective: rec.Price * factor, amount: rec.Amount})
+		}
+	}
+	sort.SliceStable(levels, func(i, j int) bool {
+		if side == BUY {
+			return levels[i].effective < levels[j].effective
+		}
+		return levels[i].effective > levels[j].effective
+	})
+
+	// 交易所按最优一档的价格排序
+	var order []int
+	seen := make(map[int]bool)
+	for _, l := range levels {
+		if !seen[l.venue] {
+			seen[l.venue] = true
+			order = append(order, l.venue)
+		}
+	}
+
+	// 各交易所最多分配的数量，每次只调整一个交易所，使调整出的数量能分配给后面的交易所
+	limits := make([]float64, len(venues))
+	for i := range limits {
+		limits[i] = math.Inf(1)
+	}
+
+	for {
+		allocs := fillRoute(venues, levels, limits, side, amount)
+
+		changed := false
+		for _, i := range order {
+			a := &allocs[i]
+			if a.Amount <= 0 {
+				continue
+			}
+			ss := venues[i].setting
+			if ss.StepSize > 0 || ss.AmountPrecision > 0 {
+				truncated := ss.TruncateAmount(a.Amount)
+				a.Cost *= truncated / a.Amount
+				if truncated < a.Amount-1e-12 {
+					limits[i], changed = truncated, true
+				}
+				a.Amount = truncated
+			}
+			if a.Amount <= 0 || a.Amount < ss.MinSize || a.Cost < ss.MinNotional {
+				limits[i], changed = 0, true
+			}
+			if changed {
+				break
+			}
+		}
+		if changed {
+			continue
+		}
+
+		plan := &RoutePlan{Market: pair, Side: side, Amount: amount}
+		var allocated float64
+		for i, a := range allocs {
+			if a.Amount <= 0 {
+				continue
+			}
+			a.Fee = a.Cost * venues[i].setting.TakerFee
+			plan.Allocations = append(plan.Allocations, a)
+			allocated += a.Amount
+		}
+		plan.Unfilled = math.Max(amount-allocated, 0)
+		return plan
+	}
+}
+
+// 按档位顺序分配数量，每个交易所不超过limits
+func fillRoute(venues []routeVenue, levels []routeLevel, limits []float64, side TradeSide, amount float64) []RouteAllocation {
+	allocs := make([]RouteAllocation, len(venues))
+	budget := make([]float64, len(venues))
+	for i, v := range venues {
+		allocs[i].Exchange = v.api.GetExchangeName()
+		allocs[i].venue = i
+		budget[i] = v.balance
+	}
+
+	left := amount
+	for _, l := range levels {
+		if left <= 0 {
+			break
+		}
+		a := &allocs[l.venue]
+		q := math.Min(math.Min(left, l.amount), limits[l.venue]-a.Amount)
+		// 买入消耗报价币种，含手续费；卖出消耗基础币种
+		if side == BUY {
+			q = math.Min(q, budget[l.venue]/l.effective)
+			budget[l.venue] -= q * l.effective
+		} else {
+			q = math.Min(q, budget[l.venue])
+			budget[l.venue] -= q
+		}
+		if q <= 0 {
+			continue
+		}
+
+		a.Amount += q
+		a.Cost += q * l.price
+		a.Price = l.price
+		left -= q
+	}
+	return allocs
+}
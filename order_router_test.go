@@ -0,0 +1,89 @@
+package exapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestOrderRouter(t *testing.T) {
+	pair := NewCurrencyPairFromString("BTC/USDT")
+	ss := SymbolSetting{TakerFee: 0.001, StepSize: 0.01, TickSize: 0.01}
+	a := newMockSpotAPI("a")
+	a.ssm = map[string]SymbolSetting{"BTC/USDT": ss}
+	a.depth = &Depth{AskList: DepthRecords{{Price: 100, Amount: 1}, {Price: 102, Amount: 5}}}
+	a.acc = &Account{SubAccounts: map[Currency]SubAccount{USDT: {Amount: 1000}}}
+	a.fillRatio = 1
+	ss.TakerFee = 0.01
+	b := newMockSpotAPI("b")
+	b.ssm = map[string]SymbolSetting{"BTC/USDT": ss}
+	b.depth = &Depth{AskList: DepthRecords{{Price: 100.5, Amount: 2}, {Price: 101, Amount: 5}}}
+	b.acc = &Account{SubAccounts: map[Currency]SubAccount{USDT: {Amount: 150}}}
+	b.fillRatio = 0.5
+	router := NewOrderRouter(a, b)
+	router.SetTimeout(0, time.Millisecond)
+
+	// 计入手续费后依次为：a的100.1、b的101.505、b的102.01、a的102.102
+	plan, err := router.Plan(pair, BUY, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(plan.Allocations))
+	assert.Equal(t, "a", plan.Allocations[0].Exchange)
+	assert.InDelta(t, 1+(4-1-150/101.505), plan.Allocations[0].Amount, 0.01)
+	assert.Equal(t, 102.0, plan.Allocations[0].Price)
+	// b的余额只够买入约1.47个，按精度截断
+	assert.InDelta(t, 1.47, plan.Allocations[1].Amount, 1e-9)
+	assert.Equal(t, 100.5, plan.Allocations[1].Price)
+	assert.InDelta(t, 0, plan.Unfilled, 0.011)
+
+	result, err := router.Execute(pair, BUY, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(result.Orders))
+	assert.InDelta(t, plan.Allocations[0].Amount+plan.Allocations[1].Amount*0.5, result.DealAmount, 1e-9)
+	assert.Equal(t, ORDER_CANCEL, result.Orders[1].Order.Status)
+
+	_, err = router.Plan(pair, BUY_MARKET, 1)
+	assert.NotNil(t, err)
+}
+
+func TestOrderRouterReallocate(t *testing.T) {
+	pair := NewCurrencyPairFromString("BTC/USDT")
+	acc := &Account{SubAccounts: map[Currency]SubAccount{USDT: {Amount: 10000}}}
+	newAPI := func(ss SymbolSetting, asks DepthRecords) *mockSpotAPI {
+		// 同一交易所的多个账户
+		api := newMockSpotAPI("same")
+		api.ssm = map[string]SymbolSetting{"BTC/USDT": ss}
+		api.depth = &Depth{AskList: asks}
+		api.acc = acc
+		api.fillRatio = 1
+		return api
+	}
+
+	// b只分到0.4，不满足最小下单量，重新分配给a
+	a := newAPI(SymbolSetting{StepSize: 0.1}, DepthRecords{{Price: 100, Amount: 1.2}, {Price: 103, Amount: 10}})
+	b := newAPI(SymbolSetting{StepSize: 0.1, MinSize: 1}, DepthRecords{{Price: 101, Amount: 0.5}, {Price: 102, Amount: 10}})
+	plan, err := NewOrderRouter(a, b).Plan(pair, BUY, 1.6)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(plan.Allocations))
+	assert.InDelta(t, 1.6, plan.Allocations[0].Amount, 1e-9)
+	assert.Equal(t, 103.0, plan.Allocations[0].Price)
+	assert.InDelta(t, 0, plan.Unfilled, 1e-9)
+
+	// a截断为1.2，截掉的0.05重新分配给b
+	a = newAPI(SymbolSetting{StepSize: 0.1}, DepthRecords{{Price: 100, Amount: 1.25}, {Price: 103, Amount: 10}})
+	b = newAPI(SymbolSetting{StepSize: 0.1}, DepthRecords{{Price: 101, Amount: 0.5}, {Price: 102, Amount: 10}})
+	router := NewOrderRouter(a, b)
+	router.SetTimeout(0, time.Millisecond)
+	plan, err = router.Plan(pair, BUY, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(plan.Allocations))
+	assert.InDelta(t, 1.2, plan.Allocations[0].Amount, 1e-9)
+	assert.InDelta(t, 0.8, plan.Allocations[1].Amount, 1e-9)
+	assert.InDelta(t, 0, plan.Unfilled, 1e-9)
+
+	// 按序号而不是交易所名字找到各自的账户下单
+	result, err := router.Execute(pair, BUY, 2)
+	assert.Nil(t, err)
+	assert.InDelta(t, 1.2, a.orders["1"].Amount, 1e-9)
+	assert.InDelta(t, 0.8, b.orders["1"].Amount, 1e-9)
+	assert.InDelta(t, 2, result.DealAmount, 1e-9)
+}
@@ -0,0 +1,473 @@
+package exapi
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 持仓成本的计算方式
+type CostBasis int
+
+const (
+	COST_FIFO    CostBasis = iota // 先进先出
+	COST_LIFO                     // 后进先出
+	COST_AVERAGE                  // 移动平均
+)
+
+func (b CostBasis) String() string {
+	switch b {
+	case COST_FIFO:
+		return "FIFO"
+	case COST_LIFO:
+		return "LIFO"
+	case COST_AVERAGE:
+		return "AVERAGE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// 统一的成交记录，来自OrderDeal、GetUserTrades或实时推送
+type Fill struct {
+	ID       string       `json:"id"`            // 成交id，非空时用于去重
+	OrderID  string       `json:"order_id"`      // 订单id，非空时同一订单的成交按订单去重
+	Exchange string       `json:"exchange"`      // 交易所名字
+	Market   CurrencyPair `json:"market"`        // 交易对
+	Side     TradeSide    `json:"side"`          // 交易方向
+	Price    float64      `json:"price,string"`  // 成交价
+	Amount   float64      `json:"amount,string"` // 成交量
+	Fee      float64      `json:"fee,string"`    // 手续费，报价币种，为0时按设置的taker费率估算
+	TS       int64        `json:"ts"`            // 时间，单位为毫秒(millisecond)
+}
+
+// 用订单成交明细构建成交记录，成交价为成交金额除以成交量
+func FillFromDeal(exchange string, deal *OrderDeal) Fill {
+	price := deal.Price
+	if deal.FilledAmount > 0 && deal.FilledCashAmount > 0 {
+		price = deal.FilledCashAmount / deal.FilledAmount
+	}
+	return Fill{
+		ID:       deal.DealID,
+		OrderID:  deal.OrderID,
+		Exchange: exchange,
+		Market:   deal.Market,
+		Side:     deal.Side,
+		Price:    price,
+		Amount:   deal.FilledAmount,
+		Fee:      quoteFee(deal.Market, price, deal.Fee, deal.FeeCurrency),
+		TS:       deal.TS,
+	}
+}
+
+// 用GetUserTrades返回的成交构建成交记录
+func FillFromTrade(exchange string, trade *Trade) Fill {
+	id := ""
+	if trade.Tid != 0 {
+		id = ToString(trade.Tid)
+	}
+	return Fill{
+		ID:       id,
+		Exchange: exchange,
+		Market:   trade.Market,
+		Side:     trade.Side,
+		Price:    trade.Price,
+		Amount:   trade.Amount,
+		Fee:      quoteFee(trade.Market, trade.Price, trade.Fee, trade.FeeCurrency),
+		TS:       trade.TS,
+	}
+}
+
+// 手续费换算为报价币种，以其他币种(如BNB、点卡)收取时无法换算，返回0，由PnLTracker按费率估算
+func quoteFee(pair CurrencyPair, price, fee float64, currency Currency) float64 {
+	switch {
+	case currency.Equal(pair.Money):
+		return fee
+	case currency.Equal(pair.Stock):
+		return fee * price
+	default:
+		return 0
+	}
+}
+
+// 交易所在交易对上的持仓和盈亏，金额均为报价币种
+type Position struct {
+	Exchange      string       `json:"exchange"`              // 交易所名字
+	Market        CurrencyPair `json:"market"`                // 交易对
+	Amount        float64      `json:"amount,string"`         // 持仓数量，负数为卖出多于买入
+	AvgCost       float64      `json:"avg_cost,string"`       // 持仓均价
+	RealizedPnL   float64      `json:"realized_pnl,string"`   // 已实现盈亏，不含手续费
+	Fee           float64      `json:"fee,string"`            // 累计手续费
+	EstimatedFee  float64      `json:"estimated_fee,string"`  // 累计手续费中按费率估算的部分
+	NetPnL        float64      `json:"net_pnl,string"`        // 已实现盈亏扣除手续费
+	MarkPrice     float64      `json:"mark_price,string"`     // 最新价格，0表示未知
+	UnrealizedPnL float64      `json:"unrealized_pnl,string"` // 按最新价格计算的未实现盈亏
+	TS            int64        `json:"ts"`                    // 最近成交时间，单位为毫秒(millisecond)
+}
+
+// 每日的盈亏统计
+type DailyPnL struct {
+	Date         string  `json:"date"`                 // 日期，2006-01-02格式
+	Exchange     string  `json:"exchange"`             // 交易所名字
+	Symbol       string  `json:"symbol"`               // 交易对，BASE/QUOTE格式
+	Trades       int     `json:"trades"`               // 成交笔数
+	Volume       float64 `json:"volume,string"`        // 成交金额
+	RealizedPnL  float64 `json:"realized_pnl,string"`  // 已实现盈亏，不含手续费
+	Fee          float64 `json:"fee,string"`           // 手续费
+	EstimatedFee float64 `json:"estimated_fee,string"` // 手续费中按费率估算的部分
+	NetPnL       float64 `json:"net_pnl,string"`       // 已实现盈亏扣除手续费
+}
+
+// 订单已计算的成交量
+type pnlOrder struct {
+	deals     float64 // 交易所返回的成交明细
+	estimated float64 // OMS按订单估算的成交
+}
+
+type pnlLot struct {
+	amount float64 // 正数为买入，负数为卖出
+	price  float64
+}
+
+type pnlPosition struct {
+	Position
+	lots []pnlLot
+}
+
+/*
+盈亏统计。
+按交易所和交易对维护持仓，成交按成本方式(先进先出、后进先出、移动平均)与反方向的持仓抵消，
+抵消部分计入已实现盈亏，手续费在成交时扣除，最新价格用于计算未实现盈亏。
+成交可以来自OrderDeal、GetUserTrades的历史或实时推送，id相同的成交只计算一次，
+历史成交应按时间顺序传入。
+OMS在交易所没有返回成交明细时按订单估算成交，估算的成交没有id，同一订单按交易所成交明细和估算成交中
+累计量较大的一方计算，避免OMS的成交事件与LoadOrderDeals重复计算。
+成交没有手续费时按设置的taker费率估算，估算的部分另外计入EstimatedFee。
+*/
+type PnLTracker struct {
+	sync.Mutex
+	basis     CostBasis
+	location  *time.Location
+	fees      map[string]TradeFee
+	positions map[string]*pnlPosition
+	daily     map[string]*DailyPnL
+	seen      map[string]bool
+	orders    map[string]*pnlOrder
+}
+
+func NewPnLTracker(basis CostBasis) *PnLTracker {
+	return &PnLTracker{
+		basis:     basis,
+		location:  time.UTC,
+		fees:      make(map[string]TradeFee),
+		positions: make(map[string]*pnlPosition),
+		daily:     make(map[string]*DailyPnL),
+		seen:      make(map[string]bool),
+		orders:    make(map[string]*pnlOrder),
+	}
+}
+
+// 设置交易所的手续费率，成交没有手续费时按taker费率估算
+func (pt *PnLTracker) SetFee(exchange string, fee TradeFee) {
+	pt.Lock()
+	pt.fees[exchange] = fee
+	pt.Unlock()
+}
+
+// 设置每日统计的时区，默认为UTC
+func (pt *PnLTracker) SetLocation(loc *time.Location) {
+	pt.Lock()
+	pt.location = loc
+	pt.Unlock()
+}
+
+// 处理一笔成交
+func (pt *PnLTracker) OnFill(fill Fill) error {
+	if fill.Amount <= 0 || fill.Price <= 0 {
+		return fmt.Errorf("invalid fill:%v", fill.ID)
+	}
+	sign := 1.0
+	switch fill.Side {
+	case BUY, BUY_MARKET:
+	case SELL, SELL_MARKET:
+		sign = -1
+	default:
+		return fmt.Errorf("unsupported TradeSide:%v", fill.Side)
+	}
+
+	pt.Lock()
+	defer pt.Unlock()
+
+	symbol := strings.ToUpper(fill.Market.ToSymbol("/"))
+	if fill.ID != "" {
+		key := fill.Exchange + "|" + symbol + "|" + fill.ID
+		if pt.seen[key] {
+			return nil
+		}
+		pt.seen[key] = true
+	}
+	if fill.OrderID != "" {
+		amount := pt.orderAmount(fill.Exchange+"|"+symbol+"|"+fill.OrderID, fill.ID != "", fill.Amount)
+		if amount < 1e-12 {
+			return nil
+		}
+		fill.Fee *= amount / fill.Amount
+		fill.Amount = amount
+	}
+
+	fee, estimated := fill.Fee, 0.0
+	if fee == 0 {
+		fee = fill.Amount * fill.Price * pt.fees[fill.Exchange].TakerRate
+		estimated = fee
+	}
+
+	p := pt.position(fill.Exchange, fill.Market)
+	realized := p.match(pt.basis, sign*fill.Amount, fill.Price)
+	p.RealizedPnL += realized
+	p.Fee += fee
+	p.EstimatedFee += estimated
+	p.NetPnL = p.RealizedPnL - p.Fee
+	if fill.TS > p.TS {
+		p.TS = fill.TS
+	}
+	p.update()
+
+	date := time.Unix(0, fill.TS*int64(time.Millisecond)).In(pt.location).Format("2006-01-02")
+	key := date + "|" + fill.Exchange + "|" + symbol
+	d, ok := pt.daily[key]
+	if !ok {
+		d = &DailyPnL{Date: date, Exchange: fill.Exchange, Symbol: symbol}
+		pt.daily[key] = d
+	}
+	d.Trades++
+	d.Volume += fill.Amount * fill.Price
+	d.RealizedPnL += realized
+	d.Fee += fee
+	d.EstimatedFee += estimated
+	d.NetPnL = d.RealizedPnL - d.Fee
+	return nil
+}
+
+// 记录订单的成交量，返回需要计算的新增部分
+func (pt *PnLTracker) orderAmount(key string, deal bool, amount float64) float64 {
+	o, ok := pt.orders[key]
+	if !ok {
+		o = &pnlOrder{}
+		pt.orders[key] = o
+	}
+	counted := math.Max(o.deals, o.estimated)
+	if deal {
+		o.deals += amount
+	} else {
+		o.estimated += amount
+	}
+	return math.Max(o.deals, o.estimated) - counted
+}
+
+// 处理订单成交明细，可用于OMS的成交事件
+func (pt *PnLTracker) OnDeal(exchange string, deal *OrderDeal) error {
+	return pt.OnFill(FillFromDeal(exchange, deal))
+}
+
+// 按时间顺序处理GetUserTrades返回的成交
+func (pt *PnLTracker) OnTrades(exchange string, trades []Trade) error {
+	sorted := append([]Trade(nil), trades...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].TS < sorted[j].TS
+	})
+	for i := range sorted {
+		if err := pt.OnFill(FillFromTrade(exchange, &sorted[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 用api.GetUserTrades加载交易对的历史成交
+func (pt *PnLTracker) LoadUserTrades(api SpotAPI, pair CurrencyPair) error {
+	trades, err := api.GetUserTrades(pair)
+	if err != nil {
+		return err
+	}
+	return pt.OnTrades(api.GetExchangeName(), trades)
+}
+
+// 用api.GetOrderDeal加载订单的成交明细
+func (pt *PnLTracker) LoadOrderDeals(api SpotAPI, orderID string, pair CurrencyPair) error {
+	deals, err := api.GetOrderDeal(orderID, pair)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(deals, func(i, j int) bool {
+		return deals[i].TS < deals[j].TS
+	})
+	for i := range deals {
+		if err := pt.OnDeal(api.GetExchangeName(), &deals[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 更新最新价格，用于计算未实现盈亏
+func (pt *PnLTracker) Mark(exchange string, pair CurrencyPair, price float64) {
+	pt.Lock()
+	defer pt.Unlock()
+	key := exchange + "|" + strings.ToUpper(pair.ToSymbol("/"))
+	if p, ok := pt.positions[key]; ok {
+		p.MarkPrice = price
+		p.update()
+	}
+}
+
+// 用行情更新最新价格，价格取最优买卖价的中间价，没有时取最新价
+func (pt *PnLTracker) OnTicker(exchange string, ticker *Ticker) {
+	price := ticker.Last
+	if ticker.Buy > 0 && ticker.Sell > 0 {
+		price = (ticker.Buy + ticker.Sell) / 2
+	}
+	if price > 0 {
+		pt.Mark(exchange, ticker.Market, price)
+	}
+}
+
+// 查询持仓
+func (pt *PnLTracker) Position(exchange string, pair CurrencyPair) (Position, bool) {
+	pt.Lock()
+	defer pt.Unlock()
+	p, ok := pt.positions[exchange+"|"+strings.ToUpper(pair.ToSymbol("/"))]
+	if !ok {
+		return Position{}, false
+	}
+	return p.Position, true
+}
+
+// 所有持仓，按交易所和交易对排序
+func (pt *PnLTracker) Positions() []Position {
+	pt.Lock()
+	defer pt.Unlock()
+
+	positions := make([]Position, 0, len(pt.positions))
+	for _, p := range pt.positions {
+		positions = append(positions, p.Position)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		if positions[i].Exchange != positions[j].Exchange {
+			return positions[i].Exchange < positions[j].Exchange
+		}
+		return positions[i].Market.ToSymbol("/") < positions[j].Market.ToSymbol("/")
+	})
+	return positions
+}
+
+// 每日盈亏统计，按日期、交易所和交易对排序
+func (pt *PnLTracker) DailyReport() []DailyPnL {
+	pt.Lock()
+	defer pt.Unlock()
+
+	report := make([]DailyPnL, 0, len(pt.daily))
+	for _, d := range pt.daily {
+		report = append(report, *d)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		a, b := report[i], report[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Exchange != b.Exchange {
+			return a.Exchange < b.Exchange
+		}
+		return a.Symbol < b.Symbol
+	})
+	return report
+}
+
+// 以csv格式导出每日盈亏统计
+func (pt *PnLTracker) WriteDailyCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"date", "exchange", "symbol", "trades", "volume", "realized_pnl", "fee", "estimated_fee", "net_pnl"})
+	for _, d := range pt.DailyReport() {
+		cw.Write([]string{
+			d.Date, d.Exchange, d.Symbol, ToString(d.Trades),
+			FloatToString(d.Volume, 8), FloatToString(d.RealizedPnL, 8),
+			FloatToString(d.Fee, 8), FloatToString(d.EstimatedFee, 8), FloatToString(d.NetPnL, 8),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (pt *PnLTracker) position(exchange string, pair CurrencyPair) *pnlPosition {
+	key := exchange + "|" + strings.ToUpper(pair.ToSymbol("/"))
+	p, ok := pt.positions[key]
+	if !ok {
+		p = &pnlPosition{Position: Position{Exchange: exchange, Market: pair}}
+		pt.positions[key] = p
+	}
+	return p
+}
+
+/*
+用带符号的成交量amount与反方向的持仓抵消，返回已实现盈亏，剩余部分开新的持仓。
+移动平均方式只保留一个持仓，同方向成交时合并计算均价。
+*/
+func (p *pnlPosition) match(basis CostBasis, amount, price float64) (realized float64) {
+	for amount != 0 && len(p.lots) > 0 && (p.lots[0].amount > 0) != (amount > 0) {
+		i := 0
+		if basis == COST_LIFO {
+			i = len(p.lots) - 1
+		}
+		lot := &p.lots[i]
+		q := math.Min(math.Abs(amount), math.Abs(lot.amount))
+		if lot.amount > 0 {
+			realized += q * (price - lot.price)
+			lot.amount -= q
+			amount += q
+		} else {
+			realized += q * (lot.price - price)
+			lot.amount += q
+			amount -= q
+		}
+		if math.Abs(lot.amount) < 1e-12 {
+			p.lots = append(p.lots[:i], p.lots[i+1:]...)
+		}
+		if math.Abs(amount) < 1e-12 {
+			amount = 0
+		}
+	}
+
+	if amount != 0 {
+		if basis == COST_AVERAGE && len(p.lots) > 0 {
+			lot := &p.lots[0]
+			total := lot.amount + amount
+			lot.price = (lot.amount*lot.price + amount*price) / total
+			lot.amount = total
+		} else {
+			p.lots = append(p.lots, pnlLot{amount: amount, price: price})
+		}
+	}
+	return realized
+}
+
+// 按剩余的持仓更新数量、均价和未实现盈亏
+func (p *pnlPosition) update() {
+	var amount, cost float64
+	for _, lot := range p.lots {
+		amount += lot.amount
+		cost += lot.amount * lot.price
+	}
+	p.Amount = amount
+	p.AvgCost = 0
+	if amount != 0 {
+		p.AvgCost = cost / amount
+	}
+	p.UnrealizedPnL = 0
+	if p.MarkPrice > 0 {
+		p.UnrealizedPnL = amount*p.MarkPrice - cost
+	}
+}
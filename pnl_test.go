@@ -0,0 +1,84 @@
+package exapi
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestPnLTracker(t *testing.T) {
+	pair := NewCurrencyPairFromString("BTC/USDT")
+	day1 := int64(1577836800000) // 2020-01-01 00:00:00 UTC
+	day2 := day1 + 86400000
+	trades := []Trade{
+		{Tid: 3, Side: SELL, Amount: 1.5, Price: 130, TS: day2, Market: pair},
+		{Tid: 1, Side: BUY, Amount: 1, Price: 100, TS: day1, Market: pair},
+		{Tid: 2, Side: BUY, Amount: 1, Price: 120, TS: day1 + 1000, Market: pair},
+	}
+
+	expected := map[CostBasis]float64{
+		COST_FIFO:    30 + 0.5*10, // 1@100 + 0.5@120
+		COST_LIFO:    10 + 0.5*30, // 1@120 + 0.5@100
+		COST_AVERAGE: 1.5 * 20,    // 均价110
+	}
+	for basis, realized := range expected {
+		pt := NewPnLTracker(basis)
+		pt.SetFee("a", TradeFee{TakerRate: 0.001})
+		assert.Nil(t, pt.OnTrades("a", trades))
+		assert.Nil(t, pt.OnTrades("a", trades)) // 重复的成交被忽略
+		pt.Mark("a", pair, 140)
+
+		p, ok := pt.Position("a", pair)
+		assert.True(t, ok)
+		assert.InDelta(t, 0.5, p.Amount, 1e-9, basis.String())
+		assert.InDelta(t, realized, p.RealizedPnL, 1e-9, basis.String())
+		assert.InDelta(t, (100+120+195)*0.001, p.Fee, 1e-9)
+		assert.InDelta(t, 0.5*140-0.5*p.AvgCost, p.UnrealizedPnL, 1e-9)
+	}
+
+	pt := NewPnLTracker(COST_FIFO)
+	assert.Nil(t, pt.OnTrades("a", trades))
+	// 卖出多于持仓后开空
+	assert.Nil(t, pt.OnDeal("a", &OrderDeal{DealID: "d1", Side: SELL, FilledAmount: 1, FilledCashAmount: 125, Market: pair, TS: day2}))
+	p, _ := pt.Position("a", pair)
+	assert.InDelta(t, -0.5, p.Amount, 1e-9)
+	assert.InDelta(t, 125, p.AvgCost, 1e-9)
+	assert.InDelta(t, 35+2.5, p.RealizedPnL, 1e-9)
+
+	report := pt.DailyReport()
+	assert.Equal(t, 2, len(report))
+	assert.Equal(t, "2020-01-01", report[0].Date)
+	assert.Equal(t, 2, report[0].Trades)
+	assert.Equal(t, 0.0, report[0].RealizedPnL)
+	assert.InDelta(t, 37.5, report[1].RealizedPnL, 1e-9)
+
+	var buf bytes.Buffer
+	assert.Nil(t, pt.WriteDailyCSV(&buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 3, len(lines))
+	assert.True(t, strings.HasPrefix(lines[1], "2020-01-01,a,BTC/USDT,2,"))
+}
+
+func TestPnLTrackerOrderDeals(t *testing.T) {
+	pair := NewCurrencyPairFromString("BTC/USDT")
+	pt := NewPnLTracker(COST_FIFO)
+	pt.SetFee("a", TradeFee{TakerRate: 0.001})
+
+	// OMS按订单估算的成交没有id，手续费按费率估算
+	assert.Nil(t, pt.OnDeal("a", &OrderDeal{OrderID: "o1", Side: BUY, FilledAmount: 1, FilledCashAmount: 100, Market: pair}))
+	// 交易所的成交明细与估算的成交重复的部分被忽略，只计算超出的0.5
+	assert.Nil(t, pt.OnDeal("a", &OrderDeal{OrderID: "o1", DealID: "d1", Side: BUY, FilledAmount: 0.8, FilledCashAmount: 80,
+		Market: pair, Fee: 0.0008, FeeCurrency: pair.Stock}))
+	assert.Nil(t, pt.OnDeal("a", &OrderDeal{OrderID: "o1", DealID: "d2", Side: BUY, FilledAmount: 0.7, FilledCashAmount: 70,
+		Market: pair, Fee: 0.07, FeeCurrency: pair.Money}))
+
+	p, _ := pt.Position("a", pair)
+	assert.InDelta(t, 1.5, p.Amount, 1e-9)
+	assert.InDelta(t, 0.1, p.EstimatedFee, 1e-9)
+	assert.InDelta(t, 0.1+0.07*0.5/0.7, p.Fee, 1e-9)
+
+	// 以其他币种收取的手续费无法换算，按费率估算
+	assert.Equal(t, 0.0, FillFromTrade("a", &Trade{Amount: 1, Price: 100, Market: pair, Fee: 0.01, FeeCurrency: NewCurrency("BNB")}).Fee)
+	assert.Equal(t, 0.1, FillFromTrade("a", &Trade{Amount: 1, Price: 100, Market: pair, Fee: 0.001, FeeCurrency: pair.Stock}).Fee)
+}
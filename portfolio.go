@@ -0,0 +1,300 @@
+package exapi
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 两步换算时优先使用的中间币种
+var DefaultValuationBridges = []Currency{USDT, NewCurrency("BTC"), NewCurrency("ETH")}
+
+/*
+资产估值。
+用行情构建币种之间的价格图，把账户中每个币种的余额换算成计价币种，例如USDT或BTC。
+换算路径依次尝试直接交易对、反向交易对和经过一个中间币种的两步换算，
+价格取最优买卖价的中间价，没有买卖价时取最新价。
+*/
+type Valuator struct {
+	sync.Mutex
+	quote   Currency
+	bridges []Currency
+	rates   map[string]map[string]float64 // rates[a][b]为1个a可换的b
+}
+
+func NewValuator(quote Currency) *Valuator {
+	return &Valuator{
+		quote:   NewCurrency(quote.Symbol()),
+		bridges: DefaultValuationBridges,
+		rates:   make(map[string]map[string]float64),
+	}
+}
+
+// 计价币种
+func (v *Valuator) Quote() Currency {
+	return v.quote
+}
+
+// 设置两步换算时优先使用的中间币种，其余币种按字母序尝试
+func (v *Valuator) SetBridges(bridges ...Currency) {
+	v.Lock()
+	v.bridges = bridges
+	v.Unlock()
+}
+
+// 用api.GetAllTicker获取所有行情并更新价格
+func (v *Valuator) Poll(api SpotAPI) error {
+	tickers, err := api.GetAllTicker()
+	if err != nil {
+		return err
+	}
+	v.OnTickers(tickers)
+	return nil
+}
+
+// 更新批量行情
+func (v *Valuator) OnTickers(tickers []Ticker) {
+	v.Lock()
+	for i := range tickers {
+		v.updateTicker(&tickers[i])
+	}
+	v.Unlock()
+}
+
+// 更新推送的行情，可作为SubTicker的回调
+func (v *Valuator) OnTicker(ticker *Ticker) error {
+	v.Lock()
+	v.updateTicker(ticker)
+	v.Unlock()
+	return nil
+}
+
+func (v *Valuator) updateTicker(ticker *Ticker) {
+	price := ticker.Last
+	if ticker.Buy > 0 && ticker.Sell > 0 {
+		price = (ticker.Buy + ticker.Sell) / 2
+	}
+	if price <= 0 {
+		return
+	}
+	base := strings.ToUpper(ticker.Market.Stock.Symbol())
+	quote := strings.ToUpper(ticker.Market.Money.Symbol())
+	if v.rates[base] == nil {
+		v.rates[base] = make(map[string]float64)
+	}
+	if v.rates[quote] == nil {
+		v.rates[quote] = make(map[string]float64)
+	}
+	v.rates[base][quote] = price
+	v.rates[quote][base] = 1 / price
+}
+
+// 1个币种c换算成计价币种的价格
+func (v *Valuator) Price(c Currency) (float64, bool) {
+	v.Lock()
+	defer v.Unlock()
+	return v.price(strings.ToUpper(c.Symbol()))
+}
+
+func (v *Valuator) price(c string) (float64, bool) {
+	quote := v.quote.Symbol()
+	if c == quote {
+		return 1, true
+	}
+	// 直接或反向交易对
+	if p, ok := v.rates[c][quote]; ok {
+		return p, true
+	}
+
+	// 经过一个中间币种
+	for _, bridge := range v.bridgeOrder(c) {
+		if p1, ok := v.rates[c][bridge]; ok {
+			if p2, ok := v.rates[bridge][quote]; ok {
+				return p1 * p2, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func (v *Valuator) bridgeOrder(c string) []string {
+	var order []string
+	seen := make(map[string]bool)
+	for _, b := range v.bridges {
+		s := strings.ToUpper(b.Symbol())
+		if !seen[s] {
+			seen[s] = true
+			order = append(order, s)
+		}
+	}
+	var rest []string
+	for b := range v.rates[c] {
+		if !seen[b] {
+			rest = append(rest, b)
+		}
+	}
+	sort.Strings(rest)
+	return append(order, rest...)
+}
+
+/*
+估算账户的总资产和净资产，填充acc.Asset和acc.NetAsset。
+总资产为可用加冻结，净资产再减去借贷，返回无法换算的币种，这些币种不计入资产。
+*/
+func (v *Valuator) Value(acc *Account) []Currency {
+	v.Lock()
+	defer v.Unlock()
+
+	acc.Asset, acc.NetAsset = 0, 0
+	var unpriced []Currency
+	for c, sub := range acc.SubAccounts {
+		total := sub.Amount + sub.FrozenAmount
+		if total == 0 && sub.LoanAmount == 0 {
+			continue
+		}
+		price, ok := v.price(strings.ToUpper(c.Symbol()))
+		if !ok {
+			unpriced = append(unpriced, c)
+			continue
+		}
+		acc.Asset += total * price
+		acc.NetAsset += (total - sub.LoanAmount) * price
+	}
+	sortCurrencies(unpriced)
+	return unpriced
+}
+
+// 获取api的账户，并用该交易所的行情估值，填充Asset和NetAsset
+func ValueAccount(api SpotAPI, quote Currency) (*Account, []Currency, error) {
+	v := NewValuator(quote)
+	if err := v.Poll(api); err != nil {
+		return nil, nil, err
+	}
+	acc, err := api.GetAccount()
+	if err != nil {
+		return nil, nil, err
+	}
+	return acc, v.Value(acc), nil
+}
+
+// 组合中一个币种的汇总余额
+type PortfolioBalance struct {
+	Currency     Currency `json:"currency"`             // 币种
+	Amount       float64  `json:"amount,string"`        // 可用余额
+	FrozenAmount float64  `json:"frozen_amount,string"` // 冻结余额
+	LoanAmount   float64  `json:"loan_amount,string"`   // 借贷余额
+	Value        float64  `json:"value,string"`         // 净余额换算成计价币种的价值，无法换算时为0
+	Priced       bool     `json:"priced"`               // 是否可以换算
+}
+
+// 多个交易所账户汇总的资产组合
+type Portfolio struct {
+	Quote    Currency                      `json:"quote"`           // 计价币种
+	Asset    float64                       `json:"asset,string"`    // 总资产
+	NetAsset float64                       `json:"netasset,string"` // 净资产
+	Balances map[Currency]PortfolioBalance `json:"balances"`        // 每个币种的汇总余额
+	Accounts []*Account                    `json:"accounts"`        // 各交易所已估值的账户
+	Unpriced []Currency                    `json:"unpriced"`        // 在某个交易所无法换算的币种
+	Errors   map[string]error              `json:"-"`               // 获取数据失败的交易所，不计入组合
+	TS       int64                         `json:"ts"`              // 估值时间，单位为毫秒(millisecond)
+}
+
+/*
+并发获取各交易所的行情和账户，每个账户用所在交易所的行情估值后汇总。
+所有交易所都失败时返回错误。
+*/
+func NewPortfolio(quote Currency, apis ...SpotAPI) (*Portfolio, error) {
+	accounts := make([]*Account, len(apis))
+	valuators := make([]*Valuator, len(apis))
+	errs := make([]error, len(apis))
+	var wg sync.WaitGroup
+	for i, api := range apis {
+		wg.Add(1)
+		go func(i int, api SpotAPI) {
+			defer wg.Done()
+			valuators[i] = NewValuator(quote)
+			if errs[i] = valuators[i].Poll(api); errs[i] != nil {
+				return
+			}
+			accounts[i], errs[i] = api.GetAccount()
+		}(i, api)
+	}
+	wg.Wait()
+
+	p := newPortfolio(quote)
+	for i, api := range apis {
+		if errs[i] != nil {
+			p.Errors[api.GetExchangeName()] = errs[i]
+			continue
+		}
+		if accounts[i].Exchange == "" {
+			accounts[i].Exchange = api.GetExchangeName()
+		}
+		p.add(valuators[i], accounts[i])
+	}
+	if len(p.Accounts) == 0 && len(apis) > 0 {
+		return p, errors.New("no available exchange")
+	}
+	sortCurrencies(p.Unpriced)
+	return p, nil
+}
+
+// 用同一个估值器汇总多个账户
+func AggregatePortfolio(v *Valuator, accounts ...*Account) *Portfolio {
+	p := newPortfolio(v.Quote())
+	for _, acc := range accounts {
+		p.add(v, acc)
+	}
+	sortCurrencies(p.Unpriced)
+	return p
+}
+
+func newPortfolio(quote Currency) *Portfolio {
+	return &Portfolio{
+		Quote:    NewCurrency(quote.Symbol()),
+		Balances: make(map[Currency]PortfolioBalance),
+		Errors:   make(map[string]error),
+		TS:       time.Now().UnixNano() / int64(time.Millisecond),
+	}
+}
+
+func (p *Portfolio) add(v *Valuator, acc *Account) {
+	v.Value(acc)
+	p.Accounts = append(p.Accounts, acc)
+	p.Asset += acc.Asset
+	p.NetAsset += acc.NetAsset
+
+	for c, sub := range acc.SubAccounts {
+		b := p.Balances[c]
+		b.Currency = c
+		b.Amount += sub.Amount
+		b.FrozenAmount += sub.FrozenAmount
+		b.LoanAmount += sub.LoanAmount
+		if price, ok := v.Price(c); ok {
+			b.Value += (sub.Amount + sub.FrozenAmount - sub.LoanAmount) * price
+			b.Priced = true
+		} else if sub.Amount+sub.FrozenAmount != 0 || sub.LoanAmount != 0 {
+			if !containsCurrency(p.Unpriced, c) {
+				p.Unpriced = append(p.Unpriced, c)
+			}
+		}
+		p.Balances[c] = b
+	}
+}
+
+func containsCurrency(cs []Currency, c Currency) bool {
+	for _, x := range cs {
+		if x.Equal(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortCurrencies(cs []Currency) {
+	sort.Slice(cs, func(i, j int) bool {
+		return cs[i].Symbol() < cs[j].Symbol()
+	})
+}
@@ -0,0 +1,50 @@
+package exapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValuator(t *testing.T) {
+	BTC, ETH, XYZ := NewCurrency("BTC"), NewCurrency("ETH"), NewCurrency("XYZ")
+	v := NewValuator(USDT)
+	v.OnTickers([]Ticker{
+		{Market: NewCurrencyPair(BTC, USDT), Buy: 9999, Sell: 10001},
+		{Market: NewCurrencyPair(ETH, BTC), Last: 0.02},
+		{Market: NewCurrencyPair(USDT, NewCurrency("TRY")), Last: 8},
+	})
+
+	p, ok := v.Price(BTC)
+	assert.True(t, ok)
+	assert.Equal(t, 10000.0, p)
+	p, ok = v.Price(ETH)
+	assert.True(t, ok)
+	assert.InDelta(t, 200, p, 1e-9)
+	p, ok = v.Price(NewCurrency("TRY"))
+	assert.True(t, ok)
+	assert.InDelta(t, 0.125, p, 1e-9)
+	_, ok = v.Price(XYZ)
+	assert.False(t, ok)
+
+	acc := &Account{Exchange: "a", SubAccounts: map[Currency]SubAccount{
+		BTC:  {Currency: BTC, Amount: 1, FrozenAmount: 0.5, LoanAmount: 0.5},
+		ETH:  {Currency: ETH, Amount: 10},
+		USDT: {Currency: USDT, Amount: 100},
+		XYZ:  {Currency: XYZ, Amount: 3},
+	}}
+	unpriced := v.Value(acc)
+	assert.Equal(t, []Currency{XYZ}, unpriced)
+	assert.InDelta(t, 15000+2000+100, acc.Asset, 1e-6)
+	assert.InDelta(t, 10000+2000+100, acc.NetAsset, 1e-6)
+
+	acc2 := &Account{Exchange: "b", SubAccounts: map[Currency]SubAccount{
+		BTC: {Currency: BTC, Amount: 2},
+	}}
+	pf := AggregatePortfolio(v, acc, acc2)
+	assert.InDelta(t, 37100, pf.Asset, 1e-6)
+	assert.InDelta(t, 32100, pf.NetAsset, 1e-6)
+	assert.Equal(t, 3.0, pf.Balances[BTC].Amount)
+	assert.InDelta(t, 30000, pf.Balances[BTC].Value, 1e-6)
+	assert.False(t, pf.Balances[XYZ].Priced)
+	assert.Equal(t, []Currency{XYZ}, pf.Unpriced)
+}
@@ -0,0 +1,290 @@
+package exapi
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// 下单校验失败的原因
+type ValidationCode int
+
+const (
+	VALIDATION_UNKNOWN_PAIR         ValidationCode = 1 + iota // 交易对不存在
+	VALIDATION_INVALID_PRICE                                  // 价格不合法
+	VALIDATION_INVALID_AMOUNT                                 // 数量不合法
+	VALIDATION_PRICE_TOO_LOW                                  // 价格低于最小价格
+	VALIDATION_PRICE_TOO_HIGH                                 // 价格高于最大价格
+	VALIDATION_PRICE_TICK                                     // 价格不是最小变动单位的整数倍
+	VALIDATION_AMOUNT_TOO_LOW                                 // 数量低于最小交易量
+	VALIDATION_AMOUNT_TOO_HIGH                                // 数量高于最大交易量
+	VALIDATION_AMOUNT_STEP                                    // 数量不是最小变动单位的整数倍
+	VALIDATION_NOTIONAL_TOO_LOW                               // 成交额低于最小成交额
+	VALIDATION_NOTIONAL_TOO_HIGH                              // 成交额高于最大成交额
+	VALIDATION_INSUFFICIENT_BALANCE                           // 余额不足
+)
+
+func (vc ValidationCode) String() string {
+	switch vc {
+	case VALIDATION_UNKNOWN_PAIR:
+		return "UNKNOWN_PAIR"
+	case VALIDATION_INVALID_PRICE:
+		return "INVALID_PRICE"
+	case VALIDATION_INVALID_AMOUNT:
+		return "INVALID_AMOUNT"
+	case VALIDATION_PRICE_TOO_LOW:
+		return "PRICE_TOO_LOW"
+	case VALIDATION_PRICE_TOO_HIGH:
+		return "PRICE_TOO_HIGH"
+	case VALIDATION_PRICE_TICK:
+		return "PRICE_TICK"
+	case VALIDATION_AMOUNT_TOO_LOW:
+		return "AMOUNT_TOO_LOW"
+	case VALIDATION_AMOUNT_TOO_HIGH:
+		return "AMOUNT_TOO_HIGH"
+	case VALIDATION_AMOUNT_STEP:
+		return "AMOUNT_STEP"
+	case VALIDATION_NOTIONAL_TOO_LOW:
+		return "NOTIONAL_TOO_LOW"
+	case VALIDATION_NOTIONAL_TOO_HIGH:
+		return "NOTIONAL_TOO_HIGH"
+	case VALIDATION_INSUFFICIENT_BALANCE:
+		return "INSUFFICIENT_BALANCE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// 下单校验错误，在请求发送到交易所之前返回
+type ValidationError struct {
+	Code  ValidationCode // 错误原因
+	Pair  CurrencyPair   // 交易对
+	Side  TradeSide      // 交易方向
+	Value float64        // 实际值
+	Limit float64        // 限制值
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("order validation failed, pair:%v, side:%v, code:%v, value:%v, limit:%v",
+		e.Pair, e.Side, e.Code, e.Value, e.Limit)
+}
+
+// 默认交易对信息缓存时长
+const DefaultSymbolCacheTTL = time.Hour
+
+/*
+下单前校验的SpotAPI装饰器。
+缓存GetAllCurrencyPair的结果，在LimitBuy/LimitSell/MarketBuy/MarketSell发送请求之前，
+按交易对的SymbolSetting校验价格和数量，可选校验账户余额是否充足。
+其他接口直接调用被装饰的SpotAPI。
+*/
+type ValidatingSpotAPI struct {
+	SpotAPI
+	// 是否校验余额
+	CheckBalance bool
+	// 交易对信息缓存时长
+	CacheTTL time.Duration
+
+	mutex     sync.Mutex
+	ssm       map[string]SymbolSetting
+	updatedAt time.Time
+}
+
+func NewValidatingSpotAPI(api SpotAPI, checkBalance bool) *ValidatingSpotAPI {
+	return &ValidatingSpotAPI{
+		SpotAPI:      api,
+		CheckBalance: checkBalance,
+		CacheTTL:     DefaultSymbolCacheTTL,
+	}
+}
+
+// 被装饰的SpotAPI的能力描述，未实现CapabilitiesAPI时返回nil
+func (v *ValidatingSpotAPI) Capabilities() *Capabilities {
+	if c, ok := v.SpotAPI.(CapabilitiesAPI); ok {
+		return c.Capabilities()
+	}
+	return nil
+}
+
+// 获取支持的交易对，同时刷新缓存
+func (v *ValidatingSpotAPI) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
+	ssm, err := v.SpotAPI.GetAllCurrencyPair()
+	if err != nil {
+		return nil, err
+	}
+
+	v.mutex.Lock()
+	v.ssm = ssm
+	v.updatedAt = time.Now()
+	v.mutex.Unlock()
+	return ssm, nil
+}
+
+// 获取交易对信息，缓存过期时重新获取
+func (v *ValidatingSpotAPI) GetSymbolSetting(pair CurrencyPair) (ss SymbolSetting, err error) {
+	v.mutex.Lock()
+	ssm := v.ssm
+	expired := ssm == nil || (v.CacheTTL > 0 && time.Since(v.updatedAt) > v.CacheTTL)
+	v.mutex.Unlock()
+
+	if expired {
+		ssm, err = v.GetAllCurrencyPair()
+		if err != nil {
+			return ss, err
+		}
+	}
+
+	ss, ok := ssm[pair.ToSymbol("/")]
+	if !ok {
+		return ss, &ValidationError{Code: VALIDATION_UNKNOWN_PAIR, Pair: pair}
+	}
+	return ss, nil
+}
+
+func (v *ValidatingSpotAPI) LimitBuy(pair CurrencyPair, price, amount string) (*Order, error) {
+	err := v.ValidateOrder(pair, BUY, price, amount)
+	if err != nil {
+		return nil, err
+	}
+	return v.SpotAPI.LimitBuy(pair, price, amount)
+}
+
+func (v *ValidatingSpotAPI) LimitSell(pair CurrencyPair, price, amount string) (*Order, error) {
+	err := v.ValidateOrder(pair, SELL, price, amount)
+	if err != nil {
+		return nil, err
+	}
+	return v.SpotAPI.LimitSell(pair, price, amount)
+}
+
+func (v *ValidatingSpotAPI) MarketBuy(pair CurrencyPair, amount string) (*Order, error) {
+	err := v.ValidateOrder(pair, BUY_MARKET, "", amount)
+	if err != nil {
+		return nil, err
+	}
+	return v.SpotAPI.MarketBuy(pair, amount)
+}
+
+func (v *ValidatingSpotAPI) MarketSell(pair CurrencyPair, amount string) (*Order, error) {
+	err := v.ValidateOrder(pair, SELL_MARKET, "", amount)
+	if err != nil {
+		return nil, err
+	}
+	return v.SpotAPI.MarketSell(pair, amount)
+}
+
+/*
+校验订单，price在市价单时忽略。
+市价买单的amount为计价货币金额，其他订单的amount为基础货币数量。
+*/
+func (v *ValidatingSpotAPI) ValidateOrder(pair CurrencyPair, side TradeSide, price, amount string) error {
+	ss, err := v.GetSymbolSetting(pair)
+	if err != nil {
+		if ve, ok := err.(*ValidationError); ok {
+			ve.Side = side
+		}
+		return err
+	}
+
+	err = ValidateOrder(ss, pair, side, ToFloat64(price), ToFloat64(amount))
+	if err != nil {
+		return err
+	}
+
+	if v.CheckBalance {
+		return v.validateBalance(pair, side, ToFloat64(price), ToFloat64(amount))
+	}
+	return nil
+}
+
+func (v *ValidatingSpotAPI) validateBalance(pair CurrencyPair, side TradeSide, price, amount float64) error {
+	acc, err := v.SpotAPI.GetAccount()
+	if err != nil {
+		return err
+	}
+
+	var currency Currency
+	var need float64
+	switch side {
+	case BUY:
+		currency, need = pair.Money, price*amount
+	case BUY_MARKET:
+		currency, need = pair.Money, amount
+	default:
+		currency, need = pair.Stock, amount
+	}
+
+	available := acc.SubAccounts[currency].Amount
+	if available < need {
+		return &ValidationError{Code: VALIDATION_INSUFFICIENT_BALANCE, Pair: pair, Side: side, Value: need, Limit: available}
+	}
+	return nil
+}
+
+/*
+按交易对信息校验订单，不发送任何请求。
+市价买单的amount为计价货币金额，只校验最小和最大成交额。
+*/
+func ValidateOrder(ss SymbolSetting, pair CurrencyPair, side TradeSide, price, amount float64) error {
+	fail := func(code ValidationCode, value, limit float64) error {
+		return &ValidationError{Code: code, Pair: pair, Side: side, Value: value, Limit: limit}
+	}
+
+	if amount <= 0 || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return fail(VALIDATION_INVALID_AMOUNT, amount, 0)
+	}
+
+	if side == BUY_MARKET {
+		if ss.MinNotional > 0 && amount < ss.MinNotional {
+			return fail(VALIDATION_NOTIONAL_TOO_LOW, amount, ss.MinNotional)
+		}
+		if ss.MaxNotional > 0 && amount > ss.MaxNotional {
+			return fail(VALIDATION_NOTIONAL_TOO_HIGH, amount, ss.MaxNotional)
+		}
+		return nil
+	}
+
+	if ss.MinSize > 0 && amount < ss.MinSize {
+		return fail(VALIDATION_AMOUNT_TOO_LOW, amount, ss.MinSize)
+	}
+	if ss.MaxSize > 0 && amount > ss.MaxSize {
+		return fail(VALIDATION_AMOUNT_TOO_HIGH, amount, ss.MaxSize)
+	}
+	if ss.StepSize > 0 && !isMultipleOf(amount, ss.StepSize) {
+		return fail(VALIDATION_AMOUNT_STEP, amount, ss.StepSize)
+	}
+
+	if side == SELL_MARKET {
+		return nil
+	}
+
+	if price <= 0 || math.IsNaN(price) || math.IsInf(price, 0) {
+		return fail(VALIDATION_INVALID_PRICE, price, 0)
+	}
+	if ss.MinPrice > 0 && price < ss.MinPrice {
+		return fail(VALIDATION_PRICE_TOO_LOW, price, ss.MinPrice)
+	}
+	if ss.MaxPrice > 0 && price > ss.MaxPrice {
+		return fail(VALIDATION_PRICE_TOO_HIGH, price, ss.MaxPrice)
+	}
+	if ss.TickSize > 0 && !isMultipleOf(price, ss.TickSize) {
+		return fail(VALIDATION_PRICE_TICK, price, ss.TickSize)
+	}
+
+	notional := price * amount
+	if ss.MinNotional > 0 && notional < ss.MinNotional {
+		return fail(VALIDATION_NOTIONAL_TOO_LOW, notional, ss.MinNotional)
+	}
+	if ss.MaxNotional > 0 && notional > ss.MaxNotional {
+		return fail(VALIDATION_NOTIONAL_TOO_HIGH, notional, ss.MaxNotional)
+	}
+
+	return nil
+}
+
+// 判断v是否为step的整数倍，允许浮点误差
+func isMultipleOf(v, step float64) bool {
+	n := v / step
+	return math.Abs(n-math.Round(n)) < 1e-6
+}
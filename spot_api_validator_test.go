@@ -0,0 +1,44 @@
+package exapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidatingSpotAPI(t *testing.T) {
+	pair := NewCurrencyPairFromString("btc/usdt")
+	mock := newMockSpotAPI("a")
+	mock.ssm = map[string]SymbolSetting{
+		"BTC/USDT": {Symbol: "BTC/USDT", MinSize: 0.001, MinNotional: 5, TickSize: 0.01, StepSize: 0.001},
+	}
+	mock.acc = &Account{SubAccounts: map[Currency]SubAccount{USDT: {Currency: USDT, Amount: 100}}}
+	api := NewValidatingSpotAPI(mock, true)
+
+	_, err := api.LimitBuy(pair, "1000.00", "0.01")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, mock.placed)
+
+	check := func(err error, code ValidationCode) {
+		ve, ok := err.(*ValidationError)
+		assert.Equal(t, true, ok, "unexpected error %v", err)
+		if ok {
+			assert.Equal(t, code, ve.Code)
+		}
+	}
+
+	_, err = api.LimitBuy(pair, "1000.005", "0.01")
+	check(err, VALIDATION_PRICE_TICK)
+	_, err = api.LimitBuy(pair, "1000", "0.0005")
+	check(err, VALIDATION_AMOUNT_TOO_LOW)
+	_, err = api.LimitBuy(pair, "1000", "0.0015")
+	check(err, VALIDATION_AMOUNT_STEP)
+	_, err = api.LimitBuy(pair, "1000", "0.004")
+	check(err, VALIDATION_NOTIONAL_TOO_LOW)
+	_, err = api.LimitBuy(pair, "1000", "0.2")
+	check(err, VALIDATION_INSUFFICIENT_BALANCE)
+	_, err = api.MarketBuy(pair, "1")
+	check(err, VALIDATION_NOTIONAL_TOO_LOW)
+	_, err = api.LimitBuy(NewCurrencyPairFromString("eth/usdt"), "100", "1")
+	check(err, VALIDATION_UNKNOWN_PAIR)
+	assert.Equal(t, 1, mock.placed)
+}
@@ -43,13 +43,15 @@ type Depth struct {
 }
 
 type Trade struct {
-	Tid    int64        `json:"tid"`           // 交易id
-	Side   TradeSide    `json:"type"`          // 交易方向
-	Amount float64      `json:"amount,string"` // 成交量
-	Price  float64      `json:"price,string"`  // 成交价
-	TS     int64        `json:"ts"`            // 时间，单位为毫秒(millisecond)
-	Market CurrencyPair `json:"market"`        // 交易对
-	Symbol string       `json:"symbol"`        // 交易对
+	Tid         int64        `json:"tid"`           // 交易id
+	Side        TradeSide    `json:"type"`          // 交易方向
+	Amount      float64      `json:"amount,string"` // 成交量
+	Price       float64      `json:"price,string"`  // 成交价
+	TS          int64        `json:"ts"`            // 时间，单位为毫秒(millisecond)
+	Market      CurrencyPair `json:"market"`        // 交易对
+	Symbol      string       `json:"symbol"`        // 交易对
+	Fee         float64      `json:"fee,string"`    // 用户成交的手续费，0表示交易所未返回
+	FeeCurrency Currency     `json:"fee_currency"`  // 手续费币种
 }
 
 type Kline struct {
@@ -61,6 +63,8 @@ type Kline struct {
 	High   float64      `json:"high,string"`  // 最高价
 	Low    float64      `json:"low,string"`   // 最低价
 	Vol    float64      `json:"vol,string"`   // 报价币种成交量
+
+	Resampled bool `json:"resampled,omitempty"` // 是否由较小周期的K线合成
 }
 
 type Order struct {
@@ -88,6 +92,8 @@ type OrderDeal struct {
 	Side             TradeSide    `json:"side"`                      // 交易方向
 	Market           CurrencyPair `json:"market"`                    // 交易对
 	Symbol           string       `json:"symbol"`                    // 交易对
+	Fee              float64      `json:"fee,string"`                // 手续费，0表示交易所未返回
+	FeeCurrency      Currency     `json:"fee_currency"`              // 手续费币种
 }
 
 type SubAccount struct {
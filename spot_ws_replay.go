@@ -0,0 +1,399 @@
+package exapi
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	jsoniter "github.com/json-iterator/go"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// 回放时使用的交易所名称
+const REPLAY = "replay"
+
+// 回放速度
+const (
+	REPLAY_SPEED_FASTEST  = 0 // 不等待，尽可能快地回放
+	REPLAY_SPEED_REALTIME = 1 // 按记录的时间间隔实时回放
+)
+
+/*
+回放文件的一行记录，文件为JSONL格式，每行一条。
+标准化记录：Stream为STREAM_TICKER/STREAM_DEPTH/STREAM_TRADE，Data分别为Ticker/Depth/[]Trade。
+原始帧记录：Stream为空，Raw为交易所推送的原始数据，交由适配器的OnMessage解析。
+*/
+type ReplayRecord struct {
+	TS     int64               `json:"ts"`               // 记录时间，单位为毫秒(millisecond)
+	Stream string              `json:"stream,omitempty"` // 流类型
+	Market CurrencyPair        `json:"market"`           // 交易对
+	Data   jsoniter.RawMessage `json:"data,omitempty"`   // 标准化数据
+	Raw    []byte              `json:"raw,omitempty"`    // 原始帧
+}
+
+// 回放记录写入器，用于录制行情
+type ReplayWriter struct {
+	sync.Mutex
+	w io.Writer
+}
+
+func NewReplayWriter(w io.Writer) *ReplayWriter {
+	return &ReplayWriter{w: w}
+}
+
+func (rw *ReplayWriter) WriteTicker(ticker *Ticker) error {
+	return rw.writeData(STREAM_TICKER, ticker.Market, ticker)
+}
+
+func (rw *ReplayWriter) WriteDepth(depth *Depth) error {
+	return rw.writeData(STREAM_DEPTH, depth.Market, depth)
+}
+
+func (rw *ReplayWriter) WriteTrade(trades []Trade) error {
+	if len(trades) == 0 {
+		return nil
+	}
+	return rw.writeData(STREAM_TRADE, trades[0].Market, trades)
+}
+
+// 写入原始帧，可在适配器的OnMessage之前调用
+func (rw *ReplayWriter) WriteRaw(data []byte) error {
+	return rw.write(&ReplayRecord{TS: nowMillisecond(), Raw: data})
+}
+
+func (rw *ReplayWriter) writeData(stream string, pair CurrencyPair, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return rw.write(&ReplayRecord{TS: nowMillisecond(), Stream: stream, Market: pair, Data: data})
+}
+
+func (rw *ReplayWriter) write(rec *ReplayRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	rw.Lock()
+	defer rw.Unlock()
+	_, err = rw.w.Write(append(line, '\n'))
+	return err
+}
+
+/*
+回放行情的SpotWebsocket实现。
+从录制的文件中读取记录，按记录时间间隔通过SubTicker/SubDepth/SubTrade设置的回调推送。
+decoder不为空时，原始帧交由decoder.OnMessage解析，订阅也转发给decoder，
+decoder不应连接网络，使用适配器提供的NewSpotWsDecoder创建，例如huobi.NewSpotWsDecoder()。
+*/
+type SpotWsReplay struct {
+	sync.Mutex
+	files    []string
+	speed    float64
+	decoder  SpotWebsocket
+	wsURL    string
+	proxyURL string
+	closed   chan struct{}
+	isClosed bool
+
+	onTicker map[string]func(*Ticker) error
+	onDepth  map[string]func(*Depth) error
+	onTrade  map[string]func([]Trade) error
+
+	OnConnect   func(*Connection) error
+	OnHeartBeat func(*Connection) error
+}
+
+// speed为回放倍速，REPLAY_SPEED_REALTIME为实时，大于1为加速，REPLAY_SPEED_FASTEST为不等待
+func NewSpotWsReplay(files []string, speed float64, decoder SpotWebsocket) (*SpotWsReplay, error) {
+	if len(files) == 0 {
+		return nil, errors.New("no replay file")
+	}
+	if speed < 0 {
+		return nil, fmt.Errorf("invalid replay speed:%v", speed)
+	}
+
+	return &SpotWsReplay{
+		files:    files,
+		speed:    speed,
+		decoder:  decoder,
+		closed:   make(chan struct{}),
+		onTicker: make(map[string]func(*Ticker) error),
+		onDepth:  make(map[string]func(*Depth) error),
+		onTrade:  make(map[string]func([]Trade) error),
+	}, nil
+}
+
+func (ws *SpotWsReplay) SetURL(exURL string) {
+	ws.wsURL = exURL
+}
+
+func (ws *SpotWsReplay) GetURL() string {
+	return ws.wsURL
+}
+
+func (ws *SpotWsReplay) SetProxyURL(proxyURL string) {
+	ws.proxyURL = proxyURL
+}
+
+func (ws *SpotWsReplay) GetProxyURL() string {
+	return ws.proxyURL
+}
+
+func (ws *SpotWsReplay) SubTicker(pair CurrencyPair, cb func(*Ticker) error) (err error) {
+	if ws.decoder != nil {
+		return ws.decoder.SubTicker(pair, cb)
+	}
+
+	ws.Lock()
+	defer ws.Unlock()
+	if cb == nil {
+		delete(ws.onTicker, pair.String())
+	} else {
+		ws.onTicker[pair.String()] = cb
+	}
+	return nil
+}
+
+func (ws *SpotWsReplay) SubDepth(pair CurrencyPair, cb func(*Depth) error) (err error) {
+	if ws.decoder != nil {
+		return ws.decoder.SubDepth(pair, cb)
+	}
+
+	ws.Lock()
+	defer ws.Unlock()
+	if cb == nil {
+		delete(ws.onDepth, pair.String())
+	} else {
+		ws.onDepth[pair.String()] = cb
+	}
+	return nil
+}
+
+func (ws *SpotWsReplay) SubTrade(pair CurrencyPair, cb func([]Trade) error) (err error) {
+	if ws.decoder != nil {
+		return ws.decoder.SubTrade(pair, cb)
+	}
+
+	ws.Lock()
+	defer ws.Unlock()
+	if cb == nil {
+		delete(ws.onTrade, pair.String())
+	} else {
+		ws.onTrade[pair.String()] = cb
+	}
+	return nil
+}
+
+// 回放没有真实连接，无需重新订阅
+func (ws *SpotWsReplay) Resubscribe() (err error) {
+	return nil
+}
+
+func (ws *SpotWsReplay) Unsubscribe() (err error) {
+	if ws.decoder != nil {
+		return ws.decoder.Unsubscribe()
+	}
+
+	ws.Lock()
+	defer ws.Unlock()
+	ws.onTicker = make(map[string]func(*Ticker) error)
+	ws.onDepth = make(map[string]func(*Depth) error)
+	ws.onTrade = make(map[string]func([]Trade) error)
+	return nil
+}
+
+func (ws *SpotWsReplay) SetConnectHandler(h func(*Connection) error) {
+	ws.OnConnect = h
+}
+
+func (ws *SpotWsReplay) SetHeartBeatHandler(h func(*Connection) error) {
+	ws.OnHeartBeat = h
+}
+
+// 停止回放
+func (ws *SpotWsReplay) Close() {
+	ws.Lock()
+	defer ws.Unlock()
+	if !ws.isClosed {
+		ws.isClosed = true
+		close(ws.closed)
+	}
+}
+
+func (ws *SpotWsReplay) GetExchangeName() string {
+	if ws.decoder != nil {
+		return ws.decoder.GetExchangeName()
+	}
+	return REPLAY
+}
+
+func (ws *SpotWsReplay) FormatTopicName(topic string, pair CurrencyPair) string {
+	if ws.decoder != nil {
+		return ws.decoder.FormatTopicName(topic, pair)
+	}
+	return topic + "." + pair.String()
+}
+
+func (ws *SpotWsReplay) FormatTopicSubData(topic string, pair CurrencyPair) []byte {
+	return nil
+}
+
+func (ws *SpotWsReplay) FormatTopicUnsubData(topic string, pair CurrencyPair) []byte {
+	return nil
+}
+
+// 解析一条回放记录，并推送给对应的回调
+func (ws *SpotWsReplay) OnMessage(data []byte) error {
+	if data == nil {
+		return nil
+	}
+
+	var rec ReplayRecord
+	err := json.Unmarshal(data, &rec)
+	if err != nil {
+		return err
+	}
+	return ws.dispatch(&rec)
+}
+
+/*
+按顺序回放所有文件，直到文件结束或调用Close。
+该函数会阻塞，需要异步回放时请在协程中调用。
+*/
+func (ws *SpotWsReplay) Play() (err error) {
+	var lastTS int64
+	for _, name := range ws.files {
+		select {
+		case <-ws.closed:
+			return nil
+		default:
+		}
+
+		lastTS, err = ws.playFile(name, lastTS)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ws *SpotWsReplay) playFile(name string, lastTS int64) (int64, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return lastTS, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var rec ReplayRecord
+		err = json.Unmarshal(scanner.Bytes(), &rec)
+		if err != nil {
+			return lastTS, fmt.Errorf("%s:%d: %v", name, line, err)
+		}
+
+		if !ws.wait(lastTS, rec.TS) {
+			return lastTS, nil
+		}
+		lastTS = rec.TS
+
+		err = ws.dispatch(&rec)
+		if err != nil {
+			Error("[ws][%s] replay %s:%d failed:%v", ws.GetExchangeName(), name, line, err)
+		}
+	}
+
+	return lastTS, scanner.Err()
+}
+
+// 按回放速度等待到下一条记录的时间，已关闭时返回false
+func (ws *SpotWsReplay) wait(lastTS, ts int64) bool {
+	if ws.speed == REPLAY_SPEED_FASTEST || lastTS == 0 || ts <= lastTS {
+		select {
+		case <-ws.closed:
+			return false
+		default:
+			return true
+		}
+	}
+
+	d := time.Duration(float64(ts-lastTS) * float64(time.Millisecond) / ws.speed)
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ws.closed:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (ws *SpotWsReplay) dispatch(rec *ReplayRecord) error {
+	if len(rec.Stream) == 0 {
+		if ws.decoder == nil {
+			return errors.New("raw frame without decoder")
+		}
+		return ws.decoder.OnMessage(rec.Raw)
+	}
+
+	if ws.decoder != nil {
+		return fmt.Errorf("normalized record with decoder:%v", rec.Stream)
+	}
+
+	key := rec.Market.String()
+	switch rec.Stream {
+	case STREAM_TICKER:
+		ws.Lock()
+		cb := ws.onTicker[key]
+		ws.Unlock()
+		if cb == nil {
+			return nil
+		}
+		ticker := new(Ticker)
+		if err := json.Unmarshal(rec.Data, ticker); err != nil {
+			return err
+		}
+		return cb(ticker)
+	case STREAM_DEPTH:
+		ws.Lock()
+		cb := ws.onDepth[key]
+		ws.Unlock()
+		if cb == nil {
+			return nil
+		}
+		depth := new(Depth)
+		if err := json.Unmarshal(rec.Data, depth); err != nil {
+			return err
+		}
+		return cb(depth)
+	case STREAM_TRADE:
+		ws.Lock()
+		cb := ws.onTrade[key]
+		ws.Unlock()
+		if cb == nil {
+			return nil
+		}
+		var trades []Trade
+		if err := json.Unmarshal(rec.Data, &trades); err != nil {
+			return err
+		}
+		return cb(trades)
+	default:
+		return fmt.Errorf("unknown stream:%v", rec.Stream)
+	}
+}
+
+func nowMillisecond() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,57 @@
+package exapi
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestSpotWsReplay(t *testing.T) {
+	pair := NewCurrencyPairFromString("btc/usdt")
+	other := NewCurrencyPairFromString("eth/usdt")
+
+	buf := &bytes.Buffer{}
+	rw := NewReplayWriter(buf)
+	assert.Equal(t, nil, rw.WriteTicker(&Ticker{Market: pair, Last: 100}))
+	assert.Equal(t, nil, rw.WriteDepth(&Depth{Market: pair, AskList: DepthRecords{{101, 1}}, BidList: DepthRecords{{99, 2}}}))
+	assert.Equal(t, nil, rw.WriteTrade([]Trade{{Market: pair, Price: 100.5, Amount: 3, Side: BUY}}))
+	assert.Equal(t, nil, rw.WriteTicker(&Ticker{Market: other, Last: 10}))
+	assert.Equal(t, nil, rw.WriteTicker(&Ticker{Market: pair, Last: 102}))
+
+	f, err := ioutil.TempFile("", "replay_*.jsonl")
+	assert.Equal(t, nil, err)
+	defer os.Remove(f.Name())
+	f.Write(buf.Bytes())
+	f.Close()
+
+	ws, err := NewSpotWsReplay([]string{f.Name()}, REPLAY_SPEED_FASTEST, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, REPLAY, ws.GetExchangeName())
+
+	var tickers []float64
+	var depth *Depth
+	var trades []Trade
+	ws.SubTicker(pair, func(ticker *Ticker) error {
+		tickers = append(tickers, ticker.Last)
+		return nil
+	})
+	ws.SubDepth(pair, func(d *Depth) error {
+		depth = d
+		return nil
+	})
+	ws.SubTrade(pair, func(t []Trade) error {
+		trades = append(trades, t...)
+		return nil
+	})
+
+	assert.Equal(t, nil, ws.Play())
+	assert.Equal(t, []float64{100, 102}, tickers)
+	assert.NotEqual(t, (*Depth)(nil), depth)
+	assert.Equal(t, 101.0, depth.AskList[0].Price)
+	assert.Equal(t, 99.0, depth.BidList[0].Price)
+	assert.Equal(t, 1, len(trades))
+	assert.Equal(t, BUY, trades[0].Side)
+	assert.Equal(t, pair, trades[0].Market)
+}
@@ -0,0 +1,44 @@
+package exapi
+
+// perpetual swap api interface
+type SwapAPI interface {
+	// 获取交易所名称
+	GetExchangeName() string
+	// 设置交易所地址，仅在初始化时设置，不支持后续动态更改
+	SetURL(exurl string)
+	// 获取交易所地址
+	GetURL() string
+
+	// 获取所有永续合约，ContractType为SWAP_CONTRACT
+	GetSwapContracts() ([]FutureContract, error)
+
+	// 公共行情
+	// 获取永续合约行情
+	GetSwapTicker(pair CurrencyPair) (*Ticker, error)
+	// 获取永续合约深度
+	GetSwapDepth(pair CurrencyPair, size int) (*Depth, error)
+	// 获取当前资金费率
+	GetFundingRate(pair CurrencyPair) (*FundingRate, error)
+	// 获取历史资金费率，按时间升序排列
+	GetFundingRateHistory(pair CurrencyPair, size int) ([]FundingRate, error)
+	// 获取标记价格和指数价格
+	GetSwapPrice(pair CurrencyPair) (*SwapPrice, error)
+
+	// 交易相关
+	// 下单，openType为OPEN_BUY/OPEN_SELL/CLOSE_BUY/CLOSE_SELL，matchPrice为1时以对手价成交，忽略price
+	PlaceSwapOrder(pair CurrencyPair, price, amount string, openType, matchPrice int) (*FutureOrder, error)
+	// 撤单
+	CancelSwapOrder(orderId string, pair CurrencyPair) (bool, error)
+	// 获取订单详情
+	GetSwapOrder(orderId string, pair CurrencyPair) (*FutureOrder, error)
+	// 获取当前未完成订单列表
+	GetUnfinishSwapOrders(pair CurrencyPair) ([]FutureOrder, error)
+	// 获取持仓
+	GetSwapPosition(pair CurrencyPair) ([]SwapPosition, error)
+	// 获取合约账户权益
+	GetSwapAccount() (*FutureAccount, error)
+	// 设置杠杆倍数
+	SetSwapLeverage(pair CurrencyPair, leverRate int) error
+	// 设置保证金模式
+	SetSwapMarginMode(pair CurrencyPair, mode MarginMode) error
+}
@@ -0,0 +1,74 @@
+package exapi
+
+// 保证金模式
+type MarginMode int
+
+const (
+	MARGIN_CROSSED  MarginMode = 1 + iota // 全仓
+	MARGIN_ISOLATED                       // 逐仓
+)
+
+func (mm MarginMode) String() string {
+	switch mm {
+	case MARGIN_CROSSED:
+		return "CROSSED"
+	case MARGIN_ISOLATED:
+		return "ISOLATED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// 持仓方向
+type PositionSide int
+
+const (
+	POSITION_LONG  PositionSide = 1 + iota // 多仓
+	POSITION_SHORT                         // 空仓
+)
+
+func (ps PositionSide) String() string {
+	switch ps {
+	case POSITION_LONG:
+		return "LONG"
+	case POSITION_SHORT:
+		return "SHORT"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// 资金费率
+type FundingRate struct {
+	Market          CurrencyPair `json:"market"`                // 交易对
+	Symbol          string       `json:"symbol"`                // 交易对
+	Rate            float64      `json:"rate,string"`           // 资金费率，历史记录中为实际收取的费率
+	EstimatedRate   float64      `json:"estimated_rate,string"` // 下一期预测费率，历史记录中为0
+	FundingTime     int64        `json:"funding_time"`          // 本期收取时间，单位为毫秒(millisecond)
+	NextFundingTime int64        `json:"next_funding_time"`     // 下期收取时间，单位为毫秒(millisecond)，历史记录中为0
+}
+
+// 标记价格和指数价格
+type SwapPrice struct {
+	Market     CurrencyPair `json:"market"`             // 交易对
+	Symbol     string       `json:"symbol"`             // 交易对
+	MarkPrice  float64      `json:"mark_price,string"`  // 标记价格
+	IndexPrice float64      `json:"index_price,string"` // 指数价格
+	TS         int64        `json:"ts"`                 // 时间，单位为毫秒(millisecond)
+}
+
+// 永续合约持仓，多空各一条
+type SwapPosition struct {
+	Market           CurrencyPair `json:"market"`                   // 交易对
+	Symbol           string       `json:"symbol"`                   // 交易对
+	Side             PositionSide `json:"side"`                     // 持仓方向
+	Amount           float64      `json:"amount,string"`            // 持仓量，单位为张（币安为基础币种数量）
+	Available        float64      `json:"available,string"`         // 可平量
+	AvgPrice         float64      `json:"avg_price,string"`         // 开仓均价
+	LiquidationPrice float64      `json:"liquidation_price,string"` // 预估强平价
+	Leverage         int          `json:"leverage"`                 // 杠杆倍数
+	MarginMode       MarginMode   `json:"margin_mode"`              // 保证金模式
+	Margin           float64      `json:"margin,string"`            // 占用保证金
+	RealizedPnl      float64      `json:"realized_pnl,string"`      // 已实现盈亏
+	UnrealizedPnl    float64      `json:"unrealized_pnl,string"`    // 未实现盈亏
+}
@@ -0,0 +1,129 @@
+package exapi
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 交易对名称查不到时重新获取交易对的最小间隔
+const DefaultSymbolIndexRetry = time.Minute
+
+/*
+交易所交易对名称到CurrencyPair的索引。
+用GetAllCurrencyPair的结果构建，首次查询时获取，缓存过期后重新获取。
+交易对名称不区分大小写，忽略分隔符，例如btcusdt、BTC_USDT、BTC-USDT都对应BTC/USDT，
+有币种别名时交易所名称和统一名称都可以查到，例如zb的bccusdt和bchusdt都对应BCH/USDT。
+查不到时重新获取一次，间隔不小于DefaultSymbolIndexRetry，仍查不到时返回错误。
+*/
+type SymbolIndex struct {
+	sync.Mutex
+	// 缓存时长，0表示不过期
+	CacheTTL time.Duration
+	// 查不到时重新获取的最小间隔
+	RetryInterval time.Duration
+
+	exchange  string
+	fetch     func() (map[string]SymbolSetting, error)
+	pairs     map[string]CurrencyPair
+	updatedAt time.Time
+	triedAt   time.Time
+}
+
+// exchange为交易所名字，用于转换币种别名，load一般为交易所的GetAllCurrencyPair
+func NewSymbolIndex(exchange string, load func() (map[string]SymbolSetting, error)) *SymbolIndex {
+	return &SymbolIndex{
+		CacheTTL:      DefaultSymbolCacheTTL,
+		RetryInterval: DefaultSymbolIndexRetry,
+		exchange:      exchange,
+		fetch:         load,
+	}
+}
+
+// 重新获取交易对
+func (si *SymbolIndex) Refresh() error {
+	si.Lock()
+	defer si.Unlock()
+	return si.refresh()
+}
+
+// 用已获取的交易对更新索引，key为BASE/QUOTE格式
+func (si *SymbolIndex) Update(ssm map[string]SymbolSetting) {
+	si.Lock()
+	si.update(ssm)
+	si.Unlock()
+}
+
+// 获取交易对，缓存过期时重新获取，获取失败且没有缓存时返回错误
+func (si *SymbolIndex) Load() error {
+	si.Lock()
+	defer si.Unlock()
+	return si.load()
+}
+
+// 交易所交易对名称对应的交易对
+func (si *SymbolIndex) Pair(symbol string) (CurrencyPair, error) {
+	si.Lock()
+	defer si.Unlock()
+
+	if err := si.load(); err != nil {
+		return UNKNOWN_PAIR, err
+	}
+
+	key := normalizeSymbol(symbol)
+	if pair, ok := si.pairs[key]; ok {
+		return pair, nil
+	}
+
+	// 可能是新上线的交易对
+	if time.Since(si.triedAt) >= si.RetryInterval {
+		if err := si.refresh(); err != nil {
+			return UNKNOWN_PAIR, err
+		}
+		if pair, ok := si.pairs[key]; ok {
+			return pair, nil
+		}
+	}
+	return UNKNOWN_PAIR, fmt.Errorf("unknown symbol:%v", symbol)
+}
+
+func (si *SymbolIndex) load() error {
+	if si.pairs == nil || (si.CacheTTL > 0 && time.Since(si.updatedAt) > si.CacheTTL) {
+		if err := si.refresh(); err != nil && si.pairs == nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (si *SymbolIndex) refresh() error {
+	si.triedAt = time.Now()
+	ssm, err := si.fetch()
+	if err != nil {
+		return err
+	}
+	si.update(ssm)
+	return nil
+}
+
+func (si *SymbolIndex) update(ssm map[string]SymbolSetting) {
+	pairs := make(map[string]CurrencyPair, len(ssm))
+	for k, ss := range ssm {
+		pair := NewCurrencyPairFromString(k)
+		if ss.Base != "" && ss.Quote != "" {
+			pair = NewCurrencyPair(NewCurrency(ss.Base), NewCurrency(ss.Quote))
+		}
+		if pair.Stock.Symbol() == "" || pair.Money.Symbol() == "" {
+			continue
+		}
+		pairs[normalizeSymbol(pair.ToSymbol(""))] = pair
+		pairs[normalizeSymbol(ExchangeSymbol(si.exchange, pair, ""))] = pair
+	}
+	si.pairs = pairs
+	si.updatedAt = time.Now()
+}
+
+func normalizeSymbol(symbol string) string {
+	return strings.NewReplacer("_", "", "-", "", "/", "").Replace(strings.ToUpper(symbol))
+}
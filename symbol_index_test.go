@@ -0,0 +1,45 @@
+package exapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSymbolIndex(t *testing.T) {
+	loads := 0
+	ssm := map[string]SymbolSetting{
+		"BTC/USDT":  {Base: "BTC", Quote: "USDT"},
+		"ETH/BTC":   {Base: "eth", Quote: "btc"},
+		"DOGE/BUSD": {},
+	}
+	si := NewSymbolIndex(HUOBI, func() (map[string]SymbolSetting, error) {
+		loads++
+		return ssm, nil
+	})
+
+	pair, err := si.Pair("btcusdt")
+	assert.Nil(t, err)
+	assert.Equal(t, "BTC/USDT", pair.ToSymbol("/"))
+	pair, err = si.Pair("ETH_BTC")
+	assert.Nil(t, err)
+	assert.Equal(t, "ETH/BTC", pair.ToSymbol("/"))
+	pair, err = si.Pair("DOGEBUSD")
+	assert.Nil(t, err)
+	assert.Equal(t, "DOGE/BUSD", pair.ToSymbol("/"))
+	assert.Equal(t, 1, loads)
+
+	// 新上线的交易对，间隔内只重新获取一次
+	ssm = map[string]SymbolSetting{"NEW/FDUSD": {Base: "NEW", Quote: "FDUSD"}}
+	si.RetryInterval = 0
+	pair, err = si.Pair("newfdusd")
+	assert.Nil(t, err)
+	assert.Equal(t, "NEW/FDUSD", pair.ToSymbol("/"))
+	assert.Equal(t, 2, loads)
+
+	si.RetryInterval = DefaultSymbolIndexRetry
+	_, err = si.Pair("abcxyz")
+	assert.NotNil(t, err)
+	_, err = si.Pair("abcxyz")
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, loads)
+}
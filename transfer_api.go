@@ -0,0 +1,28 @@
+package exapi
+
+/*
+设置账户类型的接口，huobi、binance和okex的SpotAPI都实现了此接口，
+可以对BuildSpot返回的SpotAPI断言后调用，或在构建时通过APIBuilder.AccountType设置。
+*/
+type AccountTypeAPI interface {
+	// 设置SpotAPI.GetAccount读取的账户类型，默认为现货账户，交易所不支持的类型返回ErrorUnsupported
+	SetAccountType(accountType AccountType) error
+}
+
+// asset transfer api interface
+type TransferAPI interface {
+	// 获取交易所名称
+	GetExchangeName() string
+	// 设置交易所地址，仅在初始化时设置，不支持后续动态更改
+	SetURL(exurl string)
+	// 获取交易所地址
+	GetURL() string
+
+	AccountTypeAPI
+	// 获取指定类型账户的余额，逐仓杠杆账户请使用MarginAPI，合约账户请使用FutureRestAPI或SwapAPI
+	GetAccountByType(accountType AccountType) (*Account, error)
+	// 在同一用户的不同类型账户之间划转，涉及逐仓杠杆账户时pair为杠杆币对，返回划转id
+	Transfer(currency Currency, amount string, from, to AccountType, pair CurrencyPair) (string, error)
+	// 母子账户之间划转，toSub为true时从母账户转入子账户，否则从子账户转回母账户，返回划转id
+	SubAccountTransfer(subAccount string, currency Currency, amount string, toSub bool) (string, error)
+}
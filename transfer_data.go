@@ -0,0 +1,32 @@
+package exapi
+
+// 账户类型
+type AccountType int
+
+const (
+	ACCOUNT_SPOT    AccountType = 1 + iota // 现货账户
+	ACCOUNT_MARGIN                         // 逐仓杠杆账户，每个币对一个
+	ACCOUNT_FUTURE                         // 交割合约账户
+	ACCOUNT_SWAP                           // 永续合约账户
+	ACCOUNT_FUNDING                        // 资金账户
+	ACCOUNT_POINT                          // 点卡账户
+)
+
+func (at AccountType) String() string {
+	switch at {
+	case ACCOUNT_SPOT:
+		return "SPOT"
+	case ACCOUNT_MARGIN:
+		return "MARGIN"
+	case ACCOUNT_FUTURE:
+		return "FUTURE"
+	case ACCOUNT_SWAP:
+		return "SWAP"
+	case ACCOUNT_FUNDING:
+		return "FUNDING"
+	case ACCOUNT_POINT:
+		return "POINT"
+	default:
+		return "UNKNOWN"
+	}
+}
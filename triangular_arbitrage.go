@@ -0,0 +1,354 @@
+package exapi
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 三角套利的一步，用From币种换To币种
+type TriangularLeg struct {
+	Market CurrencyPair `json:"market"`        // 交易对
+	Side   TradeSide    `json:"side"`          // BUY为用报价币种买入基础币种，SELL为卖出基础币种
+	From   string       `json:"from"`          // 换出的币种
+	To     string       `json:"to"`            // 换入的币种
+	Price  float64      `json:"price,string"`  // 成交价，买入为最优卖价，卖出为最优买价
+	Amount float64      `json:"amount,string"` // 基础币种的成交数量，没有深度数据时为0
+}
+
+// 三角套利机会，按Legs的顺序依次成交，从Path[0]换回Path[0]
+type TriangularOpportunity struct {
+	Exchange    string          `json:"exchange"`            // 交易所名字
+	Path        []string        `json:"path"`                // 币种路径，例如USDT、BTC、ETH、USDT
+	Legs        []TriangularLeg `json:"legs"`                // 三步交易
+	StartAmount float64         `json:"start_amount,string"` // 投入的起始币种数量，受各步最优价的挂单数量限制，没有深度数据时为0
+	EndAmount   float64         `json:"end_amount,string"`   // 换回的起始币种数量，已扣除taker手续费
+	Profit      float64         `json:"profit,string"`       // 利润，起始币种，没有深度数据时为单位起始币种的利润
+	ProfitRate  float64         `json:"profit_rate,string"`  // 利润率
+	TS          int64           `json:"ts"`                  // 发现时间，单位为毫秒(millisecond)
+}
+
+type triangularQuote struct {
+	bid, ask         float64
+	bidSize, askSize float64 // 最优价的挂单数量，0表示未知
+}
+
+// 三角中的一条边，用from换to
+type triangularEdge struct {
+	symbol string
+	from   string
+	to     string
+	buy    bool // symbol为to/from时买入，为from/to时卖出
+}
+
+/*
+交易所内的三角套利检测。
+用GetAllCurrencyPair的交易对构建币种图，找出所有三个币种组成的环，
+行情或深度更新时计算相关的环，扣除每步的taker手续费后利润率不低于最小利润率时回调cb。
+行情可以来自GetAllTicker轮询(OnTickers/Poll)或websocket推送(OnTicker/OnDepth)，
+只有深度提供最优价的挂单数量，用于限制可执行的数量。
+*/
+type TriangularDetector struct {
+	sync.Mutex
+	exchange      string
+	settings      map[string]SymbolSetting
+	quotes        map[string]*triangularQuote
+	cycles        [][3]triangularEdge
+	bySymbol      map[string][]int // 交易对所在的环
+	minProfitRate float64
+	cb            func(*TriangularOpportunity) error
+}
+
+/*
+ssm为交易所GetAllCurrencyPair的结果。
+starts为起始币种，例如USDT，为空时每个三角只从字母序最小的币种开始计算，避免同一个环重复回调。
+*/
+func NewTriangularDetector(exchange string, ssm map[string]SymbolSetting, starts []string,
+	cb func(*TriangularOpportunity) error) *TriangularDetector {
+	td := &TriangularDetector{
+		exchange: exchange,
+		settings: make(map[string]SymbolSetting),
+		quotes:   make(map[string]*triangularQuote),
+		bySymbol: make(map[string][]int),
+		cb:       cb,
+	}
+
+	// 币种之间的交易对
+	links := make(map[string]map[string]string)
+	link := func(a, b, symbol string) {
+		if links[a] == nil {
+			links[a] = make(map[string]string)
+		}
+		links[a][b] = symbol
+	}
+	for _, ss := range ssm {
+		base, quote := strings.ToUpper(ss.Base), strings.ToUpper(ss.Quote)
+		symbol := base + "/" + quote
+		td.settings[symbol] = ss
+		link(base, quote, symbol)
+		link(quote, base, symbol)
+	}
+
+	startSet := make(map[string]bool)
+	for _, s := range starts {
+		startSet[strings.ToUpper(s)] = true
+	}
+
+	neighbors := func(a string) []string {
+		var cs []string
+		for c := range links[a] {
+			cs = append(cs, c)
+		}
+		sort.Strings(cs)
+		return cs
+	}
+	var currencies []string
+	for c := range links {
+		currencies = append(currencies, c)
+	}
+	sort.Strings(currencies)
+
+	edge := func(from, to string) triangularEdge {
+		symbol := links[from][to]
+		return triangularEdge{symbol: symbol, from: from, to: to, buy: symbol == to+"/"+from}
+	}
+	for _, a := range currencies {
+		if len(startSet) > 0 && !startSet[a] {
+			continue
+		}
+		for _, b := range neighbors(a) {
+			for _, c := range neighbors(b) {
+				if c == a || links[c][a] == "" {
+					continue
+				}
+				if len(startSet) == 0 && (b < a || c < a) {
+					continue
+				}
+				cycle := [3]triangularEdge{edge(a, b), edge(b, c), edge(c, a)}
+				for _, e := range cycle {
+					td.bySymbol[e.symbol] = append(td.bySymbol[e.symbol], len(td.cycles))
+				}
+				td.cycles = append(td.cycles, cycle)
+			}
+		}
+	}
+	return td
+}
+
+// 用api获取交易对构建检测器
+func NewTriangularDetectorFromAPI(api SpotAPI, starts []string, cb func(*TriangularOpportunity) error) (*TriangularDetector, error) {
+	ssm, err := api.GetAllCurrencyPair()
+	if err != nil {
+		return nil, err
+	}
+	return NewTriangularDetector(api.GetExchangeName(), ssm, starts, cb), nil
+}
+
+// 设置最小利润率，默认为0，即扣除手续费后有利润即回调
+func (td *TriangularDetector) SetMinProfitRate(rate float64) {
+	td.Lock()
+	td.minProfitRate = rate
+	td.Unlock()
+}
+
+// 环中用到的交易对，可用于订阅行情或深度
+func (td *TriangularDetector) Pairs() []CurrencyPair {
+	td.Lock()
+	defer td.Unlock()
+
+	pairs := make([]CurrencyPair, 0, len(td.bySymbol))
+	for symbol := range td.bySymbol {
+		pairs = append(pairs, NewCurrencyPairFromString(symbol))
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].ToSymbol("/") < pairs[j].ToSymbol("/")
+	})
+	return pairs
+}
+
+// 订阅ws中环所用交易对的深度，注意ws每个交易对只有最后一次设置的回调生效
+func (td *TriangularDetector) Subscribe(ws SpotWebsocket) error {
+	for _, pair := range td.Pairs() {
+		if err := ws.SubDepth(pair, td.OnDepth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 用api.GetAllTicker获取所有行情并计算
+func (td *TriangularDetector) Poll(api SpotAPI) error {
+	tickers, err := api.GetAllTicker()
+	if err != nil {
+		return err
+	}
+	return td.OnTickers(tickers)
+}
+
+// 处理批量行情，更新后计算所有的环
+func (td *TriangularDetector) OnTickers(tickers []Ticker) error {
+	td.Lock()
+	for i := range tickers {
+		td.updateTicker(&tickers[i])
+	}
+	opportunities := td.evaluateAll()
+	td.Unlock()
+
+	return td.emit(opportunities)
+}
+
+// 处理推送的行情，可作为SubTicker的回调
+func (td *TriangularDetector) OnTicker(ticker *Ticker) error {
+	td.Lock()
+	symbol := td.updateTicker(ticker)
+	opportunities := td.evaluateSymbol(symbol)
+	td.Unlock()
+
+	return td.emit(opportunities)
+}
+
+// 处理推送的深度，只使用最优价，可作为SubDepth的回调
+func (td *TriangularDetector) OnDepth(depth *Depth) error {
+	if len(depth.AskList) == 0 || len(depth.BidList) == 0 {
+		return nil
+	}
+
+	td.Lock()
+	symbol := strings.ToUpper(depth.Market.ToSymbol("/"))
+	td.quotes[symbol] = &triangularQuote{
+		bid:     depth.BidList[0].Price,
+		ask:     depth.AskList[0].Price,
+		bidSize: depth.BidList[0].Amount,
+		askSize: depth.AskList[0].Amount,
+	}
+	opportunities := td.evaluateSymbol(symbol)
+	td.Unlock()
+
+	return td.emit(opportunities)
+}
+
+// 当前所有的套利机会
+func (td *TriangularDetector) Scan() []TriangularOpportunity {
+	td.Lock()
+	defer td.Unlock()
+	return td.evaluateAll()
+}
+
+// 行情没有挂单数量，价格变化时挂单数量变为未知
+func (td *TriangularDetector) updateTicker(ticker *Ticker) string {
+	symbol := strings.ToUpper(ticker.Market.ToSymbol("/"))
+	q, ok := td.quotes[symbol]
+	if !ok {
+		q = &triangularQuote{}
+		td.quotes[symbol] = q
+	}
+	if q.bid != ticker.Buy {
+		q.bid, q.bidSize = ticker.Buy, 0
+	}
+	if q.ask != ticker.Sell {
+		q.ask, q.askSize = ticker.Sell, 0
+	}
+	return symbol
+}
+
+func (td *TriangularDetector) evaluateAll() []TriangularOpportunity {
+	var opportunities []TriangularOpportunity
+	for i := range td.cycles {
+		if op, ok := td.evaluate(td.cycles[i]); ok {
+			opportunities = append(opportunities, op)
+		}
+	}
+	return opportunities
+}
+
+func (td *TriangularDetector) evaluateSymbol(symbol string) []TriangularOpportunity {
+	var opportunities []TriangularOpportunity
+	for _, i := range td.bySymbol[symbol] {
+		if op, ok := td.evaluate(td.cycles[i]); ok {
+			opportunities = append(opportunities, op)
+		}
+	}
+	return opportunities
+}
+
+func (td *TriangularDetector) evaluate(cycle [3]triangularEdge) (TriangularOpportunity, bool) {
+	op := TriangularOpportunity{
+		Exchange: td.exchange,
+		Path:     []string{cycle[0].from, cycle[1].from, cycle[2].from, cycle[0].from},
+		Legs:     make([]TriangularLeg, 3),
+	}
+
+	// rate为每单位起始币种换到的数量，limit为按挂单数量可投入的起始币种
+	rate, limit, sized := 1.0, math.Inf(1), true
+	for i, e := range cycle {
+		q, ok := td.quotes[e.symbol]
+		if !ok || q.bid <= 0 || q.ask <= 0 {
+			return TriangularOpportunity{}, false
+		}
+		fee := 1 - td.settings[e.symbol].TakerFee
+
+		before := rate
+		leg := TriangularLeg{Market: NewCurrencyPairFromString(e.symbol), From: e.from, To: e.to}
+		var size, input float64 // input为最优价挂单可接受的换出币种数量
+		if e.buy {
+			leg.Side, leg.Price, size = BUY, q.ask, q.askSize
+			input = size * q.ask
+			rate = rate / q.ask * fee
+		} else {
+			leg.Side, leg.Price, size = SELL, q.bid, q.bidSize
+			input = size
+			rate = rate * q.bid * fee
+		}
+		if size <= 0 {
+			sized = false
+		} else {
+			limit = math.Min(limit, input/before)
+		}
+		op.Legs[i] = leg
+	}
+
+	op.ProfitRate = rate - 1
+	if op.ProfitRate <= 0 || op.ProfitRate < td.minProfitRate {
+		return TriangularOpportunity{}, false
+	}
+	op.Profit = op.ProfitRate
+	op.TS = time.Now().UnixNano() / int64(time.Millisecond)
+
+	if sized {
+		// 按投入数量计算每步的成交数量
+		amount := limit
+		for i, e := range cycle {
+			q := td.quotes[e.symbol]
+			ss := td.settings[e.symbol]
+			fee := 1 - ss.TakerFee
+			if e.buy {
+				op.Legs[i].Amount = amount / q.ask
+				amount = amount / q.ask * fee
+			} else {
+				op.Legs[i].Amount = amount
+				amount = amount * q.bid * fee
+			}
+			if op.Legs[i].Amount < ss.MinSize || op.Legs[i].Amount*op.Legs[i].Price < ss.MinNotional {
+				return TriangularOpportunity{}, false
+			}
+		}
+		op.StartAmount = limit
+		op.EndAmount = amount
+		op.Profit = amount - limit
+	}
+	return op, true
+}
+
+func (td *TriangularDetector) emit(opportunities []TriangularOpportunity) error {
+	if td.cb == nil {
+		return nil
+	}
+	for i := range opportunities {
+		if err := td.cb(&opportunities[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
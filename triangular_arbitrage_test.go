@@ -0,0 +1,51 @@
+package exapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTriangularDetector(t *testing.T) {
+	ssm := map[string]SymbolSetting{
+		"BTC/USDT": {Base: "BTC", Quote: "USDT", TakerFee: 0.001},
+		"ETH/BTC":  {Base: "ETH", Quote: "BTC", TakerFee: 0.001},
+		"ETH/USDT": {Base: "ETH", Quote: "USDT", TakerFee: 0.001},
+		"EOS/USDT": {Base: "EOS", Quote: "USDT", TakerFee: 0.001},
+	}
+	var found []TriangularOpportunity
+	td := NewTriangularDetector("test", ssm, []string{"usdt"}, func(op *TriangularOpportunity) error {
+		found = append(found, *op)
+		return nil
+	})
+	assert.Equal(t, 3, len(td.Pairs()))
+
+	assert.Nil(t, td.OnTickers([]Ticker{
+		{Market: NewCurrencyPairFromString("BTC/USDT"), Buy: 99.9, Sell: 100},
+		{Market: NewCurrencyPairFromString("ETH/BTC"), Buy: 0.0499, Sell: 0.05},
+		{Market: NewCurrencyPairFromString("ETH/USDT"), Buy: 5.2, Sell: 5.21},
+	}))
+	assert.Equal(t, 1, len(found))
+	op := found[0]
+	assert.Equal(t, []string{"USDT", "BTC", "ETH", "USDT"}, op.Path)
+	assert.Equal(t, BUY, op.Legs[0].Side)
+	assert.Equal(t, BUY, op.Legs[1].Side)
+	assert.Equal(t, SELL, op.Legs[2].Side)
+	rate := 1 / 100.0 * 0.999 / 0.05 * 0.999 * 5.2 * 0.999
+	assert.InDelta(t, rate-1, op.ProfitRate, 1e-9)
+	assert.Equal(t, 0.0, op.StartAmount)
+
+	// 深度提供挂单数量，ETH/BTC的卖一只有10个ETH
+	found = nil
+	assert.Nil(t, td.OnDepth(&Depth{Market: NewCurrencyPairFromString("BTC/USDT"), AskList: DepthRecords{{Price: 100, Amount: 1}}, BidList: DepthRecords{{Price: 99.9, Amount: 1}}}))
+	assert.Nil(t, td.OnDepth(&Depth{Market: NewCurrencyPairFromString("ETH/BTC"), AskList: DepthRecords{{Price: 0.05, Amount: 10}}, BidList: DepthRecords{{Price: 0.0499, Amount: 10}}}))
+	assert.Nil(t, td.OnDepth(&Depth{Market: NewCurrencyPairFromString("ETH/USDT"), AskList: DepthRecords{{Price: 5.21, Amount: 100}}, BidList: DepthRecords{{Price: 5.2, Amount: 100}}}))
+	op = found[len(found)-1]
+	start := 0.5 / (0.01 * 0.999)
+	assert.InDelta(t, start, op.StartAmount, 1e-9)
+	assert.InDelta(t, 10, op.Legs[1].Amount, 1e-9)
+	assert.InDelta(t, start*rate, op.EndAmount, 1e-9)
+	assert.InDelta(t, start*(rate-1), op.Profit, 1e-9)
+
+	td.SetMinProfitRate(0.05)
+	assert.Equal(t, 0, len(td.Scan()))
+}
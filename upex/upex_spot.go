@@ -35,8 +35,8 @@ type Upex struct {
 	apiKey     string
 	secretKey  string
 
-	// 用于币种转换
-	symbolMap map[string]CurrencyPair
+	// 交易对名称到交易对的索引
+	symbols *SymbolIndex
 }
 
 /**
@@ -48,6 +48,7 @@ func NewSpotAPI(client *http.Client, apiKey, secretkey string) SpotAPI {
 	upex.httpClient = client
 	upex.apiKey = apiKey
 	upex.secretKey = secretkey
+	upex.symbols = NewSymbolIndex(UPEX, upex.GetAllCurrencyPair)
 	return upex
 }
 
@@ -63,6 +64,17 @@ func (upex *Upex) GetURL() string {
 	return upex.baseUrl
 }
 
+// upex没有成交记录、K线和websocket接口
+var spotCapabilities = &Capabilities{
+	Exchange:           UPEX,
+	UnsupportedMethods: []string{"GetTrades", "GetKlineRecords", "GetUserTrades"},
+	OrderSides:         AllOrderSides,
+}
+
+func (upex *Upex) Capabilities() *Capabilities {
+	return spotCapabilities.Clone()
+}
+
 func (upex *Upex) getDataMap(reqUrl string) (map[string]interface{}, error) {
 	respmap, err := HttpGet(upex.httpClient, reqUrl)
 	if err != nil {
@@ -109,6 +121,25 @@ func (upex *Upex) getDataArray(reqUrl string) ([]interface{}, error) {
 	return dataArr, nil
 }
 
+// upex没有账户费率查询接口，返回币对公布的费率
+func (upex *Upex) GetTradeFee(pair CurrencyPair) (*TradeFee, error) {
+	tfm, err := upex.GetTradeFeeMap()
+	if err != nil {
+		return nil, err
+	}
+
+	return TradeFeeOf(tfm, pair)
+}
+
+func (upex *Upex) GetTradeFeeMap() (map[string]TradeFee, error) {
+	ssm, err := upex.GetAllCurrencyPair()
+	if err != nil {
+		return nil, err
+	}
+
+	return TradeFeeMapOf(ssm), nil
+}
+
 func (upex *Upex) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
 	url := upex.baseUrl + "/common/symbols"
 	dataArr, err := upex.getDataArray(url)
@@ -126,19 +157,25 @@ func (upex *Upex) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
 		quote := ToString(obj["count_coin"])
 		symbol := base + "/" + quote
 
+		pricePrecision := ToInt(obj["price_precision"])
+		amountPrecision := ToInt(obj["amount_precision"])
 		ssm[symbol] = SymbolSetting{
 			Symbol:   symbol,
 			Base:     base,
 			Quote:    quote,
-			MinSize:  math.Pow10(-ToInt(obj["amount_precision"])),
-			MinPrice: math.Pow10(-ToInt(obj["price_precision"])),
+			MinSize:  math.Pow10(-amountPrecision),
+			MinPrice: math.Pow10(-pricePrecision),
 			//MinNotional: ToFloat64(obj["minTrade"]),
-			MakerFee: 0.002,
-			TakerFee: 0.002,
+			MakerFee:        0.002,
+			TakerFee:        0.002,
+			TickSize:        math.Pow10(-pricePrecision),
+			StepSize:        math.Pow10(-amountPrecision),
+			PricePrecision:  pricePrecision,
+			AmountPrecision: amountPrecision,
 		}
 	}
 
-	return ssm, nil
+	return CanonicalSymbolSettings(UPEX, ssm), nil
 }
 
 func (upex *Upex) GetCurrencyStatus(currency Currency) (CurrencyStatus, error) {
@@ -202,7 +239,7 @@ func (upex *Upex) parseTicker(pair CurrencyPair, tickmap map[string]interface{},
 }
 
 func (upex *Upex) GetTicker(pair CurrencyPair) (*Ticker, error) {
-	url := upex.baseUrl + "/get_ticker?symbol=" + pair.ToLowerSymbol("")
+	url := upex.baseUrl + "/get_ticker?symbol=" + ExchangeLowerSymbol(UPEX, pair, "")
 
 	datamap, err := upex.getDataMap(url)
 	if err != nil {
@@ -213,11 +250,9 @@ func (upex *Upex) GetTicker(pair CurrencyPair) (*Ticker, error) {
 }
 
 func (upex *Upex) GetAllTicker() ([]Ticker, error) {
-	if upex.symbolMap == nil {
-		err := upex.initSymbolToPair()
-		if err != nil {
-			return nil, err
-		}
+	// 交易对索引获取失败时无法转换交易对名称
+	if err := upex.symbols.Load(); err != nil {
+		return nil, err
 	}
 
 	url := upex.baseUrl + "/get_allticker"
@@ -246,7 +281,13 @@ func (upex *Upex) GetAllTicker() ([]Ticker, error) {
 			continue
 		}
 
-		t, err := upex.parseTicker(upex.symbolToPair(symbol), obj, ts)
+		pair, err := upex.symbols.Pair(symbol)
+		if err != nil {
+			Error("parse ticker failed:%v", err)
+			continue
+		}
+
+		t, err := upex.parseTicker(pair, obj, ts)
 		if err != nil {
 			Error("parse ticker failed:%v", err)
 			continue
@@ -258,7 +299,7 @@ func (upex *Upex) GetAllTicker() ([]Ticker, error) {
 }
 
 func (upex *Upex) GetDepth(pair CurrencyPair, size int, step int) (*Depth, error) {
-	url := upex.baseUrl + "/market_dept?type=step0&symbol=" + pair.ToLowerSymbol("")
+	url := upex.baseUrl + "/market_dept?type=step0&symbol=" + ExchangeLowerSymbol(UPEX, pair, "")
 	datamap, err := upex.getDataMap(url)
 	if err != nil {
 		return nil, err
@@ -358,6 +399,10 @@ func (upex *Upex) GetKlineRecords(pair CurrencyPair, period KlinePeriod, size, s
 	return nil, ErrorUnsupported
 }
 
+func (upex *Upex) GetKlineRange(pair CurrencyPair, period KlinePeriod, start, end time.Time) ([]Kline, []KlineGap, error) {
+	return nil, nil, ErrorUnsupported
+}
+
 func (upex *Upex) LimitBuy(pair CurrencyPair, price, amount string) (*Order, error) {
 	orderId, err := upex.placeOrder(amount, price, pair, "BUY", "1")
 	if err != nil {
@@ -420,7 +465,7 @@ func (upex *Upex) Cancel(orderId string, pair CurrencyPair) (bool, error) {
 	requrl := upex.baseUrl + "/cancel_order"
 	params := map[string]string{}
 	params["order_id"] = orderId
-	params["symbol"] = pair.ToLowerSymbol("")
+	params["symbol"] = ExchangeLowerSymbol(UPEX, pair, "")
 
 	_, err := upex.httpPost(requrl, params)
 	if err != nil {
@@ -435,7 +480,7 @@ func (upex *Upex) GetOrder(orderId string, pair CurrencyPair) (*Order, error) {
 	requrl := upex.baseUrl + "/order_info"
 	params := map[string]string{}
 	params["order_id"] = orderId
-	params["symbol"] = pair.ToLowerSymbol("")
+	params["symbol"] = ExchangeLowerSymbol(UPEX, pair, "")
 
 	respmap, err := upex.httpGet(requrl, params)
 	if err != nil {
@@ -459,7 +504,7 @@ func (upex *Upex) GetOrder(orderId string, pair CurrencyPair) (*Order, error) {
 func (upex *Upex) GetPendingOrders(pair CurrencyPair) ([]Order, error) {
 	requrl := upex.baseUrl + "/v2/new_order"
 	params := map[string]string{}
-	params["symbol"] = pair.ToLowerSymbol("")
+	params["symbol"] = ExchangeLowerSymbol(UPEX, pair, "")
 	params["pageSize"] = fmt.Sprint(100)
 	params["page"] = fmt.Sprint(1)
 
@@ -490,7 +535,7 @@ func (upex *Upex) GetPendingOrders(pair CurrencyPair) ([]Order, error) {
 func (upex *Upex) GetFinishedOrders(pair CurrencyPair) ([]Order, error) {
 	requrl := upex.baseUrl + "/v2/all_order"
 	params := map[string]string{}
-	params["symbol"] = pair.ToLowerSymbol("")
+	params["symbol"] = ExchangeLowerSymbol(UPEX, pair, "")
 	params["pageSize"] = fmt.Sprint(100)
 	params["page"] = fmt.Sprint(1)
 
@@ -525,7 +570,7 @@ func (upex *Upex) GetOrderDeal(orderId string, pair CurrencyPair) ([]OrderDeal,
 	requrl := upex.baseUrl + "/order_info"
 	params := map[string]string{}
 	params["order_id"] = orderId
-	params["symbol"] = pair.ToLowerSymbol("")
+	params["symbol"] = ExchangeLowerSymbol(UPEX, pair, "")
 
 	respmap, err := upex.httpGet(requrl, params)
 	if err != nil {
@@ -594,7 +639,7 @@ func (upex *Upex) GetAccount() (*Account, error) {
 		*/
 		balancemap := v.(map[string]interface{})
 		currencySymbol := balancemap["coin"].(string)
-		currency := NewCurrency(currencySymbol)
+		currency := CanonicalCurrency(UPEX, currencySymbol)
 
 		acc.SubAccounts[currency] = SubAccount{
 			Currency:     currency,
@@ -607,26 +652,6 @@ func (upex *Upex) GetAccount() (*Account, error) {
 	return acc, nil
 }
 
-func (upex *Upex) initSymbolToPair() error {
-	ssm, err := upex.GetAllCurrencyPair()
-	if err != nil {
-		Error("%v", err)
-		return err
-	}
-
-	upex.symbolMap = make(map[string]CurrencyPair)
-	for _, v := range ssm {
-		s := strings.ToLower(v.Base + v.Quote)
-		upex.symbolMap[s] = NewCurrencyPair(NewCurrency(v.Base), NewCurrency(v.Quote))
-	}
-
-	return nil
-}
-
-func (upex *Upex) symbolToPair(symbol string) (pair CurrencyPair) {
-	return upex.symbolMap[symbol]
-}
-
 func (upex *Upex) placeOrder(amount, price string, pair CurrencyPair, side, orderType string) (string, error) {
 	/*
 
@@ -639,7 +664,7 @@ func (upex *Upex) placeOrder(amount, price string, pair CurrencyPair, side, orde
 	*/
 	requrl := upex.baseUrl + "/create_order"
 	params := map[string]string{}
-	params["symbol"] = pair.ToLowerSymbol("")
+	params["symbol"] = ExchangeLowerSymbol(UPEX, pair, "")
 	params["side"] = side
 	params["type"] = orderType
 	params["volume"] = amount
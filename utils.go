@@ -128,6 +128,20 @@ func FloatToString(v float64, n int) string {
 	return fmt.Sprintf(fmt.Sprintf("%%.%df", n), v)
 }
 
+// 计算最小变动单位的小数位数，如0.001返回3，10返回0
+func PrecisionOf(step float64) int {
+	if step <= 0 {
+		return 0
+	}
+
+	s := strconv.FormatFloat(step, 'f', -1, 64)
+	p := strings.IndexByte(s, '.')
+	if p < 0 {
+		return 0
+	}
+	return len(s) - p - 1
+}
+
 func ValuesToJson(v url.Values) ([]byte, error) {
 	parammap := make(map[string]interface{})
 	for k, vv := range v {
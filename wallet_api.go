@@ -0,0 +1,22 @@
+package exapi
+
+// deposit and withdrawal api interface
+type WalletAPI interface {
+	// 获取交易所名称
+	GetExchangeName() string
+	// 设置交易所地址，仅在初始化时设置，不支持后续动态更改
+	SetURL(exurl string)
+	// 获取交易所地址
+	GetURL() string
+
+	// 获取充币地址，chain为空时使用币种的默认链
+	GetDepositAddress(currency Currency, chain string) (*DepositAddress, error)
+	// 提币，返回提币记录id，tag不需要时为空，chain为空时使用币种的默认链
+	Withdraw(currency Currency, amount, address, tag, chain string) (string, error)
+	// 撤销提币
+	CancelWithdraw(id string, currency Currency) (bool, error)
+	// 获取最近的充币记录，按时间降序排列
+	GetDepositHistory(currency Currency) ([]WalletRecord, error)
+	// 获取最近的提币记录，按时间降序排列
+	GetWithdrawHistory(currency Currency) ([]WalletRecord, error)
+}
@@ -0,0 +1,68 @@
+package exapi
+
+// 充提币记录类型
+type WalletRecordType int
+
+const (
+	WALLET_DEPOSIT  WalletRecordType = 1 + iota // 充币
+	WALLET_WITHDRAW                             // 提币
+)
+
+func (wt WalletRecordType) String() string {
+	switch wt {
+	case WALLET_DEPOSIT:
+		return "DEPOSIT"
+	case WALLET_WITHDRAW:
+		return "WITHDRAW"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// 充提币状态
+type WalletStatus int
+
+const (
+	WALLET_PENDING  WalletStatus = 1 + iota // 处理中，包括审核、确认中
+	WALLET_SUCCESS                          // 已完成
+	WALLET_FAILED                           // 失败或被拒绝
+	WALLET_CANCELED                         // 已撤销
+)
+
+func (ws WalletStatus) String() string {
+	switch ws {
+	case WALLET_PENDING:
+		return "PENDING"
+	case WALLET_SUCCESS:
+		return "SUCCESS"
+	case WALLET_FAILED:
+		return "FAILED"
+	case WALLET_CANCELED:
+		return "CANCELED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// 充币地址
+type DepositAddress struct {
+	Currency Currency `json:"currency"` // 币种
+	Chain    string   `json:"chain"`    // 链名称，如ERC20、TRC20，交易所不区分链时为空
+	Address  string   `json:"address"`  // 地址
+	Tag      string   `json:"tag"`      // 标签(memo)，不需要时为空
+}
+
+// 充提币记录
+type WalletRecord struct {
+	Id       string           `json:"id"`            // 记录id
+	Type     WalletRecordType `json:"type"`          // 充币或提币
+	Currency Currency         `json:"currency"`      // 币种
+	Chain    string           `json:"chain"`         // 链名称
+	Amount   float64          `json:"amount,string"` // 数量
+	Fee      float64          `json:"fee,string"`    // 手续费
+	Address  string           `json:"address"`       // 充币时为充币地址，提币时为目标地址
+	Tag      string           `json:"tag"`           // 标签(memo)
+	TxId     string           `json:"tx_id"`         // 链上交易哈希
+	Status   WalletStatus     `json:"status"`        // 状态
+	TS       int64            `json:"ts"`            // 创建时间，单位为毫秒(millisecond)
+}
@@ -37,6 +37,8 @@ type Zb struct {
 	httpClient *http.Client
 	accessKey,
 	secretKey string
+	safePwd string       // 资金安全密码，提币时使用
+	symbols *SymbolIndex // 交易对名称到交易对的索引
 }
 
 func NewSpotAPI(client *http.Client, apiKey, secretKey string) SpotAPI {
@@ -44,6 +46,7 @@ func NewSpotAPI(client *http.Client, apiKey, secretKey string) SpotAPI {
 		accessKey:  apiKey,
 		secretKey:  secretKey,
 		httpClient: client}
+	zb.symbols = NewSymbolIndex(ZB, zb.GetAllCurrencyPair)
 	return zb
 }
 
@@ -59,40 +62,53 @@ func (zb *Zb) GetURL() string {
 	return TRADE_URL
 }
 
-/*
-"maker": "0.001",
-"taker": "0.0015",
-"timestamp": "2019-12-05T09:06:20.260Z"
-*/
-type TradeFee struct {
-	Maker float64 `json:"maker,string"` // 挂单手续费
-	Taker float64 `json:"taker,string"` // 吃单手续费
+var spotCapabilities = &Capabilities{
+	Exchange:           ZB,
+	UnsupportedMethods: []string{"GetOrderDeal", "GetUserTrades", "MarketBuy", "MarketSell"},
+	KlinePeriods:       KlinePeriodsOf(_INERNAL_KLINE_PERIOD_CONVERTER),
+	MaxKlineSize:       1000,
+	MaxDepthSize:       50,
+	OrderSides:         []TradeSide{BUY, SELL},
+	WebsocketStreams:   AllStreams,
 }
 
-func (zb *Zb) GetTradeFee() (tf *TradeFee, err error) {
-	tf = &TradeFee{
-		Maker: 0.002,
-		Taker: 0.002,
+func (zb *Zb) Capabilities() *Capabilities {
+	return spotCapabilities.Clone()
+}
+
+// zb没有账户费率查询接口，返回币对公布的费率
+func (zb *Zb) GetTradeFee(pair CurrencyPair) (*TradeFee, error) {
+	tfm, err := zb.GetTradeFeeMap()
+	if err != nil {
+		return nil, err
 	}
-	return tf, nil
+
+	return TradeFeeOf(tfm, pair)
 }
 
-func (zb *Zb) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
-	resp, err := HttpGet(zb.httpClient, MARKET_URL+"markets")
+func (zb *Zb) GetTradeFeeMap() (map[string]TradeFee, error) {
+	ssm, err := zb.GetAllCurrencyPair()
 	if err != nil {
 		return nil, err
 	}
 
-	tf, err := zb.GetTradeFee()
+	return TradeFeeMapOf(ssm), nil
+}
+
+func (zb *Zb) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
+	resp, err := HttpGet(zb.httpClient, MARKET_URL+"markets")
 	if err != nil {
 		return nil, err
 	}
+
 	ssm := make(map[string]SymbolSetting)
 	for k, v := range resp {
 		symbol := strings.ToUpper(strings.Replace(k, "_", "/", -1))
 		currencies := strings.Split(symbol, "/")
 
 		obj, _ := v.(map[string]interface{})
+		pricePrecision := ToInt(obj["priceScale"])
+		amountPrecision := ToInt(obj["amountScale"])
 		ssm[symbol] = SymbolSetting{
 			Symbol: symbol,
 			Base:   currencies[0],
@@ -100,14 +116,18 @@ func (zb *Zb) GetAllCurrencyPair() (map[string]SymbolSetting, error) {
 			// 用浮点数的pow会有精度损失，在后面取整数位数时会不准
 			//MinSize:  math.Pow(0.1, ToFloat64(obj["amountScale"])),
 			//MinPrice:math.Pow(0.1, ToFloat64(obj["priceScale"])),
-			MinSize:  math.Pow10(-ToInt(obj["amountScale"])),
-			MinPrice: math.Pow10(-ToInt(obj["priceScale"])),
-			MakerFee: tf.Maker,
-			TakerFee: tf.Taker,
+			MinSize:         math.Pow10(-amountPrecision),
+			MinPrice:        math.Pow10(-pricePrecision),
+			MakerFee:        0.002,
+			TakerFee:        0.002,
+			TickSize:        math.Pow10(-pricePrecision),
+			StepSize:        math.Pow10(-amountPrecision),
+			PricePrecision:  pricePrecision,
+			AmountPrecision: amountPrecision,
 		}
 	}
 
-	return ssm, nil
+	return CanonicalSymbolSettings(ZB, ssm), nil
 }
 
 func (zb *Zb) GetCurrencyStatus(currency Currency) (CurrencyStatus, error) {
@@ -119,7 +139,25 @@ func (zb *Zb) GetCurrencyStatus(currency Currency) (CurrencyStatus, error) {
 		}, err
 	}
 
-	return all[currency.Symbol()], nil
+	cs := all[currency.Symbol()]
+	// 各链的提币手续费需要签名请求，未设置密钥时只返回充提状态
+	if len(zb.accessKey) == 0 {
+		return cs, nil
+	}
+
+	// 获取链信息失败时仍返回充提状态，Chains为空
+	chains, err := zb.getChainStatus(currency)
+	if err != nil {
+		Error("[%s] getChainStatus %v failed:%v", ZB, currency, err)
+		return cs, nil
+	}
+
+	for i := range chains {
+		chains[i].Deposit = cs.Deposit
+		chains[i].Withdraw = cs.Withdraw
+	}
+	cs.Chains = chains
+	return cs, nil
 }
 
 func (zb *Zb) GetAllCurrencyStatus() (all map[string]CurrencyStatus, err error) {
@@ -153,11 +191,11 @@ func (zb *Zb) GetAllCurrencyStatus() (all map[string]CurrencyStatus, err error)
 		}
 	}
 
-	return all, nil
+	return CanonicalCurrencyStatus(ZB, all), nil
 }
 
 func (zb *Zb) GetTicker(pair CurrencyPair) (*Ticker, error) {
-	symbol := pair.ToSymbol("_")
+	symbol := ExchangeSymbol(ZB, pair, "_")
 	resp, err := HttpGet(zb.httpClient, MARKET_URL+fmt.Sprintf("ticker?market=%s", symbol))
 	if err != nil {
 		return nil, err
@@ -182,6 +220,11 @@ func (zb *Zb) GetTicker(pair CurrencyPair) (*Ticker, error) {
 }
 
 func (zb *Zb) GetAllTicker() ([]Ticker, error) {
+	// 交易对索引获取失败时无法转换交易对名称
+	if err := zb.symbols.Load(); err != nil {
+		return nil, err
+	}
+
 	resp, err := HttpGet(zb.httpClient, MARKET_URL+"allTicker")
 	if err != nil {
 		return nil, err
@@ -195,13 +238,13 @@ func (zb *Zb) GetAllTicker() ([]Ticker, error) {
 			continue
 		}
 
-		base, quote := getSymbol(k)
-		if len(base) == 0 || len(quote) == 0 {
+		pair, err := zb.symbols.Pair(k)
+		if err != nil {
 			continue
 		}
 		ticker := Ticker{}
-		ticker.Symbol = base + "/" + quote
-		ticker.Market = NewCurrencyPairFromString(ticker.Symbol)
+		ticker.Symbol = pair.ToLowerSymbol("/")
+		ticker.Market = pair
 		ticker.Last, _ = strconv.ParseFloat(tickermap["last"].(string), 64)
 		ticker.High, _ = strconv.ParseFloat(tickermap["high"].(string), 64)
 		ticker.Low, _ = strconv.ParseFloat(tickermap["low"].(string), 64)
@@ -215,21 +258,8 @@ func (zb *Zb) GetAllTicker() ([]Ticker, error) {
 	return tickers, nil
 }
 
-func getSymbol(market string) (base, quote string) {
-	fiatCoin := []string{"usdt", "qc", "btc"}
-
-	for _, v := range fiatCoin {
-		if strings.HasSuffix(market, v) {
-			p := strings.LastIndex(market, v)
-			return market[0:p], v
-		}
-	}
-
-	return "", ""
-}
-
 func (zb *Zb) GetDepth(pair CurrencyPair, size int, step int) (*Depth, error) {
-	symbol := pair.ToSymbol("_")
+	symbol := ExchangeSymbol(ZB, pair, "_")
 	resp, err := HttpGet(zb.httpClient, MARKET_URL+fmt.Sprintf("depth?market=%s&size=%d", symbol, size))
 	if err != nil {
 		return nil, err
@@ -271,7 +301,7 @@ func (zb *Zb) GetDepth(pair CurrencyPair, size int, step int) (*Depth, error) {
 }
 
 func (zb *Zb) GetTrades(pair CurrencyPair, size int) ([]Trade, error) {
-	symbol := pair.ToSymbol("_")
+	symbol := ExchangeSymbol(ZB, pair, "_")
 	resp, err := HttpGet3(zb.httpClient, MARKET_URL+fmt.Sprintf("trades?market=%v", symbol), nil)
 	if err != nil {
 		return nil, err
@@ -312,12 +342,31 @@ func (zb *Zb) GetTrades(pair CurrencyPair, size int) ([]Trade, error) {
 }
 
 func (zb *Zb) GetKlineRecords(pair CurrencyPair, period KlinePeriod, size, since int) ([]Kline, error) {
-	symbol := pair.ToSymbol("_")
+	symbol := ExchangeSymbol(ZB, pair, "_")
 	periodS, isOk := _INERNAL_KLINE_PERIOD_CONVERTER[period]
 	if isOk != true {
-		return nil, fmt.Errorf("unsupported %v KlinePeriod:%v", zb.GetExchangeName(), period)
+		// 交易所不支持的周期，用较小周期的K线合成
+		return GetKlinesWithResample(spotCapabilities, func(p KlinePeriod, n, t int) ([]Kline, error) {
+			return zb.GetKlineRecords(pair, p, n, t)
+		}, period, size, since)
 	}
-	resp, err := HttpGet(zb.httpClient, MARKET_URL+fmt.Sprintf("kline?market=%v&type=%v", symbol, periodS))
+	return zb.getKlines(pair, fmt.Sprintf("kline?market=%v&type=%v", symbol, periodS))
+}
+
+// zb按区间获取K线时的请求间隔，以免超过频率限制
+const ZB_KLINE_RANGE_INTERVAL = 100 * time.Millisecond
+
+// zb只支持按开始时间since查询，每页最多返回MaxKlineSize根，超出to的部分由FetchKlineRange截取
+func (zb *Zb) GetKlineRange(pair CurrencyPair, period KlinePeriod, start, end time.Time) ([]Kline, []KlineGap, error) {
+	symbol := ExchangeSymbol(ZB, pair, "_")
+	return FetchKlineRange(spotCapabilities, func(p KlinePeriod, from, to int64) ([]Kline, error) {
+		return zb.getKlines(pair, fmt.Sprintf("kline?market=%v&type=%v&since=%v&size=%v",
+			symbol, _INERNAL_KLINE_PERIOD_CONVERTER[p], from*1000, spotCapabilities.MaxKlineSize))
+	}, period, start, end, ZB_KLINE_RANGE_INTERVAL)
+}
+
+func (zb *Zb) getKlines(pair CurrencyPair, query string) ([]Kline, error) {
+	resp, err := HttpGet(zb.httpClient, MARKET_URL+query)
 	if err != nil {
 		return nil, err
 	}
@@ -381,7 +430,7 @@ func (zb *Zb) MarketSell(pair CurrencyPair, amount string) (*Order, error) {
 }
 
 func (zb *Zb) Cancel(orderId string, pair CurrencyPair) (bool, error) {
-	symbol := pair.ToSymbol("_")
+	symbol := ExchangeSymbol(ZB, pair, "_")
 	params := url.Values{}
 	params.Set("method", "cancelOrder")
 	params.Set("id", orderId)
@@ -408,7 +457,7 @@ func (zb *Zb) Cancel(orderId string, pair CurrencyPair) (bool, error) {
 }
 
 func (zb *Zb) GetOrder(orderId string, pair CurrencyPair) (*Order, error) {
-	symbol := pair.ToSymbol("_")
+	symbol := ExchangeSymbol(ZB, pair, "_")
 	params := url.Values{}
 	params.Set("method", "getOrder")
 	params.Set("id", orderId)
@@ -435,7 +484,7 @@ func (zb *Zb) GetOrder(orderId string, pair CurrencyPair) (*Order, error) {
 
 func (zb *Zb) GetPendingOrders(pair CurrencyPair) ([]Order, error) {
 	params := url.Values{}
-	symbol := pair.ToSymbol("_")
+	symbol := ExchangeSymbol(ZB, pair, "_")
 	params.Set("method", "getUnfinishedOrdersIgnoreTradeType")
 	params.Set("currency", symbol)
 	params.Set("pageIndex", "1")
@@ -528,7 +577,7 @@ func (zb *Zb) GetAccount() (*Account, error) {
 		subAcc := SubAccount{}
 		subAcc.Amount = ToFloat64(vv["available"])
 		subAcc.FrozenAmount = ToFloat64(vv["freez"])
-		subAcc.Currency = NewCurrency(vv["key"].(string))
+		subAcc.Currency = CanonicalCurrency(ZB, vv["key"].(string))
 		acc.SubAccounts[subAcc.Currency] = subAcc
 	}
 
@@ -538,7 +587,7 @@ func (zb *Zb) GetAccount() (*Account, error) {
 // 返回最近的n条记录
 func (zb *Zb) GetOrders(pair CurrencyPair, size int) ([]Order, error) {
 	params := url.Values{}
-	symbol := pair.ToSymbol("_")
+	symbol := ExchangeSymbol(ZB, pair, "_")
 	params.Set("method", "getOrdersIgnoreTradeType")
 	params.Set("currency", symbol)
 	params.Set("pageIndex", "1")
@@ -574,60 +623,6 @@ func (zb *Zb) GetOrders(pair CurrencyPair, size int) ([]Order, error) {
 	return orders, nil
 }
 
-func (zb *Zb) Withdraw(amount string, currency Currency, fees, receiveAddr, safePwd string) (string, error) {
-	params := url.Values{}
-	params.Set("method", "withdraw")
-	params.Set("currency", currency.LowerSymbol())
-	params.Set("amount", amount)
-	params.Set("fees", fees)
-	params.Set("receiveAddr", receiveAddr)
-	params.Set("safePwd", safePwd)
-	zb.buildPostForm(&params)
-
-	resp, err := HttpPostForm(zb.httpClient, TRADE_URL+"withdraw", params)
-	if err != nil {
-		return "", err
-	}
-
-	respMap := make(map[string]interface{})
-	err = json.Unmarshal(resp, &respMap)
-	if err != nil {
-		return "", err
-	}
-
-	if respMap["code"].(float64) == 1000 {
-		return respMap["id"].(string), nil
-	}
-
-	return "", errors.New(string(resp))
-}
-
-func (zb *Zb) CancelWithdraw(id string, currency Currency, safePwd string) (bool, error) {
-	params := url.Values{}
-	params.Set("method", "cancelWithdraw")
-	params.Set("currency", currency.LowerSymbol())
-	params.Set("downloadId", id)
-	params.Set("safePwd", safePwd)
-	zb.buildPostForm(&params)
-
-	resp, err := HttpPostForm(zb.httpClient, TRADE_URL+"cancelWithdraw", params)
-	if err != nil {
-		return false, err
-	}
-
-	respMap := make(map[string]interface{})
-	err = json.Unmarshal(resp, &respMap)
-	if err != nil {
-		return false, err
-	}
-
-	if respMap["code"].(float64) == 1000 {
-		return true, nil
-	}
-
-	return false, errors.New(string(resp))
-}
-
 func (zb *Zb) buildPostForm(postForm *url.Values) error {
 	postForm.Set("accesskey", zb.accessKey)
 
@@ -679,7 +674,7 @@ func parseOrder(order *Order, ordermap map[string]interface{}) {
 }
 
 func (zb *Zb) placeOrder(amount, price string, pair CurrencyPair, tradeType int) (*Order, error) {
-	symbol := pair.ToSymbol("_")
+	symbol := ExchangeSymbol(ZB, pair, "_")
 	params := url.Values{}
 	params.Set("method", "order")
 	params.Set("price", price)
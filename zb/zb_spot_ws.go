@@ -37,7 +37,7 @@ func (ws *ZbSpotWs) GetExchangeName() string {
 
 // 格式化流名称
 func (ws *ZbSpotWs) FormatTopicName(topic string, pair CurrencyPair) string {
-	symbol := pair.ToLowerSymbol("")
+	symbol := ExchangeLowerSymbol(ZB, pair, "")
 	switch topic {
 	case STREAM_TICKER:
 		return fmt.Sprintf("%v_ticker", symbol)
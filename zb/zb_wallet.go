@@ -0,0 +1,255 @@
+package zb
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/betterjun/exapi"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/**
+ * deposit and withdrawal
+ */
+func NewWalletAPI(client *http.Client, apiKey, secretKey, safePwd string) WalletAPI {
+	zb := NewSpotAPI(client, apiKey, secretKey).(*Zb)
+	zb.safePwd = safePwd
+	return zb
+}
+
+// 私有接口请求，返回message.datas字段
+func (zb *Zb) doWalletRequest(method string, params url.Values) (map[string]interface{}, error) {
+	params.Set("method", method)
+	zb.buildPostForm(&params)
+	resp, err := HttpPostForm(zb.httpClient, TRADE_URL+method, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var respmap map[string]interface{}
+	err = json.Unmarshal(resp, &respmap)
+	if err != nil {
+		return nil, err
+	}
+
+	if ToInt(respmap["code"]) != 1000 {
+		return nil, errors.New(string(resp))
+	}
+
+	message, _ := respmap["message"].(map[string]interface{})
+	datas, _ := message["datas"].(map[string]interface{})
+	return datas, nil
+}
+
+// zb不区分链
+func (zb *Zb) GetDepositAddress(currency Currency, chain string) (*DepositAddress, error) {
+	params := url.Values{}
+	params.Set("currency", ExchangeCurrency(ZB, currency).LowerSymbol())
+	datas, err := zb.doWalletRequest("getUserAddress", params)
+	if err != nil {
+		return nil, err
+	}
+
+	addr := ToString(datas["key"])
+	if len(addr) == 0 {
+		return nil, errors.New("deposit address not found")
+	}
+
+	return &DepositAddress{Currency: currency, Address: addr}, nil
+}
+
+// 通过getFeeInfo获取各链的提币手续费，zb不单独返回各链的充提状态
+func (zb *Zb) getChainStatus(currency Currency) ([]ChainStatus, error) {
+	params := url.Values{}
+	params.Set("currency", ExchangeCurrency(ZB, currency).LowerSymbol())
+	datas, err := zb.doWalletRequest("getFeeInfo", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var chains []ChainStatus
+	for k, v := range datas {
+		if !strings.EqualFold(k, ExchangeCurrency(ZB, currency).Symbol()) {
+			continue
+		}
+
+		arr, _ := v.([]interface{})
+		for i, c := range arr {
+			obj := c.(map[string]interface{})
+			chains = append(chains, ChainStatus{
+				Chain:       ToString(obj["chainName"]),
+				IsDefault:   i == 0,
+				WithdrawFee: ToFloat64(obj["fee"]),
+			})
+		}
+	}
+
+	if len(chains) == 0 {
+		return nil, ErrorAssetNotFound
+	}
+	return chains, nil
+}
+
+// 获取提币手续费，chain为空时使用第一条链
+// withdraw接口不能指定链，只支持第一条链，其他链返回ErrorUnsupported
+func (zb *Zb) getWithdrawFee(currency Currency, chain string) (string, error) {
+	chains, err := zb.getChainStatus(currency)
+	if err != nil {
+		return "", err
+	}
+
+	cs, ok := CurrencyStatus{Chains: chains}.GetChain(chain)
+	if !ok {
+		return "", ErrorAssetNotFound
+	}
+	if !cs.IsDefault {
+		return "", ErrorUnsupported
+	}
+	return strconv.FormatFloat(cs.WithdrawFee, 'f', -1, 64), nil
+}
+
+// 使用交易所的提币手续费，不支持带标签的地址，只支持默认链
+func (zb *Zb) Withdraw(currency Currency, amount, address, tag, chain string) (string, error) {
+	if len(tag) > 0 {
+		return "", ErrorUnsupported
+	}
+
+	fees, err := zb.getWithdrawFee(currency, chain)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("method", "withdraw")
+	params.Set("currency", ExchangeCurrency(ZB, currency).LowerSymbol())
+	params.Set("amount", amount)
+	params.Set("fees", fees)
+	params.Set("receiveAddr", address)
+	params.Set("safePwd", zb.safePwd)
+	zb.buildPostForm(&params)
+
+	resp, err := HttpPostForm(zb.httpClient, TRADE_URL+"withdraw", params)
+	if err != nil {
+		return "", err
+	}
+
+	respMap := make(map[string]interface{})
+	err = json.Unmarshal(resp, &respMap)
+	if err != nil {
+		return "", err
+	}
+
+	if ToInt(respMap["code"]) == 1000 {
+		return ToString(respMap["id"]), nil
+	}
+
+	return "", errors.New(string(resp))
+}
+
+func (zb *Zb) CancelWithdraw(id string, currency Currency) (bool, error) {
+	params := url.Values{}
+	params.Set("method", "cancelWithdraw")
+	params.Set("currency", ExchangeCurrency(ZB, currency).LowerSymbol())
+	params.Set("downloadId", id)
+	params.Set("safePwd", zb.safePwd)
+	zb.buildPostForm(&params)
+
+	resp, err := HttpPostForm(zb.httpClient, TRADE_URL+"cancelWithdraw", params)
+	if err != nil {
+		return false, err
+	}
+
+	respMap := make(map[string]interface{})
+	err = json.Unmarshal(resp, &respMap)
+	if err != nil {
+		return false, err
+	}
+
+	if ToInt(respMap["code"]) == 1000 {
+		return true, nil
+	}
+
+	return false, errors.New(string(resp))
+}
+
+func (zb *Zb) GetDepositHistory(currency Currency) ([]WalletRecord, error) {
+	params := url.Values{}
+	params.Set("currency", ExchangeCurrency(ZB, currency).LowerSymbol())
+	params.Set("pageIndex", "1")
+	params.Set("pageSize", "100")
+	datas, err := zb.doWalletRequest("getChargeRecord", params)
+	if err != nil {
+		return nil, err
+	}
+
+	list, _ := datas["list"].([]interface{})
+	records := make([]WalletRecord, 0, len(list))
+	for _, v := range list {
+		obj := v.(map[string]interface{})
+		submitTime, _ := time.ParseInLocation("2006-01-02 15:04:05", ToString(obj["submit_time"]), time.Local)
+		record := WalletRecord{
+			Id:       fmt.Sprint(ToInt64(obj["id"])),
+			Type:     WALLET_DEPOSIT,
+			Currency: currency,
+			Amount:   ToFloat64(obj["amount"]),
+			Address:  ToString(obj["address"]),
+			TxId:     ToString(obj["hash"]),
+			TS:       submitTime.UnixNano() / int64(time.Millisecond),
+		}
+		// 0:确认中 1:失败 2:成功
+		switch ToInt(obj["status"]) {
+		case 1:
+			record.Status = WALLET_FAILED
+		case 2:
+			record.Status = WALLET_SUCCESS
+		default:
+			record.Status = WALLET_PENDING
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func (zb *Zb) GetWithdrawHistory(currency Currency) ([]WalletRecord, error) {
+	params := url.Values{}
+	params.Set("currency", ExchangeCurrency(ZB, currency).LowerSymbol())
+	params.Set("pageIndex", "1")
+	params.Set("pageSize", "100")
+	datas, err := zb.doWalletRequest("getWithdrawRecord", params)
+	if err != nil {
+		return nil, err
+	}
+
+	list, _ := datas["list"].([]interface{})
+	records := make([]WalletRecord, 0, len(list))
+	for _, v := range list {
+		obj := v.(map[string]interface{})
+		record := WalletRecord{
+			Id:       fmt.Sprint(ToInt64(obj["id"])),
+			Type:     WALLET_WITHDRAW,
+			Currency: currency,
+			Amount:   ToFloat64(obj["amount"]),
+			Fee:      ToFloat64(obj["fees"]),
+			Address:  ToString(obj["toAddress"]),
+			TS:       ToInt64(obj["submitTime"]),
+		}
+		// 0:提交 1:失败 2:成功 3:取消 5:转账中
+		switch ToInt(obj["status"]) {
+		case 1:
+			record.Status = WALLET_FAILED
+		case 2:
+			record.Status = WALLET_SUCCESS
+		case 3:
+			record.Status = WALLET_CANCELED
+		default:
+			record.Status = WALLET_PENDING
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}